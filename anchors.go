@@ -0,0 +1,195 @@
+package yaml
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	yaml3 "gopkg.in/yaml.v3"
+)
+
+// Anchor is an anchor defined in a YAML document, as Anchors reports it.
+type Anchor struct {
+	Name string
+	// Document is the index of the document in the YAML stream.
+	Document int
+	// Path is the path of the anchored value, such as "defaults.limits", or
+	// "" for the whole document, and Pos its position.
+	Path string
+	Pos  Position
+	// Value is the anchored value, decoded as Get decodes it, with the
+	// aliases it holds expanded.
+	Value interface{}
+	// Aliases are the aliases that refer to the anchor, in the order of the
+	// document.
+	Aliases []Alias
+}
+
+// Alias is an alias that refers to an Anchor.
+type Alias struct {
+	// Path is the path the alias stands in for, such as
+	// "staging.limits", and Pos its position.
+	Path string
+	Pos  Position
+}
+
+// Anchors returns the anchors defined in the YAML stream y, in the order of
+// the stream, along with the aliases that refer to them, to explain where
+// the values of documents that share values through aliases come from. An
+// anchor that is defined twice is reported twice, each with the aliases
+// that refer to that definition.
+func Anchors(y []byte) ([]Anchor, error) {
+	y, err := toUTF8(y)
+	if err != nil {
+		return nil, err
+	}
+	var anchors []Anchor
+	d := yaml3.NewDecoder(bytes.NewReader(y))
+	for doc := 0; ; doc++ {
+		var n yaml3.Node
+		if err := d.Decode(&n); err == io.EOF {
+			return anchors, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if err := checkRecursiveAliases(&n); err != nil {
+			return nil, err
+		}
+		// defined holds the indexes in anchors of the anchored nodes.
+		defined := map[*yaml3.Node]int{}
+		var walk func(n *yaml3.Node, path *keyPath) error
+		walk = func(n *yaml3.Node, path *keyPath) error {
+			if n.Kind == yaml3.AliasNode {
+				if i, ok := defined[n.Alias]; ok {
+					a := &anchors[i]
+					a.Aliases = append(a.Aliases, Alias{path.String(), Position{n.Line, n.Column}})
+				}
+				return nil
+			}
+			if n.Anchor != "" {
+				b, err := yaml3.Marshal(expandAliases(n))
+				if err != nil {
+					return err
+				}
+				var v interface{}
+				if err := Unmarshal(b, &v); err != nil {
+					return err
+				}
+				defined[n] = len(anchors)
+				anchors = append(anchors, Anchor{
+					Name:     n.Anchor,
+					Document: doc,
+					Path:     path.String(),
+					Pos:      Position{n.Line, n.Column},
+					Value:    v,
+				})
+			}
+			switch n.Kind {
+			case yaml3.DocumentNode:
+				for _, c := range n.Content {
+					if err := walk(c, path); err != nil {
+						return err
+					}
+				}
+			case yaml3.MappingNode:
+				for i := 0; i+1 < len(n.Content); i += 2 {
+					if err := walk(n.Content[i+1], path.key(n.Content[i].Value)); err != nil {
+						return err
+					}
+				}
+			case yaml3.SequenceNode:
+				for i, c := range n.Content {
+					if err := walk(c, path.index(i)); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		}
+		if err := walk(&n, nil); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// DuplicateAnchorError is returned when a document defines an anchor twice
+// and the DecodeUniqueAnchors option is given.
+type DuplicateAnchorError struct {
+	Name string
+	// First and Second are the positions of the first two nodes that
+	// define the anchor.
+	First, Second Position
+}
+
+func (e *DuplicateAnchorError) Error() string {
+	return fmt.Sprintf("yaml: line %d: anchor %q is already defined at line %d", e.Second.Line, e.Name, e.First.Line)
+}
+
+// DecodeUniqueAnchors makes Unmarshal fail with a *DuplicateAnchorError on
+// documents that define the same anchor more than once, which YAML allows,
+// each alias referring to the definition before it, but which is more often
+// a mistake of copying a block than intended. Aliases to anchors that are not
+// defined before them always fail.
+func DecodeUniqueAnchors() JSONOpt {
+	return decodeOpt(func(o *decodeOptions) {
+		o.uniqueAnchors = true
+	})
+}
+
+// checkAnchors returns a *DuplicateAnchorError if the document y defines an
+// anchor twice.
+func checkAnchors(y []byte) error {
+	var doc yaml3.Node
+	if err := yaml3.Unmarshal(y, &doc); err != nil {
+		return err
+	}
+	defined := map[string]Position{}
+	var walk func(n *yaml3.Node) error
+	walk = func(n *yaml3.Node) error {
+		if n.Anchor != "" {
+			pos := Position{n.Line, n.Column}
+			if first, ok := defined[n.Anchor]; ok {
+				return &DuplicateAnchorError{Name: n.Anchor, First: first, Second: pos}
+			}
+			defined[n.Anchor] = pos
+		}
+		for _, c := range n.Content {
+			if err := walk(c); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return walk(&doc)
+}
+
+// checkRecursiveAliases returns an error, as go-yaml words it, if an alias of
+// the document n refers to an anchor whose value holds the alias, which
+// would expand without end.
+func checkRecursiveAliases(n *yaml3.Node) error {
+	// open holds the nodes being walked, and done those that have been.
+	open := map[*yaml3.Node]bool{}
+	done := map[*yaml3.Node]bool{}
+	var walk func(n *yaml3.Node) error
+	walk = func(n *yaml3.Node) error {
+		if n.Kind == yaml3.AliasNode {
+			if open[n.Alias] {
+				return fmt.Errorf("yaml: line %d: anchor '%s' value contains itself", n.Line, n.Value)
+			}
+			n = n.Alias
+		}
+		if done[n] {
+			return nil
+		}
+		open[n] = true
+		for _, c := range n.Content {
+			if err := walk(c); err != nil {
+				return err
+			}
+		}
+		delete(open, n)
+		done[n] = true
+		return nil
+	}
+	return walk(n)
+}
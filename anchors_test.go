@@ -0,0 +1,84 @@
+package yaml
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeUniqueAnchors(t *testing.T) {
+	y := `defaults: &limits
+  cpu: 1
+prod:
+  limits: &limits
+    cpu: 4
+staging:
+  limits: *limits
+`
+	var v map[string]interface{}
+	if err := Unmarshal([]byte(y), &v); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+
+	err := Unmarshal([]byte(y), &v, DecodeUniqueAnchors())
+	var de *DuplicateAnchorError
+	if !errors.As(err, &de) {
+		t.Fatalf("Unmarshal() = %v, want a *DuplicateAnchorError", err)
+	}
+	if de.Name != "limits" || de.First != (Position{1, 11}) || de.Second != (Position{4, 11}) {
+		t.Errorf("Unmarshal() = %+v", *de)
+	}
+	if want := `yaml: line 4: anchor "limits" is already defined at line 1`; err.Error() != want {
+		t.Errorf("Unmarshal() = %v, want %v", err, want)
+	}
+
+	if err := Unmarshal([]byte("a: &x 1\nb: *x\n"), &v, DecodeUniqueAnchors()); err != nil {
+		t.Errorf("Unmarshal() = %v", err)
+	}
+	err = Unmarshal([]byte("a: *x\nb: &x 1\n"), &v, DecodeUniqueAnchors())
+	if err == nil || !strings.Contains(err.Error(), "unknown anchor") {
+		t.Errorf("Unmarshal() = %v, want an error for the unknown anchor", err)
+	}
+}
+
+func TestAnchors(t *testing.T) {
+	y := `defaults: &limits
+  cpu: 1
+  mem: &mem 2Gi
+prod:
+  limits: &limits
+    cpu: 4
+staging:
+  limits: *limits
+  mem: *mem
+---
+base: &base [a, b]
+copy: *base
+`
+	anchors, err := Anchors([]byte(y))
+	if err != nil {
+		t.Fatalf("Anchors() = %v", err)
+	}
+	want := []Anchor{
+		{Name: "limits", Document: 0, Path: "defaults", Pos: Position{1, 11},
+			Value: map[string]interface{}{"cpu": float64(1), "mem": "2Gi"}},
+		{Name: "mem", Document: 0, Path: "defaults.mem", Pos: Position{3, 8}, Value: "2Gi",
+			Aliases: []Alias{{"staging.mem", Position{9, 8}}}},
+		{Name: "limits", Document: 0, Path: "prod.limits", Pos: Position{5, 11},
+			Value:   map[string]interface{}{"cpu": float64(4)},
+			Aliases: []Alias{{"staging.limits", Position{8, 11}}}},
+		{Name: "base", Document: 1, Path: "base", Pos: Position{11, 7}, Value: []interface{}{"a", "b"},
+			Aliases: []Alias{{"copy", Position{12, 7}}}},
+	}
+	if !reflect.DeepEqual(anchors, want) {
+		t.Errorf("Anchors() =\n%+v\nwant\n%+v", anchors, want)
+	}
+
+	if _, err := Anchors([]byte("a: *x\n")); err == nil {
+		t.Errorf("Anchors() of an unknown anchor = nil, want an error")
+	}
+	if _, err := Anchors([]byte("a: &a\n  b: [*a]\n")); err == nil || !strings.Contains(err.Error(), "contains itself") {
+		t.Errorf("Anchors() = %v, want an error for the recursive alias", err)
+	}
+}
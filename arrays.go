@@ -0,0 +1,54 @@
+package yaml
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ArrayLength selects what becomes of a YAML sequence whose length differs
+// from that of the Go array it is decoded into.
+type ArrayLength int
+
+const (
+	// ArrayLengthAny drops the items of a sequence that do not fit the
+	// array and sets the elements a sequence has no items for to their zero
+	// value, as encoding/json does. This is the default.
+	ArrayLengthAny ArrayLength = iota
+	// ArrayLengthExact fails on a sequence of any other length than the
+	// array.
+	ArrayLengthExact
+	// ArrayLengthTruncate drops the items that do not fit the array, but
+	// fails on a sequence that is shorter than it.
+	ArrayLengthTruncate
+	// ArrayLengthPad sets the elements a sequence has no items for to their
+	// zero value, but fails on a sequence that is longer than the array.
+	ArrayLengthPad
+)
+
+// DecodeArrayLength sets what the conversion to JSON does with sequences
+// that are decoded into arrays of another length.
+func DecodeArrayLength(p ArrayLength) JSONOpt {
+	return decodeOpt(func(o *decodeOptions) {
+		o.arrayLength = p
+	})
+}
+
+// checkArrayLength returns an error if a sequence of n items may not be
+// decoded into a value of the array type t.
+func (o *decodeOptions) checkArrayLength(n int, t reflect.Type) error {
+	var ok bool
+	switch o.arrayLength {
+	case ArrayLengthExact:
+		ok = n == t.Len()
+	case ArrayLengthTruncate:
+		ok = n >= t.Len()
+	case ArrayLengthPad:
+		ok = n <= t.Len()
+	default:
+		return nil
+	}
+	if !ok {
+		return fmt.Errorf("cannot decode a sequence of %d items into a %v", n, t)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package yaml
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecodeArrayLength(t *testing.T) {
+	type point struct {
+		Coords [3]int `json:"coords"`
+	}
+	tests := []struct {
+		p    ArrayLength
+		y    string
+		want [3]int
+		err  bool
+	}{
+		{ArrayLengthAny, "coords: [1, 2, 3, 4]", [3]int{1, 2, 3}, false},
+		{ArrayLengthAny, "coords: [1, 2]", [3]int{1, 2, 0}, false},
+		{ArrayLengthExact, "coords: [1, 2, 3]", [3]int{1, 2, 3}, false},
+		{ArrayLengthExact, "coords: [1, 2, 3, 4]", [3]int{}, true},
+		{ArrayLengthExact, "coords: [1, 2]", [3]int{}, true},
+		{ArrayLengthTruncate, "coords: [1, 2, 3, 4]", [3]int{1, 2, 3}, false},
+		{ArrayLengthTruncate, "coords: [1, 2]", [3]int{}, true},
+		{ArrayLengthPad, "coords: [1, 2]", [3]int{1, 2, 0}, false},
+		{ArrayLengthPad, "coords: [1, 2, 3, 4]", [3]int{}, true},
+	}
+	for _, tt := range tests {
+		var p point
+		err := Unmarshal([]byte(tt.y), &p, DecodeArrayLength(tt.p))
+		if tt.err {
+			if err == nil || !strings.Contains(err.Error(), "coords: cannot decode a sequence of") {
+				t.Errorf("Unmarshal(%q) with %d = %v, want an error for coords", tt.y, tt.p, err)
+			}
+			continue
+		}
+		if err != nil || p.Coords != tt.want {
+			t.Errorf("Unmarshal(%q) with %d = %v, %v, want %v", tt.y, tt.p, p.Coords, err, tt.want)
+		}
+	}
+
+	// The items are converted to the element type of the array.
+	var names [2]string
+	if err := Unmarshal([]byte("[1, true]"), &names); err != nil || names != [2]string{"1", "true"} {
+		t.Errorf("Unmarshal() = %q, %v", names, err)
+	}
+}
@@ -0,0 +1,124 @@
+package yaml
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"regexp"
+	"strconv"
+	"sync"
+)
+
+// go-yaml parses numbers that do not fit an int64 or a uint64 into a float64,
+// which loses the precision of huge integers and of decimals with many digits.
+// When decoding into a type that holds big.Int, big.Float or big.Rat values,
+// the scalars of the document are resolved by resolveScalars instead, which
+// keeps the text of such numbers as a json.Number.
+
+var (
+	bigIntType   = reflect.TypeOf(big.Int{})
+	bigFloatType = reflect.TypeOf(big.Float{})
+	bigRatType   = reflect.TypeOf(big.Rat{})
+)
+
+var bigCache struct {
+	sync.RWMutex
+	m map[reflect.Type]bool
+}
+
+// typeHasBigNumbers reports whether values of type t may hold a big.Int,
+// big.Float or big.Rat.
+func typeHasBigNumbers(t reflect.Type) bool {
+	bigCache.RLock()
+	has, ok := bigCache.m[t]
+	bigCache.RUnlock()
+	if ok {
+		return has
+	}
+
+	has = findBigNumbers(t, map[reflect.Type]bool{})
+
+	bigCache.Lock()
+	if bigCache.m == nil {
+		bigCache.m = map[reflect.Type]bool{}
+	}
+	bigCache.m[t] = has
+	bigCache.Unlock()
+	return has
+}
+
+func findBigNumbers(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+	switch t {
+	case bigIntType, bigFloatType, bigRatType:
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return findBigNumbers(t.Elem(), seen)
+	case reflect.Struct:
+		for _, f := range cachedTypeFields(t) {
+			if findBigNumbers(f.typ, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// jsonNumber matches the numbers that are valid in JSON.
+var jsonNumber = regexp.MustCompile(`^-?(?:0|[1-9][0-9]*)(?:\.[0-9]+)?(?:[eE][-+]?[0-9]+)?$`)
+
+// numberText returns the text of the YAML number v, for decoding it into an
+// encoding.TextUnmarshaler.
+func numberText(v interface{}) (string, bool) {
+	switch n := v.(type) {
+	case int:
+		return strconv.Itoa(n), true
+	case int64:
+		return strconv.FormatInt(n, 10), true
+	case uint64:
+		return strconv.FormatUint(n, 10), true
+	case float64:
+		return strconv.FormatFloat(n, 'g', -1, 64), true
+	case json.Number:
+		return string(n), true
+	}
+	return "", false
+}
+
+// exactNumber is the JSON-compatible form of an integer that is decoded into
+// an interface{} but that a float64 cannot hold. It is written to the JSON
+// document as is, and fillUndecoded sets the interface{} to a json.Number.
+type exactNumber string
+
+func (n exactNumber) MarshalJSON() ([]byte, error) {
+	return []byte(n), nil
+}
+
+// maxExactFloat is the largest integer below which all integers fit in a
+// float64.
+const maxExactFloat = 1 << 53
+
+// inexactInteger returns the YAML integer v as an exactNumber if a float64
+// cannot hold it.
+func inexactInteger(v interface{}) (exactNumber, bool) {
+	switch n := v.(type) {
+	case int:
+		if n > maxExactFloat || n < -maxExactFloat {
+			return exactNumber(strconv.Itoa(n)), true
+		}
+	case int64:
+		if n > maxExactFloat || n < -maxExactFloat {
+			return exactNumber(strconv.FormatInt(n, 10)), true
+		}
+	case uint64:
+		if n > maxExactFloat {
+			return exactNumber(strconv.FormatUint(n, 10)), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,87 @@
+package yaml
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+func TestBigNumbers(t *testing.T) {
+	type BigTest struct {
+		Int   *big.Int   `json:"int"`
+		Value big.Int    `json:"value"`
+		Float *big.Float `json:"float"`
+		Rat   *big.Rat   `json:"rat"`
+		Small *big.Float `json:"small"`
+		Str   string     `json:"str"`
+	}
+	y := "int: 123456789012345678901234567890\n" +
+		"value: -18446744073709551616\n" +
+		"float: 3.14159265358979323846264338327950288\n" +
+		"rat: 0.1\n" +
+		"small: 2\n" +
+		"str: 98765432109876543210\n"
+
+	// big.Float keeps the precision it is set to, or 64 bits if it is unset.
+	got := BigTest{Float: new(big.Float).SetPrec(200)}
+	if err := Unmarshal([]byte(y), &got); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	if s := got.Int.String(); s != "123456789012345678901234567890" {
+		t.Errorf("Int = %s", s)
+	}
+	if s := got.Value.String(); s != "-18446744073709551616" {
+		t.Errorf("Value = %s", s)
+	}
+	if s := got.Float.Text('f', 35); s != "3.14159265358979323846264338327950288" {
+		t.Errorf("Float = %s", s)
+	}
+	if s := got.Rat.String(); s != "1/10" {
+		t.Errorf("Rat = %s", s)
+	}
+	if s := got.Small.String(); s != "2" {
+		t.Errorf("Small = %s", s)
+	}
+	if got.Str != "98765432109876543210" {
+		t.Errorf("Str = %s", got.Str)
+	}
+}
+
+func TestUint64RoundTrip(t *testing.T) {
+	type Uint64Test struct {
+		Max   uint64      `json:"max"`
+		Any   interface{} `json:"any"`
+		Small interface{} `json:"small"`
+	}
+	v := Uint64Test{Max: math.MaxUint64, Any: json.Number("18446744073709551615"), Small: float64(42)}
+	want := "any: 18446744073709551615\nmax: 18446744073709551615\nsmall: 42\n"
+
+	y, err := Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal() = %v", err)
+	}
+	if string(y) != want {
+		t.Errorf("Marshal() = %#q; want %#q", string(y), want)
+	}
+
+	var got Uint64Test
+	if err := Unmarshal(y, &got); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	if !reflect.DeepEqual(got, v) {
+		t.Errorf("Unmarshal() = %#v; want %#v", got, v)
+	}
+
+	var generic interface{}
+	if err := Unmarshal([]byte("a: [9007199254740993, -9223372036854775808, 1]\n"), &generic); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	wantGeneric := map[string]interface{}{
+		"a": []interface{}{json.Number("9007199254740993"), json.Number("-9223372036854775808"), float64(1)},
+	}
+	if !reflect.DeepEqual(generic, wantGeneric) {
+		t.Errorf("Unmarshal() = %#v; want %#v", generic, wantGeneric)
+	}
+}
@@ -0,0 +1,73 @@
+package yaml
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// YAML documents may be encoded in UTF-8, UTF-16 or UTF-32, with or without a
+// byte order mark. go-yaml reads UTF-16 only with a byte order mark and
+// encoding/json reads neither, so documents are transcoded to UTF-8 first.
+
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// toUTF8 returns the document b in UTF-8 and without a byte order mark. As in
+// section 5.2 of the YAML spec, the encoding is detected by the byte order
+// mark or, without one, by the null bytes around the first character, which
+// is then ASCII.
+func toUTF8(b []byte) ([]byte, error) {
+	switch {
+	case bytes.HasPrefix(b, utf8BOM):
+		return b[len(utf8BOM):], nil
+	case bytes.HasPrefix(b, []byte("\x00\x00\xfe\xff")):
+		return decodeUTF32(b[4:], binary.BigEndian)
+	case bytes.HasPrefix(b, []byte("\xff\xfe\x00\x00")):
+		return decodeUTF32(b[4:], binary.LittleEndian)
+	case bytes.HasPrefix(b, []byte("\xfe\xff")):
+		return decodeUTF16(b[2:], binary.BigEndian)
+	case bytes.HasPrefix(b, []byte("\xff\xfe")):
+		return decodeUTF16(b[2:], binary.LittleEndian)
+	case len(b) >= 4 && b[0] == 0 && b[1] == 0 && b[2] == 0 && b[3] != 0:
+		return decodeUTF32(b, binary.BigEndian)
+	case len(b) >= 4 && b[0] != 0 && b[1] == 0 && b[2] == 0 && b[3] == 0:
+		return decodeUTF32(b, binary.LittleEndian)
+	case len(b) >= 2 && b[0] == 0 && b[1] != 0:
+		return decodeUTF16(b, binary.BigEndian)
+	case len(b) >= 2 && b[0] != 0 && b[1] == 0:
+		return decodeUTF16(b, binary.LittleEndian)
+	}
+	return b, nil
+}
+
+func decodeUTF16(b []byte, order binary.ByteOrder) ([]byte, error) {
+	if len(b)%2 != 0 {
+		return nil, errors.New("yaml: invalid UTF-16: odd number of bytes")
+	}
+	s := make([]uint16, len(b)/2)
+	for i := range s {
+		s[i] = order.Uint16(b[2*i:])
+	}
+	out := make([]byte, 0, len(s))
+	for _, r := range utf16.Decode(s) {
+		out = append(out, string(r)...)
+	}
+	return out, nil
+}
+
+func decodeUTF32(b []byte, order binary.ByteOrder) ([]byte, error) {
+	if len(b)%4 != 0 {
+		return nil, errors.New("yaml: invalid UTF-32: number of bytes is not a multiple of 4")
+	}
+	out := make([]byte, 0, len(b)/4)
+	for i := 0; i < len(b); i += 4 {
+		r := rune(order.Uint32(b[i:]))
+		if !utf8.ValidRune(r) {
+			return nil, errors.New("yaml: invalid UTF-32: invalid code point")
+		}
+		out = append(out, string(r)...)
+	}
+	return out, nil
+}
@@ -0,0 +1,77 @@
+package yaml
+
+import (
+	"encoding/binary"
+	"testing"
+	"unicode/utf16"
+)
+
+func encodeUTF16(s string, order binary.ByteOrder) []byte {
+	s16 := utf16.Encode([]rune(s))
+	b := make([]byte, 2*len(s16))
+	for i, c := range s16 {
+		order.PutUint16(b[2*i:], c)
+	}
+	return b
+}
+
+func encodeUTF32(s string, order binary.ByteOrder) []byte {
+	rs := []rune(s)
+	b := make([]byte, 4*len(rs))
+	for i, r := range rs {
+		order.PutUint32(b[4*i:], uint32(r))
+	}
+	return b
+}
+
+func TestEncodings(t *testing.T) {
+	const doc = "a: é\nb: [1, 😀]\n"
+	const want = `{"a":"é","b":[1,"😀"]}`
+	for _, tc := range []struct {
+		name string
+		y    []byte
+	}{
+		{"UTF-8", []byte(doc)},
+		{"UTF-8 BOM", []byte("\ufeff" + doc)},
+		{"UTF-16BE BOM", encodeUTF16("\ufeff"+doc, binary.BigEndian)},
+		{"UTF-16LE BOM", encodeUTF16("\ufeff"+doc, binary.LittleEndian)},
+		{"UTF-16BE", encodeUTF16(doc, binary.BigEndian)},
+		{"UTF-16LE", encodeUTF16(doc, binary.LittleEndian)},
+		{"UTF-32BE BOM", encodeUTF32("\ufeff"+doc, binary.BigEndian)},
+		{"UTF-32LE BOM", encodeUTF32("\ufeff"+doc, binary.LittleEndian)},
+		{"UTF-32BE", encodeUTF32(doc, binary.BigEndian)},
+		{"UTF-32LE", encodeUTF32(doc, binary.LittleEndian)},
+	} {
+		j, err := YAMLToJSON(tc.y)
+		if err != nil {
+			t.Errorf("%s: YAMLToJSON() = %v", tc.name, err)
+			continue
+		}
+		if string(j) != want {
+			t.Errorf("%s: YAMLToJSON() = %s; want %s", tc.name, j, want)
+		}
+		var v struct {
+			A string `json:"a"`
+		}
+		if err := Unmarshal(tc.y, &v); err != nil || v.A != "é" {
+			t.Errorf("%s: Unmarshal() = %+v, %v; want a: é", tc.name, v, err)
+		}
+	}
+
+	// JSON documents may be encoded in the same ways.
+	for _, j := range [][]byte{
+		[]byte("\ufeff{\"a\":1}"),
+		encodeUTF16("{\"a\":1}", binary.LittleEndian),
+	} {
+		for _, opts := range [][]MarshalOpt{nil, {SortKeys(KeyOrderNatural)}} {
+			y, err := JSONToYAMLWithOpts(j, opts...)
+			if err != nil || string(y) != "a: 1\n" {
+				t.Errorf("JSONToYAMLWithOpts(%q) = %#q, %v; want a: 1", j, string(y), err)
+			}
+		}
+	}
+
+	if _, err := YAMLToJSON([]byte("\xfe\xff\x00a\x00")); err == nil {
+		t.Errorf("YAMLToJSON() = nil; want an error for an odd number of UTF-16 bytes")
+	}
+}
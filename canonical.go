@@ -0,0 +1,70 @@
+package yaml
+
+import (
+	"bytes"
+	"io"
+
+	"gopkg.in/yaml.v2"
+)
+
+// canonicalOpts are the encoding options of the canonical form.
+var canonicalOpts = []MarshalOpt{
+	SortKeys(KeyOrderAlphabetical),
+	QuoteWhenNeeded(QuoteDouble),
+	LineWidth(0),
+}
+
+// Canonicalize rewrites the YAML stream data in a canonical form, so that
+// documents holding the same values are byte for byte the same and can be
+// hashed, signed or compared. Values are read as Unmarshal reads them, so
+// keys become strings and numbers, booleans and nulls are written in a single
+// spelling each, such as 10 for 0xA and true for yes. Keys are sorted by their
+// bytes, strings are double-quoted only where they have to be, long strings
+// are never folded, and comments, anchors and tags are dropped. Documents
+// after the first start with "---".
+func Canonicalize(data []byte) ([]byte, error) {
+	data, err := toUTF8(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	d := yaml.NewDecoder(bytes.NewReader(data))
+	for n := 0; ; n++ {
+		var v interface{}
+		if err := d.Decode(&v); err == io.EOF {
+			return out.Bytes(), nil
+		} else if err != nil {
+			return nil, err
+		}
+		obj, err := convertToJSONableObject(v, nil, &decodeOptions{}, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		e := newEncoder(canonicalOpts)
+		e.documentStart = n > 0
+		e.document(canonicalValue(obj))
+		out.Write(e.out.Bytes())
+	}
+}
+
+// canonicalValue converts the mappings in v, as returned by
+// convertToJSONableObject, into the yaml.MapSlice the encoder expects.
+func canonicalValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := make(yaml.MapSlice, 0, len(v))
+		for k, item := range v {
+			m = append(m, yaml.MapItem{Key: k, Value: canonicalValue(item)})
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, item := range v {
+			s[i] = canonicalValue(item)
+		}
+		return s
+	}
+	return v
+}
@@ -0,0 +1,78 @@
+package yaml
+
+import "testing"
+
+func TestCanonicalize(t *testing.T) {
+	a := `# Settings.
+name: 'web'
+replicas: 0x3
+enabled: yes
+ratio: 1.50
+empty: ~
+labels: {tier: front, app: web}
+ports: [80, 443]
+note: 'it''s'
+10: ten
+9: nine
+`
+	b := `9: nine
+10: "ten"
+enabled: true
+labels:
+  app: web
+  tier: "front"
+name: web
+note: "it's"
+ports:
+- 80
+- 443
+ratio: 1.5
+replicas: 3
+empty: null
+`
+	want := `"10": ten
+"9": nine
+empty: null
+enabled: true
+labels:
+  app: web
+  tier: front
+name: web
+note: it's
+ports:
+- 80
+- 443
+ratio: 1.5
+replicas: 3
+`
+	for _, y := range []string{a, b} {
+		got, err := Canonicalize([]byte(y))
+		if err != nil {
+			t.Fatalf("Canonicalize(%#q) = %v", y, err)
+		}
+		if string(got) != want {
+			t.Errorf("Canonicalize(%#q) = %#q; want %#q", y, string(got), want)
+		}
+	}
+
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"a: b\n---\n- 1\n--- ~\n", "a: b\n---\n- 1\n--- null\n"},
+		{"- 'true'\n- 'a: b'\n- '0777'\n", "- \"true\"\n- \"a: b\"\n- \"0777\"\n"},
+		{"a: &x {b: 1}\nc: *x\n", "a:\n  b: 1\nc:\n  b: 1\n"},
+	} {
+		got, err := Canonicalize([]byte(tc.in))
+		if err != nil {
+			t.Fatalf("Canonicalize(%#q) = %v", tc.in, err)
+		}
+		if string(got) != tc.want {
+			t.Errorf("Canonicalize(%#q) = %#q; want %#q", tc.in, string(got), tc.want)
+		}
+	}
+
+	if _, err := Canonicalize([]byte("a: [")); err == nil {
+		t.Error("Canonicalize() of invalid YAML returned no error")
+	}
+}
@@ -0,0 +1,275 @@
+package yaml
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"gopkg.in/yaml.v2"
+)
+
+// YAMLToJSONStream converts the YAML document read from r to JSON, as
+// YAMLToJSONWithOpts does, writing the JSON to w as it goes, for documents
+// too large to hold in memory, such as multi-gigabyte exports. A document
+// that is a block mapping or a block sequence is converted one top-level
+// entry or item at a time, so that memory use is proportional to the
+// largest of them rather than to the document; the keys of the top-level
+// mapping are kept too, as a key that occurs twice is an error here rather
+// than the later value replacing the earlier one. Keys are written in the
+// order of the document, rather than sorted as YAMLToJSON sorts them.
+//
+// As each entry is converted on its own, an alias must refer to an anchor
+// of its own entry. Other documents, such as flow collections, scalars, and
+// documents in UTF-16 or UTF-32 or with %TAG directives, are read and
+// converted whole. As with YAMLToJSON, only the first document of a stream
+// is converted.
+//
+// If an error occurs, part of the JSON may already have been written to w.
+func YAMLToJSONStream(w io.Writer, r io.Reader, opts ...JSONOpt) error {
+	_, do := newJSONDecoder(bytes.NewReader(nil), opts)
+	c := &chunkConverter{r: bufio.NewReader(r), w: w, do: do, keys: map[string]int{}}
+	return c.run()
+}
+
+// chunkConverter converts a document for YAMLToJSONStream.
+type chunkConverter struct {
+	r  *bufio.Reader
+	w  io.Writer
+	do *decodeOptions
+	// line is the number of lines read, of which the current entry starts
+	// at entryLine.
+	line, entryLine int
+	// entry holds the lines of the current entry, and next the line read
+	// after them.
+	entry, next []byte
+	// s splits the document into entries.
+	s entrySplitter
+	// ended is set once the end of the document is read.
+	ended bool
+	// n is the number of entries or items written.
+	n int
+	// keys holds the line of each key of a mapping.
+	keys map[string]int
+	out  []byte
+}
+
+func (c *chunkConverter) run() error {
+	if b, _ := c.r.Peek(2); len(b) == 2 && (b[0] == 0 || b[1] == 0 || b[0] >= 0xfe) {
+		return c.whole()
+	}
+	if b, _ := c.r.Peek(len(utf8BOM)); bytes.Equal(b, utf8BOM) {
+		c.r.Discard(len(utf8BOM))
+	}
+
+	// The lines before the first entry, such as comments and the "---" line,
+	// are kept with it.
+	c.entryLine = 1
+	for {
+		err := c.readLine()
+		if err != nil && err != io.EOF {
+			return err
+		}
+		line := c.next
+		c.entry, c.next = append(c.entry, line...), c.next[:0]
+		switch {
+		case len(line) == 0:
+			// The document is empty.
+			return c.write([]byte("null"))
+		case isBlankLine(line) || isComment(line):
+		case line[0] == '%':
+			if bytes.HasPrefix(line, []byte("%TAG")) {
+				// Tag handles only apply to the entry the directive is
+				// kept with.
+				return c.whole()
+			}
+		case isMarker(line, "---"):
+			if !isBlankLine(line[3:]) && !isComment(line[3:]) {
+				return c.whole()
+			}
+		case isMarker(line, "..."):
+			return c.write([]byte("null"))
+		case line[0] == ' ' || line[0] == '\t':
+			return c.whole()
+		default:
+			c.s.seq = isMarker(line, "-")
+			c.s.block = -1
+			c.s.scan(line)
+			return c.entries()
+		}
+		if err == io.EOF {
+			return c.write([]byte("null"))
+		}
+	}
+}
+
+// entries converts the entries of the document, the first of which is
+// started.
+func (c *chunkConverter) entries() error {
+	for {
+		err := c.readLine()
+		if err != nil && err != io.EOF {
+			return err
+		}
+		line := c.next
+		if len(line) > 0 && !c.s.open() && (isMarker(line, "---") || isMarker(line, "...")) {
+			break
+		}
+		if c.s.startsEntry(line) {
+			if err := c.convert(); err != nil || c.out != nil {
+				return err
+			}
+			c.entry, c.entryLine = c.entry[:0], c.line
+		}
+		c.entry = append(c.entry, line...)
+		c.s.scan(line)
+		if err == io.EOF {
+			break
+		}
+	}
+	c.next, c.ended = c.next[:0], true
+	if err := c.convert(); err != nil || c.out != nil {
+		return err
+	}
+	if c.s.seq {
+		return c.write([]byte("]"))
+	}
+	return c.write([]byte("}"))
+}
+
+// readLine reads the next line into next, returning io.EOF with the last
+// line, which may be empty.
+func (c *chunkConverter) readLine() error {
+	c.next = c.next[:0]
+	for {
+		b, err := c.r.ReadSlice('\n')
+		c.next = append(c.next, b...)
+		if err != bufio.ErrBufferFull {
+			if len(c.next) > 0 {
+				c.line++
+			}
+			return err
+		}
+	}
+}
+
+// convert converts the current entry and writes it. The first entry is
+// checked to be one of the document expected, and if it is not, the whole
+// document is converted, setting out.
+func (c *chunkConverter) convert() error {
+	obj, err := yamlToJSONObject(c.entry, nil, yaml.Unmarshal, c.do)
+	if err != nil {
+		if c.n == 0 {
+			return c.whole()
+		}
+		return c.entryError(err)
+	}
+
+	if c.s.seq {
+		items, ok := obj.([]interface{})
+		if !ok {
+			if c.n == 0 {
+				return c.whole()
+			}
+			return fmt.Errorf("yaml: line %d: not a sequence item", c.entryLine)
+		}
+		for _, item := range items {
+			if err := c.writeValue("[", nil, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	m, ok := obj.(map[string]interface{})
+	if !ok {
+		if c.n == 0 {
+			return c.whole()
+		}
+		return fmt.Errorf("yaml: line %d: not a mapping entry", c.entryLine)
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if line, ok := c.keys[k]; ok {
+			return fmt.Errorf("yaml: line %d: key %q already set at line %d", c.entryLine, k, line)
+		}
+		c.keys[k] = c.entryLine
+		key, err := json.Marshal(k)
+		if err != nil {
+			return err
+		}
+		if err := c.writeValue("{", key, m[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeValue writes v, with its key if it has one, after open if it is the
+// first entry and after a comma otherwise.
+func (c *chunkConverter) writeValue(open string, key []byte, v interface{}) error {
+	j, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	b := []byte(",")
+	if c.n == 0 {
+		b = []byte(open)
+	}
+	if key != nil {
+		b = append(append(b, key...), ':')
+	}
+	c.n++
+	return c.write(append(b, j...))
+}
+
+func (c *chunkConverter) write(b []byte) error {
+	_, err := c.w.Write(b)
+	return err
+}
+
+// whole converts the document in one go, reading the rest of it unless its
+// end is read. It is only called before anything is written.
+func (c *chunkConverter) whole() error {
+	y := append(c.entry, c.next...)
+	if !c.ended {
+		rest, err := io.ReadAll(c.r)
+		if err != nil {
+			return err
+		}
+		y = append(y, rest...)
+	}
+	out, err := yamlToJSON(y, nil, yaml.Unmarshal, c.do)
+	if err != nil {
+		return err
+	}
+	c.out = out
+	return c.write(out)
+}
+
+var lineNumber = regexp.MustCompile(`\bline (\d+)`)
+
+// entryError returns err, an error converting the current entry, with the
+// lines it names counted from the start of the document.
+func (c *chunkConverter) entryError(err error) error {
+	offset := c.entryLine - 1
+	var te *TabIndentError
+	if errors.As(err, &te) {
+		e := *te
+		e.Line += offset
+		return &e
+	}
+	return errors.New(lineNumber.ReplaceAllStringFunc(err.Error(), func(s string) string {
+		n, _ := strconv.Atoi(s[len("line "):])
+		return "line " + strconv.Itoa(n+offset)
+	}))
+}
@@ -0,0 +1,141 @@
+package yaml
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestYAMLToJSONStream(t *testing.T) {
+	cases := []string{
+		"",
+		"# nothing but a comment\n",
+		"a: 1\nb: [x, y]\nc:\n  d: true\n",
+		// Comments, markers and directives before the first entry, and a
+		// second document, which is not converted.
+		"%YAML 1.1\n# comment\n---\na: 1\n# between\nb: 2\n...\nc: 3\n",
+		"a: 1\n---\nb: 2\n",
+		// Sequences at the indentation of their key.
+		"a:\n- 1\n- 2\nb:\n- c: 3\n",
+		// Block scalars, whose lines may look like anything.
+		"a: |\n  \"open\n  [open\nb: >-\n  text\n\n  more\nc: 1\n",
+		"- |\n  'open\n- b\n",
+		// Flow collections and quoted scalars going on at the start of a
+		// line.
+		"a: [1,\n2]\nb: {c: 1,\nd: 2}\ne: \"x\ny: z\"\nf: 'it''s\ng'\nh: 1\n",
+		"a: [\"]\",\n'['\n]\nb: 1\n",
+		// Explicit keys, and keys that are not plain.
+		"? a\n: 1\n\"b c\": 2\n'd': 3\n:e: 4\n",
+		// Anchors and merges within an entry.
+		"a:\n  base: &b {x: 1}\n  c:\n    <<: *b\n    y: 2\nd: 1\n",
+		"- a: 1\n  b: [2, 3]\n-\n  c: 4\n- - 5\n  - 6\n- 7 # comment\n",
+		// Documents that are converted whole.
+		"hello\n",
+		"[1, 2,\n3]\n",
+		"{a: 1,\nb: 2}\n",
+		"&root\na: 1\n",
+		"--- !!map\na: 1\n",
+		"  a: 1\n  b: 2\n",
+		"\xef\xbb\xbfa: 1\nb: 2\n",
+		"%TAG !e! tag:example.com,2000:\n---\na: !e!x 1\nb: 2\n",
+		"a: 1",
+		"- 1\n- 2",
+		"a: " + strings.Repeat("x", 10000) + "\nb: 1\n",
+	}
+	for _, y := range cases {
+		var out bytes.Buffer
+		if err := YAMLToJSONStream(&out, strings.NewReader(y)); err != nil {
+			t.Errorf("YAMLToJSONStream(%q) = %v", y, err)
+			continue
+		}
+		want, err := YAMLToJSON([]byte(y))
+		if err != nil {
+			t.Fatalf("YAMLToJSON(%q) = %v", y, err)
+		}
+		var g, w interface{}
+		if err := json.Unmarshal(out.Bytes(), &g); err != nil {
+			t.Errorf("YAMLToJSONStream(%q) wrote invalid JSON %s: %v", y, out.Bytes(), err)
+			continue
+		}
+		json.Unmarshal(want, &w)
+		if !reflect.DeepEqual(g, w) {
+			t.Errorf("YAMLToJSONStream(%q) wrote %s, want %s", y, out.Bytes(), want)
+		}
+	}
+
+	utf16 := []byte{0xff, 0xfe, 'a', 0, ':', 0, ' ', 0, '1', 0, '\n', 0}
+	var out bytes.Buffer
+	if err := YAMLToJSONStream(&out, bytes.NewReader(utf16)); err != nil || out.String() != `{"a":1}` {
+		t.Errorf("YAMLToJSONStream() of UTF-16 = %v, %s", err, out.Bytes())
+	}
+
+	out.Reset()
+	if err := YAMLToJSONStream(&out, strings.NewReader("b: 1e5\na: 1\n"), AmbiguousNumbersAsStrings()); err != nil || out.String() != `{"b":"1e5","a":1}` {
+		t.Errorf("YAMLToJSONStream() with options = %v, %s", err, out.Bytes())
+	}
+}
+
+func TestYAMLToJSONStreamErrors(t *testing.T) {
+	cases := []struct {
+		y, want string
+	}{
+		{"a: 1\nb: 2\na: 3\n", `yaml: line 3: key "a" already set at line 1`},
+		{"a: &x 1\nb: *x\n", `yaml: unknown anchor 'x' referenced`},
+		{"- 1\n- [2\n", ""},
+		{"a: 1\nb:\n  c: 1\n d: 2\n", ""},
+		{"a: 1\nb:\n\tc: 1\n", ""},
+	}
+	for _, c := range cases {
+		want := c.want
+		if want == "" {
+			// The lines of errors are those of the document.
+			_, err := YAMLToJSON([]byte(c.y))
+			if err == nil {
+				t.Fatalf("YAMLToJSON(%q) returned no error", c.y)
+			}
+			want = err.Error()
+		}
+		var out bytes.Buffer
+		if err := YAMLToJSONStream(&out, strings.NewReader(c.y)); err == nil || err.Error() != want {
+			t.Errorf("YAMLToJSONStream(%q) = %v, want %s", c.y, err, want)
+		}
+	}
+}
+
+// lineReader reads one line at a time, calling read before each.
+type lineReader struct {
+	lines []string
+	read  func(i int)
+	i     int
+}
+
+func (r *lineReader) Read(p []byte) (int, error) {
+	if r.i == len(r.lines) {
+		return 0, io.EOF
+	}
+	r.read(r.i)
+	n := copy(p, r.lines[r.i])
+	r.lines[r.i] = r.lines[r.i][n:]
+	if r.lines[r.i] == "" {
+		r.i++
+	}
+	return n, nil
+}
+
+func TestYAMLToJSONStreamIncremental(t *testing.T) {
+	var out bytes.Buffer
+	lines := []string{"- a: 1\n", "  b: 2\n", "- c\n", "- d\n"}
+	written := []string{"", "", "", `[{"a":1,"b":2}`}
+	r := &lineReader{lines: lines, read: func(i int) {
+		// An item is written once the line after it is read.
+		if out.String() != written[i] {
+			t.Errorf("before line %d, wrote %s, want %s", i+1, out.Bytes(), written[i])
+		}
+	}}
+	if err := YAMLToJSONStream(&out, r); err != nil || out.String() != `[{"a":1,"b":2},"c","d"]` {
+		t.Errorf("YAMLToJSONStream() = %v, %s", err, out.Bytes())
+	}
+}
@@ -0,0 +1,28 @@
+package yaml
+
+// JSONCodec is an implementation of JSON, such as jsoniter's
+// ConfigCompatibleWithStandardLibrary, that this package can use in place of
+// encoding/json for the JSON that Marshal and Unmarshal go through. It must
+// follow the rules of encoding/json, as to struct tags, to the MarshalJSON and
+// UnmarshalJSON methods and to which values are valid, for the conversion
+// to and from YAML to stay correct.
+type JSONCodec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// WithJSONCodec makes Marshal marshal values to JSON with c.
+func WithJSONCodec(c JSONCodec) MarshalOpt {
+	return func(e *encoder) {
+		e.codec = c
+	}
+}
+
+// DecodeJSONCodec makes Unmarshal decode the JSON it converts the YAML
+// document to with c. The options that configure a json.Decoder, such as
+// DisallowUnknownFields, then have no effect; c is to be configured instead.
+func DecodeJSONCodec(c JSONCodec) JSONOpt {
+	return decodeOpt(func(o *decodeOptions) {
+		o.codec = c
+	})
+}
@@ -0,0 +1,59 @@
+package yaml
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// countingCodec is encoding/json counting its calls.
+type countingCodec struct {
+	marshals, unmarshals int
+}
+
+func (c *countingCodec) Marshal(v interface{}) ([]byte, error) {
+	c.marshals++
+	return json.Marshal(v)
+}
+
+func (c *countingCodec) Unmarshal(data []byte, v interface{}) error {
+	c.unmarshals++
+	return json.Unmarshal(data, v)
+}
+
+func TestJSONCodec(t *testing.T) {
+	type config struct {
+		Name  string                 `json:"name"`
+		Extra map[string]interface{} `json:"-" yaml:",rest"`
+	}
+	c := &countingCodec{}
+	y, err := Marshal(config{Name: "web"}, WithJSONCodec(c))
+	if err != nil || string(y) != "name: web\n" || c.marshals != 1 {
+		t.Errorf("Marshal() = %q, %v with %d calls to the codec", y, err, c.marshals)
+	}
+
+	c = &countingCodec{}
+	var v config
+	if err := Unmarshal([]byte("name: web\nport: 80\n"), &v, DecodeJSONCodec(c)); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	if v.Name != "web" || v.Extra["port"] != float64(80) {
+		t.Errorf("Unmarshal() = %+v", v)
+	}
+	// The document and the keys of the rest field.
+	if c.marshals != 1 || c.unmarshals != 2 {
+		t.Errorf("Unmarshal() called the codec %d and %d times; want 1 and 2", c.marshals, c.unmarshals)
+	}
+
+	if _, err := Marshal(1, WithJSONCodec(failingCodec{})); err == nil {
+		t.Error("Marshal() did not return the error of the codec")
+	}
+	if err := Unmarshal([]byte("a: 1"), &v, DecodeJSONCodec(failingCodec{})); err == nil {
+		t.Error("Unmarshal() did not return the error of the codec")
+	}
+}
+
+type failingCodec struct{}
+
+func (failingCodec) Marshal(interface{}) ([]byte, error) { return nil, errors.New("codec failed") }
+func (failingCodec) Unmarshal([]byte, interface{}) error { return errors.New("codec failed") }
@@ -0,0 +1,63 @@
+package yaml
+
+// Contains reports whether the YAML stream superset holds everything in the
+// YAML stream subset, as Unmarshal reads them. A mapping contains another if
+// it has each of its keys with a value that contains the other's value; a
+// sequence contains another if each item of the other is contained by an item
+// of the sequence, in the same order; and scalars contain only the values they
+// are equal to, as Equal compares them. The documents of a stream are matched
+// in order, like the items of a sequence.
+//
+// Contains suits assertions on partial objects, such as a Kubernetes
+// Deployment given only by the fields a test cares about.
+func Contains(superset, subset []byte) (bool, error) {
+	sup, err := semanticDocuments(superset)
+	if err != nil {
+		return false, err
+	}
+	sub, err := semanticDocuments(subset)
+	if err != nil {
+		return false, err
+	}
+	return containsSequence(sup, sub), nil
+}
+
+// containsValue reports whether the JSON-compatible value sup contains sub.
+func containsValue(sup, sub interface{}) bool {
+	switch sub := sub.(type) {
+	case map[string]interface{}:
+		sup, ok := sup.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for k, v := range sub {
+			w, ok := sup[k]
+			if !ok || !containsValue(w, v) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		sup, ok := sup.([]interface{})
+		return ok && containsSequence(sup, sub)
+	}
+	return equalValues(sup, sub)
+}
+
+// containsSequence reports whether each item of sub is contained by an item of
+// sup, in the same order.
+func containsSequence(sup, sub []interface{}) bool {
+	i := 0
+	for _, v := range sub {
+		// Matching each item with the first one that contains it leaves
+		// as many as possible for the rest.
+		for i < len(sup) && !containsValue(sup[i], v) {
+			i++
+		}
+		if i == len(sup) {
+			return false
+		}
+		i++
+	}
+	return true
+}
@@ -0,0 +1,54 @@
+package yaml
+
+import "testing"
+
+func TestContains(t *testing.T) {
+	deployment := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels: {app: web, tier: front}
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - name: web
+        image: nginx:1.25
+        ports: [{containerPort: 80}]
+      - name: sidecar
+        image: envoy
+`
+	for _, tc := range []struct {
+		subset string
+		want   bool
+	}{
+		{"kind: Deployment\n", true},
+		{"metadata: {labels: {app: web}}\nspec: {replicas: 3.0}\n", true},
+		{"spec:\n  template:\n    spec:\n      containers:\n      - name: sidecar\n", true},
+		{"spec:\n  template:\n    spec:\n      containers:\n      - name: web\n        ports: [{containerPort: 80}]\n      - image: envoy\n", true},
+		{"spec:\n  template:\n    spec:\n      containers:\n      - name: sidecar\n      - name: web\n", false},
+		{"spec: {replicas: '3'}\n", false},
+		{"metadata: {namespace: default}\n", false},
+		{"metadata: [web]\n", false},
+		{"{}\n", true},
+		{"kind: Deployment\n---\nkind: Service\n", false},
+	} {
+		got, err := Contains([]byte(deployment), []byte(tc.subset))
+		if err != nil {
+			t.Fatalf("Contains(%#q) = %v", tc.subset, err)
+		}
+		if got != tc.want {
+			t.Errorf("Contains(%#q) = %v; want %v", tc.subset, got, tc.want)
+		}
+	}
+
+	got, err := Contains([]byte("kind: A\n---\nkind: B\n---\nkind: C\n"), []byte("kind: A\n---\nkind: C\n"))
+	if err != nil || !got {
+		t.Errorf("Contains() of documents = %v, %v; want true", got, err)
+	}
+
+	if _, err := Contains([]byte(deployment), []byte("a: [")); err == nil {
+		t.Error("Contains() of invalid YAML returned no error")
+	}
+}
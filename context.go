@@ -0,0 +1,69 @@
+package yaml
+
+import (
+	"context"
+
+	"gopkg.in/yaml.v2"
+)
+
+// contextCheckInterval is the number of values converted between checks of
+// the context of a conversion.
+const contextCheckInterval = 1024
+
+// UnmarshalContext is like Unmarshal, but stops with the error of ctx once
+// ctx is done, such as when the client of the request whose body is being
+// decoded goes away. The context is checked before the document is parsed,
+// periodically while it is converted to JSON, and before it is decoded;
+// go-yaml cannot be stopped while it parses, so a document is always parsed
+// in full once that starts.
+func UnmarshalContext(ctx context.Context, y []byte, o interface{}, opts ...JSONOpt) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return unmarshal(yaml.Unmarshal, y, o, withContext(ctx, opts))
+}
+
+// UnmarshalContext is like the function UnmarshalContext with the options of
+// d.
+func (d *Decoder) UnmarshalContext(ctx context.Context, y []byte, o interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	dc := *d
+	dc.opts.ctx = ctx
+	return dc.Unmarshal(y, o)
+}
+
+// YAMLToJSONContext is like YAMLToJSONWithOpts, but stops with the error of
+// ctx once ctx is done, as UnmarshalContext does.
+func YAMLToJSONContext(ctx context.Context, y []byte, opts ...JSONOpt) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	j, err := YAMLToJSONWithOpts(y, withContext(ctx, opts)...)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	return j, err
+}
+
+// withContext returns opts with the option that sets the context of the
+// conversion to ctx.
+func withContext(ctx context.Context, opts []JSONOpt) []JSONOpt {
+	return append(opts[:len(opts):len(opts)], decodeOpt(func(o *decodeOptions) {
+		o.ctx = ctx
+	}))
+}
+
+// checkContext returns the error of the context of o if it is done, checking
+// it once every contextCheckInterval calls.
+func (o *decodeOptions) checkContext() error {
+	if o.ctx == nil {
+		return nil
+	}
+	o.converted++
+	if o.converted%contextCheckInterval != 1 {
+		return nil
+	}
+	return o.ctx.Err()
+}
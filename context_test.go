@@ -0,0 +1,50 @@
+package yaml
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUnmarshalContext(t *testing.T) {
+	var v map[string]int
+	if err := UnmarshalContext(context.Background(), []byte("a: 1\n"), &v); err != nil || v["a"] != 1 {
+		t.Fatalf("UnmarshalContext() = %v, %v", v, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := UnmarshalContext(ctx, []byte("a: 1\n"), &v); !errors.Is(err, context.Canceled) {
+		t.Errorf("UnmarshalContext() = %v, want %v", err, context.Canceled)
+	}
+	if _, err := YAMLToJSONContext(ctx, []byte("a: 1\n")); !errors.Is(err, context.Canceled) {
+		t.Errorf("YAMLToJSONContext() = %v, want %v", err, context.Canceled)
+	}
+	if err := NewDecoder().UnmarshalContext(ctx, []byte("a: 1\n"), &v); !errors.Is(err, context.Canceled) {
+		t.Errorf("Decoder.UnmarshalContext() = %v, want %v", err, context.Canceled)
+	}
+
+	// The context is done while the document is being converted.
+	var b strings.Builder
+	for i := 0; i < 3*contextCheckInterval; i++ {
+		fmt.Fprintf(&b, "k%d: %d\n", i, i)
+	}
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	hooks := 0
+	cancelling := DecodeHooks(func(from, to reflect.Type, v interface{}) (interface{}, error) {
+		if hooks++; hooks == 10 {
+			cancel()
+		}
+		return v, nil
+	})
+	if err := UnmarshalContext(ctx, []byte(b.String()), &v, cancelling); !errors.Is(err, context.Canceled) {
+		t.Errorf("UnmarshalContext() = %v, want %v", err, context.Canceled)
+	}
+	if hooks >= 3*contextCheckInterval {
+		t.Errorf("the conversion went on after the context was done, with %d hooks called", hooks)
+	}
+}
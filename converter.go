@@ -0,0 +1,164 @@
+package yaml
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+
+	"gopkg.in/yaml.v2"
+)
+
+// maxFoundFields is the number of keys a Converter caches the fields of.
+const maxFoundFields = 4096
+
+// Converter converts documents with options applied once, as a Decoder does,
+// and keeps what it allocates to convert them from one call to the next: the
+// buffers of the JSON documents, the json.Decoder reading them, the paths of
+// the values being converted, and the struct fields that keys were found to
+// match. A service that converts documents at a high rate can keep a
+// Converter in each goroutine to avoid allocating these again for every
+// document. The YAML itself is parsed by go-yaml, which allocates its own
+// buffers on every call.
+//
+// A Converter is not safe for concurrent use.
+type Converter struct {
+	dec *Decoder
+	// fast is set if the Converter has no options, so that Unmarshal may
+	// take the fast path of the function Unmarshal.
+	fast bool
+	// fields caches findField, for the options of dec.
+	fields map[foundFieldKey]foundField
+	paths  keyPaths
+	// j is the JSON document being decoded, which jd reads. jd is made on
+	// first use and again after an error, since what is left of j then is
+	// not known.
+	j  bytes.Buffer
+	jd *json.Decoder
+	// out holds the document last returned by YAMLToJSON.
+	out bytes.Buffer
+}
+
+// NewConverter returns a Converter with the options opts, which are the
+// options of Unmarshal, kept as NewDecoder keeps them.
+func NewConverter(opts ...JSONOpt) *Converter {
+	return &Converter{
+		dec:    NewDecoder(opts...),
+		fast:   len(opts) == 0,
+		fields: map[foundFieldKey]foundField{},
+	}
+}
+
+// options returns the options of a conversion by c.
+func (c *Converter) options() *decodeOptions {
+	do := c.dec.options()
+	// Calls of a Converter are not concurrent, so they may all write to the
+	// map of a DecodeFieldPositions option.
+	do.fieldPositions = c.dec.opts.fieldPositions
+	do.foundFields = c.fields
+	c.paths.reset()
+	do.keyPaths = &c.paths
+	return do
+}
+
+// Unmarshal is like the function Unmarshal with the options of c.
+func (c *Converter) Unmarshal(y []byte, o interface{}) error {
+	if c.fast && fastUnmarshal(y, o) {
+		return nil
+	}
+	return c.unmarshal(yaml.Unmarshal, y, o)
+}
+
+// UnmarshalStrict is like the function UnmarshalStrict with the options of
+// c.
+func (c *Converter) UnmarshalStrict(y []byte, o interface{}) error {
+	return c.unmarshal(yaml.UnmarshalStrict, y, o)
+}
+
+func (c *Converter) unmarshal(f func(in []byte, out interface{}) (err error), y []byte, o interface{}) error {
+	if c.jd == nil {
+		c.j.Reset()
+		c.jd = c.dec.jsonDecoder(&c.j)
+	}
+	// Each document is written to j after the one before it was read, so
+	// that jd reads them as a stream of JSON values.
+	err := decode(f, y, o, &c.j, c.jd, c.options(), c.dec.jsonDecoder)
+	if err != nil {
+		c.jd = nil
+	}
+	return err
+}
+
+// YAMLToJSON is like YAMLToJSONWithOpts with the options of c. The JSON
+// document returned is held by c and is only valid until the next call of
+// YAMLToJSON; it must be copied to be kept longer.
+func (c *Converter) YAMLToJSON(y []byte) ([]byte, error) {
+	jsonObj, err := yamlToJSONObject(y, nil, yaml.Unmarshal, c.options())
+	if err != nil {
+		return nil, err
+	}
+	c.out.Reset()
+	if err := json.NewEncoder(&c.out).Encode(jsonObj); err != nil {
+		return nil, err
+	}
+	// Encode ends the document with a newline, which json.Marshal does not
+	// write.
+	return bytes.TrimSuffix(c.out.Bytes(), []byte("\n")), nil
+}
+
+// foundFieldKey is a key of the struct type t.
+type foundFieldKey struct {
+	t   reflect.Type
+	key string
+}
+
+// foundField is what findField returns for a foundFieldKey.
+type foundField struct {
+	f       *field
+	jsonKey string
+}
+
+// keyPathBlock is the number of keyPaths that keyPaths allocates at once.
+const keyPathBlock = 64
+
+// keyPaths allocates keyPaths in blocks that are reused once reset. Paths
+// only live as long as the conversion they are made for, as anything kept
+// from them, such as the path of an error, is formatted first.
+type keyPaths struct {
+	blocks [][]keyPath
+	// block is the block in use, of which n keyPaths are allocated.
+	block, n int
+}
+
+func (a *keyPaths) reset() {
+	a.block, a.n = 0, 0
+}
+
+func (a *keyPaths) alloc(p keyPath) *keyPath {
+	if a.block == len(a.blocks) {
+		a.blocks = append(a.blocks, make([]keyPath, keyPathBlock))
+	}
+	kp := &a.blocks[a.block][a.n]
+	*kp = p
+	if a.n++; a.n == keyPathBlock {
+		a.block, a.n = a.block+1, 0
+	}
+	return kp
+}
+
+// pathKey returns path.key(name), allocated by the keyPaths of o if it has
+// them.
+func (o *decodeOptions) pathKey(path *keyPath, name string) *keyPath {
+	if o.keyPaths == nil {
+		return path.key(name)
+	}
+	return o.keyPaths.alloc(keyPath{parent: path, name: name, idx: -1})
+}
+
+// pathIndex returns path.index(i), allocated by the keyPaths of o if it has
+// them.
+func (o *decodeOptions) pathIndex(path *keyPath, i int) *keyPath {
+	if o.keyPaths == nil {
+		return path.index(i)
+	}
+	return o.keyPaths.alloc(keyPath{parent: path, idx: i})
+}
@@ -0,0 +1,174 @@
+package yaml
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestConverter(t *testing.T) {
+	type server struct {
+		Name  string            `json:"name"`
+		Ports []int             `json:"ports"`
+		Tags  map[string]string `json:"tags"`
+		Extra interface{}       `json:"extra"`
+	}
+	docs := []string{
+		"name: web\nports: [80, 443]\ntags: {tier: front}\n",
+		"name: db\nports:\n- 5432\nextra: 1\n",
+		"name: cache\nports: []\nextra: [a, {b: c}]\n",
+		// A flat mapping of scalars, which takes the fast path.
+		"name: flat\n",
+	}
+
+	c := NewConverter()
+	for round := 0; round < 3; round++ {
+		for _, doc := range docs {
+			var got, want server
+			gotErr := c.Unmarshal([]byte(doc), &got)
+			wantErr := Unmarshal([]byte(doc), &want)
+			if gotErr != nil || wantErr != nil || !reflect.DeepEqual(got, want) {
+				t.Errorf("Unmarshal(%q) = %v, %+v, want %v, %+v", doc, gotErr, got, wantErr, want)
+			}
+
+			gotJ, gotErr := c.YAMLToJSON([]byte(doc))
+			wantJ, wantErr := YAMLToJSON([]byte(doc))
+			if gotErr != nil || wantErr != nil || string(gotJ) != string(wantJ) {
+				t.Errorf("YAMLToJSON(%q) = %v, %s, want %v, %s", doc, gotErr, gotJ, wantErr, wantJ)
+			}
+		}
+	}
+
+	// Documents of other types and top-level scalars are read from the same
+	// stream of JSON values.
+	var n int
+	if err := c.Unmarshal([]byte("42"), &n); err != nil || n != 42 {
+		t.Errorf("Unmarshal() of a number = %v, %d", err, n)
+	}
+	var s []string
+	if err := c.Unmarshal([]byte("[a, b]"), &s); err != nil || !reflect.DeepEqual(s, []string{"a", "b"}) {
+		t.Errorf("Unmarshal() of a sequence = %v, %q", err, s)
+	}
+
+	// The Converter goes on after an error in the middle of the JSON
+	// document.
+	var srv server
+	if err := c.Unmarshal([]byte("name: [x]\nports: [1]\n"), &srv); err == nil {
+		t.Error("Unmarshal() of a sequence into a string returned no error")
+	}
+	srv = server{}
+	if err := c.Unmarshal([]byte("name: web\nports: [1]\n"), &srv); err != nil || srv.Name != "web" || !reflect.DeepEqual(srv.Ports, []int{1}) {
+		t.Errorf("Unmarshal() after an error = %v, %+v", err, srv)
+	}
+	if _, err := c.YAMLToJSON([]byte("a: [")); err == nil {
+		t.Error("YAMLToJSON() of invalid YAML returned no error")
+	}
+}
+
+func TestConverterOptions(t *testing.T) {
+	var c struct {
+		Count interface{} `json:"count"`
+	}
+	conv := NewConverter(func(d *json.Decoder) *json.Decoder { d.UseNumber(); return d }, DisallowUnknownFields)
+	for i := 0; i < 2; i++ {
+		if err := conv.Unmarshal([]byte("count: 1\n"), &c); err != nil || c.Count != json.Number("1") {
+			t.Errorf("Unmarshal() = %v, %+v", err, c)
+		}
+		if err := conv.Unmarshal([]byte("name: ann\n"), &c); err == nil || !strings.Contains(err.Error(), `unknown field "name"`) {
+			t.Errorf("Unmarshal() of an unknown field = %v", err)
+		}
+	}
+	if err := conv.UnmarshalStrict([]byte("count: 1\ncount: 2\n"), &c); err == nil {
+		t.Error("UnmarshalStrict() of a duplicate key returned no error")
+	}
+
+	// The paths of the unknown fields found in one document are not
+	// overwritten by the paths of the next, which reuse their memory.
+	type item struct {
+		Name string `json:"name"`
+	}
+	var items struct {
+		Items []item `json:"items"`
+	}
+	strict := NewConverter(StrictFields())
+	var errs []error
+	for _, doc := range []string{"items: [{nmae: a}, {name: b}]\n", "items: [{name: a}, {name: b}, {naem: c}]\n"} {
+		errs = append(errs, strict.Unmarshal([]byte(doc), &items))
+	}
+	want := []string{
+		`unknown fields: items[0].nmae`,
+		`unknown fields: items[2].naem`,
+	}
+	for i, err := range errs {
+		if err == nil || err.Error() != want[i] {
+			t.Errorf("Unmarshal() of document %d = %v, want %s", i, err, want[i])
+		}
+	}
+
+	j, err := NewConverter(AmbiguousNumbersAsStrings()).YAMLToJSON([]byte("v: 1e5\n"))
+	wantJ, _ := YAMLToJSONWithOpts([]byte("v: 1e5\n"), AmbiguousNumbersAsStrings())
+	if err != nil || string(j) != string(wantJ) {
+		t.Errorf("YAMLToJSON() = %v, %s, want %s", err, j, wantJ)
+	}
+}
+
+const benchmarkConverterDoc = `name: web
+ports: [80, 443]
+tags: {tier: front, team: platform}
+backends:
+- host: a.example.com
+  weight: 1
+- host: b.example.com
+  weight: 2
+`
+
+type benchmarkConverterConfig struct {
+	Name     string            `json:"name"`
+	Ports    []int             `json:"ports"`
+	Tags     map[string]string `json:"tags"`
+	Backends []struct {
+		Host   string `json:"host"`
+		Weight int    `json:"weight"`
+	} `json:"backends"`
+}
+
+func BenchmarkConverter(b *testing.B) {
+	y := []byte(benchmarkConverterDoc)
+	b.Run("Unmarshal", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var c benchmarkConverterConfig
+			if err := Unmarshal(y, &c); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Converter", func(b *testing.B) {
+		b.ReportAllocs()
+		conv := NewConverter()
+		for i := 0; i < b.N; i++ {
+			var c benchmarkConverterConfig
+			if err := conv.Unmarshal(y, &c); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("YAMLToJSON", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := YAMLToJSON(y); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("ConverterYAMLToJSON", func(b *testing.B) {
+		b.ReportAllocs()
+		conv := NewConverter()
+		for i := 0; i < b.N; i++ {
+			if _, err := conv.YAMLToJSON(y); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
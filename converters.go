@@ -0,0 +1,73 @@
+package yaml
+
+import (
+	"fmt"
+	"reflect"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// Converters makes Marshal convert values with the functions fns, each of
+// the form func(v T) (interface{}, error), before they are marshaled to
+// JSON, and write what a function returns in place of a value of its type T,
+// so that types one does not own, such as the structs of a vendor SDK, are
+// written sensibly without wrapping them:
+//
+//	yaml.Converters(func(c *sdk.Client) (interface{}, error) {
+//		return map[string]string{"endpoint": c.Endpoint()}, nil
+//	})
+//
+// A function applies to the values of its type wherever they are found,
+// rather than their json tags or MarshalJSON method, except within what
+// MarshalJSON methods write. What it returns is written like a value passed
+// to Marshal, converters included, so it must not be of the type T itself.
+// Converters panics if a function is not of that form.
+func Converters(fns ...interface{}) MarshalOpt {
+	converters := make(map[reflect.Type]reflect.Value, len(fns))
+	for _, fn := range fns {
+		f := reflect.ValueOf(fn)
+		t := f.Type()
+		if f.Kind() != reflect.Func || t.NumIn() != 1 || t.NumOut() != 2 ||
+			t.Out(0).Kind() != reflect.Interface || t.Out(0).NumMethod() != 0 || t.Out(1) != errorType {
+			panic(fmt.Sprintf("yaml: converter of type %T is not a func(T) (interface{}, error)", fn))
+		}
+		converters[t.In(0)] = f
+	}
+	return func(e *encoder) {
+		if e.converters == nil {
+			e.converters = make(map[reflect.Type]reflect.Value)
+		}
+		for t, f := range converters {
+			e.converters[t] = f
+		}
+	}
+}
+
+// convert returns the value the converter of e for the type of v returns for
+// it, and false if there is none.
+func (e *encoder) convert(v reflect.Value) (interface{}, bool, error) {
+	f, ok := e.converters[v.Type()]
+	if !ok || !v.CanInterface() {
+		return nil, false, nil
+	}
+	out := f.Call([]reflect.Value{v})
+	if err, _ := out[1].Interface().(error); err != nil {
+		return nil, true, fmt.Errorf("converting %v: %v", v.Type(), err)
+	}
+	return out[0].Interface(), true, nil
+}
+
+// converted reports whether v, or what it points to, is converted by a
+// converter of e.
+func (e *encoder) converted(v reflect.Value) bool {
+	for v.IsValid() {
+		if _, ok := e.converters[v.Type()]; ok {
+			return true
+		}
+		if v.Kind() != reflect.Ptr && v.Kind() != reflect.Interface || v.IsNil() {
+			return false
+		}
+		v = v.Elem()
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package yaml
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// vendorClient stands for a type of another package, which JSON cannot hold.
+type vendorClient struct {
+	endpoint string
+	calls    chan string
+}
+
+type vendorRegion struct {
+	Name string
+}
+
+func TestConverters(t *testing.T) {
+	type service struct {
+		Name    string         `json:"name"`
+		Client  *vendorClient  `json:"client"`
+		Regions []vendorRegion `json:"regions"`
+	}
+	s := service{
+		Name:    "api",
+		Client:  &vendorClient{endpoint: "https://example.com", calls: make(chan string)},
+		Regions: []vendorRegion{{"eu"}, {"us"}},
+	}
+	convert := Converters(
+		func(c *vendorClient) (interface{}, error) {
+			return map[string]interface{}{"endpoint": c.endpoint, "region": vendorRegion{"eu"}}, nil
+		},
+		func(r vendorRegion) (interface{}, error) {
+			return strings.ToUpper(r.Name), nil
+		},
+	)
+	want := "client:\n  endpoint: https://example.com\n  region: EU\nname: api\nregions:\n- EU\n- US\n"
+	y, err := Marshal(s, convert)
+	if err != nil || string(y) != want {
+		t.Errorf("Marshal() = %q, %v, want %q", y, err, want)
+	}
+
+	// Struct options do not apply within converted values.
+	type untagged struct {
+		RegionName vendorRegion
+	}
+	y, err = Marshal(untagged{vendorRegion{"eu"}}, FieldNaming(SnakeCase), Converters(func(r vendorRegion) (interface{}, error) {
+		return map[string]string{"Name": r.Name}, nil
+	}))
+	if want := "region_name:\n  Name: eu\n"; err != nil || string(y) != want {
+		t.Errorf("Marshal() = %q, %v, want %q", y, err, want)
+	}
+
+	_, err = Marshal(s, Converters(func(*vendorClient) (interface{}, error) {
+		return nil, errors.New("closed")
+	}))
+	if err == nil || !strings.Contains(err.Error(), "closed") {
+		t.Errorf("Marshal() = %v, want the error of the converter", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Converters() did not panic for a func of another form")
+		}
+	}()
+	Converters(func(vendorRegion) string { return "" })
+}
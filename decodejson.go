@@ -0,0 +1,68 @@
+//go:build !goexperiment.jsonv2 || !go1.27
+
+package yaml
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v2"
+)
+
+// decodeJSON decodes a JSON document into the values go-yaml would produce
+// for it, except that objects are decoded into a yaml.MapSlice so that the
+// order of their keys is kept.
+func decodeJSON(j []byte) (interface{}, error) {
+	d := json.NewDecoder(bytes.NewReader(j))
+	d.UseNumber()
+	v, err := decodeJSONValue(d)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := d.Token(); err != io.EOF {
+		return nil, fmt.Errorf("invalid data after top-level value")
+	}
+	return v, nil
+}
+
+func decodeJSONValue(d *json.Decoder) (interface{}, error) {
+	t, err := d.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := t.(type) {
+	case json.Delim:
+		if t == '{' {
+			m := yaml.MapSlice{}
+			for d.More() {
+				k, err := d.Token()
+				if err != nil {
+					return nil, err
+				}
+				v, err := decodeJSONValue(d)
+				if err != nil {
+					return nil, err
+				}
+				m = append(m, yaml.MapItem{Key: k, Value: v})
+			}
+			_, err = d.Token()
+			return m, err
+		}
+		s := []interface{}{}
+		for d.More() {
+			v, err := decodeJSONValue(d)
+			if err != nil {
+				return nil, err
+			}
+			s = append(s, v)
+		}
+		_, err = d.Token()
+		return s, err
+	case json.Number:
+		return jsonNumberValue(string(t))
+	default:
+		return t, nil
+	}
+}
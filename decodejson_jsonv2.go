@@ -0,0 +1,76 @@
+//go:build goexperiment.jsonv2 && go1.27
+
+package yaml
+
+import (
+	"bytes"
+	"encoding/json/jsontext"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v2"
+)
+
+// With encoding/json/v2 available, the JSON documents that Marshal converts
+// to YAML are read token by token by jsontext, which unlike the Token method
+// of encoding/json does not box every token in an interface and reads the
+// text of numbers and keys without copying it first.
+
+// decodeJSON decodes a JSON document into the values go-yaml would produce
+// for it, except that objects are decoded into a yaml.MapSlice so that the
+// order of their keys is kept.
+func decodeJSON(j []byte) (interface{}, error) {
+	d := jsontext.NewDecoder(bytes.NewReader(j), jsontext.AllowDuplicateNames(true), jsontext.AllowInvalidUTF8(true))
+	v, err := decodeJSONValue(d)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := d.ReadToken(); err != io.EOF {
+		return nil, fmt.Errorf("invalid data after top-level value")
+	}
+	return v, nil
+}
+
+func decodeJSONValue(d *jsontext.Decoder) (interface{}, error) {
+	t, err := d.ReadToken()
+	if err != nil {
+		return nil, err
+	}
+	switch t.Kind() {
+	case '{':
+		m := yaml.MapSlice{}
+		for d.PeekKind() != '}' {
+			k, err := d.ReadToken()
+			if err != nil {
+				return nil, err
+			}
+			// The token is only valid until the next read.
+			key := k.String()
+			v, err := decodeJSONValue(d)
+			if err != nil {
+				return nil, err
+			}
+			m = append(m, yaml.MapItem{Key: key, Value: v})
+		}
+		_, err = d.ReadToken()
+		return m, err
+	case '[':
+		s := []interface{}{}
+		for d.PeekKind() != ']' {
+			v, err := decodeJSONValue(d)
+			if err != nil {
+				return nil, err
+			}
+			s = append(s, v)
+		}
+		_, err = d.ReadToken()
+		return s, err
+	case '0':
+		return jsonNumberValue(t.String())
+	case '"':
+		return t.String(), nil
+	case 't', 'f':
+		return t.Bool(), nil
+	}
+	return nil, nil
+}
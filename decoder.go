@@ -0,0 +1,95 @@
+package yaml
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Decoder unmarshals YAML documents with options applied once, when it is
+// made, rather than on every call as Unmarshal does. A Decoder is safe for
+// concurrent use. The map of a DecodeFieldPositions option, which all calls
+// would write to, is left as it is; UnmarshalPositions returns the positions
+// of each call instead.
+type Decoder struct {
+	opts decodeOptions
+	// jsonOpts are the options that configure the json.Decoder, which are
+	// applied to a new one for every document.
+	jsonOpts []JSONOpt
+}
+
+// NewDecoder returns a Decoder with the options opts, which are the options
+// of Unmarshal.
+func NewDecoder(opts ...JSONOpt) *Decoder {
+	dec := &Decoder{}
+	// The options that configure the json.Decoder are told from the others
+	// by applying them to one that is not used.
+	jd := json.NewDecoder(bytes.NewReader(nil))
+	for _, opt := range opts {
+		var set bool
+		if jd, set = applyJSONOpt(opt, jd, &dec.opts); !set {
+			dec.jsonOpts = append(dec.jsonOpts, opt)
+		}
+	}
+	return dec
+}
+
+// Unmarshal is like the function Unmarshal with the options of d.
+func (d *Decoder) Unmarshal(y []byte, o interface{}) error {
+	return d.unmarshal(yaml.Unmarshal, y, o)
+}
+
+// UnmarshalStrict is like the function UnmarshalStrict with the options of
+// d.
+func (d *Decoder) UnmarshalStrict(y []byte, o interface{}) error {
+	return d.unmarshal(yaml.UnmarshalStrict, y, o)
+}
+
+// UnmarshalPositions is like Unmarshal, but also returns where each value
+// decoded into a struct field is written in y, as DecodeFieldPositions
+// records it.
+func (d *Decoder) UnmarshalPositions(y []byte, o interface{}) (map[string]Position, error) {
+	do := d.options()
+	do.fieldPositions = map[string]Position{}
+	if err := d.decode(yaml.Unmarshal, y, o, do); err != nil {
+		return nil, err
+	}
+	return do.fieldPositions, nil
+}
+
+func (d *Decoder) unmarshal(f func(in []byte, out interface{}) (err error), y []byte, o interface{}) error {
+	return d.decode(f, y, o, d.options())
+}
+
+// decode is like the function decode with the options of d and the
+// settings do.
+func (d *Decoder) decode(f func(in []byte, out interface{}) (err error), y []byte, o interface{}, do *decodeOptions) error {
+	var j bytes.Buffer
+	return decode(f, y, o, &j, d.jsonDecoder(&j), do, d.jsonDecoder)
+}
+
+// options returns a copy of the options of d, so that calls do not share the
+// state a conversion keeps in them.
+func (d *Decoder) options() *decodeOptions {
+	do := d.opts
+	do.fieldPositions = nil
+	if do.includes != nil {
+		// The includer keeps the stack of the documents being included.
+		in := *do.includes
+		in.stack = nil
+		do.includes = &in
+	}
+	return &do
+}
+
+// jsonDecoder returns a json.Decoder reading from r with the options of d
+// that configure it.
+func (d *Decoder) jsonDecoder(r io.Reader) *json.Decoder {
+	jd := json.NewDecoder(r)
+	for _, opt := range d.jsonOpts {
+		jd = opt(jd)
+	}
+	return jd
+}
@@ -0,0 +1,106 @@
+package yaml
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+)
+
+func TestDecoder(t *testing.T) {
+	type config struct {
+		UserName string      `json:"user_name"`
+		Count    interface{} `json:"count"`
+		Peer     *config     `json:"peer"`
+	}
+	fsys := fstest.MapFS{"peer.yaml": {Data: []byte("user_name: bob\ncount: 2\n")}}
+	d := NewDecoder(
+		func(d *json.Decoder) *json.Decoder { d.UseNumber(); return d },
+		DecodeIncludes(IncludeFS(fsys), 2),
+	)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var c config
+			if err := d.Unmarshal([]byte("user_name: ann\ncount: 1\npeer: !include peer.yaml\n"), &c); err != nil {
+				t.Errorf("Unmarshal() = %v", err)
+				return
+			}
+			if c.UserName != "ann" || c.Count != json.Number("1") || c.Peer == nil || c.Peer.UserName != "bob" {
+				t.Errorf("Unmarshal() = %+v", c)
+			}
+		}()
+	}
+	wg.Wait()
+
+	strict := NewDecoder(DisallowUnknownFields)
+	var c config
+	if err := strict.Unmarshal([]byte("name: ann\n"), &c); err == nil || !strings.Contains(err.Error(), `unknown field "name"`) {
+		t.Errorf("Unmarshal() of an unknown field = %v", err)
+	}
+	if err := strict.UnmarshalStrict([]byte("count: 1\ncount: 2\n"), &c); err == nil {
+		t.Error("UnmarshalStrict() of a duplicate key returned no error")
+	}
+	if err := NewDecoder().Unmarshal([]byte("count: 1\n"), &c); err != nil || c.Count != float64(1) {
+		t.Errorf("Unmarshal() = %v, %+v", err, c)
+	}
+}
+
+func TestDecoderJSONOpts(t *testing.T) {
+	// The options that configure the json.Decoder are applied to the decoder
+	// of every document, whatever they do to it.
+	type config struct {
+		Name string `json:"name"`
+	}
+	var calls int32
+	d := NewDecoder(func(d *json.Decoder) *json.Decoder {
+		atomic.AddInt32(&calls, 1)
+		return json.NewDecoder(strings.NewReader(`{"name": "fixed"}`))
+	})
+	for i := 0; i < 2; i++ {
+		before := atomic.LoadInt32(&calls)
+		var c config
+		if err := d.Unmarshal([]byte("name: ann\n"), &c); err != nil || c.Name != "fixed" {
+			t.Errorf("Unmarshal() = %v, %+v", err, c)
+		}
+		if n := atomic.LoadInt32(&calls) - before; n != 1 {
+			t.Errorf("option applied %d times by Unmarshal(), want 1", n)
+		}
+	}
+}
+
+func TestDecoderUnmarshalPositions(t *testing.T) {
+	type config struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+	pos := map[string]Position{}
+	d := NewDecoder(DecodeFieldPositions(pos))
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			y := strings.Repeat("\n", i) + "name: ann\ncount: 1\n"
+			var c config
+			got, err := d.UnmarshalPositions([]byte(y), &c)
+			want := map[string]Position{"name": {i + 1, 1}, "count": {i + 2, 1}}
+			if err != nil || !reflect.DeepEqual(got, want) {
+				t.Errorf("UnmarshalPositions(%q) = %v, %v, want %v", y, got, err, want)
+			}
+			if err := d.Unmarshal([]byte(y), &c); err != nil {
+				t.Errorf("Unmarshal(%q) = %v", y, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if len(pos) != 0 {
+		t.Errorf("Decoder recorded %v in the map of DecodeFieldPositions", pos)
+	}
+}
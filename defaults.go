@@ -0,0 +1,117 @@
+package yaml
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+
+	"gopkg.in/yaml.v2"
+)
+
+// A struct field tagged `default:"..."` is given the value written in the tag
+// when the YAML mapping it is decoded from has no key for it. The value is
+// read as a YAML document, so that `default:"8080"` sets an int and
+// `default:"[a, b]"` a slice, with the same options as the rest of the
+// document. Any key for the field, even one with a null value, keeps the
+// default from being used. Defaults also apply to the fields of a struct
+// field, other than a pointer, whose key is missing, and to those of the
+// struct an empty document is decoded into.
+
+var defaultsCache sync.Map // map[reflect.Type]bool
+
+// typeHasDefaults reports whether the struct type t has a field with a
+// default, directly or in a struct field.
+func typeHasDefaults(t reflect.Type) bool {
+	if has, ok := defaultsCache.Load(t); ok {
+		return has.(bool)
+	}
+	has := findDefaults(t, map[reflect.Type]bool{})
+	defaultsCache.Store(t, has)
+	return has
+}
+
+func findDefaults(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if t.Kind() != reflect.Struct || seen[t] {
+		return false
+	}
+	seen[t] = true
+	for _, f := range cachedTypeFields(t) {
+		if f.hasDefault || findDefaults(t.FieldByIndex(f.index).Type, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyDefaults adds the defaults of the fields of the struct type t that
+// have no key in obj, the JSON object converted so far from the mapping at
+// path. set holds the names of the fields that had a key.
+func (o *decodeOptions) applyDefaults(obj map[string]interface{}, t reflect.Type, set map[string]bool, path *keyPath) error {
+	fields := cachedTypeFields(t)
+	for i := range fields {
+		f := &fields[i]
+		if set[f.name] || f.yamlInline || skipField(f, o.tags) {
+			continue
+		}
+		ft := t.FieldByIndex(f.index).Type
+		var v interface{}
+		switch {
+		case f.hasDefault:
+			var err error
+			if v, err = o.parseDefault(f.defaultTag); err != nil {
+				return fmt.Errorf("%s: invalid default %q: %v", path.key(f.name), f.defaultTag, err)
+			}
+		case ft.Kind() == reflect.Struct && typeHasDefaults(ft):
+			v = map[interface{}]interface{}{}
+		default:
+			continue
+		}
+		target := reflect.Zero(ft)
+		converted, err := convertToJSONableObject(v, &target, o, path.key(f.name))
+		if err != nil {
+			return err
+		}
+		if f.quoted {
+			converted = quotedValue(converted, f.typ)
+		}
+		obj[f.name] = converted
+	}
+	return nil
+}
+
+// checksMissingKeys reports whether decoding a mapping into the struct type t
+// looks for the fields that have no key, to give them defaults or report
+// them as required.
+func checksMissingKeys(t reflect.Type) bool {
+	return typeHasDefaults(t) || typeHasRequired(t)
+}
+
+// emptyStructDocument reports whether y holds no document and is decoded
+// into a struct of type t, or a pointer to one, whose missing keys are
+// checked. Such a document is decoded like an empty mapping.
+func emptyStructDocument(y []byte, t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || !checksMissingKeys(t) {
+		return false
+	}
+	var v interface{}
+	return yaml.NewDecoder(bytes.NewReader(y)).Decode(&v) == io.EOF
+}
+
+// parseDefault parses the value s of a default tag.
+func (o *decodeOptions) parseDefault(s string) (interface{}, error) {
+	if o.resolvesScalars() {
+		var t textYAML
+		if err := yaml.Unmarshal([]byte(s), &t); err != nil {
+			return nil, err
+		}
+		return o.resolveScalars(t.v, false), nil
+	}
+	var v interface{}
+	err := yaml.Unmarshal([]byte(s), &v)
+	return v, err
+}
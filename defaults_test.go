@@ -0,0 +1,63 @@
+package yaml
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDefaults(t *testing.T) {
+	type TLS struct {
+		Enabled bool   `json:"enabled" default:"true"`
+		Cert    string `json:"cert"`
+	}
+	type Server struct {
+		Host    string            `json:"host" default:"localhost"`
+		Port    int               `json:"port" default:"8080"`
+		Name    string            `json:"name" default:"web-1"`
+		Tags    []string          `json:"tags" default:"[a, b]"`
+		Labels  map[string]string `json:"labels" default:"{app: web}"`
+		Timeout *int              `json:"timeout" default:"30"`
+		TLS     TLS               `json:"tls"`
+		Backup  *TLS              `json:"backup"`
+		Count   int64             `json:"count,string" default:"5"`
+	}
+	thirty := 30
+	for _, tc := range []struct {
+		y    string
+		want Server
+	}{
+		{"host: example.com\n", Server{
+			Host: "example.com", Port: 8080, Name: "web-1", Tags: []string{"a", "b"},
+			Labels: map[string]string{"app": "web"}, Timeout: &thirty, TLS: TLS{Enabled: true}, Count: 5,
+		}},
+		{"port: null\ntags: []\ntimeout: ~\ntls: {cert: x.pem}\nname: web\n", Server{
+			Host: "localhost", Name: "web", Tags: []string{},
+			Labels: map[string]string{"app": "web"}, TLS: TLS{Enabled: true, Cert: "x.pem"}, Count: 5,
+		}},
+		{"# Nothing.\n", Server{
+			Host: "localhost", Port: 8080, Name: "web-1", Tags: []string{"a", "b"},
+			Labels: map[string]string{"app": "web"}, Timeout: &thirty, TLS: TLS{Enabled: true}, Count: 5,
+		}},
+		{"tls: {enabled: false}\nbackup: {}\n", Server{
+			Host: "localhost", Port: 8080, Name: "web-1", Tags: []string{"a", "b"},
+			Labels: map[string]string{"app": "web"}, Timeout: &thirty, Backup: &TLS{Enabled: true}, Count: 5,
+		}},
+	} {
+		var got Server
+		if err := Unmarshal([]byte(tc.y), &got); err != nil {
+			t.Errorf("Unmarshal(%#q) = %v", tc.y, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Unmarshal(%#q) = %+v; want %+v", tc.y, got, tc.want)
+		}
+	}
+
+	var bad struct {
+		Port int `json:"port" default:"[8080"`
+	}
+	if err := Unmarshal([]byte("{}"), &bad); err == nil || !strings.Contains(err.Error(), `port: invalid default "[8080"`) {
+		t.Errorf("Unmarshal() with an invalid default = %v", err)
+	}
+}
@@ -0,0 +1,209 @@
+package yaml
+
+import (
+	"sort"
+	"strconv"
+
+	yaml3 "gopkg.in/yaml.v3"
+)
+
+// Position is the 1-based line and column of a value in a YAML document. The
+// zero Position means the position is not known.
+type Position struct {
+	Line, Column int
+}
+
+func (p Position) String() string {
+	return strconv.Itoa(p.Line) + ":" + strconv.Itoa(p.Column)
+}
+
+// ChangeKind is the kind of a Change.
+type ChangeKind int
+
+const (
+	// Added marks a value that is only in the new document.
+	Added ChangeKind = iota
+	// Removed marks a value that is only in the old document.
+	Removed
+	// Changed marks a value that differs between the documents.
+	Changed
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Changed:
+		return "changed"
+	}
+	return "ChangeKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// Change is a difference between two YAML documents.
+type Change struct {
+	Kind ChangeKind
+	// Document is the index of the document in the YAML stream.
+	Document int
+	// Path is the path of the value, such as "spec.containers[0].image",
+	// or "" for the whole document.
+	Path string
+	// Old and New are the values in the old and new documents, in the
+	// JSON-compatible form of YAMLToJSON. Old is nil for added values and
+	// New for removed ones.
+	Old, New interface{}
+	// OldPos and NewPos are the positions of the values in the old and new
+	// documents. Values that come from merge keys have no known position.
+	OldPos, NewPos Position
+}
+
+// Diff compares the YAML streams old and new and returns the values that were
+// added, removed or changed, in the order of their paths. Values are compared
+// as Equal compares them, so differences in formatting, quoting, comments and
+// the order of keys are not reported. Sequences are compared item by item, so
+// an item inserted in the middle changes all the items after it.
+func Diff(old, new []byte) ([]Change, error) {
+	oldDocs, err := semanticDocuments(old)
+	if err != nil {
+		return nil, err
+	}
+	newDocs, err := semanticDocuments(new)
+	if err != nil {
+		return nil, err
+	}
+	d := &differ{}
+	if d.oldPos, err = valuePositions(old); err != nil {
+		return nil, err
+	}
+	if d.newPos, err = valuePositions(new); err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < len(oldDocs) || i < len(newDocs); i++ {
+		d.doc = i
+		switch {
+		case i >= len(newDocs):
+			d.change(Removed, nil, oldDocs[i], nil)
+		case i >= len(oldDocs):
+			d.change(Added, nil, nil, newDocs[i])
+		default:
+			d.diff(nil, oldDocs[i], newDocs[i])
+		}
+	}
+	return d.changes, nil
+}
+
+// differ collects the changes between two YAML streams.
+type differ struct {
+	oldPos, newPos []map[string]Position
+	doc            int
+	changes        []Change
+}
+
+// diff compares the values at path in the current documents.
+func (d *differ) diff(path *keyPath, old, new interface{}) {
+	switch o := old.(type) {
+	case map[string]interface{}:
+		n, ok := new.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(o)+len(n))
+		for k := range o {
+			keys = append(keys, k)
+		}
+		for k := range n {
+			if _, ok := o[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return keyLess(keys[i], keys[j])
+		})
+		for _, k := range keys {
+			ov, inOld := o[k]
+			nv, inNew := n[k]
+			switch {
+			case !inNew:
+				d.change(Removed, path.key(k), ov, nil)
+			case !inOld:
+				d.change(Added, path.key(k), nil, nv)
+			default:
+				d.diff(path.key(k), ov, nv)
+			}
+		}
+		return
+	case []interface{}:
+		n, ok := new.([]interface{})
+		if !ok {
+			break
+		}
+		for i := 0; i < len(o) || i < len(n); i++ {
+			switch {
+			case i >= len(n):
+				d.change(Removed, path.index(i), o[i], nil)
+			case i >= len(o):
+				d.change(Added, path.index(i), nil, n[i])
+			default:
+				d.diff(path.index(i), o[i], n[i])
+			}
+		}
+		return
+	}
+	if !equalValues(old, new) {
+		d.change(Changed, path, old, new)
+	}
+}
+
+func (d *differ) change(kind ChangeKind, path *keyPath, old, new interface{}) {
+	c := Change{Kind: kind, Document: d.doc, Path: path.String(), Old: old, New: new}
+	if kind != Added && d.doc < len(d.oldPos) {
+		c.OldPos = d.oldPos[d.doc][c.Path]
+	}
+	if kind != Removed && d.doc < len(d.newPos) {
+		c.NewPos = d.newPos[d.doc][c.Path]
+	}
+	d.changes = append(d.changes, c)
+}
+
+// valuePositions returns the positions of the values of each document of the
+// YAML stream y, by path.
+func valuePositions(y []byte) ([]map[string]Position, error) {
+	var docs []map[string]Position
+	err := eachNodeDocument(y, func(_ int, doc *yaml3.Node) {
+		pos := map[string]Position{}
+		recordPositions(doc, nil, pos)
+		docs = append(docs, pos)
+	})
+	return docs, err
+}
+
+// recordPositions adds the positions of n and the values it holds to pos,
+// keeping any already recorded for a path. The values an alias refers to are
+// recorded at their anchor.
+func recordPositions(n *yaml3.Node, path *keyPath, pos map[string]Position) {
+	if n.Kind != yaml3.DocumentNode {
+		if _, ok := pos[path.String()]; !ok {
+			pos[path.String()] = Position{n.Line, n.Column}
+		}
+	}
+	switch n.Kind {
+	case yaml3.DocumentNode:
+		for _, c := range n.Content {
+			recordPositions(c, path, pos)
+		}
+	case yaml3.AliasNode:
+		recordPositions(n.Alias, path, pos)
+	case yaml3.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if k := n.Content[i]; k.Kind == yaml3.ScalarNode && k.ShortTag() != "!!merge" {
+				recordPositions(n.Content[i+1], path.key(k.Value), pos)
+			}
+		}
+	case yaml3.SequenceNode:
+		for i, c := range n.Content {
+			recordPositions(c, path.index(i), pos)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package yaml
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	old := `name: web
+replicas: 3
+labels: {app: web, tier: front}
+ports: [80, 443]
+`
+	new := `# Scaled up.
+name: "web"
+replicas: 5
+labels:
+  app: web
+  team: core
+ports:
+- 80
+`
+	got, err := Diff([]byte(old), []byte(new))
+	if err != nil {
+		t.Fatalf("Diff() = %v", err)
+	}
+	want := []Change{
+		{Kind: Added, Path: "labels.team", New: "core", NewPos: Position{6, 9}},
+		{Kind: Removed, Path: "labels.tier", Old: "front", OldPos: Position{3, 26}},
+		{Kind: Removed, Path: "ports[1]", Old: 443, OldPos: Position{4, 13}},
+		{Kind: Changed, Path: "replicas", Old: 3, New: 5, OldPos: Position{2, 11}, NewPos: Position{3, 11}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() = %+v; want %+v", got, want)
+	}
+
+	got, err = Diff([]byte("a: &x {b: 1}\nc: *x\n"), []byte("a: {b: 1}\nc: {b: 2}\n---\nd: 1\n"))
+	if err != nil {
+		t.Fatalf("Diff() = %v", err)
+	}
+	want = []Change{
+		{Kind: Changed, Path: "c.b", Old: 1, New: 2, OldPos: Position{1, 11}, NewPos: Position{2, 8}},
+		{Kind: Added, Document: 1, New: map[string]interface{}{"d": 1}, NewPos: Position{4, 1}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() = %+v; want %+v", got, want)
+	}
+
+	got, err = Diff([]byte("a: 1\nb: [x]\n"), []byte("b: ['x']\na: 1.0\n"))
+	if err != nil || len(got) != 0 {
+		t.Errorf("Diff() of equal documents = %+v, %v", got, err)
+	}
+
+	if _, err := Diff([]byte("a: 1\n"), []byte("a: [")); err == nil {
+		t.Error("Diff() of invalid YAML returned no error")
+	}
+}
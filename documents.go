@@ -0,0 +1,145 @@
+package yaml
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// UnmarshalDocuments decodes each document of the stream y, in which
+// documents are separated by "---" lines, into a new element of the slice
+// that o points to, as Unmarshal would, replacing the slice. An empty
+// document, such as one between two "---" lines, decodes as null. Errors name
+// the document, counting from 0, as in "document 3: error unmarshaling JSON:
+// ...". With DecodeConcurrency, documents are decoded concurrently, which the
+// DecodeFieldPositions option does not support.
+func UnmarshalDocuments(y []byte, o interface{}, opts ...JSONOpt) error {
+	sv := reflect.ValueOf(o)
+	if sv.Kind() != reflect.Ptr || sv.IsNil() || sv.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("yaml: UnmarshalDocuments needs a pointer to a slice, not %T", o)
+	}
+	sv = sv.Elem()
+
+	y, err := toUTF8(y)
+	if err != nil {
+		return err
+	}
+	docs := splitDocuments(y)
+	d := NewDecoder(opts...)
+	elems := reflect.MakeSlice(sv.Type(), len(docs), len(docs))
+	errs := make([]error, len(docs))
+	decode := func(i int) {
+		errs[i] = d.Unmarshal(docs[i], elems.Index(i).Addr().Interface())
+	}
+
+	workers := d.opts.concurrency
+	if workers > len(docs) {
+		workers = len(docs)
+	}
+	if workers <= 1 {
+		for i := range docs {
+			if decode(i); errs[i] != nil {
+				break
+			}
+		}
+	} else {
+		next := make(chan int)
+		var wg sync.WaitGroup
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range next {
+					decode(i)
+				}
+			}()
+		}
+		for i := range docs {
+			next <- i
+		}
+		close(next)
+		wg.Wait()
+	}
+
+	for i, err := range errs {
+		if err != nil {
+			return fmt.Errorf("document %d: %w", i, err)
+		}
+	}
+	sv.Set(elems)
+	return nil
+}
+
+// DecodeConcurrency makes UnmarshalDocuments decode up to workers documents
+// at a time, each in its own goroutine, for streams of many documents. The
+// documents are still decoded into the slice in the order of the stream.
+// Other functions ignore it.
+func DecodeConcurrency(workers int) JSONOpt {
+	return decodeOpt(func(o *decodeOptions) {
+		o.concurrency = workers
+	})
+}
+
+// splitDocuments splits the stream y into its documents. YAML forbids "---"
+// and "..." at the start of a line within a document, so it is split at those
+// lines without being parsed. Each document keeps the "---" line it starts
+// with, along with any directives and comments before it.
+func splitDocuments(y []byte) [][]byte {
+	var s documentSplitter
+	for i := 0; i < len(y); {
+		j := bytes.IndexByte(y[i:], '\n') + 1
+		if j == 0 {
+			j = len(y) - i
+		}
+		s.line(y, i, j)
+		i += j
+	}
+	s.end(y, len(y))
+	return s.docs
+}
+
+// documentSplitter splits a stream into its documents as splitDocuments
+// does, a line at a time.
+type documentSplitter struct {
+	// docs are the documents found so far.
+	docs [][]byte
+	// start is the offset of the current document in the stream.
+	start int
+	// explicit is set if the current document has a "---" line, and content
+	// if it has anything but comments and directives.
+	explicit, content bool
+}
+
+// line reads the line y[i:i+j] of the stream y.
+func (s *documentSplitter) line(y []byte, i, j int) {
+	line := y[i : i+j]
+	switch {
+	case isMarker(line, "---"):
+		if s.explicit || s.content {
+			s.end(y, i)
+		}
+		s.explicit = true
+		// Content may follow the marker, as in "--- text".
+		if rest := bytes.TrimSpace(line[3:]); len(rest) > 0 && rest[0] != '#' {
+			s.content = true
+		}
+	case isMarker(line, "..."):
+		s.end(y, i+j)
+	default:
+		t := bytes.TrimSpace(line)
+		if len(t) > 0 && t[0] != '#' && !(t[0] == '%' && !s.explicit && !s.content) {
+			s.content = true
+		}
+	}
+}
+
+// end ends the current document at the offset at of the stream y, keeping
+// it unless it is empty.
+func (s *documentSplitter) end(y []byte, at int) {
+	if s.explicit || s.content {
+		s.docs = append(s.docs, y[s.start:at])
+	}
+	s.start = at
+	s.explicit, s.content = false, false
+}
@@ -0,0 +1,77 @@
+package yaml
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitDocuments(t *testing.T) {
+	tests := []struct {
+		y    string
+		want []string
+	}{
+		{"", nil},
+		{"# just a comment\n", nil},
+		{"a: 1\n", []string{"a: 1\n"}},
+		{"a: 1\n---\nb: 2", []string{"a: 1\n", "---\nb: 2"}},
+		{"# head\n---\na: 1\n---\n---\n", []string{"# head\n---\na: 1\n", "---\n", "---\n"}},
+		{"%YAML 1.2\n--- text\n...\n# tail\n", []string{"%YAML 1.2\n--- text\n...\n"}},
+		{"a: |\n  ---x\n---x: 1\n", []string{"a: |\n  ---x\n---x: 1\n"}},
+		{"a: 1\r\n---\r\nb: 2\r\n", []string{"a: 1\r\n", "---\r\nb: 2\r\n"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, d := range splitDocuments([]byte(tt.y)) {
+			got = append(got, string(d))
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitDocuments(%q) = %q, want %q", tt.y, got, tt.want)
+		}
+	}
+}
+
+func TestUnmarshalDocuments(t *testing.T) {
+	type manifest struct {
+		Kind string `json:"kind"`
+		N    int    `json:"index"`
+	}
+	var b strings.Builder
+	var want []*manifest
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(&b, "---\nkind: Pod\nindex: %d\n", i)
+		want = append(want, &manifest{"Pod", i})
+	}
+	b.WriteString("---\n")
+	want = append(want, nil)
+
+	for _, workers := range []int{0, 1, 8} {
+		var got []*manifest
+		if err := UnmarshalDocuments([]byte(b.String()), &got, DecodeConcurrency(workers), DisallowUnknownFields); err != nil {
+			t.Fatalf("UnmarshalDocuments() with %d workers = %v", workers, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("UnmarshalDocuments() with %d workers = %v", workers, got)
+		}
+	}
+
+	got := []manifest{{Kind: "old"}}
+	y := "kind: a\n---\nkind: b\nextra: 1\n---\nkind:\n\t- c\n"
+	err := UnmarshalDocuments([]byte(y), &got, DecodeConcurrency(2), DisallowUnknownFields)
+	if err == nil || !strings.HasPrefix(err.Error(), "document 1: ") {
+		t.Errorf("UnmarshalDocuments() = %v, want an error for document 1", err)
+	}
+	if len(got) != 1 || got[0].Kind != "old" {
+		t.Errorf("UnmarshalDocuments() changed the slice to %v on error", got)
+	}
+	var te *TabIndentError
+	if err := UnmarshalDocuments([]byte(y), &got); !errors.As(err, &te) || te.Line != 3 {
+		t.Errorf("UnmarshalDocuments() = %v, want a *TabIndentError on line 3", err)
+	}
+
+	if err := UnmarshalDocuments([]byte("a: 1"), &manifest{}); err == nil {
+		t.Error("UnmarshalDocuments() into a struct did not fail")
+	}
+}
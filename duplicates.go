@@ -0,0 +1,276 @@
+package yaml
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	yaml3 "gopkg.in/yaml.v3"
+)
+
+// Duplicate is a mapping or sequence that occurs more than once in a
+// document.
+type Duplicate struct {
+	// Document is the index of the document in the YAML stream.
+	Document int
+	// Paths are the paths of the occurrences, such as
+	// "spec.containers[1].resources", in the order of the document.
+	Paths []string
+	// Size is the number of nodes in the subtree, counting every key, value
+	// and collection once.
+	Size int
+}
+
+// FindDuplicates reports the mappings and sequences of at least minSize
+// nodes that occur more than once in the YAML stream y, such as blocks of
+// settings repeated across a generated config. Two subtrees are the same if
+// they hold the same values with the same keys in the same order. Only the
+// largest duplicates are reported: a subtree is left out unless at least two of
+// its occurrences lie outside the occurrences of larger duplicates. Subtrees that
+// hold anchors or aliases are never reported.
+func FindDuplicates(y []byte, minSize int) ([]Duplicate, error) {
+	var dups []Duplicate
+	err := eachNodeDocument(y, func(i int, doc *yaml3.Node) {
+		for _, g := range findDuplicates(doc, minSize) {
+			d := Duplicate{Document: i, Size: g.size}
+			for _, o := range g.occurrences {
+				d.Paths = append(d.Paths, o.path.String())
+			}
+			dups = append(dups, d)
+		}
+	})
+	return dups, err
+}
+
+// Deduplicate rewrites the YAML stream y so that each duplicate found by
+// FindDuplicates is written once, with an anchor, and referred to by an alias
+// everywhere else. Anchors are named after the key holding the subtree. The
+// documents hold the same values as before, and keep their comments.
+func Deduplicate(y []byte, minSize int) ([]byte, error) {
+	var out bytes.Buffer
+	enc := yaml3.NewEncoder(&out)
+	enc.SetIndent(2)
+	var encErr error
+	n := 0
+	err := eachNodeDocument(y, func(_ int, doc *yaml3.Node) {
+		n++
+		used := map[string]bool{}
+		collectAnchors(doc, used)
+		for _, g := range findDuplicates(doc, minSize) {
+			first := g.occurrences[0]
+			first.node.Anchor = anchorName(first.path, used)
+			for _, o := range g.occurrences[1:] {
+				*o.node = yaml3.Node{
+					Kind:   yaml3.AliasNode,
+					Value:  first.node.Anchor,
+					Alias:  first.node,
+					Line:   o.node.Line,
+					Column: o.node.Column,
+				}
+			}
+		}
+		untagMergeKeys(doc)
+		if encErr == nil {
+			encErr = enc.Encode(doc)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if encErr != nil {
+		return nil, encErr
+	}
+	if n == 0 {
+		return y, nil
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	if err := sameDocuments(y, out.Bytes()); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// eachNodeDocument parses each document of the YAML stream y with yaml.v3
+// and calls f with its index and root node.
+func eachNodeDocument(y []byte, f func(i int, doc *yaml3.Node)) error {
+	y, err := toUTF8(y)
+	if err != nil {
+		return err
+	}
+	d := yaml3.NewDecoder(bytes.NewReader(y))
+	for i := 0; ; i++ {
+		var doc yaml3.Node
+		if err := d.Decode(&doc); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		f(i, &doc)
+	}
+}
+
+// subtree is an occurrence of a duplicate.
+type subtree struct {
+	node *yaml3.Node
+	path *keyPath
+}
+
+// duplicateGroup holds the occurrences of a duplicate subtree.
+type duplicateGroup struct {
+	size        int
+	occurrences []subtree
+}
+
+// findDuplicates returns the duplicates of the document doc, in the order of
+// their first occurrence.
+func findDuplicates(doc *yaml3.Node, minSize int) []*duplicateGroup {
+	// Every distinct subtree is given an ID, so that the key of a collection
+	// is made of the IDs of its children and stays short.
+	ids := map[string]int{}
+	id := func(key string) string {
+		i, ok := ids[key]
+		if !ok {
+			i = len(ids)
+			ids[key] = i
+		}
+		return strconv.Itoa(i)
+	}
+	groups := map[string]*duplicateGroup{}
+	var all []*duplicateGroup
+
+	var walk func(n *yaml3.Node, path *keyPath, record bool) (string, int, bool)
+	walk = func(n *yaml3.Node, path *keyPath, record bool) (key string, size int, ok bool) {
+		ok = n.Anchor == ""
+		var b strings.Builder
+		switch n.Kind {
+		case yaml3.DocumentNode:
+			for _, c := range n.Content {
+				walk(c, path, true)
+			}
+			return "", 0, false
+		case yaml3.ScalarNode:
+			return id(n.ShortTag() + " " + n.Value), 1, ok
+		case yaml3.MappingNode:
+			b.WriteString("{")
+			for i, c := range n.Content {
+				cp := path
+				if i%2 == 1 {
+					cp = path.key(n.Content[i-1].Value)
+				}
+				k, s, cok := walk(c, cp, i%2 == 1)
+				b.WriteString(k + ",")
+				size += s
+				ok = ok && cok
+			}
+		case yaml3.SequenceNode:
+			b.WriteString("[")
+			for i, c := range n.Content {
+				k, s, cok := walk(c, path.index(i), true)
+				b.WriteString(k + ",")
+				size += s
+				ok = ok && cok
+			}
+		default:
+			return "", 0, false
+		}
+		key, size = id(n.ShortTag()+b.String()), size+1
+		if ok && record && size >= minSize {
+			g := groups[key]
+			if g == nil {
+				g = &duplicateGroup{size: size}
+				groups[key] = g
+				all = append(all, g)
+			}
+			g.occurrences = append(g.occurrences, subtree{n, path})
+		}
+		return key, size, ok
+	}
+	walk(doc, nil, false)
+
+	// Take the largest duplicates first and leave out the occurrences of
+	// smaller ones that they hold.
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].size > all[j].size
+	})
+	covered := map[*yaml3.Node]bool{}
+	var dups []*duplicateGroup
+	for _, g := range all {
+		var kept []subtree
+		for _, o := range g.occurrences {
+			if !covered[o.node] {
+				kept = append(kept, o)
+			}
+		}
+		if len(kept) < 2 {
+			continue
+		}
+		sort.SliceStable(kept, func(i, j int) bool {
+			return nodeBefore(kept[i].node, kept[j].node)
+		})
+		g.occurrences = kept
+		dups = append(dups, g)
+		for _, o := range kept {
+			coverNodes(o.node, covered)
+		}
+	}
+	sort.SliceStable(dups, func(i, j int) bool {
+		return nodeBefore(dups[i].occurrences[0].node, dups[j].occurrences[0].node)
+	})
+	return dups
+}
+
+func nodeBefore(a, b *yaml3.Node) bool {
+	if a.Line != b.Line {
+		return a.Line < b.Line
+	}
+	return a.Column < b.Column
+}
+
+// coverNodes marks n and the nodes it holds.
+func coverNodes(n *yaml3.Node, covered map[*yaml3.Node]bool) {
+	covered[n] = true
+	for _, c := range n.Content {
+		coverNodes(c, covered)
+	}
+}
+
+// collectAnchors adds the names of the anchors of n and its nodes to used.
+func collectAnchors(n *yaml3.Node, used map[string]bool) {
+	if n.Anchor != "" {
+		used[n.Anchor] = true
+	}
+	for _, c := range n.Content {
+		collectAnchors(c, used)
+	}
+}
+
+// anchorName returns an unused anchor name for the subtree at path, made from
+// the nearest mapping key, and adds it to used.
+func anchorName(path *keyPath, used map[string]bool) string {
+	for path != nil && path.idx >= 0 {
+		path = path.parent
+	}
+	base := "dup"
+	if path != nil {
+		name := strings.Map(func(r rune) rune {
+			if r == '-' || r == '_' || r >= '0' && r <= '9' || r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' {
+				return r
+			}
+			return -1
+		}, path.name)
+		if name != "" {
+			base = name
+		}
+	}
+	name := base
+	for i := 2; used[name]; i++ {
+		name = base + strconv.Itoa(i)
+	}
+	used[name] = true
+	return name
+}
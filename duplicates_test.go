@@ -0,0 +1,70 @@
+package yaml
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	y := `services:
+  web:
+    resources: {cpu: 1, memory: 2Gi}
+    ports: [80, 443]
+  api:
+    resources: {cpu: 1, memory: 2Gi}
+    ports: [80, 443]
+  worker:
+    resources: {cpu: 1, memory: 2Gi}
+    ports: ["80", "443"]
+defaults:
+  resources: {cpu: 1, memory: 2Gi}
+---
+a: [1, 2]
+b: [1, 2]
+c: &c [3, 4]
+d: *c
+`
+	got, err := FindDuplicates([]byte(y), 3)
+	if err != nil {
+		t.Fatalf("FindDuplicates() = %v", err)
+	}
+	want := []Duplicate{
+		{0, []string{"services.web", "services.api"}, 11},
+		// The occurrences within services.web and services.api are left out.
+		{0, []string{"services.worker.resources", "defaults.resources"}, 5},
+		{1, []string{"a", "b"}, 3},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindDuplicates() = %+v; want %+v", got, want)
+	}
+
+	if _, err := FindDuplicates([]byte("a: ["), 1); err == nil {
+		t.Error("FindDuplicates() of invalid YAML returned no error")
+	}
+}
+
+func TestDeduplicate(t *testing.T) {
+	y := `# Jobs.
+build:
+  run: go build
+  env: {GOOS: linux, GOARCH: amd64}
+test:
+  run: go test
+  env: {GOOS: linux, GOARCH: amd64}
+`
+	want := `# Jobs.
+build:
+  run: go build
+  env: &env {GOOS: linux, GOARCH: amd64}
+test:
+  run: go test
+  env: *env
+`
+	got, err := Deduplicate([]byte(y), 3)
+	if err != nil {
+		t.Fatalf("Deduplicate() = %v", err)
+	}
+	if string(got) != want {
+		t.Errorf("Deduplicate() = %#q; want %#q", string(got), want)
+	}
+}
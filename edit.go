@@ -0,0 +1,261 @@
+package yaml
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	yaml3 "gopkg.in/yaml.v3"
+)
+
+// Set sets the value at path in the YAML document doc to value, marshaled as
+// Marshal does, and returns the edited document. Missing keys along the path
+// are added as mappings, or as sequences where the path goes on with index 0,
+// and an index one past the end of a sequence appends to it. The rest of the document keeps its comments and the order of its
+// keys, and the value keeps the comments of the one it replaces. Only the
+// first document of a stream is edited.
+//
+// By default, the document is written out again as a whole, in the style of
+// Format; with PreserveFormatting, only the text of the value is changed.
+func Set(doc []byte, path string, value interface{}, opts ...EditOpt) ([]byte, error) {
+	p, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	v, err := valueNode(value)
+	if err != nil {
+		return nil, err
+	}
+	edited, err := editDocument(doc, func(root *yaml3.Node) error {
+		return setNode(root, p, v)
+	})
+	if err != nil || !newEditOptions(opts).preserve {
+		return edited, err
+	}
+	// setNode has made v part of the edited document.
+	v, _ = valueNode(value)
+	return editInPlace(doc, edited, path, func(t *textEditor, root *yaml3.Node) error {
+		return t.set(root, p, v)
+	})
+}
+
+// Delete removes the value at path from the YAML document doc, along with
+// its key, and returns the edited document. It returns a *PathNotFoundError
+// if the document has no value at path. Like Set, it keeps the comments and
+// order of the rest of the document, and PreserveFormatting keeps the rest of
+// its text.
+func Delete(doc []byte, path string, opts ...EditOpt) ([]byte, error) {
+	p, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(p) == 0 {
+		return nil, errors.New("yaml: cannot delete the whole document")
+	}
+	edited, err := editDocument(doc, func(root *yaml3.Node) error {
+		return deleteNode(root, p)
+	})
+	if err != nil || !newEditOptions(opts).preserve {
+		return edited, err
+	}
+	return editInPlace(doc, edited, path, func(t *textEditor, root *yaml3.Node) error {
+		return t.delete(root, p)
+	})
+}
+
+// valueNode returns the node of the YAML document Marshal writes for v.
+func valueNode(v interface{}) (*yaml3.Node, error) {
+	y, err := Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var n yaml3.Node
+	if err := yaml3.Unmarshal(y, &n); err != nil {
+		return nil, err
+	}
+	return n.Content[0], nil
+}
+
+// editDocument calls edit with the root node of the first document of the
+// YAML stream y, which it may change, and returns the stream as yaml.v3
+// writes it.
+func editDocument(y []byte, edit func(root *yaml3.Node) error) ([]byte, error) {
+	y, err := toUTF8(y)
+	if err != nil {
+		return nil, err
+	}
+	var docs []*yaml3.Node
+	d := yaml3.NewDecoder(bytes.NewReader(y))
+	for {
+		var doc yaml3.Node
+		if err := d.Decode(&doc); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		docs = append(docs, &doc)
+	}
+	if len(docs) == 0 {
+		docs = append(docs, &yaml3.Node{Kind: yaml3.DocumentNode, Content: []*yaml3.Node{{Kind: yaml3.ScalarNode, Tag: "!!null"}}})
+	}
+	if err := edit(docs[0]); err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	enc := yaml3.NewEncoder(&out)
+	enc.SetIndent(2)
+	for _, doc := range docs {
+		untagMergeKeys(doc)
+		if err := enc.Encode(doc); err != nil {
+			return nil, err
+		}
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// ownNode returns the index into the content of n of the value under the key
+// or index e, not counting keys merged into n with "<<", or -1 if n has no
+// such value.
+func ownNode(n *yaml3.Node, e pathElem) int {
+	switch n.Kind {
+	case yaml3.MappingNode:
+		if e.isIndex {
+			return -1
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if k := n.Content[i]; k.Kind == yaml3.ScalarNode && k.ShortTag() != "!!merge" && k.Value == e.key {
+				return i + 1
+			}
+		}
+	case yaml3.SequenceNode:
+		if i, ok := sequenceIndex(e, len(n.Content)); ok {
+			return i
+		}
+	}
+	return -1
+}
+
+// editableNode returns the node of the value under e in n, which must be n's
+// own rather than an alias or a merged key, since editing it would change
+// other parts of the document too.
+func editableNode(n *yaml3.Node, p []pathElem, i int) (*yaml3.Node, error) {
+	j := ownNode(n, p[i])
+	if j < 0 {
+		return nil, missingError(n, p, i)
+	}
+	c := n.Content[j]
+	if c.Kind == yaml3.AliasNode {
+		return nil, fmt.Errorf("yaml: cannot edit %s, which is an alias", pathString(p[:i+1]))
+	}
+	return c, nil
+}
+
+// missingError returns the error for the key or index p[i] that the node n
+// has no value of its own under.
+func missingError(n *yaml3.Node, p []pathElem, i int) error {
+	if lookupNode(n, p[i]) != nil {
+		return fmt.Errorf("yaml: cannot edit %s, which is merged from another mapping", pathString(p[:i+1]))
+	}
+	return &PathNotFoundError{pathString(p[:i+1])}
+}
+
+func setNode(doc *yaml3.Node, p []pathElem, v *yaml3.Node) error {
+	n := doc.Content[0]
+	if len(p) == 0 {
+		doc.Content[0] = replaceNode(n, v)
+		return nil
+	}
+	for i, e := range p {
+		if n.Kind == yaml3.ScalarNode && n.ShortTag() == "!!null" && (!e.isIndex || e.index == 0) {
+			// A missing or null value becomes a mapping, or a sequence if
+			// its first item is set.
+			c := yaml3.Node{Kind: yaml3.MappingNode, Tag: "!!map", HeadComment: n.HeadComment, LineComment: n.LineComment, FootComment: n.FootComment}
+			if e.isIndex {
+				c.Kind, c.Tag = yaml3.SequenceNode, "!!seq"
+			}
+			*n = c
+		}
+		last := i == len(p)-1
+		j := ownNode(n, e)
+		switch {
+		case j >= 0 && last:
+			if n.Content[j].Kind == yaml3.AliasNode {
+				n.Content[j] = v
+			} else {
+				n.Content[j] = replaceNode(n.Content[j], v)
+			}
+			return nil
+		case j >= 0:
+			c, err := editableNode(n, p, i)
+			if err != nil {
+				return err
+			}
+			n = c
+			continue
+		case n.Kind == yaml3.SequenceNode && e.isIndex && e.index == len(n.Content):
+			c := v
+			if !last {
+				c = &yaml3.Node{Kind: yaml3.ScalarNode, Tag: "!!null"}
+			}
+			n.Content = append(n.Content, c)
+			n = c
+		case n.Kind == yaml3.MappingNode && !e.isIndex:
+			if !last && lookupNode(n, e) != nil {
+				return missingError(n, p, i)
+			}
+			c := v
+			if !last {
+				c = &yaml3.Node{Kind: yaml3.ScalarNode, Tag: "!!null"}
+			}
+			n.Content = append(n.Content, keyNode(e.key), c)
+			n = c
+		default:
+			return &PathNotFoundError{pathString(p[:i+1])}
+		}
+	}
+	return nil
+}
+
+// keyNode returns a node for the mapping key k, quoted if go-yaml would
+// read it as something other than a string.
+func keyNode(k string) *yaml3.Node {
+	n := &yaml3.Node{Kind: yaml3.ScalarNode, Tag: "!!str", Value: k}
+	if !resolvesToString(k) || base60Float.MatchString(k) {
+		n.Style = yaml3.DoubleQuotedStyle
+	}
+	return n
+}
+
+// replaceNode returns v with the comments of the node old that it replaces.
+func replaceNode(old, v *yaml3.Node) *yaml3.Node {
+	v.HeadComment = old.HeadComment
+	v.LineComment = old.LineComment
+	v.FootComment = old.FootComment
+	return v
+}
+
+func deleteNode(doc *yaml3.Node, p []pathElem) error {
+	n := doc.Content[0]
+	for i := range p[:len(p)-1] {
+		c, err := editableNode(n, p, i)
+		if err != nil {
+			return err
+		}
+		n = c
+	}
+	j := ownNode(n, p[len(p)-1])
+	if j < 0 {
+		return missingError(n, p, len(p)-1)
+	}
+	if n.Kind == yaml3.MappingNode {
+		n.Content = append(n.Content[:j-1], n.Content[j+1:]...)
+	} else {
+		n.Content = append(n.Content[:j], n.Content[j+1:]...)
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package yaml
+
+import "testing"
+
+const editDoc = `# Deployment.
+metadata:
+  name: web # The name.
+spec:
+  defaults: &defaults
+    pull: Always
+  containers:
+    - name: web
+      <<: *defaults
+      image: nginx:1.24 # Pinned.
+---
+other: document
+`
+
+func TestSet(t *testing.T) {
+	for _, tc := range []struct {
+		path  string
+		value interface{}
+		want  string
+	}{
+		{".spec.containers[0].image", "nginx:1.25", `# Deployment.
+metadata:
+  name: web # The name.
+spec:
+  defaults: &defaults
+    pull: Always
+  containers:
+    - name: web
+      <<: *defaults
+      image: nginx:1.25 # Pinned.
+---
+other: document
+`},
+		{".metadata.labels.yes", map[string]interface{}{"on": true}, `# Deployment.
+metadata:
+  name: web # The name.
+  labels:
+    "yes":
+      "on": true
+spec:
+  defaults: &defaults
+    pull: Always
+  containers:
+    - name: web
+      <<: *defaults
+      image: nginx:1.24 # Pinned.
+---
+other: document
+`},
+		{".spec.containers[1]", struct {
+			Name string `json:"name"`
+		}{"sidecar"}, `# Deployment.
+metadata:
+  name: web # The name.
+spec:
+  defaults: &defaults
+    pull: Always
+  containers:
+    - name: web
+      <<: *defaults
+      image: nginx:1.24 # Pinned.
+    - name: sidecar
+---
+other: document
+`},
+		{".spec.containers[0].pull", "Never", `# Deployment.
+metadata:
+  name: web # The name.
+spec:
+  defaults: &defaults
+    pull: Always
+  containers:
+    - name: web
+      <<: *defaults
+      image: nginx:1.24 # Pinned.
+      pull: Never
+---
+other: document
+`},
+	} {
+		got, err := Set([]byte(editDoc), tc.path, tc.value)
+		if err != nil {
+			t.Errorf("Set(%q) = %v", tc.path, err)
+		} else if string(got) != tc.want {
+			t.Errorf("Set(%q) = %#q; want %#q", tc.path, string(got), tc.want)
+		}
+	}
+
+	got, err := Set(nil, ".a.b", 1)
+	if err != nil || string(got) != "a:\n  b: 1\n" {
+		t.Errorf("Set() on an empty document = %#q, %v", string(got), err)
+	}
+
+	// Missing values along the path are added as sequences where it goes
+	// on with index 0.
+	for _, tc := range []struct{ path, want string }{
+		{".new[0]", "a: 1\nb: ~\nnew:\n  - v\n"},
+		{".new.deep[0]", "a: 1\nb: ~\nnew:\n  deep:\n    - v\n"},
+		{".b[0].c", "a: 1\nb:\n  - c: v\n"},
+	} {
+		got, err := Set([]byte("a: 1\nb: ~\n"), tc.path, "v")
+		if err != nil || string(got) != tc.want {
+			t.Errorf("Set(%q) = %#q, %v; want %#q", tc.path, string(got), err, tc.want)
+		}
+	}
+
+	for _, path := range []string{".metadata.name.first", ".spec.containers[2]", ".spec.containers[0].pull.x", ".new[1]", "a..b"} {
+		if _, err := Set([]byte(editDoc), path, 1); err == nil {
+			t.Errorf("Set(%q) returned no error", path)
+		}
+	}
+}
+
+func TestDelete(t *testing.T) {
+	got, err := Delete([]byte(editDoc), ".spec.containers[0].image")
+	want := `# Deployment.
+metadata:
+  name: web # The name.
+spec:
+  defaults: &defaults
+    pull: Always
+  containers:
+    - name: web
+      <<: *defaults
+---
+other: document
+`
+	if err != nil || string(got) != want {
+		t.Errorf("Delete() = %#q, %v; want %#q", string(got), err, want)
+	}
+
+	got, err = Delete([]byte("a: [1, 2, 3]\n"), ".a[-2]")
+	if err != nil || string(got) != "a: [1, 3]\n" {
+		t.Errorf("Delete() = %#q, %v", string(got), err)
+	}
+
+	_, err = Delete([]byte(editDoc), ".metadata.labels")
+	if e, ok := err.(*PathNotFoundError); !ok || e.Path != ".metadata.labels" {
+		t.Errorf("Delete() of a missing key = %v", err)
+	}
+	for _, path := range []string{".", ".spec.containers[0].pull"} {
+		if _, err := Delete([]byte(editDoc), path); err == nil {
+			t.Errorf("Delete(%q) returned no error", path)
+		}
+	}
+}
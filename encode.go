@@ -0,0 +1,1044 @@
+package yaml
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v2"
+)
+
+// MarshalOpt is an encoding option for emitting YAML.
+//
+// go-yaml does not expose any of its emitter settings, so when options are
+// given the YAML is written by this package's own emitter instead. It follows
+// the layout and quoting rules of go-yaml, which means that for any given
+// document it produces the same output as go-yaml unless an option asks for
+// something different.
+type MarshalOpt func(*encoder)
+
+// MultilineLiteral emits every string containing a line break as a literal
+// block scalar ("|"). go-yaml already does this for most strings, but falls
+// back to a double-quoted string full of "\n" escapes as soon as a line ends
+// in a space or contains a tab.
+func MultilineLiteral() MarshalOpt {
+	return func(e *encoder) {
+		e.literal = true
+	}
+}
+
+// LineWidth sets the preferred width of the output lines. Like go-yaml, the
+// encoder folds a long string at the first space past this column; the
+// default width is 80. A width of zero or less disables folding, so that
+// every string stays on a single line.
+func LineWidth(width int) MarshalOpt {
+	return func(e *encoder) {
+		if width <= 0 {
+			width = math.MaxInt32
+		}
+		e.width = width
+	}
+}
+
+// Quoting is a style for writing strings.
+type Quoting int
+
+const (
+	// QuotePlain writes strings without quotes where possible.
+	QuotePlain Quoting = iota
+	// QuoteSingle writes strings in single quotes where possible, and in
+	// double quotes where single quotes cannot represent the string.
+	QuoteSingle
+	// QuoteDouble writes strings in double quotes.
+	QuoteDouble
+)
+
+func (q Quoting) style() scalarStyle {
+	switch q {
+	case QuoteSingle:
+		return singleQuotedStyle
+	case QuoteDouble:
+		return doubleQuotedStyle
+	}
+	return plainStyle
+}
+
+// QuoteStrings writes every string value in the given style. The default,
+// QuotePlain, only quotes strings that would otherwise not be read back as
+// the same string. Mapping keys and strings written as block scalars are not
+// affected.
+func QuoteStrings(q Quoting) MarshalOpt {
+	return func(e *encoder) {
+		e.quoting = q
+	}
+}
+
+// QuoteWhenNeeded sets the quotes used for string values that cannot be
+// written plain, such as "true" or "a: b", without quoting any others. By
+// default, or when given QuotePlain, the encoder picks the same quotes as
+// go-yaml.
+func QuoteWhenNeeded(q Quoting) MarshalOpt {
+	return func(e *encoder) {
+		e.neededQuoting = q
+	}
+}
+
+// NullStyle is a style for writing null values.
+type NullStyle int
+
+const (
+	// NullKeyword writes null values as "null".
+	NullKeyword NullStyle = iota
+	// NullTilde writes null values as "~".
+	NullTilde
+	// NullEmpty writes null values as nothing at all, e.g. "key:".
+	NullEmpty
+)
+
+// NullsAs sets how null values are written. The default is NullKeyword,
+// like in go-yaml.
+func NullsAs(style NullStyle) MarshalOpt {
+	return func(e *encoder) {
+		e.nulls = style
+	}
+}
+
+// DocumentStart starts the document with an explicit "---" marker, so that
+// the output can be concatenated with other documents into a stream.
+func DocumentStart() MarshalOpt {
+	return func(e *encoder) {
+		e.documentStart = true
+	}
+}
+
+// VersionDirective starts the document with a "%YAML" directive declaring
+// the given version, e.g. "1.2", followed by a "---" marker. Note that
+// go-yaml, and with it this package, reads only documents that declare
+// version 1.1 or no version at all.
+func VersionDirective(version string) MarshalOpt {
+	return func(e *encoder) {
+		e.version = version
+		e.documentStart = true
+	}
+}
+
+// DocumentEnd terminates the document with a "..." marker, which streaming
+// protocols use to frame documents.
+func DocumentEnd() MarshalOpt {
+	return func(e *encoder) {
+		e.documentEnd = true
+	}
+}
+
+// KeyOrder is an order for the keys of a mapping.
+type KeyOrder int
+
+const (
+	// KeyOrderNatural sorts keys like go-yaml does, comparing embedded
+	// numbers by their value so that "a2" comes before "a10".
+	KeyOrderNatural KeyOrder = iota
+	// KeyOrderAlphabetical sorts keys by their bytes.
+	KeyOrderAlphabetical
+	// KeyOrderDocument keeps keys in the order of the JSON document. For
+	// Marshal, that is the declaration order of struct fields; encoding/json
+	// sorts the keys of Go maps.
+	KeyOrderDocument
+)
+
+// SortKeys sets the order of the keys of every mapping. The default is
+// KeyOrderNatural.
+func SortKeys(order KeyOrder) MarshalOpt {
+	return func(e *encoder) {
+		e.keyOrder = order
+	}
+}
+
+// KeysFirst moves the given keys, where present, to the front of every
+// mapping in the given order, e.g. KeysFirst("apiVersion", "kind",
+// "metadata"). The remaining keys follow in the order set by SortKeys.
+func KeysFirst(keys ...string) MarshalOpt {
+	return func(e *encoder) {
+		e.keysFirst = make(map[string]int, len(keys))
+		for i, k := range keys {
+			if _, ok := e.keysFirst[k]; !ok {
+				e.keysFirst[k] = i
+			}
+		}
+	}
+}
+
+// JSONToYAMLWithOpts is like JSONToYAML but applies the given encoding
+// options.
+func JSONToYAMLWithOpts(j []byte, opts ...MarshalOpt) ([]byte, error) {
+	return jsonToYAML(j, reflect.Value{}, opts)
+}
+
+// jsonToYAML converts the JSON document j, which was marshaled from v if v is
+// valid, to YAML.
+func jsonToYAML(j []byte, v reflect.Value, opts []MarshalOpt) ([]byte, error) {
+	walk := v.IsValid() && typeNeedsStructs(v.Type())
+	if len(opts) == 0 && !walk {
+		return JSONToYAML(j)
+	}
+
+	j, err := toUTF8(j)
+	if err != nil {
+		return nil, err
+	}
+	jsonObj, err := decodeJSON(j)
+	if err != nil {
+		return nil, err
+	}
+	return newEncoder(opts).encode(jsonObj, v)
+}
+
+// encode writes jsonObj, the decoded JSON that json.Marshal produced for v if
+// v is valid, as a YAML document and returns it.
+func (e *encoder) encode(jsonObj interface{}, v reflect.Value) ([]byte, error) {
+	var err error
+	if v.IsValid() && (e.needsStructs() || typeNeedsStructs(v.Type())) {
+		jsonObj, err = e.applyStructs(jsonObj, v)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if e.mask != nil {
+		jsonObj, _, err = e.applyMask(jsonObj, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	e.document(jsonObj)
+	return e.out.Bytes(), nil
+}
+
+// jsonNumberValue returns the value go-yaml produces for the JSON number s.
+func jsonNumberValue(s string) (interface{}, error) {
+	// Pick the same number types go-yaml picks when resolving a plain
+	// scalar.
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if i == int64(int(i)) {
+			return int(i), nil
+		}
+		return i, nil
+	}
+	if u, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return u, nil
+	}
+	if !yamlStyleFloat.MatchString(s) {
+		// go-yaml does not recognize exponents without a sign, and reads
+		// numbers like 1e5 as strings.
+		return s, nil
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+// CRLFLineEndings ends lines with "\r\n", as is the convention on Windows.
+// Without it, lines end with "\n". Line breaks within strings are written the
+// same way, and read back as "\n" as YAML requires.
+func CRLFLineEndings() MarshalOpt {
+	return func(e *encoder) {
+		e.crlf = true
+	}
+}
+
+// encoder is a block-style YAML emitter for the values produced by
+// decodeJSON.
+type encoder struct {
+	out    bytes.Buffer
+	column int
+
+	// whitespace is set if the last character written was whitespace, and
+	// indention if the current line holds nothing but indentation so far.
+	whitespace bool
+	indention  bool
+
+	width         int
+	literal       bool
+	quoting       Quoting
+	neededQuoting Quoting
+	nulls         NullStyle
+	documentStart bool
+	documentEnd   bool
+	crlf          bool
+	version       string
+	keyOrder      KeyOrder
+	keysFirst     map[string]int
+	fieldNaming   FieldNamer
+	tags          TagPrecedence
+	nonFinite     NonFinite
+	yamlVersion   YAMLVersion
+	redact        bool
+	placeholder   string
+	mask          MaskFunc
+
+	// yamlMarshalers is set if values are written by their MarshalYAML
+	// methods.
+	yamlMarshalers bool
+	// codec, if set, replaces encoding/json.
+	codec JSONCodec
+	// marshalFuncs hold the functions that write values of the given types.
+	marshalFuncs map[reflect.Type]func(interface{}) ([]byte, error)
+	// floatStyle, floatPrecision and noExponents set how floats are
+	// written.
+	floatStyle     FloatStyle
+	floatPrecision int
+	noExponents    bool
+	// unsupported sets what becomes of values of unsupported types.
+	unsupported Unsupported
+	// converters hold the functions that convert values of the given types
+	// before they are marshaled.
+	converters map[reflect.Type]reflect.Value
+	// metrics, if set, receives the measures of each document written.
+	metrics Metrics
+}
+
+// newEncoder returns an encoder with the given options applied.
+func newEncoder(opts []MarshalOpt) *encoder {
+	e := &encoder{width: 80, whitespace: true, indention: true, floatPrecision: -1}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+type scalarStyle int
+
+const (
+	plainStyle scalarStyle = iota
+	singleQuotedStyle
+	doubleQuotedStyle
+	literalStyle
+)
+
+func (e *encoder) write(s string) {
+	e.out.WriteString(s)
+	e.column += utf8.RuneCountInString(s)
+	e.whitespace = false
+	e.indention = false
+}
+
+func (e *encoder) writeRune(r rune) {
+	e.out.WriteRune(r)
+	e.column++
+	e.whitespace = false
+	e.indention = false
+}
+
+// writeBreak writes the line break r, which go-yaml writes as it is unless
+// it is a "\n".
+func (e *encoder) writeBreak(r rune) {
+	if r == '\n' {
+		e.newline()
+		return
+	}
+	e.out.WriteRune(r)
+	e.column = 0
+	e.whitespace = true
+	e.indention = true
+}
+
+func (e *encoder) newline() {
+	if e.crlf {
+		e.out.WriteByte('\r')
+	}
+	e.out.WriteByte('\n')
+	e.column = 0
+	e.whitespace = true
+	e.indention = true
+}
+
+// writeIndent moves to the given column, starting a new line first unless
+// the current one holds nothing but indentation up to that column.
+func (e *encoder) writeIndent(indent int) {
+	if !e.indention || e.column > indent || (e.column == indent && !e.whitespace) {
+		e.newline()
+	}
+	for e.column < indent {
+		e.out.WriteByte(' ')
+		e.column++
+	}
+	e.whitespace = true
+	e.indention = true
+}
+
+// writeIndicator writes an indicator, separating it from what precedes it
+// by a space if needed. An indicator that is part of the indentation, such
+// as the "-" of a sequence entry, leaves the line counting as indentation.
+func (e *encoder) writeIndicator(s string, needWhitespace, isIndention bool) {
+	if needWhitespace && !e.whitespace {
+		e.out.WriteByte(' ')
+		e.column++
+	}
+	indention := e.indention && isIndention
+	e.write(s)
+	e.indention = indention
+}
+
+func (e *encoder) document(v interface{}) {
+	if e.version != "" {
+		e.write("%YAML " + e.version)
+		e.newline()
+	}
+	if e.documentStart {
+		e.writeIndicator("---", true, false)
+	}
+	switch v.(type) {
+	case yaml.MapSlice, []interface{}:
+		e.node(v, 0)
+	default:
+		e.node(v, 2)
+	}
+	if e.column > 0 {
+		e.newline()
+	}
+	if e.documentEnd {
+		e.write("...")
+		e.newline()
+	}
+}
+
+// node writes v, placing a nested collection at the given indentation and
+// indenting the continuation lines of a scalar by it.
+func (e *encoder) node(v interface{}, indent int) {
+	switch v := v.(type) {
+	case yaml.MapSlice:
+		if len(v) == 0 {
+			e.writeIndicator("{}", true, false)
+			return
+		}
+		e.mapping(v, indent)
+	case []interface{}:
+		if len(v) == 0 {
+			e.writeIndicator("[]", true, false)
+			return
+		}
+		e.sequence(v, indent)
+	default:
+		e.scalar(v, indent, false)
+	}
+}
+
+func (e *encoder) mapping(m yaml.MapSlice, indent int) {
+	m = e.sortKeys(m)
+	for _, item := range m {
+		e.writeIndent(indent)
+		if !e.simpleKey(item.Key) {
+			e.writeIndicator("?", true, true)
+			e.scalar(item.Key, indent+2, false)
+			e.writeIndent(indent)
+			e.writeIndicator(":", true, true)
+			e.node(item.Value, indent+2)
+			continue
+		}
+		e.scalar(item.Key, indent+2, true)
+		e.writeIndicator(":", false, false)
+		if _, ok := item.Value.([]interface{}); ok {
+			// Sequences in a mapping are not indented.
+			e.node(item.Value, indent)
+		} else {
+			e.node(item.Value, indent+2)
+		}
+	}
+}
+
+func (e *encoder) sequence(s []interface{}, indent int) {
+	for _, v := range s {
+		e.writeIndent(indent)
+		e.writeIndicator("-", true, true)
+		e.node(v, indent+2)
+	}
+}
+
+// simpleKey reports whether k can be written as an implicit key, which is
+// what go-yaml does for all keys that fit on a line of up to 128 characters.
+func (e *encoder) simpleKey(k interface{}) bool {
+	s, _ := e.formatScalar(k, false)
+	return !analyzeScalar(s).multiline && len(s) <= 128
+}
+
+// scalar writes v, indenting continuation lines by indent.
+func (e *encoder) scalar(v interface{}, indent int, key bool) {
+	s, style := e.formatScalar(v, key)
+	if s == "" && style == plainStyle {
+		return
+	}
+	if !e.whitespace {
+		e.writeRune(' ')
+	}
+	switch style {
+	case plainStyle:
+		e.writePlain(s, indent, !key)
+	case singleQuotedStyle:
+		e.writeSingleQuoted(s, indent, !key)
+	case doubleQuotedStyle:
+		e.writeDoubleQuoted(s, indent, !key)
+	case literalStyle:
+		e.writeLiteral(s, indent)
+	}
+}
+
+// formatScalar returns the text of the scalar v and the style to write it in.
+func (e *encoder) formatScalar(v interface{}, key bool) (string, scalarStyle) {
+	switch v := v.(type) {
+	case nil:
+		switch e.nulls {
+		case NullTilde:
+			return "~", plainStyle
+		case NullEmpty:
+			return "", plainStyle
+		}
+		return "null", plainStyle
+	case bool:
+		return strconv.FormatBool(v), plainStyle
+	case int:
+		return strconv.Itoa(v), plainStyle
+	case int64:
+		return strconv.FormatInt(v, 10), plainStyle
+	case uint64:
+		return strconv.FormatUint(v, 10), plainStyle
+	case float64:
+		return e.formatFloat(v), plainStyle
+	case string:
+		return v, e.stringStyle(v, key)
+	default:
+		return fmt.Sprint(v), plainStyle
+	}
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return ".inf"
+	case math.IsInf(f, -1):
+		return "-.inf"
+	case math.IsNaN(f):
+		return ".nan"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// stringStyle picks the style go-yaml would pick for s, or, with YAML12,
+// the style it would pick if it followed YAML 1.2.
+func (e *encoder) stringStyle(s string, key bool) scalarStyle {
+	a := analyzeScalar(s)
+	canUsePlain := resolvesToString(s) && !base60Float.MatchString(s)
+	if e.yamlVersion == YAML12 {
+		canUsePlain = resolvesToString12(s)
+	}
+
+	// go-yaml double-quotes strings that would be read back as something
+	// other than a string, and single-quotes those that are not valid plain
+	// scalars to begin with.
+	style := plainStyle
+	switch {
+	case a.newlines:
+		style = literalStyle
+	case !canUsePlain:
+		style = doubleQuotedStyle
+	case !a.plainAllowed:
+		style = singleQuotedStyle
+	}
+
+	if !key && style != literalStyle {
+		if e.quoting != QuotePlain {
+			style = e.quoting.style()
+		} else if style != plainStyle && e.neededQuoting != QuotePlain {
+			style = e.neededQuoting.style()
+		}
+	}
+
+	if key && a.multiline {
+		style = doubleQuotedStyle
+	}
+	if style == singleQuotedStyle && !a.singleQuotedAllowed {
+		style = doubleQuotedStyle
+	}
+	if style == literalStyle && !key && e.literal && a.literalAllowed {
+		return literalStyle
+	}
+	if style == literalStyle && (key || !a.blockAllowed) {
+		style = doubleQuotedStyle
+	}
+	return style
+}
+
+// scalarAnalysis records which styles can represent a scalar, as determined
+// by go-yaml's emitter.
+type scalarAnalysis struct {
+	// multiline is set if the scalar holds a line break, and newlines if it
+	// holds a "\n", the only break for which go-yaml picks the literal
+	// style.
+	multiline bool
+	newlines  bool
+
+	plainAllowed        bool
+	singleQuotedAllowed bool
+	blockAllowed        bool
+
+	// literalAllowed is like blockAllowed, but ignores the restrictions
+	// go-yaml has in place only to keep its output readable.
+	literalAllowed bool
+}
+
+func analyzeScalar(s string) scalarAnalysis {
+	if s == "" {
+		return scalarAnalysis{plainAllowed: true, singleQuotedAllowed: true}
+	}
+
+	var (
+		indicators, lineBreaks, special, unprintable bool
+		newlines, otherBreaks, tabIndent             bool
+		leadingSpace, leadingBreak                   bool
+		trailingSpace, trailingBreak                 bool
+		breakSpace, spaceBreak                       bool
+		previousSpace, previousBreak                 bool
+		precededByWhitespace, followedByWhitespace   bool
+	)
+
+	if strings.HasPrefix(s, "---") || strings.HasPrefix(s, "...") {
+		indicators = true
+	}
+
+	precededByWhitespace = true
+	for i, r := range s {
+		w := utf8.RuneLen(r)
+		followedByWhitespace = i+w >= len(s) || isBlank(s[i+w])
+
+		if i == 0 {
+			switch r {
+			case '#', ',', '[', ']', '{', '}', '&', '*', '!', '|', '>', '\'', '"', '%', '@', '`':
+				indicators = true
+			case '?', ':', '-':
+				if followedByWhitespace {
+					indicators = true
+				}
+			}
+		} else {
+			switch r {
+			case ':':
+				if followedByWhitespace {
+					indicators = true
+				}
+			case '#':
+				if precededByWhitespace {
+					indicators = true
+				}
+			}
+		}
+
+		if !isPrintable(r) {
+			special = true
+			if r != '\t' {
+				unprintable = true
+			}
+		}
+		if r == '\t' && (i == 0 || s[i-1] == '\n') {
+			tabIndent = true
+		}
+		switch {
+		case r == ' ':
+			if i == 0 {
+				leadingSpace = true
+			}
+			if i+w == len(s) {
+				trailingSpace = true
+			}
+			if previousBreak {
+				breakSpace = true
+			}
+			previousSpace, previousBreak = true, false
+		case isBreak(r):
+			lineBreaks = true
+			if r == '\n' {
+				newlines = true
+			} else {
+				otherBreaks = true
+			}
+			if i == 0 {
+				leadingBreak = true
+			}
+			if i+w == len(s) {
+				trailingBreak = true
+			}
+			if previousSpace {
+				spaceBreak = true
+			}
+			previousSpace, previousBreak = false, true
+		default:
+			previousSpace, previousBreak = false, false
+		}
+
+		precededByWhitespace = isBlank(s[i]) || isBreak(r)
+	}
+
+	a := scalarAnalysis{
+		multiline:           lineBreaks,
+		newlines:            newlines,
+		plainAllowed:        true,
+		singleQuotedAllowed: true,
+		blockAllowed:        true,
+		literalAllowed:      true,
+	}
+	if leadingSpace || leadingBreak || trailingSpace || trailingBreak || lineBreaks || indicators {
+		a.plainAllowed = false
+	}
+	if trailingSpace {
+		a.blockAllowed = false
+	}
+	if breakSpace {
+		a.plainAllowed = false
+		a.singleQuotedAllowed = false
+	}
+	if spaceBreak || special {
+		a.plainAllowed = false
+		a.singleQuotedAllowed = false
+		a.blockAllowed = false
+	}
+	// A break other than "\n" or a tab that starts a line is not read back
+	// as written from a literal block where go-yaml would not write one.
+	if unprintable || otherBreaks || tabIndent {
+		a.literalAllowed = false
+	}
+	return a
+}
+
+func isBreak(r rune) bool {
+	return r == '\r' || r == '\n' || r == '\u0085' || r == '\u2028' || r == '\u2029'
+}
+
+// isPrintable reports whether go-yaml can write r unescaped.
+func isPrintable(r rune) bool {
+	return r == '\n' ||
+		r >= 0x20 && r <= 0x7E ||
+		r >= 0xA0 && r <= 0xD7FF ||
+		r >= 0xE000 && r <= 0xFFFD && r != 0xFEFF
+}
+
+// From http://yaml.org/type/float.html, except the regular expression there
+// is bogus. In practice parsers do not enforce the "\.[0-9_]*" suffix.
+var base60Float = regexp.MustCompile(`^[-+]?[0-9][0-9_]*(?::[0-5]?[0-9])+(?:\.[0-9_]*)?$`)
+
+var yamlStyleFloat = regexp.MustCompile(`^[-+]?[0-9]*\.?[0-9]+([eE][-+][0-9]+)?$`)
+
+// Plain scalars that go-yaml resolves to something other than a string.
+var nonStringScalars = map[string]bool{
+	"y": true, "Y": true, "yes": true, "Yes": true, "YES": true,
+	"n": true, "N": true, "no": true, "No": true, "NO": true,
+	"true": true, "True": true, "TRUE": true,
+	"false": true, "False": true, "FALSE": true,
+	"on": true, "On": true, "ON": true,
+	"off": true, "Off": true, "OFF": true,
+	"": true, "~": true, "null": true, "Null": true, "NULL": true,
+	".nan": true, ".NaN": true, ".NAN": true,
+	".inf": true, ".Inf": true, ".INF": true,
+	"+.inf": true, "+.Inf": true, "+.INF": true,
+	"-.inf": true, "-.Inf": true, "-.INF": true,
+}
+
+// Plain scalars other than numbers that the YAML 1.2 core schema resolves to
+// something other than a string.
+var nonStringScalars12 = map[string]bool{
+	"true": true, "True": true, "TRUE": true,
+	"false": true, "False": true, "FALSE": true,
+	"": true, "~": true, "null": true, "Null": true, "NULL": true,
+}
+
+// resolvesToString12 reports whether the YAML 1.2 core schema resolves s as a
+// string when it is written as a plain scalar.
+func resolvesToString12(s string) bool {
+	return !nonStringScalars12[s] && !yaml12Int.MatchString(s) && !yaml12Float.MatchString(s)
+}
+
+// resolvesToString reports whether go-yaml reads s back as a string when it
+// is written as a plain scalar. It mirrors go-yaml's resolve function.
+func resolvesToString(s string) bool {
+	if nonStringScalars[s] {
+		return false
+	}
+	switch s[0] {
+	case '.':
+		_, err := strconv.ParseFloat(s, 64)
+		return err != nil
+	case '+', '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		if isTimestamp(s) {
+			return false
+		}
+		plain := strings.Replace(s, "_", "", -1)
+		if _, err := strconv.ParseInt(plain, 0, 64); err == nil {
+			return false
+		}
+		if _, err := strconv.ParseUint(plain, 0, 64); err == nil {
+			return false
+		}
+		if yamlStyleFloat.MatchString(plain) {
+			if _, err := strconv.ParseFloat(plain, 64); err == nil {
+				return false
+			}
+		}
+		if strings.HasPrefix(plain, "0b") {
+			if _, err := strconv.ParseUint(plain[2:], 2, 64); err == nil {
+				return false
+			}
+		} else if strings.HasPrefix(plain, "-0b") {
+			if _, err := strconv.ParseInt("-"+plain[3:], 2, 64); err == nil {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// This is the subset of the formats allowed by the regular expression
+// defined at http://yaml.org/type/timestamp.html that go-yaml resolves.
+var timestampFormats = []string{
+	"2006-1-2T15:4:5.999999999Z07:00",
+	"2006-1-2t15:4:5.999999999Z07:00",
+	"2006-1-2 15:4:5.999999999",
+	"2006-1-2",
+}
+
+// isTimestamp reports whether go-yaml resolves s as a timestamp.
+func isTimestamp(s string) bool {
+	// Quick check: all date formats start with YYYY-.
+	i := 0
+	for ; i < len(s); i++ {
+		if c := s[i]; c < '0' || c > '9' {
+			break
+		}
+	}
+	if i != 4 || i == len(s) || s[i] != '-' {
+		return false
+	}
+	for _, format := range timestampFormats {
+		if _, err := time.Parse(format, s); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *encoder) writePlain(s string, indent int, allowBreaks bool) {
+	spaces := false
+	for i, r := range s {
+		if r == ' ' {
+			if allowBreaks && !spaces && e.column > e.width && i+1 < len(s) && s[i+1] != ' ' {
+				e.writeIndent(indent)
+			} else {
+				e.writeRune(r)
+			}
+			spaces = true
+			continue
+		}
+		e.writeRune(r)
+		spaces = false
+	}
+}
+
+func (e *encoder) writeSingleQuoted(s string, indent int, allowBreaks bool) {
+	e.writeRune('\'')
+	spaces, breaks := false, false
+	for i, r := range s {
+		switch {
+		case r == ' ':
+			if allowBreaks && !spaces && e.column > e.width && i > 0 && i < len(s)-1 && s[i+1] != ' ' {
+				e.writeIndent(indent)
+			} else {
+				e.writeRune(r)
+			}
+			spaces = true
+		case isBreak(r):
+			if !breaks && r == '\n' {
+				e.newline()
+			}
+			e.writeBreak(r)
+			breaks = true
+		default:
+			if breaks {
+				e.writeIndent(indent)
+			}
+			if r == '\'' {
+				e.writeRune('\'')
+			}
+			e.writeRune(r)
+			spaces, breaks = false, false
+		}
+	}
+	e.writeRune('\'')
+}
+
+func (e *encoder) writeDoubleQuoted(s string, indent int, allowBreaks bool) {
+	e.writeRune('"')
+	spaces := false
+	for i, r := range s {
+		switch {
+		case !isPrintable(r) || isBreak(r) || r == '"' || r == '\\':
+			e.writeRune('\\')
+			switch r {
+			case 0x00:
+				e.writeRune('0')
+			case 0x07:
+				e.writeRune('a')
+			case 0x08:
+				e.writeRune('b')
+			case 0x09:
+				e.writeRune('t')
+			case 0x0A:
+				e.writeRune('n')
+			case 0x0B:
+				e.writeRune('v')
+			case 0x0C:
+				e.writeRune('f')
+			case 0x0D:
+				e.writeRune('r')
+			case 0x1B:
+				e.writeRune('e')
+			case 0x22:
+				e.writeRune('"')
+			case 0x5C:
+				e.writeRune('\\')
+			case 0x85:
+				e.writeRune('N')
+			case 0xA0:
+				e.writeRune('_')
+			case 0x2028:
+				e.writeRune('L')
+			case 0x2029:
+				e.writeRune('P')
+			default:
+				switch {
+				case r <= 0xFF:
+					e.write(fmt.Sprintf("x%02X", r))
+				case r <= 0xFFFF:
+					e.write(fmt.Sprintf("u%04X", r))
+				default:
+					e.write(fmt.Sprintf("U%08X", r))
+				}
+			}
+			spaces = false
+		case r == ' ':
+			if allowBreaks && !spaces && e.column > e.width && i > 0 && i < len(s)-1 {
+				e.writeIndent(indent)
+				if s[i+1] == ' ' {
+					e.writeRune('\\')
+				}
+			} else {
+				e.writeRune(r)
+			}
+			spaces = true
+		default:
+			e.writeRune(r)
+			spaces = false
+		}
+	}
+	e.writeRune('"')
+}
+
+func (e *encoder) writeLiteral(s string, indent int) {
+	e.writeRune('|')
+	if first, _ := utf8.DecodeRuneInString(s); first == ' ' || isBreak(first) {
+		e.write("2")
+	}
+	last, n := utf8.DecodeLastRuneInString(s)
+	switch {
+	case !isBreak(last):
+		e.writeRune('-')
+	case n == len(s):
+		e.writeRune('+')
+	default:
+		if prev, _ := utf8.DecodeLastRuneInString(s[:len(s)-n]); isBreak(prev) {
+			e.writeRune('+')
+		}
+	}
+	e.newline()
+	breaks := true
+	for _, r := range s {
+		if isBreak(r) {
+			e.writeBreak(r)
+			breaks = true
+			continue
+		}
+		if breaks {
+			e.writeIndent(indent)
+		}
+		e.writeRune(r)
+		breaks = false
+	}
+}
+
+// sortKeys returns a copy of m with its keys in the configured order.
+func (e *encoder) sortKeys(m yaml.MapSlice) yaml.MapSlice {
+	sorted := make(yaml.MapSlice, len(m))
+	copy(sorted, m)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := fmt.Sprint(sorted[i].Key), fmt.Sprint(sorted[j].Key)
+		ai, aFirst := e.keysFirst[a]
+		bi, bFirst := e.keysFirst[b]
+		switch {
+		case aFirst && bFirst:
+			return ai < bi
+		case aFirst || bFirst:
+			return aFirst
+		}
+		switch e.keyOrder {
+		case KeyOrderAlphabetical:
+			return a < b
+		case KeyOrderDocument:
+			return false
+		}
+		return keyLess(a, b)
+	})
+	return sorted
+}
+
+// keyLess orders strings so that runs of digits compare by their numeric
+// value, following go-yaml.
+func keyLess(a, b string) bool {
+	ar, br := []rune(a), []rune(b)
+	for i := 0; i < len(ar) && i < len(br); i++ {
+		if ar[i] == br[i] {
+			continue
+		}
+		al := unicode.IsLetter(ar[i])
+		bl := unicode.IsLetter(br[i])
+		if al && bl {
+			return ar[i] < br[i]
+		}
+		if al || bl {
+			return bl
+		}
+		var ai, bi int
+		var an, bn int64
+		if ar[i] == '0' || br[i] == '0' {
+			for j := i - 1; j >= 0 && unicode.IsDigit(ar[j]); j-- {
+				if ar[j] != '0' {
+					an = 1
+					bn = 1
+					break
+				}
+			}
+		}
+		for ai = i; ai < len(ar) && unicode.IsDigit(ar[ai]); ai++ {
+			an = an*10 + int64(ar[ai]-'0')
+		}
+		for bi = i; bi < len(br) && unicode.IsDigit(br[bi]); bi++ {
+			bn = bn*10 + int64(br[bi]-'0')
+		}
+		if an != bn {
+			return an < bn
+		}
+		if ai != bi {
+			return ai < bi
+		}
+		return ar[i] < br[i]
+	}
+	return len(ar) < len(br)
+}
@@ -0,0 +1,481 @@
+package yaml
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// encodeJSON runs j through this package's emitter with the given options.
+func encodeJSON(t *testing.T, j string, opts ...MarshalOpt) string {
+	t.Helper()
+	jsonObj, err := decodeJSON([]byte(j))
+	if err != nil {
+		t.Fatalf("decodeJSON(%#q) = %v", j, err)
+	}
+	e := newEncoder(opts)
+	e.document(jsonObj)
+	return e.out.String()
+}
+
+// TestEncoderMatchesGoYAML tests that without options the emitter produces the
+// same output as go-yaml.
+func TestEncoderMatchesGoYAML(t *testing.T) {
+	long := strings.Repeat("lorem ipsum ", 12)
+	for _, j := range []string{
+		`null`,
+		`"a"`,
+		`""`,
+		`1`,
+		`-1.5`,
+		`1e300`,
+		`18446744073709551615`,
+		`true`,
+		`{}`,
+		`[]`,
+		`{"a":1,"b":"x","c":null,"d":true,"e":1.5}`,
+		`{"b":1,"a":2,"a10":3,"a9":4,"A":5,"_":6,"1":7,"10":8,"2":9}`,
+		`{"a":{"b":{"c":[1,2,{"d":[]}]}},"e":{}}`,
+		`[[1,[2,3]],{"a":[{"b":1,"c":[4]}]},[],{}]`,
+		`{"s":["true","yes","null","~","10","1.5","0x1F","1_000","1:20","2001-01-01",".inf","<<","1e5","-"]}`,
+		`{"s":[" a","a ","a: b","a #b","a#b","- a","? a","#a","&a","*a","!a","|","%a","@a","'a","\"a","---","..."]}`,
+		`{"s":["a\nb","a\nb\n","a\nb\n\n","\n"," a\nb","a \nb","a\tb","a\u0000b","é","😀","a\\b"]}`,
+		`{"a b":1,"a: b":2,"":3,"true":4,"a\nb":5}`,
+		`{"long":"` + long + `","longer":{"nested":["` + long + `"]}}`,
+		`{"quoted":"'` + long + `'","double":"` + long + `\t"}`,
+		`{"` + strings.Repeat("k", 200) + `":1}`,
+	} {
+		want, err := JSONToYAML([]byte(j))
+		if err != nil {
+			t.Fatalf("JSONToYAML(%#q) = %v", j, err)
+		}
+		if got := encodeJSON(t, j); got != string(want) {
+			t.Errorf("encoding %#q = %#q; want %#q", j, got, string(want))
+		}
+	}
+}
+
+// TestEncoderStringsMatchGoYAML compares how the emitter writes every short
+// string made of characters that affect the choice of style with how go-yaml
+// writes it, and checks that the strings written with options are read back
+// unchanged.
+func TestEncoderStringsMatchGoYAML(t *testing.T) {
+	chars := []string{"x", " ", "\t", "\n", ":", "#", "'", "\u0085", "\u2028", "\u2029"}
+	strs := []string{""}
+	for n, prev := 0, []string{""}; n < 4; n++ {
+		var next []string
+		for _, s := range prev {
+			for _, c := range chars {
+				next = append(next, s+c)
+			}
+		}
+		strs = append(strs, next...)
+		prev = next
+	}
+
+	for _, s := range strs {
+		for _, v := range []interface{}{
+			map[string]string{"k": s},
+			[]string{s},
+			map[string]int{s: 1},
+		} {
+			want, err := yaml.Marshal(v)
+			if err != nil {
+				t.Fatalf("yaml.Marshal(%#v) = %v", v, err)
+			}
+			j, err := json.Marshal(v)
+			if err != nil {
+				t.Fatalf("json.Marshal(%#v) = %v", v, err)
+			}
+			if got := encodeJSON(t, string(j)); got != string(want) {
+				t.Errorf("encoding %#v = %#q; want %#q", v, got, want)
+			}
+			if got, err := Marshal(v, LineWidth(80)); err != nil || string(got) != string(want) {
+				t.Errorf("Marshal(%#v, LineWidth(80)) = %#q, %v; want %#q", v, got, err, want)
+			}
+			for _, opts := range [][]MarshalOpt{{MultilineLiteral()}, {MultilineLiteral(), LineWidth(4)}, {QuoteStrings(QuoteSingle)}} {
+				got, err := Marshal(v, opts...)
+				if err != nil {
+					t.Fatalf("Marshal(%#v) = %v", v, err)
+				}
+				back := reflect.New(reflect.TypeOf(v))
+				if err := yaml.Unmarshal(got, back.Interface()); err != nil || !reflect.DeepEqual(back.Elem().Interface(), v) {
+					t.Errorf("Marshal(%#v) = %#q, read back as %#v, %v", v, got, back.Elem().Interface(), err)
+				}
+			}
+		}
+	}
+}
+
+func TestMultilineLiteral(t *testing.T) {
+	for _, tc := range []struct {
+		json string
+		want string
+	}{
+		{`{"a":"x\ny"}`, "a: |-\n  x\n  y\n"},
+		{`{"a":"x \ny\n"}`, "a: |\n  x \n  y\n"},
+		{`{"a":"x\ty\nz"}`, "a: |-\n  x\ty\n  z\n"},
+		{`["x\ny "]`, "- |-\n  x\n  y \n"},
+		// Unprintable characters still need escaping.
+		{`{"a":"x\u0001\ny"}`, "a: \"x\\x01\\ny\"\n"},
+		// Keys are never written as block scalars.
+		{`{"a\tb":"c"}`, "\"a\\tb\": c\n"},
+	} {
+		got, err := JSONToYAMLWithOpts([]byte(tc.json), MultilineLiteral())
+		if err != nil {
+			t.Errorf("JSONToYAMLWithOpts(%#q) = %v", tc.json, err)
+			continue
+		}
+		if string(got) != tc.want {
+			t.Errorf("JSONToYAMLWithOpts(%#q) = %#q; want %#q", tc.json, string(got), tc.want)
+			continue
+		}
+		j, err := YAMLToJSON(got)
+		if err != nil {
+			t.Errorf("YAMLToJSON(%#q) = %v", string(got), err)
+			continue
+		}
+		if !jsonEqual(t, j, []byte(tc.json)) {
+			t.Errorf("YAMLToJSON(%#q) = %s; want %s", string(got), j, tc.json)
+		}
+	}
+}
+
+func TestMarshalWithOpts(t *testing.T) {
+	s := struct {
+		Script string `json:"script"`
+	}{"set -e\t\necho hi \n"}
+	y, err := Marshal(s, MultilineLiteral())
+	if err != nil {
+		t.Fatalf("Marshal() = %v", err)
+	}
+	if want := "script: |\n  set -e\t\n  echo hi \n"; string(y) != want {
+		t.Errorf("Marshal() = %#q; want %#q", string(y), want)
+	}
+}
+
+// jsonEqual reports whether a and b hold the same JSON value.
+func jsonEqual(t *testing.T, a, b []byte) bool {
+	t.Helper()
+	var av, bv interface{}
+	if err := json.Unmarshal(a, &av); err != nil {
+		t.Fatalf("json.Unmarshal(%s) = %v", a, err)
+	}
+	if err := json.Unmarshal(b, &bv); err != nil {
+		t.Fatalf("json.Unmarshal(%s) = %v", b, err)
+	}
+	return reflect.DeepEqual(av, bv)
+}
+
+func TestLineWidth(t *testing.T) {
+	url := "https://example.com/" + strings.Repeat("a", 90)
+	for _, tc := range []struct {
+		json  string
+		width int
+		want  string
+	}{
+		{`{"a":"aaa bbb ccc ddd"}`, 5, "a: aaa\n  bbb ccc\n  ddd\n"},
+		{`{"a":"aaa bbb ccc ddd"}`, 10, "a: aaa bbb ccc\n  ddd\n"},
+		{`{"a":"` + url + ` signed"}`, 0, "a: " + url + " signed\n"},
+		{`{"a":"` + url + ` signed"}`, -1, "a: " + url + " signed\n"},
+		{`{"a":"` + url + ` signed"}`, 200, "a: " + url + " signed\n"},
+		{`{"a":"` + url + ` signed"}`, 80, "a: " + url + "\n  signed\n"},
+		{`{"a":"true x y"}`, 4, "a: true\n  x y\n"},
+		{`{"a":"#aa bb"}`, 4, "a: '#aa\n  bb'\n"},
+	} {
+		got, err := JSONToYAMLWithOpts([]byte(tc.json), LineWidth(tc.width))
+		if err != nil {
+			t.Errorf("JSONToYAMLWithOpts(%#q, LineWidth(%d)) = %v", tc.json, tc.width, err)
+			continue
+		}
+		if string(got) != tc.want {
+			t.Errorf("JSONToYAMLWithOpts(%#q, LineWidth(%d)) = %#q; want %#q", tc.json, tc.width, string(got), tc.want)
+			continue
+		}
+		j, err := YAMLToJSON(got)
+		if err != nil {
+			t.Errorf("YAMLToJSON(%#q) = %v", string(got), err)
+			continue
+		}
+		if !jsonEqual(t, j, []byte(tc.json)) {
+			t.Errorf("YAMLToJSON(%#q) = %s; want %s", string(got), j, tc.json)
+		}
+	}
+}
+
+func TestQuoting(t *testing.T) {
+	const j = `{"a":"x","b":"true","c":"a: b","d":"it's","e":"x\ny","f":"","g":1,"h\"":"\u0001"}`
+	for _, tc := range []struct {
+		opts []MarshalOpt
+		want string
+	}{
+		{
+			opts: []MarshalOpt{QuoteStrings(QuotePlain)},
+			want: "a: x\nb: \"true\"\nc: 'a: b'\nd: it's\ne: |-\n  x\n  y\nf: \"\"\ng: 1\nh\": \"\\x01\"\n",
+		},
+		{
+			opts: []MarshalOpt{QuoteStrings(QuoteSingle)},
+			want: "a: 'x'\nb: 'true'\nc: 'a: b'\nd: 'it''s'\ne: |-\n  x\n  y\nf: ''\ng: 1\nh\": \"\\x01\"\n",
+		},
+		{
+			opts: []MarshalOpt{QuoteStrings(QuoteDouble)},
+			want: "a: \"x\"\nb: \"true\"\nc: \"a: b\"\nd: \"it's\"\ne: |-\n  x\n  y\nf: \"\"\ng: 1\nh\": \"\\x01\"\n",
+		},
+		{
+			opts: []MarshalOpt{QuoteWhenNeeded(QuoteSingle)},
+			want: "a: x\nb: 'true'\nc: 'a: b'\nd: it's\ne: |-\n  x\n  y\nf: ''\ng: 1\nh\": \"\\x01\"\n",
+		},
+		{
+			opts: []MarshalOpt{QuoteWhenNeeded(QuoteDouble)},
+			want: "a: x\nb: \"true\"\nc: \"a: b\"\nd: it's\ne: |-\n  x\n  y\nf: \"\"\ng: 1\nh\": \"\\x01\"\n",
+		},
+	} {
+		got, err := JSONToYAMLWithOpts([]byte(j), tc.opts...)
+		if err != nil {
+			t.Errorf("JSONToYAMLWithOpts(%#q) = %v", j, err)
+			continue
+		}
+		if string(got) != tc.want {
+			t.Errorf("JSONToYAMLWithOpts(%#q) = %#q; want %#q", j, string(got), tc.want)
+			continue
+		}
+		back, err := YAMLToJSON(got)
+		if err != nil {
+			t.Errorf("YAMLToJSON(%#q) = %v", string(got), err)
+			continue
+		}
+		if !jsonEqual(t, back, []byte(j)) {
+			t.Errorf("YAMLToJSON(%#q) = %s; want %s", string(got), back, j)
+		}
+	}
+}
+
+func TestNullsAs(t *testing.T) {
+	const j = `{"a":null,"b":[null,{"c":null}],"d":"null"}`
+	for _, tc := range []struct {
+		style NullStyle
+		want  string
+	}{
+		{NullKeyword, "a: null\nb:\n- null\n- c: null\nd: \"null\"\n"},
+		{NullTilde, "a: ~\nb:\n- ~\n- c: ~\nd: \"null\"\n"},
+		{NullEmpty, "a:\nb:\n-\n- c:\nd: \"null\"\n"},
+	} {
+		got, err := JSONToYAMLWithOpts([]byte(j), NullsAs(tc.style))
+		if err != nil {
+			t.Errorf("JSONToYAMLWithOpts(%#q, NullsAs(%d)) = %v", j, tc.style, err)
+			continue
+		}
+		if string(got) != tc.want {
+			t.Errorf("JSONToYAMLWithOpts(%#q, NullsAs(%d)) = %#q; want %#q", j, tc.style, string(got), tc.want)
+			continue
+		}
+		back, err := YAMLToJSON(got)
+		if err != nil {
+			t.Errorf("YAMLToJSON(%#q) = %v", string(got), err)
+			continue
+		}
+		if !jsonEqual(t, back, []byte(j)) {
+			t.Errorf("YAMLToJSON(%#q) = %s; want %s", string(got), back, j)
+		}
+	}
+
+	got, err := JSONToYAMLWithOpts([]byte(`null`), NullsAs(NullEmpty))
+	if err != nil || string(got) != "" {
+		t.Errorf("JSONToYAMLWithOpts(`null`, NullsAs(NullEmpty)) = %#q, %v; want \"\", nil", string(got), err)
+	}
+}
+
+func TestDocumentStart(t *testing.T) {
+	for _, tc := range []struct {
+		json string
+		opts []MarshalOpt
+		want string
+	}{
+		{`{"a":1}`, []MarshalOpt{DocumentStart()}, "---\na: 1\n"},
+		{`[1]`, []MarshalOpt{DocumentStart()}, "---\n- 1\n"},
+		{`"a"`, []MarshalOpt{DocumentStart()}, "--- a\n"},
+		{`"a\nb"`, []MarshalOpt{DocumentStart()}, "--- |-\n  a\n  b\n"},
+		{`{}`, []MarshalOpt{DocumentStart()}, "--- {}\n"},
+		{`null`, []MarshalOpt{DocumentStart(), NullsAs(NullEmpty)}, "---\n"},
+		{`{"a":1}`, []MarshalOpt{VersionDirective("1.1")}, "%YAML 1.1\n---\na: 1\n"},
+		{`{"a":1}`, []MarshalOpt{VersionDirective("1.2")}, "%YAML 1.2\n---\na: 1\n"},
+	} {
+		got, err := JSONToYAMLWithOpts([]byte(tc.json), tc.opts...)
+		if err != nil {
+			t.Errorf("JSONToYAMLWithOpts(%#q) = %v", tc.json, err)
+			continue
+		}
+		if string(got) != tc.want {
+			t.Errorf("JSONToYAMLWithOpts(%#q) = %#q; want %#q", tc.json, string(got), tc.want)
+		}
+	}
+
+	// go-yaml reads back documents declaring version 1.1.
+	y, err := JSONToYAMLWithOpts([]byte(`{"a":1}`), VersionDirective("1.1"))
+	if err != nil {
+		t.Fatalf("JSONToYAMLWithOpts() = %v", err)
+	}
+	if j, err := YAMLToJSON(y); err != nil || string(j) != `{"a":1}` {
+		t.Errorf("YAMLToJSON(%#q) = %s, %v; want {\"a\":1}, nil", string(y), j, err)
+	}
+}
+
+func TestDocumentEnd(t *testing.T) {
+	for _, tc := range []struct {
+		json string
+		opts []MarshalOpt
+		want string
+	}{
+		{`{"a":1}`, []MarshalOpt{DocumentEnd()}, "a: 1\n...\n"},
+		{`"a\n"`, []MarshalOpt{DocumentEnd()}, "|\n  a\n...\n"},
+		{`[1]`, []MarshalOpt{DocumentStart(), DocumentEnd()}, "---\n- 1\n...\n"},
+	} {
+		got, err := JSONToYAMLWithOpts([]byte(tc.json), tc.opts...)
+		if err != nil {
+			t.Errorf("JSONToYAMLWithOpts(%#q) = %v", tc.json, err)
+			continue
+		}
+		if string(got) != tc.want {
+			t.Errorf("JSONToYAMLWithOpts(%#q) = %#q; want %#q", tc.json, string(got), tc.want)
+			continue
+		}
+		back, err := YAMLToJSON(got)
+		if err != nil {
+			t.Errorf("YAMLToJSON(%#q) = %v", string(got), err)
+			continue
+		}
+		if !jsonEqual(t, back, []byte(tc.json)) {
+			t.Errorf("YAMLToJSON(%#q) = %s; want %s", string(got), back, tc.json)
+		}
+	}
+}
+
+func TestCRLFLineEndings(t *testing.T) {
+	for _, tc := range []struct {
+		json string
+		opts []MarshalOpt
+		want string
+	}{
+		{`{"a":1,"b":[1,2]}`, nil, "a: 1\r\nb:\r\n- 1\r\n- 2\r\n"},
+		{`{"a":"x\ny\n"}`, []MarshalOpt{MultilineLiteral()}, "a: |\r\n  x\r\n  y\r\n"},
+		{`{"a":"x\r\ny"}`, nil, "a: \"x\\r\\ny\"\r\n"},
+		{`[1]`, []MarshalOpt{DocumentStart(), DocumentEnd()}, "---\r\n- 1\r\n...\r\n"},
+	} {
+		got, err := JSONToYAMLWithOpts([]byte(tc.json), append(tc.opts, CRLFLineEndings())...)
+		if err != nil {
+			t.Errorf("JSONToYAMLWithOpts(%#q) = %v", tc.json, err)
+			continue
+		}
+		if string(got) != tc.want {
+			t.Errorf("JSONToYAMLWithOpts(%#q) = %#q; want %#q", tc.json, string(got), tc.want)
+			continue
+		}
+		back, err := YAMLToJSON(got)
+		if err != nil {
+			t.Errorf("YAMLToJSON(%#q) = %v", string(got), err)
+			continue
+		}
+		if !jsonEqual(t, back, []byte(tc.json)) {
+			t.Errorf("YAMLToJSON(%#q) = %s; want %s", string(got), back, tc.json)
+		}
+	}
+}
+
+func TestKeyOrder(t *testing.T) {
+	const j = `{"kind":"Pod","b10":1,"B":2,"metadata":{"name":"x","labels":{}},"b9":3,"apiVersion":"v1"}`
+	for _, tc := range []struct {
+		opts []MarshalOpt
+		want string
+	}{
+		{
+			opts: []MarshalOpt{SortKeys(KeyOrderNatural)},
+			want: "B: 2\napiVersion: v1\nb9: 3\nb10: 1\nkind: Pod\nmetadata:\n  labels: {}\n  name: x\n",
+		},
+		{
+			opts: []MarshalOpt{SortKeys(KeyOrderAlphabetical)},
+			want: "B: 2\napiVersion: v1\nb10: 1\nb9: 3\nkind: Pod\nmetadata:\n  labels: {}\n  name: x\n",
+		},
+		{
+			opts: []MarshalOpt{SortKeys(KeyOrderDocument)},
+			want: "kind: Pod\nb10: 1\nB: 2\nmetadata:\n  name: x\n  labels: {}\nb9: 3\napiVersion: v1\n",
+		},
+		{
+			opts: []MarshalOpt{KeysFirst("apiVersion", "kind", "metadata", "name")},
+			want: "apiVersion: v1\nkind: Pod\nmetadata:\n  name: x\n  labels: {}\nB: 2\nb9: 3\nb10: 1\n",
+		},
+		{
+			opts: []MarshalOpt{KeysFirst("metadata"), SortKeys(KeyOrderDocument)},
+			want: "metadata:\n  name: x\n  labels: {}\nkind: Pod\nb10: 1\nB: 2\nb9: 3\napiVersion: v1\n",
+		},
+	} {
+		got, err := JSONToYAMLWithOpts([]byte(j), tc.opts...)
+		if err != nil {
+			t.Errorf("JSONToYAMLWithOpts(%#q) = %v", j, err)
+			continue
+		}
+		if string(got) != tc.want {
+			t.Errorf("JSONToYAMLWithOpts(%#q) = %#q; want %#q", j, string(got), tc.want)
+		}
+	}
+}
+
+func TestMarshalKeyOrderDocument(t *testing.T) {
+	type Object struct {
+		Kind       string            `json:"kind"`
+		APIVersion string            `json:"apiVersion"`
+		Labels     map[string]string `json:"labels"`
+	}
+	o := Object{"Pod", "v1", map[string]string{"z": "1", "a": "2"}}
+	y, err := Marshal(o, SortKeys(KeyOrderDocument))
+	if err != nil {
+		t.Fatalf("Marshal() = %v", err)
+	}
+	if want := "kind: Pod\napiVersion: v1\nlabels:\n  a: \"2\"\n  z: \"1\"\n"; string(y) != want {
+		t.Errorf("Marshal() = %#q; want %#q", string(y), want)
+	}
+}
+
+type zeroWhenEmpty struct {
+	Items []string `json:"items"`
+}
+
+func (z zeroWhenEmpty) IsZero() bool { return len(z.Items) == 0 }
+
+func TestOmitZero(t *testing.T) {
+	type OmitZeroTest struct {
+		Time   time.Time       `json:"time,omitzero"`
+		Struct struct{ A int } `json:"struct,omitzero"`
+		Custom zeroWhenEmpty   `json:"custom,omitzero"`
+		Ptr    *int            `json:"ptr,omitzero"`
+		Empty  int             `json:"empty,omitempty"`
+		Kept   int             `json:"kept"`
+	}
+
+	// encoding/json leaves the fields out, so Marshal does not walk the
+	// value.
+	if typeNeedsStructs(reflect.TypeOf(OmitZeroTest{})) {
+		t.Error("typeNeedsStructs() = true for fields with the omitzero option")
+	}
+
+	y, err := Marshal(OmitZeroTest{Custom: zeroWhenEmpty{Items: []string{}}})
+	if err != nil {
+		t.Fatalf("Marshal() = %v", err)
+	}
+	if want := "kept: 0\n"; string(y) != want {
+		t.Errorf("Marshal() = %#q; want %#q", string(y), want)
+	}
+
+	v := OmitZeroTest{Time: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+	v.Struct.A = 1
+	y, err = Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal() = %v", err)
+	}
+	if want := "kept: 0\nstruct:\n  A: 1\ntime: \"2024-01-02T03:04:05Z\"\n"; string(y) != want {
+		t.Errorf("Marshal() = %#q; want %#q", string(y), want)
+	}
+}
@@ -0,0 +1,75 @@
+package yaml
+
+import (
+	"bytes"
+	"io"
+)
+
+// Encoder writes values as a stream of YAML documents, such as a log that a
+// long-running process appends to.
+type Encoder struct {
+	w    io.Writer
+	opts []MarshalOpt
+	eol  string
+	n    int
+	// ended is set when the last document written ends with a "..." line.
+	ended bool
+}
+
+// NewEncoder returns an Encoder that writes to w, marshaling each value with
+// the options opts.
+func NewEncoder(w io.Writer, opts ...MarshalOpt) *Encoder {
+	eol := "\n"
+	if newEncoder(opts).crlf {
+		eol = "\r\n"
+	}
+	return &Encoder{w: w, opts: opts, eol: eol}
+}
+
+// Encode writes v as the next document of the stream, starting it with a
+// "---" line unless it is the first, and otherwise as Marshal writes it with
+// the options of e. A document that starts with a directive, as with the
+// VersionDirective option, has its own "---" line, and the document before
+// it is ended with a "..." line instead, since directives may only follow
+// the end of a document. The document is written with a single call to Write, and
+// if the writer has a Flush method, such as a bufio.Writer, it is flushed, so
+// that a reader of the stream sees each document as soon as it is encoded.
+func (e *Encoder) Encode(v interface{}) error {
+	y, err := Marshal(v, e.opts...)
+	if err != nil {
+		return err
+	}
+	if e.n > 0 {
+		switch {
+		case bytes.HasPrefix(y, []byte("%")):
+			if !e.ended {
+				y = append([]byte("..."+e.eol), y...)
+			}
+		// A document marshaled with the DocumentStart option has its
+		// marker already.
+		case !bytes.HasPrefix(y, []byte("---")):
+			y = append([]byte("---"+e.eol), y...)
+		}
+	}
+	if _, err := e.w.Write(y); err != nil {
+		return err
+	}
+	e.n++
+	e.ended = bytes.HasSuffix(y, []byte("..."+e.eol))
+	if f, ok := e.w.(interface{ Flush() error }); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// MarshalWrite writes v, marshaled with opts, to w as a single document, for
+// writing a value to a file or an HTTP response. Nothing is written if v
+// cannot be marshaled.
+func MarshalWrite(w io.Writer, v interface{}, opts ...MarshalOpt) error {
+	y, err := Marshal(v, opts...)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(y)
+	return err
+}
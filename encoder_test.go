@@ -0,0 +1,114 @@
+package yaml
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestEncoder(t *testing.T) {
+	var out bytes.Buffer
+	w := bufio.NewWriter(&out)
+	e := NewEncoder(w, SortKeys(KeyOrderAlphabetical))
+	for i, v := range []interface{}{
+		map[string]interface{}{"b": 1, "a": 2},
+		[]string{"x"},
+		"done",
+	} {
+		if err := e.Encode(v); err != nil {
+			t.Fatalf("Encode() = %v", err)
+		}
+		if i == 0 && out.String() != "a: 2\nb: 1\n" {
+			t.Errorf("Encode() did not flush: %q", out.String())
+		}
+	}
+	want := "a: 2\nb: 1\n---\n- x\n---\ndone\n"
+	if out.String() != want {
+		t.Errorf("Encode() wrote %q; want %q", out.String(), want)
+	}
+
+	// Each document is written as Marshal writes it, with the marker of the
+	// DocumentStart option written once.
+	values := []interface{}{"a\nb", map[string]string{"k": "é\tx"}, 1.5}
+	for _, opts := range [][]MarshalOpt{nil, {DocumentStart()}} {
+		out.Reset()
+		e := NewEncoder(&out, opts...)
+		want := ""
+		for i, v := range values {
+			if err := e.Encode(v); err != nil {
+				t.Fatalf("Encode() = %v", err)
+			}
+			y, _ := Marshal(v, opts...)
+			if i > 0 && opts == nil {
+				want += "---\n"
+			}
+			want += string(y)
+		}
+		if out.String() != want {
+			t.Errorf("Encode() with %d options wrote %q; want %q", len(opts), out.String(), want)
+		}
+	}
+
+	// The stream reads back as the documents encoded, with the separators
+	// following the options.
+	for _, opts := range [][]MarshalOpt{
+		{VersionDirective("1.1")},
+		{CRLFLineEndings()},
+		{VersionDirective("1.1"), CRLFLineEndings()},
+		{VersionDirective("1.1"), DocumentEnd()},
+	} {
+		out.Reset()
+		e := NewEncoder(&out, opts...)
+		for _, v := range []map[string]int{{"a": 1}, {"b": 2}} {
+			if err := e.Encode(v); err != nil {
+				t.Fatalf("Encode() = %v", err)
+			}
+		}
+		var docs []map[string]int
+		if err := UnmarshalDocuments(out.Bytes(), &docs); err != nil {
+			t.Errorf("UnmarshalDocuments(%q) = %v", out.String(), err)
+		} else if len(docs) != 2 || docs[0]["a"] != 1 || docs[1]["b"] != 2 {
+			t.Errorf("UnmarshalDocuments(%q) = %v", out.String(), docs)
+		}
+		if newEncoder(opts).crlf && bytes.Count(out.Bytes(), []byte("\n")) != bytes.Count(out.Bytes(), []byte("\r\n")) {
+			t.Errorf("Encode() mixed line endings: %q", out.String())
+		}
+	}
+	out.Reset()
+	e = NewEncoder(&out, VersionDirective("1.1"))
+	e.Encode(map[string]int{"a": 1})
+	e.Encode(map[string]int{"b": 2})
+	if want := "%YAML 1.1\n---\na: 1\n...\n%YAML 1.1\n---\nb: 2\n"; out.String() != want {
+		t.Errorf("Encode() with VersionDirective wrote %q; want %q", out.String(), want)
+	}
+
+	if err := NewEncoder(&out).Encode(func() {}); err == nil {
+		t.Error("Encode() of a func returned no error")
+	}
+	if err := NewEncoder(failingWriter{}).Encode(1); err == nil {
+		t.Error("Encode() to a failing writer returned no error")
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("write failed") }
+
+func TestMarshalWrite(t *testing.T) {
+	var out bytes.Buffer
+	if err := MarshalWrite(&out, map[string]int{"b": 1, "a": 2}, DocumentStart()); err != nil {
+		t.Fatalf("MarshalWrite() = %v", err)
+	}
+	if want := "---\na: 2\nb: 1\n"; out.String() != want {
+		t.Errorf("MarshalWrite() wrote %q; want %q", out.String(), want)
+	}
+
+	out.Reset()
+	if err := MarshalWrite(&out, func() {}); err == nil || out.Len() != 0 {
+		t.Errorf("MarshalWrite() of a func = %v and wrote %q", err, out.String())
+	}
+	if err := MarshalWrite(failingWriter{}, 1); err == nil {
+		t.Error("MarshalWrite() to a failing writer returned no error")
+	}
+}
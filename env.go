@@ -0,0 +1,164 @@
+package yaml
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	yaml3 "gopkg.in/yaml.v3"
+)
+
+// ExpandEnv makes the conversion expand references to environment variables
+// in the scalars of the document, other than mapping keys, before they are
+// decoded. A reference is one of
+//
+//	${VAR}          the value of VAR, or "" if it is unset
+//	${VAR:-default} the value of VAR, or default if it is unset or empty
+//	${VAR-default}  the value of VAR, or default if it is unset
+//	${VAR:?message} the value of VAR, or an error if it is unset or empty
+//	${VAR?message}  the value of VAR, or an error if it is unset
+//
+// and $${ stands for a literal ${. Quoted scalars stay strings, while plain
+// scalars are resolved after they are expanded, so that "port: ${PORT}"
+// decodes into an int field. Variables are looked up with lookup, or with
+// os.LookupEnv if it is nil.
+func ExpandEnv(lookup func(name string) (string, bool)) JSONOpt {
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+	return decodeOpt(func(o *decodeOptions) {
+		o.lookupEnv = lookup
+	})
+}
+
+// expandEnv returns the document y with the references to environment
+// variables in its scalars expanded.
+func (o *decodeOptions) expandEnv(y []byte) ([]byte, error) {
+	if !strings.Contains(string(y), "${") {
+		return y, nil
+	}
+	var doc yaml3.Node
+	if err := yaml3.Unmarshal(y, &doc); err != nil {
+		return nil, err
+	}
+	found, err := o.expandNode(&doc)
+	if err != nil || !found {
+		return y, err
+	}
+	return yaml3.Marshal(&doc)
+}
+
+// expandNode expands the references in the scalars of n and reports whether
+// it found any.
+func (o *decodeOptions) expandNode(n *yaml3.Node) (bool, error) {
+	found := false
+	for i, c := range n.Content {
+		if n.Kind == yaml3.MappingNode && i%2 == 0 {
+			continue
+		}
+		f, err := o.expandNode(c)
+		if err != nil {
+			return false, err
+		}
+		found = found || f
+	}
+	if n.Kind != yaml3.ScalarNode || !strings.Contains(n.Value, "${") {
+		return found, nil
+	}
+	v, err := expandString(n.Value, o.lookupEnv)
+	if err != nil {
+		return false, fmt.Errorf("yaml: line %d: %v", n.Line, err)
+	}
+	n.Value = v
+	if n.Style == 0 && n.Tag == "!!str" {
+		// Let the expanded plain scalar be resolved.
+		n.Tag = ""
+	}
+	return true, nil
+}
+
+// expandString expands the references in s.
+func expandString(s string, lookup func(string) (string, bool)) (string, error) {
+	var b strings.Builder
+	for {
+		i := strings.Index(s, "${")
+		if i < 0 {
+			b.WriteString(s)
+			return b.String(), nil
+		}
+		if i > 0 && s[i-1] == '$' {
+			b.WriteString(s[:i] + "{")
+			s = s[i+2:]
+			continue
+		}
+		end := strings.IndexByte(s[i:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated reference %q", s[i:])
+		}
+		b.WriteString(s[:i])
+		v, err := expandReference(s[i+2:i+end], lookup)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(v)
+		s = s[i+end+1:]
+	}
+}
+
+// expandReference returns the value of the reference ref, the text between
+// "${" and "}".
+func expandReference(ref string, lookup func(string) (string, bool)) (string, error) {
+	name, op, arg := ref, "", ""
+	if i := strings.IndexAny(ref, ":-?"); i >= 0 {
+		name, op = ref[:i], ref[i:i+1]
+		if op == ":" && i+1 < len(ref) && (ref[i+1] == '-' || ref[i+1] == '?') {
+			op = ref[i : i+2]
+		}
+		arg = ref[i+len(op):]
+		if op == ":" {
+			return "", fmt.Errorf("invalid reference ${%s}", ref)
+		}
+	}
+	if !validEnvName(name) {
+		return "", fmt.Errorf("invalid reference ${%s}", ref)
+	}
+	v, ok := lookup(name)
+	switch op {
+	case ":-":
+		if v == "" {
+			return arg, nil
+		}
+	case "-":
+		if !ok {
+			return arg, nil
+		}
+	case ":?":
+		if v == "" {
+			return "", envError(name, arg)
+		}
+	case "?":
+		if !ok {
+			return "", envError(name, arg)
+		}
+	}
+	return v, nil
+}
+
+func envError(name, message string) error {
+	if message == "" {
+		message = "not set"
+	}
+	return fmt.Errorf("environment variable %s: %s", name, message)
+}
+
+func validEnvName(name string) bool {
+	if name == "" || name[0] >= '0' && name[0] <= '9' {
+		return false
+	}
+	for _, r := range name {
+		if r != '_' && (r < '0' || r > '9') && (r < 'A' || r > 'Z') && (r < 'a' || r > 'z') {
+			return false
+		}
+	}
+	return true
+}
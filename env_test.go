@@ -0,0 +1,80 @@
+package yaml
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExpandEnv(t *testing.T) {
+	env := map[string]string{"PORT": "8080", "HOST": "example.com", "EMPTY": "", "FLAG": "true", "TEXT": "a: b"}
+	lookup := func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+	type Config struct {
+		Port    int    `json:"port"`
+		Quoted  string `json:"quoted"`
+		URL     string `json:"url"`
+		Debug   bool   `json:"debug"`
+		Text    string `json:"text"`
+		Script  string `json:"script"`
+		Default string `json:"default"`
+		Empty   string `json:"empty"`
+		Unset   string `json:"unset"`
+		Escaped string `json:"escaped"`
+		Key     string `json:"${HOST}"`
+	}
+	y := `port: ${PORT}
+quoted: "${PORT}"
+url: http://${HOST}:${PORT}/
+debug: ${FLAG}
+text: ${TEXT}
+script: |
+  echo ${HOST}
+default: ${MISSING:-fallback}
+empty: ${EMPTY-unused}
+unset: ${MISSING}
+escaped: $${HOST}
+${HOST}: key
+`
+	var c Config
+	if err := Unmarshal([]byte(y), &c, ExpandEnv(lookup)); err != nil {
+		t.Fatal(err)
+	}
+	want := Config{
+		Port:    8080,
+		Quoted:  "8080",
+		URL:     "http://example.com:8080/",
+		Debug:   true,
+		Text:    "a: b",
+		Script:  "echo example.com\n",
+		Default: "fallback",
+		Escaped: "${HOST}",
+		Key:     "key",
+	}
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("Unmarshal() = %+v; want %+v", c, want)
+	}
+
+	for _, tc := range []struct {
+		y, err string
+	}{
+		{"a: ${MISSING?is required}", "yaml: line 1: environment variable MISSING: is required"},
+		{"a: ${EMPTY:?}", "environment variable EMPTY: not set"},
+		{"a: ${PORT", "unterminated reference"},
+		{"a: ${1X}", "invalid reference ${1X}"},
+		{"a: ${X:y}", "invalid reference ${X:y}"},
+	} {
+		var v interface{}
+		if err := Unmarshal([]byte(tc.y), &v, ExpandEnv(lookup)); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("Unmarshal(%#q) = %v; want an error containing %q", tc.y, err, tc.err)
+		}
+	}
+
+	t.Setenv("YAML_TEST_ENV", "set")
+	j, err := YAMLToJSONWithOpts([]byte("a: ${YAML_TEST_ENV}\n"), ExpandEnv(nil))
+	if err != nil || string(j) != `{"a":"set"}` {
+		t.Errorf("YAMLToJSONWithOpts() with ExpandEnv(nil) = %s, %v", j, err)
+	}
+}
@@ -0,0 +1,137 @@
+package yaml
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Equal reports whether the YAML streams a and b hold the same values, as
+// Unmarshal reads them, regardless of the order of keys, quoting, comments and
+// formatting. Numbers are compared by their exact value, so 1, 1.0 and 0x1
+// are equal but the integers 9007199254740993 and 9007199254740992, which a
+// float64 cannot tell apart, are not. Streams with several documents are equal
+// if their documents are equal in order.
+func Equal(a, b []byte) (bool, error) {
+	av, err := semanticDocuments(a)
+	if err != nil {
+		return false, err
+	}
+	bv, err := semanticDocuments(b)
+	if err != nil {
+		return false, err
+	}
+	if len(av) != len(bv) {
+		return false, nil
+	}
+	for i := range av {
+		if !equalValues(av[i], bv[i]) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// semanticDocuments decodes each document of the YAML stream y into its
+// JSON-compatible form, keeping the exact text of numbers that a float64
+// cannot hold.
+func semanticDocuments(y []byte) ([]interface{}, error) {
+	y, err := toUTF8(y)
+	if err != nil {
+		return nil, err
+	}
+	var docs []interface{}
+	opts := &decodeOptions{}
+	d := yaml.NewDecoder(bytes.NewReader(y))
+	for {
+		var t textYAML
+		if err := d.Decode(&t); err == io.EOF {
+			return docs, nil
+		} else if err != nil {
+			return nil, err
+		}
+		v, err := convertToJSONableObject(opts.resolveScalars(t.v, true), nil, opts, nil)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, v)
+	}
+}
+
+// equalValues reports whether the JSON-compatible values a and b are equal.
+func equalValues(a, b interface{}) bool {
+	switch a := a.(type) {
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for k, av := range a {
+			bv, ok := b[k]
+			if !ok || !equalValues(av, bv) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !equalValues(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	if af, ar, ok := numberValue(a); ok {
+		bf, br, ok := numberValue(b)
+		switch {
+		case !ok:
+			return false
+		case ar != nil && br != nil:
+			return ar.Cmp(br) == 0
+		case ar != nil || br != nil:
+			return false
+		}
+		return af == bf || math.IsNaN(af) && math.IsNaN(bf)
+	}
+	return a == b
+}
+
+// numberValue returns the exact value of the number v. A float that is not
+// finite has none and is returned as it is.
+func numberValue(v interface{}) (float64, *big.Rat, bool) {
+	switch n := v.(type) {
+	case int:
+		return 0, new(big.Rat).SetInt64(int64(n)), true
+	case int64:
+		return 0, new(big.Rat).SetInt64(n), true
+	case uint64:
+		return 0, new(big.Rat).SetInt(new(big.Int).SetUint64(n)), true
+	case float64:
+		if math.IsNaN(n) || math.IsInf(n, 0) {
+			return n, nil, true
+		}
+		return 0, new(big.Rat).SetFloat64(n), true
+	case json.Number:
+		return parseNumber(string(n))
+	case exactNumber:
+		return parseNumber(string(n))
+	}
+	return 0, nil, false
+}
+
+func parseNumber(s string) (float64, *big.Rat, bool) {
+	if r, ok := new(big.Rat).SetString(s); ok {
+		return 0, r, true
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	return f, nil, err == nil
+}
@@ -0,0 +1,39 @@
+package yaml
+
+import "testing"
+
+func TestEqual(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		want bool
+	}{
+		{"a: 1\nb: [x, 'z']\n", "# Comment.\nb: [\"x\", z]\na: 1\n", true},
+		{"a: 1\n", "a: 1.0\n", true},
+		{"a: 0x10\n", "a: 16\n", true},
+		{"a: yes\n", "a: true\n", true},
+		{"1: a\n", "'1': a\n", true},
+		{"a: &x [1]\nb: *x\n", "a: [1]\nb: [1]\n", true},
+		{"a: 9007199254740993\n", "a: 9007199254740992\n", false},
+		{"a: 12345678901234567890123\n", "a: 12345678901234567890124\n", false},
+		{"a: 1\n", "a: '1'\n", false},
+		{"a: null\n", "a: ''\n", false},
+		{"a: '~'\n'null': b\n", "a: \"~\"\n\"null\": b\n", true},
+		{"a: '~'\n", "a: ~\n", false},
+		{"a: [1, 2]\n", "a: [2, 1]\n", false},
+		{"a: 1\n", "a: 1\nb: 2\n", false},
+		{"a: 1\n---\nb: 2\n", "a: 1\n", false},
+		{"a: 1\n---\nb: 2\n", "a: 1.0\n---\nb: 2\n", true},
+	} {
+		got, err := Equal([]byte(tc.a), []byte(tc.b))
+		if err != nil {
+			t.Fatalf("Equal(%#q, %#q) = %v", tc.a, tc.b, err)
+		}
+		if got != tc.want {
+			t.Errorf("Equal(%#q, %#q) = %v; want %v", tc.a, tc.b, got, tc.want)
+		}
+	}
+
+	if _, err := Equal([]byte("a: 1\n"), []byte("a: [")); err == nil {
+		t.Error("Equal() of invalid YAML returned no error")
+	}
+}
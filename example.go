@@ -0,0 +1,206 @@
+package yaml
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	yaml3 "gopkg.in/yaml.v3"
+)
+
+// Example writes an example YAML document for v, a struct or a pointer to
+// one, such as a config.example.yaml for the struct a program reads its
+// configuration into. Every field is written, whether or not it is empty or
+// has the omitempty option, with its value in v if that is not the zero
+// value, else with its default tag, else with the zero value of its type.
+// Empty slices and maps are given one item, so that the fields of their
+// items are shown too. The description tag of a field, such as
+// `description:"Port to listen on."`, is written as a comment above its key,
+// followed by "Required." for required fields. FieldNaming and StructTags
+// name the keys as they do for Marshal; other options are ignored.
+func Example(v interface{}, opts ...MarshalOpt) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() == reflect.Ptr {
+		rv = reflect.Zero(rv.Type().Elem())
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("yaml: Example of non-struct type %T", v)
+	}
+
+	g := &exampleGenerator{e: newEncoder(opts), seen: map[reflect.Type]bool{}}
+	n, err := g.node(rv, nil)
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	enc := yaml3.NewEncoder(&out)
+	enc.SetIndent(2)
+	if err := enc.Encode(n); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// exampleGenerator builds the nodes of an example document.
+type exampleGenerator struct {
+	e *encoder
+	// seen holds the struct types being generated, so that a recursive type
+	// ends in null rather than going on forever.
+	seen map[reflect.Type]bool
+}
+
+// node returns the node of the example for v, found at path.
+func (g *exampleGenerator) node(v reflect.Value, path *keyPath) (*yaml3.Node, error) {
+	t := v.Type()
+	if t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) ||
+		reflect.PtrTo(t).Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType) {
+		return g.scalar(v, path)
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			if g.seen[t.Elem()] {
+				return &yaml3.Node{Kind: yaml3.ScalarNode, Tag: "!!null", Value: "null"}, nil
+			}
+			return g.node(reflect.Zero(t.Elem()), path)
+		}
+		return g.node(v.Elem(), path)
+	case reflect.Interface:
+		if v.IsNil() {
+			return &yaml3.Node{Kind: yaml3.ScalarNode, Tag: "!!null", Value: "null"}, nil
+		}
+		return g.node(v.Elem(), path)
+	case reflect.Struct:
+		return g.structNode(v, path)
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return g.scalar(v, path)
+		}
+		n := &yaml3.Node{Kind: yaml3.SequenceNode, Tag: "!!seq"}
+		items := v
+		if v.Len() == 0 {
+			items = reflect.New(reflect.ArrayOf(1, t.Elem())).Elem()
+		}
+		for i := 0; i < items.Len(); i++ {
+			c, err := g.node(items.Index(i), path.index(i))
+			if err != nil {
+				return nil, err
+			}
+			n.Content = append(n.Content, c)
+		}
+		return n, nil
+	case reflect.Map:
+		n := &yaml3.Node{Kind: yaml3.MappingNode, Tag: "!!map"}
+		if v.Len() == 0 {
+			k := reflect.Zero(t.Key())
+			if t.Key().Kind() == reflect.String {
+				k = reflect.ValueOf("key").Convert(t.Key())
+			}
+			return g.mapEntry(n, k, reflect.Zero(t.Elem()), path)
+		}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+		})
+		for _, k := range keys {
+			if _, err := g.mapEntry(n, k, v.MapIndex(k), path); err != nil {
+				return nil, err
+			}
+		}
+		return n, nil
+	}
+	return g.scalar(v, path)
+}
+
+// mapEntry adds the entry of the map key k and its value v to the mapping n.
+func (g *exampleGenerator) mapEntry(n *yaml3.Node, k, v reflect.Value, path *keyPath) (*yaml3.Node, error) {
+	kn, err := g.scalar(k, path)
+	if err != nil {
+		return nil, err
+	}
+	vn, err := g.node(v, path.key(kn.Value))
+	if err != nil {
+		return nil, err
+	}
+	n.Content = append(n.Content, kn, vn)
+	return n, nil
+}
+
+// structNode returns the mapping of the example for the struct v.
+func (g *exampleGenerator) structNode(v reflect.Value, path *keyPath) (*yaml3.Node, error) {
+	t := v.Type()
+	g.seen[t] = true
+	defer delete(g.seen, t)
+
+	n := &yaml3.Node{Kind: yaml3.MappingNode, Tag: "!!map"}
+	fields := cachedTypeFields(t)
+	for i := range fields {
+		f := &fields[i]
+		if skipField(f, g.e.tags) {
+			continue
+		}
+		name := fieldKey(f, g.e.tags, g.e.fieldNaming)
+		fv := fieldByIndex(v, f.index)
+		if !fv.IsValid() {
+			fv = reflect.Zero(t.FieldByIndex(f.index).Type)
+		}
+
+		var vn *yaml3.Node
+		var err error
+		if fv.IsZero() && f.hasDefault {
+			vn, err = exampleDefault(f.defaultTag)
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid default %q: %v", path.key(name), f.defaultTag, err)
+			}
+		} else if vn, err = g.node(fv, path.key(name)); err != nil {
+			return nil, err
+		}
+
+		kn := &yaml3.Node{Kind: yaml3.ScalarNode, Tag: "!!str", Value: name}
+		var comment []string
+		if d := t.FieldByIndex(f.index).Tag.Get("description"); d != "" {
+			comment = append(comment, d)
+		}
+		if f.required {
+			comment = append(comment, "Required.")
+		}
+		kn.HeadComment = strings.Join(comment, "\n")
+		n.Content = append(n.Content, kn, vn)
+	}
+	return n, nil
+}
+
+// scalar returns the node of v as Marshal writes it.
+func (g *exampleGenerator) scalar(v reflect.Value, path *keyPath) (*yaml3.Node, error) {
+	if !v.CanInterface() {
+		// The value of a field of an unexported embedded struct.
+		v = reflect.Zero(v.Type())
+	}
+	y, err := Marshal(v.Interface())
+	if err != nil {
+		return nil, path.wrap(err)
+	}
+	return exampleDefault(string(y))
+}
+
+// exampleDefault returns the node of the YAML document s, such as the value
+// of a default tag.
+func exampleDefault(s string) (*yaml3.Node, error) {
+	var doc yaml3.Node
+	if err := yaml3.Unmarshal([]byte(s), &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return &yaml3.Node{Kind: yaml3.ScalarNode, Tag: "!!null", Value: "null"}, nil
+	}
+	return doc.Content[0], nil
+}
@@ -0,0 +1,80 @@
+package yaml
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+type exampleServer struct {
+	Host    string            `json:"host" description:"Address to listen on." default:"0.0.0.0"`
+	Port    int               `json:"port,omitempty" description:"Port to listen on." required:"true"`
+	Timeout time.Duration     `json:"timeout"`
+	TLS     *exampleTLS       `json:"tls"`
+	Routes  []exampleRoute    `json:"routes" description:"Routes served,\nin order."`
+	Labels  map[string]string `json:"labels"`
+	Next    *exampleServer    `json:"next"`
+	Secret  string            `json:"-"`
+}
+
+type exampleTLS struct {
+	Cert string `json:"cert"`
+}
+
+type exampleRoute struct {
+	Path    string   `json:"path" default:"/"`
+	Methods []string `json:"methods" default:"[GET, HEAD]"`
+}
+
+func TestExample(t *testing.T) {
+	want := `# Address to listen on.
+host: 0.0.0.0
+# Port to listen on.
+# Required.
+port: 8080
+timeout: 0
+tls:
+  cert: ""
+# Routes served,
+# in order.
+routes:
+  - path: /
+    methods: [GET, HEAD]
+labels:
+  key: ""
+next: null
+`
+	got, err := Example(&exampleServer{Port: 8080})
+	if err != nil {
+		t.Fatalf("Example() = %v", err)
+	}
+	if string(got) != want {
+		t.Errorf("Example() =\n%s\nwant\n%s", got, want)
+	}
+
+	var s exampleServer
+	if err := Unmarshal(got, &s); err != nil {
+		t.Fatalf("Unmarshal() of the example = %v", err)
+	}
+	if s.Port != 8080 || len(s.Routes) != 1 || s.Routes[0].Methods[1] != "HEAD" {
+		t.Errorf("Unmarshal() of the example = %+v", s)
+	}
+
+	got, err = Example(exampleServer{Host: "localhost", Labels: map[string]string{"b": "2", "a": "1"}}, FieldNaming(SnakeCase))
+	if err != nil {
+		t.Fatalf("Example() = %v", err)
+	}
+	if !strings.Contains(string(got), "host: localhost\n") || !strings.Contains(string(got), "labels:\n  a: \"1\"\n  b: \"2\"\n") {
+		t.Errorf("Example() with values set =\n%s", got)
+	}
+
+	if _, err := Example(3); err == nil {
+		t.Error("Example() of an int returned no error")
+	}
+	type bad struct {
+		A int `json:"a" default:"["`
+	}
+	if _, err := Example(bad{}); err == nil || !strings.Contains(err.Error(), `a: invalid default "["`) {
+		t.Errorf("Example() with an invalid default = %v", err)
+	}
+}
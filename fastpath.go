@@ -0,0 +1,357 @@
+package yaml
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"sync"
+	"unicode/utf8"
+)
+
+// Small configuration documents are often flat mappings of scalars, such as
+//
+//	name: web
+//	port: 8080
+//	debug: false
+//
+// which Unmarshal decodes into structs of strings, numbers and booleans. The
+// conversion to JSON and back costs most of the time such documents take, so
+// Unmarshal without options decodes them directly into the fields of the
+// struct instead. Only documents and structs for which this gives the same
+// result as the conversion take this fast path; it leaves anything else,
+// including every document it would find an error in, to the conversion.
+
+// fastStruct is what the fast path knows of a struct type.
+type fastStruct struct {
+	// ok is set if values of the type may be decoded by the fast path.
+	ok     bool
+	fields []field
+	// kinds are the kinds of the fields the fast path sets, or
+	// reflect.Invalid for the fields it leaves to the conversion.
+	kinds []reflect.Kind
+}
+
+var numberType = reflect.TypeOf(json.Number(""))
+
+// fastStructs caches the fastStruct of each struct type.
+var fastStructs sync.Map
+
+func cachedFastStruct(t reflect.Type) *fastStruct {
+	if fs, ok := fastStructs.Load(t); ok {
+		return fs.(*fastStruct)
+	}
+	fs := &fastStruct{fields: cachedTypeFields(t)}
+	pt := reflect.PtrTo(t)
+	fs.ok = !pt.Implements(jsonUnmarshalerType) && !pt.Implements(textUnmarshalerType) &&
+		restField(t) == nil && !checksMissingKeys(t) && !typeHasBigNumbers(t) && !typeHasTextScalars(t)
+	fs.kinds = make([]reflect.Kind, len(fs.fields))
+	for i := range fs.fields {
+		f := &fs.fields[i]
+		if f.yamlInline {
+			fs.ok = false
+		}
+		// The type of a field of pointer type is the type it points to.
+		if len(f.index) == 1 && t.Field(f.index[0]).Type == f.typ && !f.quoted && fastKind(f.typ) {
+			fs.kinds[i] = f.typ.Kind()
+		}
+	}
+	fastStructs.Store(t, fs)
+	return fs
+}
+
+// fastKind reports whether the fast path sets fields of type t.
+func fastKind(t reflect.Type) bool {
+	pt := reflect.PtrTo(t)
+	if t == numberType || pt.Implements(jsonUnmarshalerType) || pt.Implements(textUnmarshalerType) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// fastEntry is a key of the document and the field it sets.
+type fastEntry struct {
+	field int
+	value []byte
+}
+
+// fastUnmarshal decodes y into o, a pointer to a struct, if the fast path
+// applies to them, and reports whether it did. It leaves o alone if it does
+// not.
+func fastUnmarshal(y []byte, o interface{}) bool {
+	v := reflect.ValueOf(o)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return false
+	}
+	v = v.Elem()
+	fs := cachedFastStruct(v.Type())
+	if !fs.ok {
+		return false
+	}
+
+	// The document is read in full before any field is set.
+	var buf [16]fastEntry
+	entries := buf[:0]
+	for len(y) > 0 {
+		var line []byte
+		if i := bytes.IndexByte(y, '\n'); i >= 0 {
+			line, y = y[:i], y[i+1:]
+		} else {
+			line, y = y, nil
+		}
+		if !fastText(line) {
+			return false
+		}
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		key, value, ok := fastLine(line)
+		if !ok {
+			return false
+		}
+		field := -1
+		for i := range fs.fields {
+			f := &fs.fields[i]
+			if f.name == string(key) {
+				field = i
+				break
+			}
+			// Keys that encoding/json would match without regard to case
+			// are left to the conversion, which finds their field as it does.
+			if bytes.EqualFold(f.nameBytes, key) {
+				return false
+			}
+		}
+		if field < 0 {
+			// A key that matches no field is dropped.
+			continue
+		}
+		if fs.kinds[field] == reflect.Invalid {
+			return false
+		}
+		for _, e := range entries {
+			if e.field == field {
+				return false
+			}
+		}
+		if !fastValid(fs.kinds[field], value) {
+			return false
+		}
+		entries = append(entries, fastEntry{field, value})
+	}
+	if len(entries) == 0 {
+		return false
+	}
+
+	// Values that do not fit their fields are the only errors left, which
+	// are checked for before any field is set too.
+	var values [16]fastValue
+	parsed := values[:0]
+	for _, e := range entries {
+		pv, ok := fastParse(fs.fields[e.field].typ, fs.kinds[e.field], e.value)
+		if !ok {
+			return false
+		}
+		parsed = append(parsed, pv)
+	}
+	for i, e := range entries {
+		if parsed[i].null {
+			// null leaves a field that is not a pointer as it is.
+			continue
+		}
+		fv := v.Field(fs.fields[e.field].index[0])
+		switch fs.kinds[e.field] {
+		case reflect.Bool:
+			fv.SetBool(parsed[i].b)
+		case reflect.String:
+			fv.SetString(string(e.value))
+		case reflect.Float32, reflect.Float64:
+			fv.SetFloat(parsed[i].f)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			fv.SetInt(parsed[i].i)
+		default:
+			fv.SetUint(parsed[i].u)
+		}
+	}
+	return true
+}
+
+// fastLine splits a line of a flat mapping into its key and value, and
+// reports whether the line is one the fast path reads: a plain key at the
+// start of the line, made of letters, digits, "_" and "-", followed by ":"
+// and an optional plain value on the same line.
+func fastLine(line []byte) (key, value []byte, ok bool) {
+	i := 0
+	for ; i < len(line); i++ {
+		c := line[i]
+		if c == ':' {
+			break
+		}
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c == '_' ||
+			i > 0 && ('0' <= c && c <= '9' || c == '-')) {
+			return nil, nil, false
+		}
+	}
+	// Keys start with a letter, so only the words of nonStringScalars
+	// resolve to something other than a string.
+	if i == 0 || i == len(line) || nonStringScalars[string(line[:i])] {
+		return nil, nil, false
+	}
+	key, value = line[:i], line[i+1:]
+	if len(value) > 0 && value[0] != ' ' {
+		return nil, nil, false
+	}
+	value = bytes.TrimLeft(value, " ")
+	value = bytes.TrimRight(value, " ")
+	if len(value) == 0 {
+		return key, value, true
+	}
+	switch value[0] {
+	case '-', '?', ':', ',', '[', ']', '{', '}', '#', '&', '*', '!', '|', '>', '\'', '"', '%', '@', '`':
+		// An indicator, except that "-" may start a number, which
+		// fastValid checks for.
+		if value[0] != '-' || len(value) == 1 || value[1] < '0' || value[1] > '9' {
+			return nil, nil, false
+		}
+	}
+	for j, c := range value {
+		if c < ' ' || c == 0x7f || c == ':' && (j == len(value)-1 || value[j+1] == ' ') || c == '#' && value[j-1] == ' ' {
+			return nil, nil, false
+		}
+	}
+	if !utf8.Valid(value) {
+		return nil, nil, false
+	}
+	return key, value, true
+}
+
+// fastText reports whether line, which may be a comment, holds nothing but
+// characters that go-yaml reads on a line of its own: tabs and the printable
+// characters of YAML, in valid UTF-8. It does not hold the line breaks other
+// than "\n" that go-yaml also ends lines at, even within comments: "\r",
+// U+0085, U+2028 and U+2029. A byte order mark is left to the conversion as
+// well.
+func fastText(line []byte) bool {
+	for i := 0; i < len(line); {
+		c := line[i]
+		if c < utf8.RuneSelf {
+			if c < ' ' && c != '\t' || c == 0x7f {
+				return false
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRune(line[i:])
+		switch {
+		case r == utf8.RuneError && size == 1,
+			r < 0xa0, r == 0x2028, r == 0x2029,
+			r >= 0xd800 && r < 0xe000, r == 0xfeff, r == 0xfffe, r == 0xffff:
+			return false
+		}
+		i += size
+	}
+	return true
+}
+
+// isNull reports whether the plain scalar s is null.
+func isNull(s []byte) bool {
+	switch string(s) {
+	case "", "~", "null", "Null", "NULL":
+		return true
+	}
+	return false
+}
+
+// fastValid reports whether the fast path decodes the plain scalar s into a
+// field of kind k as the conversion does: null into any field, strings that
+// go-yaml resolves as strings into string fields, "true" and "false" into
+// booleans, and decimal integers and, for floats, decimal fractions without
+// exponents, into numbers.
+func fastValid(k reflect.Kind, s []byte) bool {
+	if isNull(s) {
+		return true
+	}
+	switch k {
+	case reflect.String:
+		return resolvesToString(string(s)) && !base60Float.Match(s)
+	case reflect.Bool:
+		return string(s) == "true" || string(s) == "false"
+	}
+	digits := s
+	if k != reflect.Uint && k != reflect.Uint8 && k != reflect.Uint16 && k != reflect.Uint32 && k != reflect.Uint64 &&
+		len(digits) > 1 && digits[0] == '-' {
+		digits = digits[1:]
+	}
+	// Leading zeros make octal numbers in YAML 1.1.
+	n := 0
+	for n < len(digits) && '0' <= digits[n] && digits[n] <= '9' {
+		n++
+	}
+	if n == 0 || digits[0] == '0' && n > 1 {
+		return false
+	}
+	if n == len(digits) {
+		// go-yaml reads -0 as the integer 0, which is not the float -0.
+		return string(s) != "-0"
+	}
+	if k != reflect.Float32 && k != reflect.Float64 || digits[n] != '.' || n+1 == len(digits) {
+		return false
+	}
+	for _, c := range digits[n+1:] {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// fastValue is a scalar as parsed for a field.
+type fastValue struct {
+	null bool
+	b    bool
+	i    int64
+	u    uint64
+	f    float64
+}
+
+// fastParse parses the scalar s, which fastValid accepts, for a field of
+// type t and kind k, and reports whether it fits the field.
+func fastParse(t reflect.Type, k reflect.Kind, s []byte) (fastValue, bool) {
+	var v fastValue
+	if isNull(s) {
+		v.null = true
+		return v, true
+	}
+	var err error
+	switch k {
+	case reflect.Bool:
+		v.b = string(s) == "true"
+	case reflect.String:
+	case reflect.Float32, reflect.Float64:
+		if bytes.IndexByte(s, '.') < 0 {
+			// An integer, which encoding/json parses as written, unless
+			// go-yaml takes it for a float as it is too large.
+			if _, err = strconv.ParseInt(string(s), 10, 64); err == nil {
+				v.f, err = strconv.ParseFloat(string(s), t.Bits())
+			}
+			break
+		}
+		// go-yaml parses the float, and encoding/json parses the shortest
+		// text that holds the same float64.
+		if v.f, err = strconv.ParseFloat(string(s), 64); err == nil && k == reflect.Float32 {
+			var b [32]byte
+			v.f, err = strconv.ParseFloat(string(strconv.AppendFloat(b[:0], v.f, 'g', -1, 64)), 32)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.i, err = strconv.ParseInt(string(s), 10, t.Bits())
+	default:
+		v.u, err = strconv.ParseUint(string(s), 10, t.Bits())
+	}
+	return v, err == nil
+}
@@ -0,0 +1,201 @@
+package yaml
+
+import (
+	"encoding/json"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type flatConfig struct {
+	Name    string  `json:"name"`
+	Host    string  `json:"host"`
+	Port    int     `json:"port"`
+	Workers uint8   `json:"workers"`
+	Offset  int8    `json:"offset"`
+	Ratio   float64 `json:"ratio"`
+	Scale   float32 `json:"scale"`
+	Debug   bool    `json:"debug"`
+	Max     uint64  `json:"max"`
+	Timeout int64   `json:"timeout,string"`
+	Extra   *string `json:"extra"`
+	Labels  map[string]string
+}
+
+// conversion is an option that changes nothing, which keeps Unmarshal from
+// taking the fast path.
+func conversion(d *json.Decoder) *json.Decoder { return d }
+
+func TestFastUnmarshal(t *testing.T) {
+	for _, tc := range []struct {
+		y    string
+		fast bool
+	}{
+		{"name: web\nport: 8080\nratio: 0.5\nscale: 1.1\ndebug: true\n", true},
+		{"# Comment.\n\nname: web server  \nhost: \nworkers: 255\noffset: -128\nunknown: x\n", true},
+		{"name: ~\nport: null\nmax: 18446744073709551615\nratio: -3\nscale: 16777217\n", true},
+		{"name: a-b.c_d/e 日本\n", true},
+		{"ratio: 0.1\nscale: 0.1\n", true},
+		{"name: yes\n", false},
+		{"name: 'web'\n", false},
+		{"name: 1.5\n", false},
+		{"port: 0x10\n", false},
+		{"port: 010\n", false},
+		{"port: 1e3\n", false},
+		{"port: -0\n", false},
+		{"ratio: -0\n", false},
+		{"ratio: 1e3\n", false},
+		{"scale: 100000000000000000000\n", false},
+		{"workers: 256\n", false},
+		{"offset: -129\n", false},
+		{"port: x\n", false},
+		{"debug: on\n", false},
+		{"Name: web\n", false},
+		{"name: web\nname: api\n", false},
+		{"name: web # comment\n", false},
+		{"name: a: b\n", false},
+		{"name: web\r\n", false},
+		{"name:\tweb\n", false},
+		{"name: [web]\n", false},
+		{"name: &a web\n", false},
+		{"name: -web\n", false},
+		{"name: 12:30\n", false},
+		{"name: 2001-12-14\n", false},
+		{"timeout: 5\n", false},
+		{"extra: x\n", false},
+		{"Labels: x\n", false},
+		{"---\nname: web\n", false},
+		{"  name: web\n", false},
+		{"name:\n  web\n", false},
+		{"y: 1\n", false},
+		{"", false},
+		{"# Only a comment.\n", false},
+		// Characters that go-yaml does not allow, in values and comments.
+		{"name: \u0080x\n", false},
+		{"name: \ufffe\n", false},
+		{"name: a\x7fb\n", false},
+		{"name: \xff\n", false},
+		{"# \x01\nname: a\n", false},
+		{"# \x7f\nname: a\n", false},
+		{"# \xff\nname: a\n", false},
+		{"# \ufffe\nname: a\n", false},
+		{"name: a\ufeffb\n", false},
+		{"name: \u00a0x \U0001F600\n", true},
+		{"# é\tx\nname: a\n", true},
+	} {
+		var fast, slow, auto flatConfig
+		gotFast := fastUnmarshal([]byte(tc.y), &fast)
+		if gotFast != tc.fast {
+			t.Errorf("fastUnmarshal(%q) = %v, want %v", tc.y, gotFast, tc.fast)
+		}
+		// Unmarshal decodes the document as the conversion does, whichever
+		// path it takes.
+		slowErr := Unmarshal([]byte(tc.y), &slow, conversion)
+		autoErr := Unmarshal([]byte(tc.y), &auto)
+		if (autoErr == nil) != (slowErr == nil) || !reflect.DeepEqual(auto, slow) {
+			t.Errorf("Unmarshal(%q) = %+v, %v, want %+v, %v", tc.y, auto, autoErr, slow, slowErr)
+		}
+		if !gotFast {
+			continue
+		}
+		if slowErr != nil {
+			t.Errorf("Unmarshal(%q) = %v", tc.y, slowErr)
+			continue
+		}
+		if !reflect.DeepEqual(fast, slow) {
+			t.Errorf("fastUnmarshal(%q) = %+v, want %+v", tc.y, fast, slow)
+		}
+	}
+
+	// Fields the document has no key for are left as they are.
+	c := flatConfig{Host: "localhost", Port: 80}
+	if err := Unmarshal([]byte("port: 8080\nhost: ~\n"), &c); err != nil || c.Host != "localhost" || c.Port != 8080 {
+		t.Errorf("Unmarshal() = %+v, %v", c, err)
+	}
+
+	var m map[string]interface{}
+	if fastUnmarshal([]byte("a: 1\n"), &m) {
+		t.Errorf("fastUnmarshal() into a map = true")
+	}
+	var required struct {
+		Name string `json:"name" required:"true"`
+	}
+	if fastUnmarshal([]byte("name: web\n"), &required) {
+		t.Errorf("fastUnmarshal() into a struct with a required field = true")
+	}
+}
+
+// TestFastUnmarshalRandom checks that the fast path decodes documents made of
+// keys and values likely to tell it apart from the conversion as the
+// conversion does.
+func TestFastUnmarshalRandom(t *testing.T) {
+	keys := []string{"name", "host", "port", "workers", "offset", "ratio", "scale", "debug", "max", "NAME", "other", "y", "timeout", "extra"}
+	values := []string{"", "~", "null", "web", "true", "false", "yes", "0", "-0", "1", "-1", "007", "255", "256",
+		"-128", "-129", "1.5", "-2.25", "0.1", "3.4028235e38", "1e5", "99999999999999999999", "9007199254740993",
+		"18446744073709551615", "0x1f", ".5", "1.", "12:30", "-x", "a b", "é", "'q'",
+		"a\u0085b", "a\u2028b", "a\u2029b", "a\u2028 port", "a\u2028-1", "\u2029",
+		"\u0080x", "\ufffe", "a\x7f", "\xff", "\u00a0"}
+	// Comments end at any line break, after which the line goes on.
+	comments := []string{"# comment", "# a\u0085port: 1", "# a\u2028name: x", "# a\u2029debug: true", "# a\rport: 2",
+		"# \x01", "# \u0080", "# é"}
+	r := rand.New(rand.NewSource(1))
+	taken := 0
+	for i := 0; i < 30000; i++ {
+		var b strings.Builder
+		for n := r.Intn(4) + 1; n > 0; n-- {
+			if r.Intn(8) == 0 {
+				b.WriteString(comments[r.Intn(len(comments))] + "\n")
+			}
+			b.WriteString(keys[r.Intn(len(keys))] + ": " + values[r.Intn(len(values))] + "\n")
+		}
+		y := b.String()
+		var fast, slow flatConfig
+		if !fastUnmarshal([]byte(y), &fast) {
+			continue
+		}
+		taken++
+		if err := Unmarshal([]byte(y), &slow, conversion); err != nil {
+			t.Fatalf("fastUnmarshal(%q) = true, but Unmarshal() = %v", y, err)
+		}
+		if !reflect.DeepEqual(fast, slow) {
+			t.Fatalf("fastUnmarshal(%q) = %+v, want %+v", y, fast, slow)
+		}
+	}
+	if taken < 1000 {
+		t.Errorf("the fast path decoded %d of the documents, want at least 1000", taken)
+	}
+}
+
+const flatDocument = `name: web
+host: example.com
+port: 8080
+workers: 16
+offset: -3
+ratio: 0.75
+scale: 1.5
+debug: false
+max: 1000000
+`
+
+func BenchmarkUnmarshalFlat(b *testing.B) {
+	y := []byte(flatDocument)
+	b.Run("fast", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var c flatConfig
+			if err := Unmarshal(y, &c); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("conversion", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var c flatConfig
+			if err := Unmarshal(y, &c, conversion); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
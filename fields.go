@@ -74,7 +74,22 @@ type field struct {
 	index     []int
 	typ       reflect.Type
 	omitEmpty bool
+	omitZero  bool
 	quoted    bool
+	redact    bool
+	required  bool
+
+	// defaultTag is the value of the field's default tag, if hasDefault is
+	// set.
+	defaultTag string
+	hasDefault bool
+
+	// yamlName is the name given by the field's yaml tag, if any,
+	// yamlSkip is set if the yaml tag is "-" and yamlInline if it has the
+	// inline option.
+	yamlName   string
+	yamlSkip   bool
+	yamlInline bool
 }
 
 func fillField(f field) field {
@@ -182,13 +197,27 @@ func typeFields(t reflect.Type) []field {
 					if name == "" {
 						name = sf.Name
 					}
+					yamlTag := sf.Tag.Get("yaml")
+					yamlName, yamlOpts := parseTag(yamlTag)
+					if !isValidTag(yamlName) {
+						yamlName = ""
+					}
+					defaultTag, hasDefault := sf.Tag.Lookup("default")
 					fields = append(fields, fillField(field{
-						name:      name,
-						tag:       tagged,
-						index:     index,
-						typ:       ft,
-						omitEmpty: opts.Contains("omitempty"),
-						quoted:    opts.Contains("string"),
+						name:       name,
+						tag:        tagged,
+						index:      index,
+						typ:        ft,
+						omitEmpty:  opts.Contains("omitempty"),
+						omitZero:   opts.Contains("omitzero"),
+						quoted:     opts.Contains("string"),
+						redact:     opts.Contains("redact") || yamlOpts.Contains("redact"),
+						required:   yamlOpts.Contains("required") || sf.Tag.Get("required") == "true",
+						yamlName:   yamlName,
+						yamlSkip:   yamlTag == "-",
+						yamlInline: yamlOpts.Contains("inline"),
+						defaultTag: defaultTag,
+						hasDefault: hasDefault,
 					}))
 					if count[f.typ] > 1 {
 						// If there were multiple instances, add a second,
@@ -0,0 +1,34 @@
+package yaml
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+// UnmarshalFile reads the file at path and decodes it into o like Unmarshal.
+// Errors name the file, as in "config.yaml: error unmarshaling JSON: ...".
+func UnmarshalFile(path string, o interface{}, opts ...JSONOpt) error {
+	y, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return unmarshalFile(path, y, o, opts)
+}
+
+// UnmarshalFS is like UnmarshalFile but reads the file from fsys, such as an
+// embed.FS holding default configuration.
+func UnmarshalFS(fsys fs.FS, path string, o interface{}, opts ...JSONOpt) error {
+	y, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return err
+	}
+	return unmarshalFile(path, y, o, opts)
+}
+
+func unmarshalFile(path string, y []byte, o interface{}, opts []JSONOpt) error {
+	if err := Unmarshal(y, o, opts...); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
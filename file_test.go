@@ -0,0 +1,46 @@
+package yaml
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestUnmarshalFile(t *testing.T) {
+	type Config struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte("name: web\ncount: 2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var c Config
+	if err := UnmarshalFile(path, &c); err != nil || c != (Config{"web", 2}) {
+		t.Errorf("UnmarshalFile() = %+v, %v", c, err)
+	}
+	if err := UnmarshalFile(filepath.Join(dir, "missing.yaml"), &c); err == nil || !strings.Contains(err.Error(), "missing.yaml") {
+		t.Errorf("UnmarshalFile() of a missing file = %v", err)
+	}
+
+	fsys := fstest.MapFS{
+		"conf/good.yaml": {Data: []byte("name: api\ncount: 3\n")},
+		"conf/bad.yaml":  {Data: []byte("count: many\n")},
+	}
+	c = Config{}
+	if err := UnmarshalFS(fsys, "conf/good.yaml", &c); err != nil || c != (Config{"api", 3}) {
+		t.Errorf("UnmarshalFS() = %+v, %v", c, err)
+	}
+	err := UnmarshalFS(fsys, "conf/bad.yaml", &c)
+	if err == nil || !strings.HasPrefix(err.Error(), "conf/bad.yaml: error unmarshaling JSON: ") {
+		t.Errorf("UnmarshalFS() of a mismatched document = %v", err)
+	}
+	var strict *UnknownFieldsError
+	if err := UnmarshalFS(fsys, "conf/good.yaml", &struct{}{}, StrictFields()); !errors.As(err, &strict) {
+		t.Errorf("UnmarshalFS() with StrictFields = %v", err)
+	}
+}
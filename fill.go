@@ -0,0 +1,149 @@
+package yaml
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// Some parts of a YAML document cannot be decoded by encoding/json, so the
+// conversion to JSON leaves them out of the JSON document and keeps them in
+// special nodes of the JSON-compatible object instead: restObjects,
+// mapObjects, unionObjects, exactNumbers, yamlObjects and parsedObjects. Once
+// encoding/json is done, fillUndecoded decodes them into the Go value.
+
+// fillUndecoded walks v, which has been decoded from the JSON-compatible
+// object node, and decodes the parts of node that encoding/json left out with
+// decode. Those are the keys held by restObjects, into the rest fields of the
+// matching structs, the items of mapObjects, into the matching maps, the
+// values of unionObjects, into the matching interfaces, exactNumbers, into
+// the matching interface{} values, yamlObjects, with go-yaml, and
+// parsedObjects.
+func fillUndecoded(v reflect.Value, node interface{}, decode func([]byte, interface{}) error) error {
+	switch n := node.(type) {
+	case yamlObject:
+		return n.fill(v)
+	case parsedObject:
+		n.fill(v)
+		return nil
+	}
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.Kind() == reflect.Interface {
+			switch n := node.(type) {
+			case unionObject:
+				return n.fill(v, decode)
+			case exactNumber:
+				if v.NumMethod() == 0 && v.CanSet() {
+					v.Set(reflect.ValueOf(json.Number(n)))
+				}
+				return nil
+			}
+		}
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	switch n := node.(type) {
+	case restObject:
+		if v.Kind() != reflect.Struct || !v.CanAddr() {
+			return nil
+		}
+		if len(n.rest) > 0 {
+			b, err := json.Marshal(n.rest)
+			if err != nil {
+				return err
+			}
+			rv := v.FieldByIndex(restField(v.Type()))
+			if err := decode(b, rv.Addr().Interface()); err != nil {
+				return err
+			}
+		}
+		return fillUndecoded(v, n.fields, decode)
+	case mapObject:
+		if v.Kind() != reflect.Map || !v.CanSet() {
+			return nil
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		for k, x := range n {
+			key, err := parseMapKey(k, v.Type().Key())
+			if err != nil {
+				return err
+			}
+			b, err := json.Marshal(x)
+			if err != nil {
+				return err
+			}
+			ev := reflect.New(v.Type().Elem())
+			if err := decode(b, ev.Interface()); err != nil {
+				return err
+			}
+			if err := fillUndecoded(ev.Elem(), x, decode); err != nil {
+				return err
+			}
+			v.SetMapIndex(key, ev.Elem())
+		}
+	case map[string]interface{}:
+		switch v.Kind() {
+		case reflect.Struct:
+			fields := cachedTypeFields(v.Type())
+			for k, x := range n {
+				f := fieldByName(fields, k)
+				if f == nil {
+					continue
+				}
+				if fv := fieldByIndex(v, f.index); fv.IsValid() {
+					if err := fillUndecoded(fv, x, decode); err != nil {
+						return err
+					}
+				}
+			}
+		case reflect.Map:
+			for k, x := range n {
+				if !isContainer(x) {
+					continue
+				}
+				key, err := parseMapKey(k, v.Type().Key())
+				if err != nil {
+					continue
+				}
+				ev := v.MapIndex(key)
+				if !ev.IsValid() {
+					continue
+				}
+				// Map elements are not addressable, so fill a copy and store
+				// it back.
+				cp := reflect.New(ev.Type()).Elem()
+				cp.Set(ev)
+				if err := fillUndecoded(cp, x, decode); err != nil {
+					return err
+				}
+				v.SetMapIndex(key, cp)
+			}
+		}
+	case []interface{}:
+		if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+			for i, x := range n {
+				if i >= v.Len() {
+					break
+				}
+				if err := fillUndecoded(v.Index(i), x, decode); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// isContainer reports whether the JSON-compatible object node may hold parts
+// left for fillUndecoded.
+func isContainer(node interface{}) bool {
+	switch node.(type) {
+	case map[string]interface{}, []interface{}, restObject, mapObject, unionObject, exactNumber, yamlObject, parsedObject:
+		return true
+	}
+	return false
+}
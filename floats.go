@@ -0,0 +1,120 @@
+package yaml
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// FloatStyle selects how Marshal writes floats.
+type FloatStyle int
+
+const (
+	// FloatShortest writes floats in the fewest digits that read back as the
+	// same float, as encoding/json does, so that 1.0 is written as 1. This
+	// is the default.
+	FloatShortest FloatStyle = iota
+	// FloatDecimalPoint writes floats like FloatShortest, but always with a
+	// decimal point, so that 1.0 is written as 1.0 and reads back as a float
+	// rather than an integer.
+	FloatDecimalPoint
+)
+
+// FloatFormat sets how Marshal writes floats. Whether a number is a float is
+// known from the Go value being marshaled; numbers written by a MarshalJSON
+// method, or converted by JSONToYAML, are floats only if they have a
+// fraction or an exponent.
+func FloatFormat(style FloatStyle) MarshalOpt {
+	return func(e *encoder) {
+		e.floatStyle = style
+	}
+}
+
+// FloatPrecision makes Marshal write floats with the given number of digits
+// after the decimal point, rounding them, and never with an exponent, so that
+// 1.5 is written as 1.50 with a precision of 2. A negative precision writes
+// the fewest digits needed, which is the default. Floats are found as for
+// FloatFormat.
+func FloatPrecision(digits int) MarshalOpt {
+	return func(e *encoder) {
+		e.floatPrecision = digits
+	}
+}
+
+// NoExponents makes Marshal write floats in full decimal form, such as
+// 1000000 and 0.0000001, rather than with an exponent, as in 1e+06 and 1e-07,
+// which some readers and parsers do not understand. It also applies to the
+// float keys of maps, which are written as strings. Floats are found as for
+// FloatFormat.
+func NoExponents() MarshalOpt {
+	return func(e *encoder) {
+		e.noExponents = true
+	}
+}
+
+// formatsFloats reports whether e writes floats other than as encoding/json
+// does.
+func (e *encoder) formatsFloats() bool {
+	return e.floatStyle != FloatShortest || e.floatPrecision >= 0 || e.noExponents
+}
+
+// floatNode returns node, which json.Marshal wrote for the float v, as a
+// float64, since numbers without a fraction are decoded as integers.
+func floatNode(node interface{}, v reflect.Value) interface{} {
+	switch node.(type) {
+	case int, int64, uint64, float64:
+	default:
+		// A float written as a string, or a non-finite one.
+		return node
+	}
+	if v.Kind() == reflect.Float32 {
+		// The float64 that is written the way the float32 is.
+		f, _ := strconv.ParseFloat(strconv.FormatFloat(v.Float(), 'g', -1, 32), 64)
+		return f
+	}
+	return v.Float()
+}
+
+// formatFloat returns the text of f with the float options of e.
+func (e *encoder) formatFloat(f float64) string {
+	s := formatFloat(f)
+	if !e.formatsFloats() || math.IsInf(f, 0) || math.IsNaN(f) {
+		return s
+	}
+	switch {
+	case e.floatPrecision >= 0:
+		s = strconv.FormatFloat(f, 'f', e.floatPrecision, 64)
+	case e.noExponents:
+		s = strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	if e.floatStyle == FloatDecimalPoint && !strings.Contains(s, ".") {
+		if i := strings.IndexAny(s, "eE"); i >= 0 {
+			s = s[:i] + ".0" + s[i:]
+		} else {
+			s += ".0"
+		}
+	}
+	return s
+}
+
+// floatKeyEntry returns the entry of the map v, which has float keys, that
+// json.Marshal writes under key, along with the key to write it under.
+func (e *encoder) floatKeyEntry(v reflect.Value, key string) (mapEntry, bool) {
+	kt := v.Type().Key()
+	if kt.Kind() != reflect.Float32 && kt.Kind() != reflect.Float64 {
+		return mapEntry{}, false
+	}
+	f, err := strconv.ParseFloat(key, kt.Bits())
+	if err != nil {
+		return mapEntry{}, false
+	}
+	value := v.MapIndex(reflect.ValueOf(f).Convert(kt))
+	if !value.IsValid() {
+		return mapEntry{}, false
+	}
+	if e.noExponents {
+		key = strconv.FormatFloat(f, 'f', -1, kt.Bits())
+	}
+	return mapEntry{key, value}, true
+}
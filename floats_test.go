@@ -0,0 +1,89 @@
+package yaml
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestFloatFormat(t *testing.T) {
+	type point struct {
+		X     float64                `json:"x"`
+		Y     float32                `json:"v"`
+		Z     *float64               `json:"z"`
+		N     int                    `json:"count"`
+		S     float64                `json:"s,string"`
+		Extra map[string]interface{} `json:"extra"`
+	}
+	z := 1e21
+	p := point{X: 1, Y: 0.1, Z: &z, N: 3, S: 2, Extra: map[string]interface{}{"w": 2.0}}
+
+	tests := []struct {
+		name string
+		opts []MarshalOpt
+		want string
+	}{
+		{"default", nil, "count: 3\nextra:\n  w: 2\ns: \"2\"\nv: 0.1\nx: 1\nz: 1e+21\n"},
+		{"decimal point", []MarshalOpt{FloatFormat(FloatDecimalPoint)}, "count: 3\nextra:\n  w: 2.0\ns: \"2\"\nv: 0.1\nx: 1.0\nz: 1.0e+21\n"},
+		{"precision", []MarshalOpt{FloatPrecision(2)}, "count: 3\nextra:\n  w: 2.00\ns: \"2\"\nv: 0.10\nx: 1.00\nz: 1000000000000000000000.00\n"},
+		{"no digits", []MarshalOpt{FloatPrecision(0), FloatFormat(FloatDecimalPoint)}, "count: 3\nextra:\n  w: 2.0\ns: \"2\"\nv: 0.0\nx: 1.0\nz: 1000000000000000000000.0\n"},
+	}
+	for _, tt := range tests {
+		y, err := Marshal(p, tt.opts...)
+		if err != nil {
+			t.Errorf("%s: Marshal() = %v", tt.name, err)
+			continue
+		}
+		if string(y) != tt.want {
+			t.Errorf("%s: Marshal() = %q, want %q", tt.name, y, tt.want)
+		}
+	}
+
+	y, err := Marshal([]float64{3, math.Inf(1)}, FloatFormat(FloatDecimalPoint), NonFiniteFloats(NonFiniteFloat))
+	if err != nil || string(y) != "- 3.0\n- .inf\n" {
+		t.Errorf("Marshal() = %q, %v", y, err)
+	}
+
+	// go-yaml reads the floats back as floats.
+	var v interface{}
+	if y, err = Marshal(1.0, FloatFormat(FloatDecimalPoint)); err != nil {
+		t.Fatal(err)
+	}
+	if err := yaml.Unmarshal(y, &v); err != nil || v != 1.0 {
+		t.Errorf("yaml.Unmarshal(%q) = %#v, %v", y, v, err)
+	}
+}
+
+func TestNoExponents(t *testing.T) {
+	type sample struct {
+		Big    float64            `json:"big"`
+		Small  float32            `json:"small"`
+		Counts map[string]float64 `json:"counts"`
+	}
+	s := sample{Big: 1e21, Small: 1.5e-7, Counts: map[string]float64{"a": 3e6}}
+	want := "big: 1000000000000000000000\ncounts:\n  a: 3000000\nsmall: 0.00000015\n"
+	y, err := Marshal(s, NoExponents())
+	if err != nil || string(y) != want {
+		t.Errorf("Marshal() = %q, %v, want %q", y, err, want)
+	}
+	var back sample
+	if err := Unmarshal(y, &back); err != nil || back.Big != s.Big || back.Small != s.Small || back.Counts["a"] != 3e6 {
+		t.Errorf("Unmarshal() = %+v, %v", back, err)
+	}
+
+	y, err = Marshal(1e6, NoExponents(), FloatFormat(FloatDecimalPoint))
+	if err != nil || string(y) != "1000000.0\n" {
+		t.Errorf("Marshal() = %q, %v", y, err)
+	}
+
+	if _, err := json.Marshal(map[float64]int{}); err != nil {
+		// Older versions of encoding/json do not marshal float keys.
+		return
+	}
+	y, err = Marshal(map[float64]float64{2e-7: 3e6}, NoExponents())
+	if err != nil || string(y) != "\"0.0000002\": 3000000\n" {
+		t.Errorf("Marshal() = %q, %v", y, err)
+	}
+}
@@ -0,0 +1,278 @@
+package yaml
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+
+	"gopkg.in/yaml.v2"
+	yaml3 "gopkg.in/yaml.v3"
+)
+
+// FormatOpt is an option for Format.
+type FormatOpt func(*formatter)
+
+// formatter holds the settings of the FormatOpts.
+type formatter struct {
+	indent   int
+	quoting  Quoting
+	keyOrder KeyOrder
+	block    bool
+}
+
+// FormatIndent sets the number of spaces each level of nesting is indented
+// by, from 2 to 9. The default is 2.
+func FormatIndent(n int) FormatOpt {
+	return func(f *formatter) {
+		f.indent = n
+	}
+}
+
+// FormatQuoting sets the quotes of string values. With QuotePlain, the
+// default, quotes are removed wherever the string reads back the same without
+// them; QuoteSingle and QuoteDouble quote every string value. Keys are always
+// written without quotes where possible, and block scalars are kept as they
+// are.
+func FormatQuoting(q Quoting) FormatOpt {
+	return func(f *formatter) {
+		f.quoting = q
+	}
+}
+
+// FormatSortKeys sorts the keys of every mapping in the given order. By
+// default, keys are kept in the order of the document.
+func FormatSortKeys(order KeyOrder) FormatOpt {
+	return func(f *formatter) {
+		f.keyOrder = order
+	}
+}
+
+// FormatBlockStyle rewrites flow collections, such as [1, 2] and {a: 1}, in
+// block style. Empty collections stay in flow style, which is the only one
+// that can write them.
+func FormatBlockStyle() FormatOpt {
+	return func(f *formatter) {
+		f.block = true
+	}
+}
+
+// Format rewrites the YAML stream y with consistent indentation and quoting,
+// keeping its comments and the values it holds. Documents are separated by
+// "---". Format returns an error rather than output that go-yaml would read
+// differently from y.
+func Format(y []byte, opts ...FormatOpt) ([]byte, error) {
+	f := &formatter{indent: 2, keyOrder: KeyOrderDocument}
+	for _, opt := range opts {
+		opt(f)
+	}
+	if f.indent < 2 || f.indent > 9 {
+		return nil, errors.New("yaml: Format indentation must be from 2 to 9 spaces")
+	}
+	y, err := toUTF8(y)
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	enc := yaml3.NewEncoder(&out)
+	enc.SetIndent(f.indent)
+	d := yaml3.NewDecoder(bytes.NewReader(y))
+	n := 0
+	for ; ; n++ {
+		var doc yaml3.Node
+		if err := d.Decode(&doc); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		f.node(&doc, false)
+		untagMergeKeys(&doc)
+		if err := enc.Encode(&doc); err != nil {
+			return nil, err
+		}
+	}
+	if n == 0 {
+		// There are no documents, only whitespace and comments.
+		return y, nil
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	if err := sameDocuments(y, out.Bytes()); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// node formats n and the nodes it holds. key is set if n is a mapping key.
+func (f *formatter) node(n *yaml3.Node, key bool) {
+	switch n.Kind {
+	case yaml3.ScalarNode:
+		if n.Tag != "!!str" || n.Style&(yaml3.TaggedStyle|yaml3.LiteralStyle|yaml3.FoldedStyle) != 0 {
+			break
+		}
+		if n.Style == 0 && !resolvesToString(n.Value) {
+			// A plain scalar that go-yaml reads as something other than
+			// a string, such as the boolean y.
+			break
+		}
+		n.Style = 0
+		if !resolvesToString(n.Value) || base60Float.MatchString(n.Value) {
+			// go-yaml would read the string back as something else.
+			n.Style = yaml3.DoubleQuotedStyle
+		} else if !key {
+			switch f.quoting {
+			case QuoteSingle:
+				n.Style = yaml3.SingleQuotedStyle
+			case QuoteDouble:
+				n.Style = yaml3.DoubleQuotedStyle
+			}
+		}
+	case yaml3.DocumentNode:
+		if len(n.Content) == 1 && n.Content[0].Tag == "!!null" && n.Content[0].Value == "" {
+			// yaml.v3 writes empty documents as nothing at all.
+			n.Content[0].Value = "null"
+		}
+	case yaml3.MappingNode:
+		if f.keyOrder != KeyOrderDocument {
+			sortMappingNode(n, f.keyOrder)
+		}
+		fallthrough
+	case yaml3.SequenceNode:
+		if f.block && len(n.Content) > 0 {
+			n.Style &^= yaml3.FlowStyle
+		}
+	}
+	for i, c := range n.Content {
+		f.node(c, n.Kind == yaml3.MappingNode && i%2 == 0)
+	}
+}
+
+// untagMergeKeys clears the tag of the merge keys ("<<") in n, which yaml.v3
+// would otherwise write out as "!!merge <<".
+func untagMergeKeys(n *yaml3.Node) {
+	if n.Kind == yaml3.MappingNode {
+		for i := 0; i < len(n.Content); i += 2 {
+			if k := n.Content[i]; k.Tag == "!!merge" {
+				k.Tag = ""
+			}
+		}
+	}
+	for _, c := range n.Content {
+		untagMergeKeys(c)
+	}
+}
+
+// sortMappingNode sorts the keys of the mapping n in the given order.
+func sortMappingNode(n *yaml3.Node, order KeyOrder) {
+	pairs := make([][2]*yaml3.Node, len(n.Content)/2)
+	for i := range pairs {
+		pairs[i] = [2]*yaml3.Node{n.Content[2*i], n.Content[2*i+1]}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		a, b := pairs[i][0].Value, pairs[j][0].Value
+		if order == KeyOrderAlphabetical {
+			return a < b
+		}
+		return keyLess(a, b)
+	})
+	for i, p := range pairs {
+		n.Content[2*i], n.Content[2*i+1] = p[0], p[1]
+	}
+}
+
+// sameDocuments returns an error unless go-yaml reads the same values from the
+// YAML streams a and b.
+func sameDocuments(a, b []byte) error {
+	av, err := decodeDocuments(a)
+	if err != nil {
+		return err
+	}
+	bv, err := decodeDocuments(b)
+	if err != nil {
+		return err
+	}
+	if !sameValues(av, bv) {
+		return errors.New("yaml: formatting would change the values of the document")
+	}
+	return nil
+}
+
+// sameValues reports whether the values a and b decoded by go-yaml are equal,
+// taking NaN to be equal to NaN.
+func sameValues(a, b interface{}) bool {
+	switch a := a.(type) {
+	case map[interface{}]interface{}:
+		b, ok := b.(map[interface{}]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		var aNaN, bNaN []interface{}
+		for k, av := range a {
+			if f, ok := k.(float64); ok && math.IsNaN(f) {
+				aNaN = append(aNaN, av)
+				continue
+			}
+			bv, ok := b[k]
+			if !ok || !sameValues(av, bv) {
+				return false
+			}
+		}
+		for k, bv := range b {
+			if f, ok := k.(float64); ok && math.IsNaN(f) {
+				bNaN = append(bNaN, bv)
+			}
+		}
+		// A key that is NaN cannot be looked up, so the values of such keys
+		// are matched in any order.
+		if len(aNaN) != len(bNaN) {
+			return false
+		}
+		matched := make([]bool, len(bNaN))
+	values:
+		for _, av := range aNaN {
+			for i, bv := range bNaN {
+				if !matched[i] && sameValues(av, bv) {
+					matched[i] = true
+					continue values
+				}
+			}
+			return false
+		}
+		return true
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !sameValues(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	case float64:
+		b, ok := b.(float64)
+		return ok && (a == b || math.IsNaN(a) && math.IsNaN(b))
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// decodeDocuments decodes each document of the YAML stream y with go-yaml.
+func decodeDocuments(y []byte) ([]interface{}, error) {
+	var docs []interface{}
+	d := yaml.NewDecoder(bytes.NewReader(y))
+	for {
+		var v interface{}
+		if err := d.Decode(&v); err == io.EOF {
+			return docs, nil
+		} else if err != nil {
+			return nil, err
+		}
+		docs = append(docs, v)
+	}
+}
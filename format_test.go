@@ -0,0 +1,88 @@
+package yaml
+
+import "testing"
+
+func TestFormat(t *testing.T) {
+	in := `# Service settings.
+
+name:    'web'   # the name
+replicas:   3
+flags:
+    - "yes"
+    - 'plain'
+    - "0777"
+    - {a: 1, b: [x, y]}
+"quoted key": 1
+script: |
+    echo hi
+---
+b: 1
+a: 2
+`
+	for _, tc := range []struct {
+		name string
+		opts []FormatOpt
+		want string
+	}{
+		{"default", nil, `# Service settings.
+
+name: web # the name
+replicas: 3
+flags:
+  - "yes"
+  - plain
+  - "0777"
+  - {a: 1, b: [x, y]}
+quoted key: 1
+script: |
+  echo hi
+---
+b: 1
+a: 2
+`},
+		{"options", []FormatOpt{FormatIndent(4), FormatQuoting(QuoteDouble), FormatSortKeys(KeyOrderNatural), FormatBlockStyle()}, `# Service settings.
+
+flags:
+    - "yes"
+    - "plain"
+    - "0777"
+    - a: 1
+      b:
+        - "x"
+        - y
+name: "web" # the name
+quoted key: 1
+replicas: 3
+script: |
+    echo hi
+---
+a: 2
+b: 1
+`},
+	} {
+		got, err := Format([]byte(in), tc.opts...)
+		if err != nil {
+			t.Errorf("%s: Format() = %v", tc.name, err)
+			continue
+		}
+		if string(got) != tc.want {
+			t.Errorf("%s: Format() =\n%s\nwant\n%s", tc.name, got, tc.want)
+		}
+	}
+
+	if _, err := Format([]byte("a: [1\n")); err == nil {
+		t.Errorf("Format() = nil; want a syntax error")
+	}
+	if _, err := Format([]byte("a: 1\n"), FormatIndent(1)); err == nil {
+		t.Errorf("Format() = nil; want an error for an indentation of 1")
+	}
+	merge := "a: &x {b: 1}\nc:\n  <<: *x\n"
+	if got, err := Format([]byte(merge)); err != nil || string(got) != merge {
+		t.Errorf("Format(%#q) = %#q, %v", merge, string(got), err)
+	}
+	for _, y := range []string{"", "---\n", "~\n", "a: !!binary aGk=\n", "a: &x [1]\nb: *x\n", "a: .nan\n", ".nan: [.NaN, 1]\n.NAN: {b: .nan}\n"} {
+		if _, err := Format([]byte(y)); err != nil {
+			t.Errorf("Format(%#q) = %v", y, err)
+		}
+	}
+}
@@ -0,0 +1,331 @@
+// Package gen infers Go types from sample YAML documents, to start the
+// structs that a new configuration format is decoded into.
+package gen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/ghodss/yaml"
+)
+
+// Structs returns Go source declaring the type name and the struct types it
+// is made of, inferred from the sample documents. The samples are read as
+// yaml.Unmarshal reads them, and their types are unified: a key found in any
+// sample becomes a field, given the omitempty option unless every sample has
+// it, integers found along with other numbers become float64, scalars that
+// are null in some samples become pointers, and values whose types disagree
+// otherwise become interface{}. A mapping becomes a struct type named after
+// the type and field holding it, such as ServerTLS for the key tls of the
+// type Server. Fields are in the order their keys first occur and carry json
+// tags with the keys. The source holds no package clause.
+func Structs(name string, samples ...[]byte) ([]byte, error) {
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("gen: no samples")
+	}
+	var t *typ
+	for i, s := range samples {
+		j, sm, err := yaml.YAMLToJSONWithSourceMap(s)
+		if err != nil {
+			return nil, fmt.Errorf("gen: sample %d: %v", i, err)
+		}
+		d := json.NewDecoder(bytes.NewReader(j))
+		d.UseNumber()
+		var v interface{}
+		if err := d.Decode(&v); err != nil && err != io.EOF {
+			return nil, fmt.Errorf("gen: sample %d: %v", i, err)
+		}
+		t = unify(t, infer(v, "", sm))
+	}
+
+	g := &generator{names: map[string]bool{}}
+	g.declare(exportedName(name, "T"), t)
+	src, err := format.Source(append(bytes.TrimSpace(g.out.Bytes()), '\n'))
+	if err != nil {
+		return nil, fmt.Errorf("gen: %v", err)
+	}
+	return src, nil
+}
+
+type kind int
+
+const (
+	nullKind kind = iota
+	boolKind
+	intKind
+	floatKind
+	stringKind
+	sliceKind
+	structKind
+	anyKind
+)
+
+// typ is an inferred type.
+type typ struct {
+	kind kind
+	// nullable is set if the value is null in some samples.
+	nullable bool
+	// elem is the type of the items of a slice, or nil if every sample of
+	// it is empty.
+	elem *typ
+	// fields are the fields of a struct, in the order their keys first
+	// occur.
+	fields []*fieldType
+}
+
+type fieldType struct {
+	key string
+	typ *typ
+	// optional is set if some samples of the struct lack the key.
+	optional bool
+}
+
+// infer returns the type of the JSON value v, found at pointer in the
+// document of sm.
+func infer(v interface{}, pointer string, sm yaml.SourceMap) *typ {
+	switch v := v.(type) {
+	case nil:
+		return &typ{kind: nullKind, nullable: true}
+	case bool:
+		return &typ{kind: boolKind}
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return &typ{kind: intKind}
+		}
+		return &typ{kind: floatKind}
+	case string:
+		return &typ{kind: stringKind}
+	case []interface{}:
+		t := &typ{kind: sliceKind}
+		for i, item := range v {
+			t.elem = unify(t.elem, infer(item, pointer+"/"+strconv.Itoa(i), sm))
+		}
+		return t
+	case map[string]interface{}:
+		// Decoding loses the order of the keys, so it is taken from their
+		// positions.
+		t := &typ{kind: structKind}
+		pointers := map[string]string{}
+		for k := range v {
+			pointers[k] = pointer + "/" + pointerEscaper.Replace(k)
+			t.fields = append(t.fields, &fieldType{key: k, typ: infer(v[k], pointers[k], sm)})
+		}
+		sort.Slice(t.fields, func(i, j int) bool {
+			a, b := sm[pointers[t.fields[i].key]], sm[pointers[t.fields[j].key]]
+			if a != b {
+				return a.Line < b.Line || a.Line == b.Line && a.Column < b.Column
+			}
+			return t.fields[i].key < t.fields[j].key
+		})
+		return t
+	}
+	return &typ{kind: anyKind}
+}
+
+// unify returns the type that holds the values of both a and b. Either may
+// be nil, for no samples.
+func unify(a, b *typ) *typ {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	nullable := a.nullable || b.nullable
+	switch {
+	case a.kind == nullKind:
+		a, b = b, a
+		fallthrough
+	case b.kind == nullKind:
+		t := *a
+		t.nullable = nullable
+		return &t
+	case a.kind == b.kind:
+	case (a.kind == intKind || a.kind == floatKind) && (b.kind == intKind || b.kind == floatKind):
+		return &typ{kind: floatKind, nullable: nullable}
+	default:
+		return &typ{kind: anyKind, nullable: nullable}
+	}
+
+	t := &typ{kind: a.kind, nullable: nullable}
+	switch a.kind {
+	case sliceKind:
+		t.elem = unify(a.elem, b.elem)
+	case structKind:
+		inB := map[string]*fieldType{}
+		for _, f := range b.fields {
+			inB[f.key] = f
+		}
+		seen := map[string]bool{}
+		for _, f := range a.fields {
+			seen[f.key] = true
+			if g, ok := inB[f.key]; ok {
+				t.fields = append(t.fields, &fieldType{key: f.key, typ: unify(f.typ, g.typ), optional: f.optional || g.optional})
+			} else {
+				t.fields = append(t.fields, &fieldType{key: f.key, typ: f.typ, optional: true})
+			}
+		}
+		for _, f := range b.fields {
+			if !seen[f.key] {
+				t.fields = append(t.fields, &fieldType{key: f.key, typ: f.typ, optional: true})
+			}
+		}
+	}
+	return t
+}
+
+// generator writes the declarations of the inferred types.
+type generator struct {
+	out bytes.Buffer
+	// names holds the names of the types declared so far.
+	names map[string]bool
+}
+
+// declare writes the declaration of the type name, and of the struct types
+// it is made of, as t.
+func (g *generator) declare(name string, t *typ) {
+	g.names[name] = true
+	top := *t
+	top.nullable = false
+	var pending []func()
+	var expr string
+	if top.kind == structKind {
+		expr = g.structExpr(name, &top, &pending)
+	} else {
+		expr = g.typeExpr(name, &top, &pending)
+	}
+	fmt.Fprintf(&g.out, "type %s %s\n\n", name, expr)
+	for i := 0; i < len(pending); i++ {
+		pending[i]()
+	}
+}
+
+// typeExpr returns the Go type of t, found in the type name. Struct types
+// are named after name and declared after the current declaration, by the
+// functions added to pending.
+func (g *generator) typeExpr(name string, t *typ, pending *[]func()) string {
+	var s string
+	switch t.kind {
+	case nullKind, anyKind:
+		return "interface{}"
+	case boolKind:
+		s = "bool"
+	case intKind:
+		s = "int64"
+	case floatKind:
+		s = "float64"
+	case stringKind:
+		s = "string"
+	case sliceKind:
+		if t.elem == nil {
+			return "[]interface{}"
+		}
+		return "[]" + g.typeExpr(name, t.elem, pending)
+	case structKind:
+		s = g.uniqueName(name)
+		*pending = append(*pending, func() {
+			fmt.Fprintf(&g.out, "type %s %s\n\n", s, g.structExpr(s, t, pending))
+		})
+	}
+	if t.nullable {
+		return "*" + s
+	}
+	return s
+}
+
+// structExpr returns the struct type t, declared as name.
+func (g *generator) structExpr(name string, t *typ, pending *[]func()) string {
+	var b strings.Builder
+	b.WriteString("struct {\n")
+	used := map[string]bool{}
+	for _, f := range t.fields {
+		field := exportedName(f.key, "Field")
+		for i := 2; used[field]; i++ {
+			field = exportedName(f.key, "Field") + strconv.Itoa(i)
+		}
+		used[field] = true
+
+		tag := f.key
+		if f.optional {
+			tag += ",omitempty"
+		}
+		fmt.Fprintf(&b, "%s %s `json:%s`\n", field, g.typeExpr(name+field, f.typ, pending), strconv.Quote(tag))
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// uniqueName returns name, or name with a number added if a type of that
+// name is already declared.
+func (g *generator) uniqueName(name string) string {
+	n := name
+	for i := 2; g.names[n]; i++ {
+		n = name + strconv.Itoa(i)
+	}
+	g.names[n] = true
+	return n
+}
+
+// initialisms are the words written in upper case in Go names.
+var initialisms = map[string]bool{
+	"API": true, "CPU": true, "DNS": true, "HTTP": true, "HTTPS": true,
+	"ID": true, "IP": true, "JSON": true, "TCP": true, "TLS": true,
+	"TTL": true, "UDP": true, "UI": true, "URI": true, "URL": true,
+	"UUID": true, "YAML": true,
+}
+
+// exportedName returns the exported Go name made from the words of the key
+// s, such as APIVersion for "apiVersion" and MaxConns for "max_conns".
+// Names that would not start with a letter are given the prefix.
+func exportedName(s, prefix string) string {
+	var b strings.Builder
+	for _, w := range splitWords(s) {
+		if initialisms[strings.ToUpper(w)] {
+			b.WriteString(strings.ToUpper(w))
+			continue
+		}
+		r := []rune(w)
+		b.WriteString(string(unicode.ToUpper(r[0])) + string(r[1:]))
+	}
+	name := b.String()
+	if name == "" || !unicode.IsLetter([]rune(name)[0]) {
+		name = prefix + name
+	}
+	return name
+}
+
+// splitWords splits s into words at characters other than letters and
+// digits, and before upper case letters that follow lower case ones.
+func splitWords(s string) []string {
+	var words []string
+	var w []rune
+	prev := rune(0)
+	for _, r := range s {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			if len(w) > 0 {
+				words = append(words, string(w))
+				w = nil
+			}
+		case unicode.IsUpper(r) && (unicode.IsLower(prev) || unicode.IsDigit(prev)) && len(w) > 0:
+			words = append(words, string(w))
+			w = []rune{r}
+		default:
+			w = append(w, r)
+		}
+		prev = r
+	}
+	if len(w) > 0 {
+		words = append(words, string(w))
+	}
+	return words
+}
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
@@ -0,0 +1,84 @@
+package gen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStructs(t *testing.T) {
+	a := `apiVersion: v1
+name: web
+replicas: 3
+ratio: 1
+owner: ~
+tls:
+  cert: a.pem
+ports:
+- port: 80
+  protocol: TCP
+- port: 443
+tags: []
+enabled: yes
+`
+	b := `apiVersion: v1
+name: api
+ratio: 0.5
+owner: ops
+tls: ~
+ports:
+- port: 8080
+  host_ip: 10.0.0.1
+extra: [1, a]
+enabled: false
+`
+	want := "type Server struct {\n" +
+		"\tAPIVersion string        `json:\"apiVersion\"`\n" +
+		"\tName       string        `json:\"name\"`\n" +
+		"\tReplicas   int64         `json:\"replicas,omitempty\"`\n" +
+		"\tRatio      float64       `json:\"ratio\"`\n" +
+		"\tOwner      *string       `json:\"owner\"`\n" +
+		"\tTLS        *ServerTLS    `json:\"tls\"`\n" +
+		"\tPorts      []ServerPorts `json:\"ports\"`\n" +
+		"\tTags       []interface{} `json:\"tags,omitempty\"`\n" +
+		"\tEnabled    bool          `json:\"enabled\"`\n" +
+		"\tExtra      []interface{} `json:\"extra,omitempty\"`\n" +
+		"}\n\n" +
+		"type ServerTLS struct {\n" +
+		"\tCert string `json:\"cert\"`\n" +
+		"}\n\n" +
+		"type ServerPorts struct {\n" +
+		"\tPort     int64  `json:\"port\"`\n" +
+		"\tProtocol string `json:\"protocol,omitempty\"`\n" +
+		"\tHostIP   string `json:\"host_ip,omitempty\"`\n" +
+		"}\n"
+	got, err := Structs("server", []byte(a), []byte(b))
+	if err != nil {
+		t.Fatalf("Structs() = %v", err)
+	}
+	if string(got) != want {
+		t.Errorf("Structs() =\n%s\nwant\n%s", got, want)
+	}
+
+	for _, tc := range []struct {
+		name, sample, want string
+	}{
+		{"list", "- 1\n- 2\n", "type List []int64\n"},
+		{"x", "a: 1\nA: 2\n\"1\": 3\n", "type X struct {\n\tA      int64 `json:\"a\"`\n\tA2     int64 `json:\"A\"`\n\tField1 int64 `json:\"1\"`\n}\n"},
+		{"node", "node: {node: {v: 1}}\n", "type Node struct {\n\tNode NodeNode `json:\"node\"`\n}\n\ntype NodeNode struct {\n\tNode NodeNodeNode `json:\"node\"`\n}\n\ntype NodeNodeNode struct {\n\tV int64 `json:\"v\"`\n}\n"},
+	} {
+		got, err := Structs(tc.name, []byte(tc.sample))
+		if err != nil {
+			t.Fatalf("Structs(%q) = %v", tc.sample, err)
+		}
+		if strings.TrimSpace(string(got)) != strings.TrimSpace(tc.want) {
+			t.Errorf("Structs(%q) =\n%s\nwant\n%s", tc.sample, got, tc.want)
+		}
+	}
+
+	if _, err := Structs("x"); err == nil {
+		t.Error("Structs() with no samples returned no error")
+	}
+	if _, err := Structs("x", []byte("a: [")); err == nil || !strings.Contains(err.Error(), "sample 0") {
+		t.Errorf("Structs() of invalid YAML = %v", err)
+	}
+}
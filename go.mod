@@ -1,3 +1,9 @@
 module github.com/ghodss/yaml
 
-require gopkg.in/yaml.v2 v2.2.2
+go 1.24
+
+require (
+	google.golang.org/protobuf v1.36.11
+	gopkg.in/yaml.v2 v2.2.2
+	gopkg.in/yaml.v3 v3.0.1
+)
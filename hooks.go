@@ -0,0 +1,55 @@
+package yaml
+
+import (
+	"reflect"
+	"time"
+)
+
+// DecodeHook transforms a value of a YAML document before it is decoded into a
+// value of type to. from is the type of v as parsed from YAML, which is one of
+// string, bool, int, int64, uint64, float64, []interface{} and
+// map[interface{}]interface{}, or nil if v is null. to never is a pointer type.
+//
+// A hook returns v unchanged if it does not apply to it. Otherwise it returns
+// a value that encoding/json marshals into JSON that decodes into a value of
+// type to, which is usually simply a value of type to.
+type DecodeHook func(from, to reflect.Type, v interface{}) (interface{}, error)
+
+// DecodeHooks registers hooks that are applied in order to each value of the
+// YAML document that is decoded into a known Go type.
+func DecodeHooks(hooks ...DecodeHook) JSONOpt {
+	return decodeOpt(func(o *decodeOptions) {
+		o.hooks = append(o.hooks, hooks...)
+	})
+}
+
+// StringToDuration is a DecodeHook that parses strings such as "1m30s" into a
+// time.Duration with time.ParseDuration.
+func StringToDuration(from, to reflect.Type, v interface{}) (interface{}, error) {
+	s, ok := v.(string)
+	if !ok || to != reflect.TypeOf(time.Duration(0)) {
+		return v, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// applyHooks runs the hooks of o on v, found at path and decoded into a value
+// of type to. It reports whether the result is no longer a value parsed from
+// YAML and so needs no further conversion.
+func (o *decodeOptions) applyHooks(v interface{}, to reflect.Type, path *keyPath) (interface{}, bool, error) {
+	for to.Kind() == reflect.Ptr {
+		to = to.Elem()
+	}
+	for _, h := range o.hooks {
+		var err error
+		v, err = h(reflect.TypeOf(v), to, v)
+		if err != nil {
+			return nil, true, path.wrap(err)
+		}
+	}
+	switch v.(type) {
+	case nil, string, bool, int, int64, uint64, float64, []interface{}, map[interface{}]interface{}:
+		return v, false, nil
+	}
+	return v, true, nil
+}
@@ -0,0 +1,72 @@
+package yaml
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type HookPoint struct {
+	X, Y int
+}
+
+type HookTest struct {
+	Timeout  time.Duration   `json:"timeout"`
+	Retries  []time.Duration `json:"retries"`
+	Optional *time.Duration  `json:"optional"`
+	Point    HookPoint       `json:"point"`
+	Count    int             `json:"count"`
+}
+
+// pointFromString decodes strings such as "1,2" into a HookPoint.
+func pointFromString(from, to reflect.Type, v interface{}) (interface{}, error) {
+	s, ok := v.(string)
+	if !ok || to != reflect.TypeOf(HookPoint{}) {
+		return v, nil
+	}
+	var p HookPoint
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 {
+		return nil, errors.New("bad point")
+	}
+	var err error
+	if p.X, err = strconv.Atoi(parts[0]); err != nil {
+		return nil, err
+	}
+	if p.Y, err = strconv.Atoi(parts[1]); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func TestDecodeHooks(t *testing.T) {
+	y := "timeout: 1m30s\nretries: [1s, 2s]\noptional: 5ms\npoint: 3,4\ncount: 7\n"
+	d := 5 * time.Millisecond
+	want := HookTest{
+		Timeout:  90 * time.Second,
+		Retries:  []time.Duration{time.Second, 2 * time.Second},
+		Optional: &d,
+		Point:    HookPoint{3, 4},
+		Count:    7,
+	}
+
+	var got HookTest
+	if err := Unmarshal([]byte(y), &got, DecodeHooks(StringToDuration, pointFromString)); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal() = %+v; want %+v", got, want)
+	}
+
+	err := Unmarshal([]byte("retries: [1s, soon]\n"), &got, DecodeHooks(StringToDuration))
+	if err == nil || !strings.Contains(err.Error(), "retries[1]: time: invalid duration") {
+		t.Errorf("Unmarshal() = %v; want an invalid duration error at retries[1]", err)
+	}
+
+	if err := Unmarshal([]byte(y), &got); err == nil {
+		t.Errorf("Unmarshal() without hooks succeeded; want an error")
+	}
+}
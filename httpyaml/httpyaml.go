@@ -0,0 +1,156 @@
+// Package httpyaml reads YAML request bodies and writes YAML responses with
+// the yaml package, for HTTP handlers that accept YAML as well as JSON. It
+// is kept apart from the yaml package so that programs that do not serve
+// HTTP do not import net/http.
+package httpyaml
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ghodss/yaml"
+)
+
+// MediaType is the media type of YAML documents, registered by RFC 9512.
+const MediaType = "application/yaml"
+
+// MediaTypeError is returned by UnmarshalRequest for a request body that is
+// neither YAML nor JSON.
+type MediaTypeError struct {
+	MediaType string
+}
+
+func (e *MediaTypeError) Error() string {
+	return fmt.Sprintf("unsupported media type %q", e.MediaType)
+}
+
+// UnmarshalRequest reads the body of the request r and unmarshals it into o
+// as yaml.Unmarshal does. The body may be YAML or, since JSON is YAML, JSON,
+// as given by the Content-Type header of the request; a body without one is
+// read as YAML. For other media types it returns a *MediaTypeError, upon
+// which a handler would respond with http.StatusUnsupportedMediaType. At
+// most maxBytes of the body are read; for a longer body it returns an
+// *http.MaxBytesError, upon which a handler would respond with
+// http.StatusRequestEntityTooLarge.
+func UnmarshalRequest(r *http.Request, o interface{}, maxBytes int64, opts ...yaml.JSONOpt) error {
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		mt, _, err := mime.ParseMediaType(ct)
+		if err != nil || mediaFormat(mt) == "" {
+			return &MediaTypeError{MediaType: ct}
+		}
+	}
+	if r.Body == nil {
+		return yaml.Unmarshal(nil, o, opts...)
+	}
+	y, err := io.ReadAll(http.MaxBytesReader(nil, r.Body, maxBytes))
+	if err != nil {
+		return err
+	}
+	return yaml.Unmarshal(y, o, opts...)
+}
+
+// WriteYAML writes v, marshaled with opts, as the YAML body of a response
+// with the status code.
+func WriteYAML(w http.ResponseWriter, code int, v interface{}, opts ...yaml.MarshalOpt) error {
+	y, err := yaml.Marshal(v, opts...)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", MediaType)
+	w.WriteHeader(code)
+	_, err = w.Write(y)
+	return err
+}
+
+// WriteResponse writes v as the body of a response with the status code to
+// the request r, as YAML or JSON, whichever the Accept header of r prefers.
+// Both are written from the json tags of v; opts only apply to YAML. JSON is
+// written if r accepts neither or has no Accept header, and when it accepts
+// both equally, such as with "*/*".
+func WriteResponse(w http.ResponseWriter, r *http.Request, code int, v interface{}, opts ...yaml.MarshalOpt) error {
+	if acceptsYAML(r.Header.Values("Accept")) {
+		return WriteYAML(w, code, v, opts...)
+	}
+	j, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_, err = w.Write(append(j, '\n'))
+	return err
+}
+
+// mediaFormat returns "yaml" or "json" if the media type mt is one of
+// those formats, and "" otherwise.
+func mediaFormat(mt string) string {
+	mt = strings.ToLower(mt)
+	switch {
+	case mt == MediaType, mt == "application/x-yaml", mt == "text/yaml", mt == "text/x-yaml",
+		strings.HasPrefix(mt, "application/") && strings.HasSuffix(mt, "+yaml"):
+		return "yaml"
+	case mt == "application/json", mt == "text/json",
+		strings.HasPrefix(mt, "application/") && strings.HasSuffix(mt, "+json"):
+		return "json"
+	}
+	return ""
+}
+
+// acceptsYAML reports whether the Accept headers accept prefers YAML to
+// JSON.
+func acceptsYAML(accept []string) bool {
+	type mediaRange struct {
+		format string
+		q      float64
+		// exact is set for a media type, rather than a range like "*/*".
+		exact bool
+	}
+	var ranges []mediaRange
+	for _, h := range accept {
+		for _, part := range strings.Split(h, ",") {
+			mt, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+			if err != nil {
+				continue
+			}
+			q := 1.0
+			if s, ok := params["q"]; ok {
+				if q, err = strconv.ParseFloat(s, 64); err != nil {
+					continue
+				}
+			}
+			if f := mediaFormat(mt); f != "" {
+				ranges = append(ranges, mediaRange{f, q, true})
+			} else if mt == "*/*" || mt == "application/*" {
+				ranges = append(ranges, mediaRange{"", q, false})
+			}
+		}
+	}
+	// The most specific range that matches a format sets its quality.
+	quality := func(format string) float64 {
+		q, found := 0.0, false
+		for _, r := range ranges {
+			if r.exact && r.format == format {
+				if !found || r.q > q {
+					q = r.q
+				}
+				found = true
+			}
+		}
+		if found {
+			return q
+		}
+		for _, r := range ranges {
+			if !r.exact && r.q > q {
+				q = r.q
+			}
+		}
+		return q
+	}
+	yq, jq := quality("yaml"), quality("json")
+	return yq > 0 && yq > jq
+}
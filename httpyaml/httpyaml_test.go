@@ -0,0 +1,82 @@
+package httpyaml
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestUnmarshalRequest(t *testing.T) {
+	type pet struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+	for _, tc := range []struct {
+		contentType, body string
+	}{
+		{"application/yaml", "name: rex\nage: 3\n"},
+		{"application/x-yaml; charset=utf-8", "name: rex\nage: 3\n"},
+		{"application/json", `{"name": "rex", "age": 3}`},
+		{"application/merge-patch+json", `{"name": "rex", "age": 3}`},
+		{"", "{name: rex, age: 3}"},
+	} {
+		r := httptest.NewRequest("POST", "/pets", strings.NewReader(tc.body))
+		if tc.contentType != "" {
+			r.Header.Set("Content-Type", tc.contentType)
+		}
+		var p pet
+		if err := UnmarshalRequest(r, &p, 1<<20); err != nil || p != (pet{"rex", 3}) {
+			t.Errorf("UnmarshalRequest() of %s = %v, %+v", tc.contentType, err, p)
+		}
+	}
+
+	r := httptest.NewRequest("POST", "/pets", strings.NewReader("name=rex"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	var me *MediaTypeError
+	if err := UnmarshalRequest(r, &pet{}, 1<<20); !errors.As(err, &me) {
+		t.Errorf("UnmarshalRequest() of a form = %v; want a *MediaTypeError", err)
+	}
+	r = httptest.NewRequest("POST", "/pets", strings.NewReader("age: x"))
+	if err := UnmarshalRequest(r, &pet{}, 1<<20); err == nil {
+		t.Error("UnmarshalRequest() of a mistyped value returned no error")
+	}
+	r = httptest.NewRequest("POST", "/pets", strings.NewReader("name: rex\nage: 3\n"))
+	var mbe *http.MaxBytesError
+	if err := UnmarshalRequest(r, &pet{}, 10); !errors.As(err, &mbe) {
+		t.Errorf("UnmarshalRequest() of a body over the limit = %v; want an *http.MaxBytesError", err)
+	}
+}
+
+func TestWriteResponse(t *testing.T) {
+	v := map[string]interface{}{"name": "rex"}
+	for _, tc := range []struct {
+		accept, contentType, body string
+	}{
+		{"", "application/json", "{\"name\":\"rex\"}\n"},
+		{"*/*", "application/json", "{\"name\":\"rex\"}\n"},
+		{"application/yaml", MediaType, "name: rex\n"},
+		{"text/html, application/x-yaml;q=0.9, application/json;q=0.5", MediaType, "name: rex\n"},
+		{"application/yaml;q=0.5, application/json", "application/json", "{\"name\":\"rex\"}\n"},
+		{"application/yaml;q=0, */*", "application/json", "{\"name\":\"rex\"}\n"},
+		{"application/json;q=0, */*;q=0.1", MediaType, "name: rex\n"},
+	} {
+		r := httptest.NewRequest("GET", "/pets/1", nil)
+		if tc.accept != "" {
+			r.Header.Set("Accept", tc.accept)
+		}
+		w := httptest.NewRecorder()
+		if err := WriteResponse(w, r, http.StatusCreated, v); err != nil {
+			t.Fatalf("WriteResponse() = %v", err)
+		}
+		if w.Code != http.StatusCreated || w.Header().Get("Content-Type") != tc.contentType || w.Body.String() != tc.body {
+			t.Errorf("WriteResponse() for Accept %q = %d %s %q; want %s %q", tc.accept, w.Code, w.Header().Get("Content-Type"), w.Body.String(), tc.contentType, tc.body)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	if err := WriteYAML(w, http.StatusOK, func() {}); err == nil || w.Code != http.StatusOK || w.Body.Len() != 0 {
+		t.Errorf("WriteYAML() of a func = %v, wrote %q", err, w.Body.String())
+	}
+}
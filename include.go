@@ -0,0 +1,162 @@
+package yaml
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+
+	yaml3 "gopkg.in/yaml.v3"
+)
+
+// includeTag is the tag of the scalars that DecodeIncludes replaces with the
+// documents they name.
+const includeTag = "!include"
+
+// IncludeLoader loads the documents that "!include" tags refer to.
+type IncludeLoader interface {
+	// LoadInclude returns the document that the document at from includes as
+	// name, along with its path. from is "" for the document being decoded.
+	// The path identifies the document in cycles and errors, and is passed as
+	// from for the includes in the document.
+	LoadInclude(name, from string) (path string, data []byte, err error)
+}
+
+// IncludeLoaderFunc is an IncludeLoader that calls the function.
+type IncludeLoaderFunc func(name, from string) (string, []byte, error)
+
+func (f IncludeLoaderFunc) LoadInclude(name, from string) (string, []byte, error) {
+	return f(name, from)
+}
+
+// IncludeFS returns an IncludeLoader that reads included documents from
+// fsys. Names are slash-separated paths, relative to the directory of the
+// including document, or to the root of fsys for the document being decoded.
+func IncludeFS(fsys fs.FS) IncludeLoader {
+	return IncludeLoaderFunc(func(name, from string) (string, []byte, error) {
+		p := path.Clean(name)
+		if from != "" && !path.IsAbs(name) {
+			p = path.Join(path.Dir(from), name)
+		}
+		data, err := fs.ReadFile(fsys, p)
+		return p, data, err
+	})
+}
+
+// DecodeIncludes makes the conversion replace each scalar tagged "!include",
+// such as
+//
+//	database: !include db.yaml
+//
+// with the first document of the YAML stream that l loads for it, whose own
+// includes are replaced in turn. Includes may be nested at most maxDepth deep,
+// and a document that includes itself, directly or not, is an error. Without
+// this option, "!include" is decoded as the string it tags, as go-yaml
+// decodes other unknown tags.
+func DecodeIncludes(l IncludeLoader, maxDepth int) JSONOpt {
+	return decodeOpt(func(o *decodeOptions) {
+		o.includes = &includer{loader: l, maxDepth: maxDepth}
+	})
+}
+
+// includer replaces the includes of a document.
+type includer struct {
+	loader   IncludeLoader
+	maxDepth int
+	stack    []string // the paths of the documents being included
+}
+
+// include returns the document y with its includes replaced.
+func (in *includer) include(y []byte) ([]byte, error) {
+	if !strings.Contains(string(y), includeTag) {
+		return y, nil
+	}
+	var doc yaml3.Node
+	if err := yaml3.Unmarshal(y, &doc); err != nil {
+		return nil, err
+	}
+	found, err := in.includeNodes(&doc, "")
+	if err != nil || !found {
+		return y, err
+	}
+	renameAnchors(&doc, map[string]bool{})
+	return yaml3.Marshal(&doc)
+}
+
+// includeNodes replaces the includes in n, which is in the document at from,
+// and reports whether it found any.
+func (in *includer) includeNodes(n *yaml3.Node, from string) (bool, error) {
+	found := false
+	for i, c := range n.Content {
+		if n.Kind == yaml3.MappingNode && i%2 == 0 {
+			continue
+		}
+		f, err := in.includeNodes(c, from)
+		if err != nil {
+			return false, err
+		}
+		found = found || f
+	}
+	if n.Kind != yaml3.ScalarNode || n.Tag != includeTag {
+		return found, nil
+	}
+
+	if len(in.stack) >= in.maxDepth {
+		return false, fmt.Errorf("yaml: line %d: includes nested more than %d deep", n.Line, in.maxDepth)
+	}
+	p, data, err := in.loader.LoadInclude(n.Value, from)
+	if err != nil {
+		return false, fmt.Errorf("yaml: line %d: include %q: %v", n.Line, n.Value, err)
+	}
+	for i, s := range in.stack {
+		if s == p {
+			cycle := append(append([]string(nil), in.stack[i:]...), p)
+			return false, fmt.Errorf("yaml: include cycle: %s", strings.Join(cycle, " -> "))
+		}
+	}
+	data, err = toUTF8(data)
+	if err != nil {
+		return false, fmt.Errorf("yaml: %s: %v", p, err)
+	}
+	var doc yaml3.Node
+	if err := yaml3.Unmarshal(data, &doc); err != nil {
+		return false, fmt.Errorf("%s: %v", p, err)
+	}
+	in.stack = append(in.stack, p)
+	defer func() { in.stack = in.stack[:len(in.stack)-1] }()
+	if _, err := in.includeNodes(&doc, p); err != nil {
+		return false, err
+	}
+
+	root := &yaml3.Node{Kind: yaml3.ScalarNode, Tag: "!!null", Value: "null"}
+	if len(doc.Content) > 0 {
+		root = doc.Content[0]
+	}
+	anchor := n.Anchor
+	*n = *root
+	if anchor != "" {
+		n.Anchor = anchor
+	}
+	return true, nil
+}
+
+// renameAnchors gives the anchors of n and the nodes it holds names that are
+// not in used, in the order of the document, so that the anchors of included
+// documents do not clash, and points the aliases at the new names.
+func renameAnchors(n *yaml3.Node, used map[string]bool) {
+	if n.Anchor != "" {
+		name := n.Anchor
+		for i := 2; used[name]; i++ {
+			name = n.Anchor + strconv.Itoa(i)
+		}
+		n.Anchor = name
+		used[name] = true
+	}
+	for _, c := range n.Content {
+		renameAnchors(c, used)
+	}
+	if n.Kind == yaml3.AliasNode && n.Alias != nil {
+		n.Value = n.Alias.Anchor
+	}
+}
@@ -0,0 +1,68 @@
+package yaml
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestDecodeIncludes(t *testing.T) {
+	fsys := fstest.MapFS{
+		"db.yaml":               {Data: []byte("host: db\nport: 5432\nuser: &user admin\n")},
+		"conf/server.yaml":      {Data: []byte("tls: !include tls/default.yaml\nports: [80, 443]\n")},
+		"conf/tls/default.yaml": {Data: []byte("enabled: true\n")},
+		"loop/a.yaml":           {Data: []byte("b: !include b.yaml\n")},
+		"loop/b.yaml":           {Data: []byte("a: !include a.yaml\n")},
+		"empty.yaml":            {Data: []byte("# Nothing.\n")},
+	}
+	y := `user: &user root
+database: !include db.yaml
+server: !include conf/server.yaml
+empty: !include empty.yaml
+owner: *user
+`
+	j, err := YAMLToJSONWithOpts([]byte(y), DecodeIncludes(IncludeFS(fsys), 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"database":{"host":"db","port":5432,"user":"admin"},"empty":null,"owner":"root",` +
+		`"server":{"ports":[80,443],"tls":{"enabled":true}},"user":"root"}`
+	if string(j) != want {
+		t.Errorf("YAMLToJSONWithOpts() = %s; want %s", j, want)
+	}
+
+	j, err = YAMLToJSON([]byte(y))
+	if err != nil || !strings.Contains(string(j), `"database":"db.yaml"`) {
+		t.Errorf("YAMLToJSON() without DecodeIncludes = %s, %v", j, err)
+	}
+
+	for _, tc := range []struct {
+		y        string
+		maxDepth int
+		err      string
+	}{
+		{"a: !include loop/a.yaml\n", 10, "yaml: include cycle: loop/a.yaml -> loop/b.yaml -> loop/a.yaml"},
+		{"a: !include conf/server.yaml\n", 1, "yaml: line 1: includes nested more than 1 deep"},
+		{"a: 1\nb: !include missing.yaml\n", 10, `yaml: line 2: include "missing.yaml": open missing.yaml`},
+	} {
+		var v interface{}
+		err := Unmarshal([]byte(tc.y), &v, DecodeIncludes(IncludeFS(fsys), tc.maxDepth))
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("Unmarshal(%#q) = %v; want an error containing %q", tc.y, err, tc.err)
+		}
+	}
+
+	loader := IncludeLoaderFunc(func(name, from string) (string, []byte, error) {
+		if name != "secret" {
+			return "", nil, errors.New("not found")
+		}
+		return name, []byte("s3cr3t"), nil
+	})
+	var v struct {
+		Password string `json:"password"`
+	}
+	if err := Unmarshal([]byte("password: !include secret\n"), &v, DecodeIncludes(loader, 1)); err != nil || v.Password != "s3cr3t" {
+		t.Errorf("Unmarshal() with an IncludeLoaderFunc = %+v, %v", v, err)
+	}
+}
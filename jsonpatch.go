@@ -0,0 +1,338 @@
+package yaml
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ApplyJSONPatch applies the RFC 6902 JSON Patch patch to the YAML document
+// doc and returns the patched document as YAML. The patch may be written in
+// JSON or YAML. Like JSONToYAML, the result has its keys sorted and no
+// comments. If any operation fails, including a failed "test", an error is
+// returned and no part of the patch is applied.
+func ApplyJSONPatch(doc, patch []byte) ([]byte, error) {
+	obj, err := decodeDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+	p, err := YAMLToJSON(patch)
+	if err != nil {
+		return nil, err
+	}
+	var ops []map[string]json.RawMessage
+	if err := json.Unmarshal(p, &ops); err != nil {
+		return nil, fmt.Errorf("yaml: invalid JSON patch: %v", err)
+	}
+	for i, op := range ops {
+		if obj, err = applyPatchOp(obj, op); err != nil {
+			return nil, fmt.Errorf("yaml: JSON patch operation %d: %v", i, err)
+		}
+	}
+	return encodeDocument(obj)
+}
+
+// decodeDocument converts the YAML document y into the values encoding/json
+// decodes JSON into, keeping numbers as a json.Number.
+func decodeDocument(y []byte) (interface{}, error) {
+	j, err := YAMLToJSON(y)
+	if err != nil {
+		return nil, err
+	}
+	return decodeJSONNumbers(j)
+}
+
+func decodeJSONNumbers(j []byte) (interface{}, error) {
+	d := json.NewDecoder(bytes.NewReader(j))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// encodeDocument writes the value v, as returned by decodeDocument, as YAML.
+func encodeDocument(v interface{}) ([]byte, error) {
+	j, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return JSONToYAML(j)
+}
+
+// applyPatchOp applies the JSON Patch operation op to doc and returns the
+// new document.
+func applyPatchOp(doc interface{}, op map[string]json.RawMessage) (interface{}, error) {
+	var name string
+	if err := json.Unmarshal(op["op"], &name); err != nil {
+		return nil, errors.New(`missing or invalid "op"`)
+	}
+	path, err := patchPointer(op, "path")
+	if err != nil {
+		return nil, err
+	}
+	value := func() (interface{}, error) {
+		raw, ok := op["value"]
+		if !ok {
+			return nil, errors.New(`missing "value"`)
+		}
+		return decodeJSONNumbers(raw)
+	}
+
+	switch name {
+	case "add", "replace", "test":
+		v, err := value()
+		if err != nil {
+			return nil, err
+		}
+		switch name {
+		case "add":
+			return addValue(doc, path, v)
+		case "replace":
+			if _, err := getValue(doc, path); err != nil {
+				return nil, err
+			}
+			if doc, err = removeValue(doc, path); err != nil {
+				return nil, err
+			}
+			return addValue(doc, path, v)
+		}
+		cur, err := getValue(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !equalValues(cur, v) {
+			return nil, fmt.Errorf("test failed: %s is not the given value", pointerString(path))
+		}
+		return doc, nil
+	case "remove":
+		if len(path) == 0 {
+			return nil, errors.New("cannot remove the whole document")
+		}
+		return removeValue(doc, path)
+	case "move", "copy":
+		from, err := patchPointer(op, "from")
+		if err != nil {
+			return nil, err
+		}
+		v, err := getValue(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		if name == "copy" {
+			return addValue(doc, path, copyValue(v))
+		}
+		if isPrefix(from, path) && len(from) < len(path) {
+			return nil, errors.New("cannot move a value into itself")
+		}
+		if len(from) > 0 {
+			if doc, err = removeValue(doc, from); err != nil {
+				return nil, err
+			}
+		}
+		return addValue(doc, path, v)
+	}
+	return nil, fmt.Errorf("unknown op %q", name)
+}
+
+// patchPointer parses the JSON Pointer in the member of op with the given
+// name.
+func patchPointer(op map[string]json.RawMessage, name string) ([]string, error) {
+	var s string
+	if err := json.Unmarshal(op[name], &s); err != nil {
+		return nil, fmt.Errorf("missing or invalid %q", name)
+	}
+	return parsePointer(s)
+}
+
+var (
+	pointerEscaper   = strings.NewReplacer("~", "~0", "/", "~1")
+	pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+)
+
+// parsePointer splits the RFC 6901 JSON Pointer s into its unescaped tokens.
+func parsePointer(s string) ([]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+	if s[0] != '/' {
+		return nil, fmt.Errorf("invalid JSON pointer %q", s)
+	}
+	tokens := strings.Split(s[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = pointerUnescaper.Replace(t)
+	}
+	return tokens, nil
+}
+
+func pointerString(tokens []string) string {
+	var b strings.Builder
+	for _, t := range tokens {
+		b.WriteString("/" + pointerEscaper.Replace(t))
+	}
+	return b.String()
+}
+
+func isPrefix(prefix, path []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// arrayIndex parses the token t as an index into an array of length n. If
+// end is set, "-" and n refer to the end of the array.
+func arrayIndex(t string, n int, end bool) (int, error) {
+	if end && t == "-" {
+		return n, nil
+	}
+	i, err := strconv.Atoi(t)
+	if err != nil || i < 0 || t != strconv.Itoa(i) {
+		return 0, fmt.Errorf("invalid array index %q", t)
+	}
+	if i > n || i == n && !end {
+		return 0, fmt.Errorf("array index %d out of range", i)
+	}
+	return i, nil
+}
+
+// getValue returns the value at path in doc.
+func getValue(doc interface{}, path []string) (interface{}, error) {
+	for i, t := range path {
+		switch c := doc.(type) {
+		case map[string]interface{}:
+			v, ok := c[t]
+			if !ok {
+				return nil, fmt.Errorf("%s does not exist", pointerString(path[:i+1]))
+			}
+			doc = v
+		case []interface{}:
+			j, err := arrayIndex(t, len(c), false)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", pointerString(path[:i+1]), err)
+			}
+			doc = c[j]
+		default:
+			return nil, fmt.Errorf("%s is not an object or array", pointerString(path[:i]))
+		}
+	}
+	return doc, nil
+}
+
+// updateParent calls f with the container that holds the value at path in
+// doc and the last token of path, and replaces the container with the one f
+// returns.
+func updateParent(doc interface{}, path []string, f func(parent interface{}, t string) (interface{}, error)) (interface{}, error) {
+	if len(path) == 1 {
+		return f(doc, path[0])
+	}
+	switch c := doc.(type) {
+	case map[string]interface{}:
+		v, ok := c[path[0]]
+		if !ok {
+			return nil, errors.New("path does not exist")
+		}
+		v, err := updateParent(v, path[1:], f)
+		if err != nil {
+			return nil, err
+		}
+		c[path[0]] = v
+		return c, nil
+	case []interface{}:
+		i, err := arrayIndex(path[0], len(c), false)
+		if err != nil {
+			return nil, err
+		}
+		if c[i], err = updateParent(c[i], path[1:], f); err != nil {
+			return nil, err
+		}
+		return c, nil
+	}
+	return nil, errors.New("value is not an object or array")
+}
+
+// addValue adds v at path in doc, as the "add" operation does.
+func addValue(doc interface{}, path []string, v interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return v, nil
+	}
+	doc, err := updateParent(doc, path, func(parent interface{}, t string) (interface{}, error) {
+		switch c := parent.(type) {
+		case map[string]interface{}:
+			c[t] = v
+			return c, nil
+		case []interface{}:
+			i, err := arrayIndex(t, len(c), true)
+			if err != nil {
+				return nil, err
+			}
+			c = append(c, nil)
+			copy(c[i+1:], c[i:])
+			c[i] = v
+			return c, nil
+		}
+		return nil, errors.New("value is not an object or array")
+	})
+	return doc, pathError(path, err)
+}
+
+// removeValue removes the value at path from doc.
+func removeValue(doc interface{}, path []string) (interface{}, error) {
+	if len(path) == 0 {
+		return nil, nil
+	}
+	doc, err := updateParent(doc, path, func(parent interface{}, t string) (interface{}, error) {
+		switch c := parent.(type) {
+		case map[string]interface{}:
+			if _, ok := c[t]; !ok {
+				return nil, errors.New("value does not exist")
+			}
+			delete(c, t)
+			return c, nil
+		case []interface{}:
+			i, err := arrayIndex(t, len(c), false)
+			if err != nil {
+				return nil, err
+			}
+			return append(c[:i], c[i+1:]...), nil
+		}
+		return nil, errors.New("value is not an object or array")
+	})
+	return doc, pathError(path, err)
+}
+
+func pathError(path []string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("%s: %v", pointerString(path), err)
+}
+
+// copyValue returns a deep copy of the value v, as returned by
+// decodeDocument.
+func copyValue(v interface{}) interface{} {
+	switch c := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(c))
+		for k, e := range c {
+			m[k] = copyValue(e)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(c))
+		for i, e := range c {
+			s[i] = copyValue(e)
+		}
+		return s
+	}
+	return v
+}
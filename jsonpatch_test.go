@@ -0,0 +1,89 @@
+package yaml
+
+import "testing"
+
+func TestApplyJSONPatch(t *testing.T) {
+	doc := `# Deployment.
+metadata:
+  name: web
+  labels: {app: web}
+spec:
+  replicas: 3
+  containers:
+  - name: web
+    image: nginx
+  big: 12345678901234567890
+`
+	for _, tc := range []struct {
+		name, patch, want string
+	}{
+		{"json", `[
+			{"op": "test", "path": "/spec/replicas", "value": 3},
+			{"op": "replace", "path": "/spec/replicas", "value": 5},
+			{"op": "add", "path": "/metadata/labels/a~1b", "value": "x"},
+			{"op": "remove", "path": "/metadata/name"}
+		]`, `metadata:
+  labels:
+    a/b: x
+    app: web
+spec:
+  big: 12345678901234567890
+  containers:
+  - image: nginx
+    name: web
+  replicas: 5
+`},
+		{"yaml", `- op: add
+  path: /spec/containers/-
+  value: {name: sidecar}
+- op: copy
+  from: /spec/containers/0
+  path: /spec/containers/0
+- op: move
+  from: /metadata
+  path: /meta
+- op: replace
+  path: /spec/containers/1/name
+  value: copy
+`, `meta:
+  labels:
+    app: web
+  name: web
+spec:
+  big: 12345678901234567890
+  containers:
+  - image: nginx
+    name: web
+  - image: nginx
+    name: copy
+  - name: sidecar
+  replicas: 3
+`},
+		{"whole document", `[{"op": "replace", "path": "", "value": [1]}]`, "- 1\n"},
+	} {
+		got, err := ApplyJSONPatch([]byte(doc), []byte(tc.patch))
+		if err != nil {
+			t.Fatalf("%s: ApplyJSONPatch() = %v", tc.name, err)
+		}
+		if string(got) != tc.want {
+			t.Errorf("%s: ApplyJSONPatch() = %#q; want %#q", tc.name, string(got), tc.want)
+		}
+	}
+
+	for _, tc := range []struct {
+		patch, err string
+	}{
+		{`[{"op": "test", "path": "/spec/replicas", "value": "3"}]`, "yaml: JSON patch operation 0: test failed: /spec/replicas is not the given value"},
+		{`[{"op": "add", "path": "/spec/x", "value": 1}, {"op": "remove", "path": "/spec/containers/1"}]`, "yaml: JSON patch operation 1: /spec/containers/1: array index 1 out of range"},
+		{`[{"op": "replace", "path": "/missing/x", "value": 1}]`, "yaml: JSON patch operation 0: /missing does not exist"},
+		{`[{"op": "move", "from": "/spec", "path": "/spec/x"}]`, "yaml: JSON patch operation 0: cannot move a value into itself"},
+		{`[{"op": "add", "path": "/spec"}]`, `yaml: JSON patch operation 0: missing "value"`},
+		{`[{"op": "merge", "path": "/spec"}]`, `yaml: JSON patch operation 0: unknown op "merge"`},
+		{`[{"op": "add", "path": "spec", "value": 1}]`, `yaml: JSON patch operation 0: invalid JSON pointer "spec"`},
+	} {
+		_, err := ApplyJSONPatch([]byte(doc), []byte(tc.patch))
+		if err == nil || err.Error() != tc.err {
+			t.Errorf("ApplyJSONPatch(%s) = %v; want %s", tc.patch, err, tc.err)
+		}
+	}
+}
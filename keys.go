@@ -0,0 +1,113 @@
+package yaml
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// JSON objects only have string keys, so the keys of a YAML mapping are
+// converted to strings and encoding/json parses them back into the key type
+// of the map they are decoded into. It does so for string, integer and
+// encoding.TextUnmarshaler keys, preferring the latter. Keys of other types,
+// such as bools and floats, are decoded by this package once encoding/json is
+// done.
+
+// parseMapKey parses key, a key of a YAML mapping converted to a string, into
+// a value of the map key type t. It returns an error if the key does not fit
+// the type.
+func parseMapKey(key string, t reflect.Type) (reflect.Value, error) {
+	v := reflect.New(t).Elem()
+	var err error
+	if tu, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		err = tu.UnmarshalText([]byte(key))
+	} else {
+		err = parseMapKeyKind(key, v)
+	}
+	if err != nil {
+		if ne, ok := err.(*strconv.NumError); ok {
+			err = ne.Err
+		}
+		return reflect.Value{}, fmt.Errorf("cannot decode key %q into a map key of type %v: %v", key, t, err)
+	}
+	return v, nil
+}
+
+// parseMapKeyKind parses key into v according to the kind of v.
+func parseMapKeyKind(key string, v reflect.Value) error {
+	var err error
+	switch t := v.Type(); t.Kind() {
+	case reflect.String:
+		v.SetString(key)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		if n, err = strconv.ParseInt(key, 10, t.Bits()); err == nil {
+			v.SetInt(n)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var n uint64
+		if n, err = strconv.ParseUint(key, 10, t.Bits()); err == nil {
+			v.SetUint(n)
+		}
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if f, err = parseYAMLFloat(key, t.Bits()); err == nil {
+			v.SetFloat(f)
+		}
+	case reflect.Bool:
+		switch key {
+		case "true":
+			v.SetBool(true)
+		case "false":
+		default:
+			err = fmt.Errorf("not a bool")
+		}
+	default:
+		err = fmt.Errorf("unsupported map key type")
+	}
+	return err
+}
+
+// parseYAMLFloat is like strconv.ParseFloat but also accepts the forms
+// go-yaml writes infinities and NaN in.
+func parseYAMLFloat(s string, bits int) (float64, error) {
+	switch s {
+	case ".inf":
+		s = "+Inf"
+	case "-.inf":
+		s = "-Inf"
+	case ".nan":
+		s = "NaN"
+	}
+	return strconv.ParseFloat(s, bits)
+}
+
+// checkMapKey returns an error if key cannot be decoded into a map key of
+// type t.
+func checkMapKey(key string, t reflect.Type) error {
+	_, err := parseMapKey(key, t)
+	return err
+}
+
+// jsonKeyUnsupported reports whether encoding/json cannot decode object keys
+// into map keys of type t.
+func jsonKeyUnsupported(t reflect.Type) bool {
+	if reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// mapObject is the JSON-compatible form of a YAML mapping that is decoded into
+// a map whose keys encoding/json does not support. It is written to the JSON
+// document as null, and fillUndecoded decodes its items into the map.
+type mapObject map[string]interface{}
+
+func (mapObject) MarshalJSON() ([]byte, error) {
+	return []byte("null"), nil
+}
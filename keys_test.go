@@ -0,0 +1,120 @@
+package yaml
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestNonStringMapKeys(t *testing.T) {
+	var ints map[int]string
+	if err := Unmarshal([]byte("1: a\n0x10: b\n-3: c\n"), &ints); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	if want := map[int]string{1: "a", 16: "b", -3: "c"}; !reflect.DeepEqual(ints, want) {
+		t.Errorf("Unmarshal() = %v; want %v", ints, want)
+	}
+
+	var bools map[bool]int
+	if err := Unmarshal([]byte("true: 1\nfalse: 0\n"), &bools); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	if want := map[bool]int{true: 1, false: 0}; !reflect.DeepEqual(bools, want) {
+		t.Errorf("Unmarshal() = %v; want %v", bools, want)
+	}
+
+	type FloatKeys struct {
+		Weights map[float64]RestInner `json:"weights"`
+	}
+	var floats FloatKeys
+	if err := Unmarshal([]byte("weights:\n  0.5: {known: a, other: b}\n  .inf: {known: c}\n"), &floats); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	want := map[float64]RestInner{
+		0.5:         {"a", map[string]interface{}{"other": "b"}},
+		math.Inf(1): {Known: "c"},
+	}
+	if !reflect.DeepEqual(floats.Weights, want) {
+		t.Errorf("Unmarshal() = %v; want %v", floats.Weights, want)
+	}
+
+	for _, tc := range []struct {
+		y   string
+		v   interface{}
+		err string
+	}{
+		{"x: a", &ints, `cannot decode key "x" into a map key of type int: invalid syntax`},
+		{"300: a", new(map[uint8]string), `cannot decode key "300" into a map key of type uint8: value out of range`},
+		{"m:\n  yes: 1\n  maybe: 2", new(map[string]map[bool]int), `m: cannot decode key "maybe" into a map key of type bool`},
+	} {
+		err := Unmarshal([]byte(tc.y), tc.v)
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("Unmarshal(%q) = %v; want error containing %q", tc.y, err, tc.err)
+		}
+	}
+}
+
+type keyColor string
+
+func (c keyColor) MarshalText() ([]byte, error) {
+	return []byte(strings.ToUpper(string(c))), nil
+}
+
+func (c *keyColor) UnmarshalText(b []byte) error {
+	*c = keyColor(strings.ToLower(string(b)))
+	return nil
+}
+
+type keyID struct {
+	N int
+}
+
+func (id keyID) MarshalText() ([]byte, error) {
+	return []byte("id-" + strconv.Itoa(id.N)), nil
+}
+
+func (id *keyID) UnmarshalText(b []byte) error {
+	s := string(b)
+	if !strings.HasPrefix(s, "id-") {
+		return fmt.Errorf("missing id- prefix")
+	}
+	var err error
+	id.N, err = strconv.Atoi(s[3:])
+	return err
+}
+
+func TestTextMarshalerMapKeys(t *testing.T) {
+	type TextKeys struct {
+		Colors map[keyColor]RestInner `json:"colors"`
+		IDs    map[keyID]string       `json:"ids"`
+	}
+	v := TextKeys{
+		Colors: map[keyColor]RestInner{"red": {"r", map[string]interface{}{"x": "y"}}},
+		IDs:    map[keyID]string{{1}: "one", {20}: "twenty"},
+	}
+	want := "colors:\n  RED:\n    known: r\n    x: \"y\"\nids:\n  id-1: one\n  id-20: twenty\n"
+
+	y, err := Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal() = %v", err)
+	}
+	if string(y) != want {
+		t.Errorf("Marshal() = %#q; want %#q", string(y), want)
+	}
+
+	var got TextKeys
+	if err := Unmarshal(y, &got); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	if !reflect.DeepEqual(got, v) {
+		t.Errorf("Unmarshal() = %+v; want %+v", got, v)
+	}
+
+	err = Unmarshal([]byte("ids:\n  seven: x\n"), &got)
+	if want := `ids: cannot decode key "seven" into a map key of type yaml.keyID: missing id- prefix`; err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("Unmarshal() = %v; want error containing %q", err, want)
+	}
+}
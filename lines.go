@@ -0,0 +1,152 @@
+package yaml
+
+import "bytes"
+
+// isBlank reports whether b is a space or a tab.
+func isBlank(b byte) bool {
+	return b == ' ' || b == '\t'
+}
+
+// isSpace reports whether b is a space, a tab or a line break.
+func isSpace(b byte) bool {
+	return isBlank(b) || b == '\r' || b == '\n'
+}
+
+// isBlankLine reports whether the line holds nothing but whitespace.
+func isBlankLine(line []byte) bool {
+	return len(bytes.TrimLeft(line, " \t\r\n")) == 0
+}
+
+// isComment reports whether the line holds nothing but a comment.
+func isComment(line []byte) bool {
+	return bytes.HasPrefix(bytes.TrimLeft(line, " \t"), []byte("#"))
+}
+
+// isMarker reports whether line starts with the marker m, such as the
+// document marker "---" or the "-" of a sequence item, alone or followed by
+// whitespace.
+func isMarker(line []byte, m string) bool {
+	return bytes.HasPrefix(line, []byte(m)) && (len(line) == len(m) || isSpace(line[len(m)]))
+}
+
+// entrySplitter splits a block mapping or a block sequence that is the root
+// of a document into its top-level entries or items, a line at a time. It
+// follows the lines far enough to tell whether a line that is not indented
+// continues a quoted scalar or a flow collection, which go-yaml allows,
+// rather than starting a new entry. It errs on the side of finding them
+// open, which only makes an entry longer.
+type entrySplitter struct {
+	// seq is set if the document is a sequence, whose items start with "-"
+	// at the column col, rather than a mapping, whose keys start at column
+	// 0.
+	seq bool
+	col int
+	// quote is the quote of the scalar that is open, if any.
+	quote byte
+	// flow is the depth of the flow collections that are open.
+	flow int
+	// block is the indentation of the line that starts the block scalar
+	// being read, whose lines are more indented, or -1 outside of one.
+	block int
+}
+
+func (s *entrySplitter) open() bool {
+	return s.quote != 0 || s.flow > 0
+}
+
+// startsEntry reports whether the line, which follows the lines scanned,
+// starts a new entry.
+func (s *entrySplitter) startsEntry(line []byte) bool {
+	if s.open() || len(line) == 0 {
+		return false
+	}
+	if s.seq {
+		return len(line) > s.col && len(bytes.TrimLeft(line[:s.col], " ")) == 0 &&
+			isMarker(line[s.col:], "-")
+	}
+	switch line[0] {
+	case ' ', '\t', '\r', '\n', '#':
+		return false
+	case ':':
+		// The value of an explicit "?" key.
+		return len(line) > 1 && !isSpace(line[1])
+	}
+	// A sequence at the indentation of the key it is the value of.
+	return !isMarker(line, "-")
+}
+
+// scan follows the line, which is part of the current entry.
+func (s *entrySplitter) scan(line []byte) {
+	indent := 0
+	for indent < len(line) && line[indent] == ' ' {
+		indent++
+	}
+	if s.block >= 0 {
+		if isBlankLine(line) || indent > s.block {
+			return
+		}
+		s.block = -1
+	}
+
+	end := len(line)
+	prev := byte(' ')
+scan:
+	for i := 0; i < len(line); i++ {
+		ch := line[i]
+		switch s.quote {
+		case '"':
+			if ch == '\\' {
+				i++
+			} else if ch == '"' {
+				s.quote = 0
+			}
+			prev = ch
+			continue
+		case '\'':
+			if ch == '\'' {
+				if i+1 < len(line) && line[i+1] == '\'' {
+					i++
+				} else {
+					s.quote = 0
+				}
+			}
+			prev = ch
+			continue
+		}
+		tokenStart := isSpace(prev) || s.flow > 0 && bytes.IndexByte([]byte("[{,:"), prev) >= 0
+		switch ch {
+		case '#':
+			if isSpace(prev) {
+				end = i
+				break scan
+			}
+		case '"', '\'':
+			if tokenStart {
+				s.quote = ch
+			}
+		case '[', '{':
+			if tokenStart {
+				s.flow++
+			}
+		case ']', '}':
+			if s.flow > 0 {
+				s.flow--
+			}
+		}
+		prev = ch
+	}
+
+	// A line ending in a block scalar indicator, such as "key: |", starts
+	// a block scalar.
+	if s.open() {
+		return
+	}
+	fields := bytes.Fields(line[:end])
+	if len(fields) == 0 {
+		return
+	}
+	last := fields[len(fields)-1]
+	if (last[0] == '|' || last[0] == '>') && len(bytes.Trim(last[1:], "+-0123456789")) == 0 {
+		s.block = indent
+	}
+}
@@ -0,0 +1,245 @@
+// Package lint checks YAML documents for constructs that are valid, or nearly
+// so, but likely to be read differently from what their authors meant.
+package lint
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	yaml3 "gopkg.in/yaml.v3"
+)
+
+// Severity is how serious a Finding is.
+type Severity int
+
+const (
+	// Warning marks constructs that are valid YAML but likely mistakes.
+	Warning Severity = iota
+	// Error marks constructs that YAML parsers reject or handle
+	// inconsistently.
+	Error
+)
+
+func (s Severity) String() string {
+	switch s {
+	case Warning:
+		return "warning"
+	case Error:
+		return "error"
+	}
+	return "Severity(" + strconv.Itoa(int(s)) + ")"
+}
+
+// The rules that Findings are reported under.
+const (
+	// RuleSyntax reports documents that cannot be parsed.
+	RuleSyntax = "syntax"
+	// RuleDuplicateKey reports keys that occur more than once in a mapping.
+	RuleDuplicateKey = "duplicate-key"
+	// RuleTabIndentation reports lines indented with tabs, which YAML does
+	// not allow.
+	RuleTabIndentation = "tab-indentation"
+	// RuleNorway reports plain scalars such as no and on that YAML 1.1
+	// parsers, go-yaml among them, read as booleans while YAML 1.2 parsers
+	// read them as strings.
+	RuleNorway = "norway"
+	// RuleOctal reports plain integers with leading zeros, such as 0644,
+	// which YAML 1.1 parsers read as octal and YAML 1.2 parsers as decimal.
+	RuleOctal = "octal"
+	// RuleNestedAlias reports aliases whose expansion goes through more than
+	// MaxAliasDepth levels of aliases, as in "billion laughs" documents.
+	RuleNestedAlias = "nested-alias"
+)
+
+// MaxAliasDepth is the depth of nested aliases beyond which RuleNestedAlias
+// reports an alias.
+const MaxAliasDepth = 3
+
+// Finding is a problem found in a document.
+type Finding struct {
+	// Line and Column are the 1-based position of the problem.
+	Line, Column int
+	Severity     Severity
+	Rule         string
+	Message      string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%d:%d: %v: %s (%s)", f.Line, f.Column, f.Severity, f.Message, f.Rule)
+}
+
+// Lint checks the documents of the YAML stream y and returns the findings,
+// sorted by their position.
+func Lint(y []byte) []Finding {
+	l := &linter{depths: map[*yaml3.Node]int{}}
+	d := yaml3.NewDecoder(bytes.NewReader(y))
+	for {
+		var doc yaml3.Node
+		err := d.Decode(&doc)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			l.syntaxError(err)
+			break
+		}
+		l.node(&doc)
+	}
+	l.tabs(y)
+
+	sort.SliceStable(l.findings, func(i, j int) bool {
+		a, b := l.findings[i], l.findings[j]
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return l.findings
+}
+
+type linter struct {
+	findings []Finding
+	// lines holds the lines nodes start on, and blocks the lines block
+	// scalars start on.
+	lines  []int
+	blocks []int
+	// depths memoizes aliasDepth.
+	depths map[*yaml3.Node]int
+}
+
+func (l *linter) add(line, column int, s Severity, rule, format string, args ...interface{}) {
+	l.findings = append(l.findings, Finding{line, column, s, rule, fmt.Sprintf(format, args...)})
+}
+
+var syntaxError = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+func (l *linter) syntaxError(err error) {
+	if m := syntaxError.FindStringSubmatch(err.Error()); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		l.add(line, 1, Error, RuleSyntax, "%s", m[2])
+		return
+	}
+	l.add(1, 1, Error, RuleSyntax, "%s", strings.TrimPrefix(err.Error(), "yaml: "))
+}
+
+func (l *linter) node(n *yaml3.Node) {
+	l.lines = append(l.lines, n.Line)
+	switch n.Kind {
+	case yaml3.ScalarNode:
+		l.scalar(n)
+	case yaml3.MappingNode:
+		l.duplicateKeys(n)
+	case yaml3.AliasNode:
+		if depth := l.aliasDepth(n, map[*yaml3.Node]bool{}); depth > MaxAliasDepth {
+			l.add(n.Line, n.Column, Warning, RuleNestedAlias, "alias *%s expands %d levels of nested aliases", n.Value, depth)
+		}
+	}
+	for _, c := range n.Content {
+		l.node(c)
+	}
+}
+
+var (
+	// norwayScalars maps the YAML 1.1 booleans that YAML 1.2 does not have to
+	// their value.
+	norwayScalars = map[string]bool{
+		"y": true, "Y": true, "yes": true, "Yes": true, "YES": true,
+		"n": false, "N": false, "no": false, "No": false, "NO": false,
+		"on": true, "On": true, "ON": true,
+		"off": false, "Off": false, "OFF": false,
+	}
+	leadingZero = regexp.MustCompile(`^[-+]?0[0-9_]+$`)
+	octalDigits = regexp.MustCompile(`^[-+]?0[0-7_]+$`)
+)
+
+func (l *linter) scalar(n *yaml3.Node) {
+	if n.Style&(yaml3.LiteralStyle|yaml3.FoldedStyle) != 0 {
+		l.blocks = append(l.blocks, n.Line)
+	}
+	if n.Style&^yaml3.FlowStyle != 0 {
+		// Only plain scalars without a tag are resolved.
+		return
+	}
+	if b, ok := norwayScalars[n.Value]; ok {
+		l.add(n.Line, n.Column, Warning, RuleNorway, "%s is read as the boolean %t by YAML 1.1 parsers and as a string by YAML 1.2 parsers; quote it if it is a string", n.Value, b)
+		return
+	}
+	switch {
+	case octalDigits.MatchString(n.Value):
+		s := strings.Replace(n.Value, "_", "", -1)
+		oct, _ := strconv.ParseInt(s, 8, 64)
+		dec, _ := strconv.ParseInt(s, 10, 64)
+		l.add(n.Line, n.Column, Warning, RuleOctal, "%s is read as %d by YAML 1.1 parsers and as %d by YAML 1.2 parsers; quote it if it is a string or write it as 0o%s if it is octal", n.Value, oct, dec, strings.TrimLeft(strings.TrimLeft(s, "+-"), "0"))
+	case leadingZero.MatchString(n.Value):
+		l.add(n.Line, n.Column, Warning, RuleOctal, "%s has a leading zero but is not octal; quote it if it is a string", n.Value)
+	}
+}
+
+func (l *linter) duplicateKeys(n *yaml3.Node) {
+	seen := map[string]bool{}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		k := n.Content[i]
+		if k.Kind != yaml3.ScalarNode || k.Value == "<<" && k.Style == 0 {
+			continue
+		}
+		id := k.ShortTag() + "\x00" + k.Value
+		if seen[id] {
+			l.add(k.Line, k.Column, Error, RuleDuplicateKey, "key %q is already defined in this mapping", k.Value)
+		}
+		seen[id] = true
+	}
+}
+
+// aliasDepth returns the number of nested aliases the expansion of n goes
+// through. active holds the nodes being expanded, to stop at self-references.
+func (l *linter) aliasDepth(n *yaml3.Node, active map[*yaml3.Node]bool) int {
+	if d, ok := l.depths[n]; ok {
+		return d
+	}
+	if active[n] {
+		return 0
+	}
+	active[n] = true
+	defer delete(active, n)
+
+	depth := 0
+	if n.Kind == yaml3.AliasNode && n.Alias != nil {
+		depth = 1 + l.aliasDepth(n.Alias, active)
+	}
+	for _, c := range n.Content {
+		if d := l.aliasDepth(c, active); d > depth {
+			depth = d
+		}
+	}
+	l.depths[n] = depth
+	return depth
+}
+
+// tabs reports lines of y indented with tabs, leaving out the content of
+// block scalars, where tabs are allowed after the indentation.
+func (l *linter) tabs(y []byte) {
+	sort.Ints(l.lines)
+	inBlock := func(line int) bool {
+		for _, b := range l.blocks {
+			// A block scalar runs until the next node.
+			i := sort.SearchInts(l.lines, b+1)
+			if line > b && (i == len(l.lines) || line < l.lines[i]) {
+				return true
+			}
+		}
+		return false
+	}
+	for i, line := range strings.Split(string(y), "\n") {
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		tab := strings.IndexByte(line[:indent], '\t')
+		if tab < 0 || indent == len(strings.TrimRight(line, "\r")) || inBlock(i+1) {
+			continue
+		}
+		l.add(i+1, tab+1, Error, RuleTabIndentation, "line is indented with a tab")
+	}
+}
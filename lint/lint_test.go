@@ -0,0 +1,49 @@
+package lint
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		yaml string
+		want []string
+	}{
+		{"clean", "a: 1\nb: [x, 'no', \"0644\"]\nc: 0o644\n", nil},
+		{"duplicate key", "a: 1\nb:\n  c: 1\n  c: 2\na: 3\n", []string{
+			`4:3: error: key "c" is already defined in this mapping (duplicate-key)`,
+			`5:1: error: key "a" is already defined in this mapping (duplicate-key)`,
+		}},
+		{"norway", "country: NO\nenabled: on\nok: yes\n", []string{
+			"1:10: warning: NO is read as the boolean false by YAML 1.1 parsers and as a string by YAML 1.2 parsers; quote it if it is a string (norway)",
+			"2:10: warning: on is read as the boolean true by YAML 1.1 parsers and as a string by YAML 1.2 parsers; quote it if it is a string (norway)",
+			"3:5: warning: yes is read as the boolean true by YAML 1.1 parsers and as a string by YAML 1.2 parsers; quote it if it is a string (norway)",
+		}},
+		{"octal", "mode: 0644\nzip: 01289\n", []string{
+			"1:7: warning: 0644 is read as 420 by YAML 1.1 parsers and as 644 by YAML 1.2 parsers; quote it if it is a string or write it as 0o644 if it is octal (octal)",
+			"2:6: warning: 01289 has a leading zero but is not octal; quote it if it is a string (octal)",
+		}},
+		{"tabs", "a:\n\t- b\n", []string{
+			"2:1: error: found character that cannot start any token (syntax)",
+			"2:1: error: line is indented with a tab (tab-indentation)",
+		}},
+		{"tabs in block scalar", "a: |\n  x\n  \ty\nb: 1\n", nil},
+		{"nested aliases", "a: &a [x, x]\nb: &b [*a, *a]\nc: &c [*b, *b]\nd: &d [*c, *c]\ne: [*d]\n", []string{
+			"5:5: warning: alias *d expands 4 levels of nested aliases (nested-alias)",
+		}},
+		{"documents", "a: 1\n---\na: 1\na: 2\n", []string{
+			`4:1: error: key "a" is already defined in this mapping (duplicate-key)`,
+		}},
+	} {
+		var got []string
+		for _, f := range Lint([]byte(tc.yaml)) {
+			got = append(got, f.String())
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: Lint() =\n%s\nwant\n%s", tc.name, strings.Join(got, "\n"), strings.Join(tc.want, "\n"))
+		}
+	}
+}
@@ -0,0 +1,269 @@
+// Package lite converts between YAML and JSON with a small part of the yaml
+// package, for builds where binary size and reflection are constrained, such
+// as TinyGo, WebAssembly plugins and embedded controllers. It depends on
+// gopkg.in/yaml.v2 alone, which parses documents into generic values, and
+// writes JSON itself with type switches rather than with encoding/json, so
+// it uses no reflection of its own. It has none of the options of the yaml
+// package and does not decode into Go types; a document may be decoded into
+// a struct by passing the JSON of YAMLToJSON to a JSON decoder that suits
+// the build, or with the yaml package where reflection is available.
+//
+// Documents are read as the yaml package reads them without options, so
+// YAMLToJSON writes the same JSON as yaml.YAMLToJSON.
+package lite
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v2"
+)
+
+// YAMLToJSON converts the YAML document y to JSON, as yaml.YAMLToJSON does.
+func YAMLToJSON(y []byte) ([]byte, error) {
+	v, err := Decode(y)
+	if err != nil {
+		return nil, err
+	}
+	return appendJSON(nil, v), nil
+}
+
+// JSONToYAML converts the JSON document j to YAML, as yaml.JSONToYAML does.
+func JSONToYAML(j []byte) ([]byte, error) {
+	var v interface{}
+	if err := yaml.Unmarshal(j, &v); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(v)
+}
+
+// Decode returns the YAML document y as the value that YAMLToJSON writes:
+// a map[string]interface{}, a []interface{}, a string, a bool, nil, or a
+// number, which is an int, an int64, a uint64 or a float64 as go-yaml
+// resolves it, rather than the float64 encoding/json decodes numbers into.
+func Decode(y []byte) (interface{}, error) {
+	var v interface{}
+	if err := yaml.Unmarshal(y, &v); err != nil {
+		return nil, err
+	}
+	return convert(v, nil)
+}
+
+// path is the path of a value in a document, such as a.b[0], for errors.
+type path struct {
+	parent *path
+	key    string
+	// index is the index into a sequence, or -1 for a mapping key.
+	index int
+}
+
+func (p *path) String() string {
+	if p == nil {
+		return ""
+	}
+	parent := p.parent.String()
+	if p.index >= 0 {
+		return parent + "[" + strconv.Itoa(p.index) + "]"
+	}
+	if parent == "" {
+		return p.key
+	}
+	return parent + "." + p.key
+}
+
+// convert converts v, a value decoded by go-yaml found at p, to the value
+// Decode returns.
+func convert(v interface{}, p *path) (interface{}, error) {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			key, ok := keyString(k)
+			if !ok {
+				return nil, fmt.Errorf("Unsupported map key of type: %T, key: %+#v, value: %+#v", k, k, e)
+			}
+			c, err := convert(e, &path{parent: p, key: key, index: -1})
+			if err != nil {
+				return nil, err
+			}
+			m[key] = c
+		}
+		return m, nil
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, e := range v {
+			c, err := convert(e, &path{parent: p, index: i})
+			if err != nil {
+				return nil, err
+			}
+			s[i] = c
+		}
+		return s, nil
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			err := fmt.Errorf("cannot convert %s to JSON", nonFinite(v))
+			if p != nil {
+				err = fmt.Errorf("%s: %v", p, err)
+			}
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+// keyString returns the key of a JSON object for the key k of a mapping
+// decoded by go-yaml, as the yaml package makes it.
+func keyString(k interface{}) (string, bool) {
+	switch k := k.(type) {
+	case string:
+		return k, true
+	case int:
+		return strconv.Itoa(k), true
+	case int64:
+		return strconv.FormatInt(k, 10), true
+	case float64:
+		// go-yaml writes floats with the precision of a float32.
+		if math.IsNaN(k) || math.IsInf(k, 0) {
+			return nonFinite(k), true
+		}
+		return strconv.FormatFloat(k, 'g', -1, 32), true
+	case bool:
+		return strconv.FormatBool(k), true
+	}
+	return "", false
+}
+
+func nonFinite(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return ".inf"
+	case math.IsInf(f, -1):
+		return "-.inf"
+	}
+	return ".nan"
+}
+
+// appendJSON appends v, a value returned by Decode, to b as JSON, as
+// json.Marshal writes it.
+func appendJSON(b []byte, v interface{}) []byte {
+	switch v := v.(type) {
+	case nil:
+		return append(b, "null"...)
+	case bool:
+		return strconv.AppendBool(b, v)
+	case string:
+		return appendString(b, v)
+	case int:
+		return strconv.AppendInt(b, int64(v), 10)
+	case int64:
+		return strconv.AppendInt(b, v, 10)
+	case uint64:
+		return strconv.AppendUint(b, v, 10)
+	case float64:
+		return appendFloat(b, v)
+	case []interface{}:
+		b = append(b, '[')
+		for i, e := range v {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = appendJSON(b, e)
+		}
+		return append(b, ']')
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		b = append(b, '{')
+		for i, k := range keys {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = appendString(b, k)
+			b = append(b, ':')
+			b = appendJSON(b, v[k])
+		}
+		return append(b, '}')
+	}
+	// go-yaml decodes nothing else into an interface{}.
+	panic(fmt.Sprintf("lite: unexpected value of type %T", v))
+}
+
+// appendFloat appends the finite f to b as encoding/json writes it.
+func appendFloat(b []byte, f float64) []byte {
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	b = strconv.AppendFloat(b, f, format, -1, 64)
+	if format == 'e' {
+		// Shorten an exponent such as e-07 to e-7.
+		if n := len(b); n >= 4 && b[n-4] == 'e' && b[n-3] == '-' && b[n-2] == '0' {
+			b[n-2] = b[n-1]
+			b = b[:n-1]
+		}
+	}
+	return b
+}
+
+const hex = "0123456789abcdef"
+
+// appendString appends s to b as a JSON string, escaped as encoding/json
+// escapes it, HTML characters included.
+func appendString(b []byte, s string) []byte {
+	b = append(b, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' && c != '<' && c != '>' && c != '&' {
+				i++
+				continue
+			}
+			b = append(b, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				b = append(b, '\\', c)
+			case '\b':
+				b = append(b, `\b`...)
+			case '\f':
+				b = append(b, `\f`...)
+			case '\n':
+				b = append(b, `\n`...)
+			case '\r':
+				b = append(b, `\r`...)
+			case '\t':
+				b = append(b, `\t`...)
+			default:
+				b = append(b, `\u00`...)
+				b = append(b, hex[c>>4], hex[c&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			b = append(b, s[start:i]...)
+			b = append(b, `\ufffd`...)
+			i += size
+			start = i
+			continue
+		}
+		if r == '\u2028' || r == '\u2029' {
+			b = append(b, s[start:i]...)
+			b = append(b, `\u202`...)
+			b = append(b, hex[r&0xf])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	b = append(b, s[start:]...)
+	return append(b, '"')
+}
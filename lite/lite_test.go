@@ -0,0 +1,112 @@
+package lite
+
+import (
+	"go/build"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/ghodss/yaml/yamlfuzz"
+)
+
+var documents = []string{
+	"",
+	"null",
+	"a: 1\nb: [x, 2.5, true, ~]\nc:\n  d: {e: f}\n",
+	"- 1\n- -2\n- 18446744073709551615\n- 9223372036854775807\n- 0x1f\n- 0o17\n",
+	"a: 1.0\nb: 1e21\nc: 0.0000001\nd: -0.0\ne: 1.5e+300\nf: 123456789.125\ng: 1e-7\n",
+	"1: a\n2.5: b\n1e100: c\ntrue: d\nno: e\n.inf: f\n-.inf: g\n.nan: h\n",
+	"a: \"<tag> & \\\"quote\\\" \\\\ \\b\\f\\n\\r\\t\\x01\\x1f\"\nb: \"\\u2028\\u2029 é 日本\"\n",
+	"a: &x {b: 1}\nc: *x\nd:\n  <<: *x\n  e: 2\n",
+	"a: !!binary aGVsbG8=\nb: 2001-12-14t21:59:43.10-05:00\nc: -.nan\n",
+	"a: |\n  block\n  text\nb: >\n  folded\n  text\n",
+	"[1, {a: [2, {b: 3}]}]",
+	"\"just a string\"",
+}
+
+func TestYAMLToJSON(t *testing.T) {
+	docs := documents
+	// The seeds of the fuzzer of the yaml package.
+	for _, seed := range yamlfuzz.Seeds() {
+		docs = append(docs, string(seed))
+	}
+	for _, y := range docs {
+		got, err := YAMLToJSON([]byte(y))
+		want, wantErr := yaml.YAMLToJSON([]byte(y))
+		if wantErr != nil {
+			// Which of several errors is found first depends on the order of
+			// the keys of a map, so only the error is checked.
+			if err == nil {
+				t.Errorf("YAMLToJSON(%q) = %s, want error %v", y, got, wantErr)
+			}
+			continue
+		}
+		if err != nil || string(got) != string(want) {
+			t.Errorf("YAMLToJSON(%q) = %s, %v, want %s", y, got, err, want)
+		}
+	}
+}
+
+func TestYAMLToJSONErrors(t *testing.T) {
+	for _, y := range []string{
+		"a: [",
+		"a: .inf\n",
+		"a:\n  b:\n  - 1\n  - -.inf\n",
+		"{[1]: 2}",
+	} {
+		_, err := YAMLToJSON([]byte(y))
+		_, want := yaml.YAMLToJSON([]byte(y))
+		if err == nil || want == nil || err.Error() != want.Error() {
+			t.Errorf("YAMLToJSON(%q) = %v, want %v", y, err, want)
+		}
+	}
+	if _, err := YAMLToJSON([]byte("~: 1\n")); err == nil || !strings.HasPrefix(err.Error(), "Unsupported map key of type: <nil>") {
+		t.Errorf("YAMLToJSON() of a null key = %v", err)
+	}
+}
+
+func TestJSONToYAML(t *testing.T) {
+	for _, j := range []string{
+		`{"a":1,"b":[true,null,"x"],"c":{"d":1.5}}`,
+		`[1, "2", {"3": 4}]`,
+		`"yes"`,
+		`{"key": "a: b", "n": 1e21}`,
+	} {
+		got, err := JSONToYAML([]byte(j))
+		want, wantErr := yaml.JSONToYAML([]byte(j))
+		if err != nil || wantErr != nil || string(got) != string(want) {
+			t.Errorf("JSONToYAML(%s) = %q, %v, want %q, %v", j, got, err, want, wantErr)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	v, err := Decode([]byte("a: 1\nb: [x, 2.5, 18446744073709551615]\n1: ~\n"))
+	want := map[string]interface{}{
+		"a": 1,
+		"b": []interface{}{"x", 2.5, uint64(18446744073709551615)},
+		"1": nil,
+	}
+	if err != nil || !reflect.DeepEqual(v, want) {
+		t.Errorf("Decode() = %#v, %v, want %#v", v, err, want)
+	}
+}
+
+// TestImports checks that the package keeps to the imports that builds
+// without full reflection support can have.
+func TestImports(t *testing.T) {
+	pkg, err := build.ImportDir(".", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	allowed := map[string]bool{
+		"fmt": true, "math": true, "sort": true, "strconv": true, "unicode/utf8": true,
+		"gopkg.in/yaml.v2": true,
+	}
+	for _, imp := range pkg.Imports {
+		if !allowed[imp] && !strings.HasPrefix(imp, "unicode/") {
+			t.Errorf("lite imports %s", imp)
+		}
+	}
+}
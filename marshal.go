@@ -0,0 +1,292 @@
+package yaml
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"sync"
+
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	jsonMarshalerType   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+// needsStructs reports whether any of the options set on e depend on the Go
+// value the JSON document was marshaled from.
+func (e *encoder) needsStructs() bool {
+	return e.fieldNaming != nil || e.tags != JSONTagsOnly || e.redact ||
+		e.yamlMarshalers || e.marshalFuncs != nil || e.formatsFloats()
+}
+
+var structsCache struct {
+	sync.RWMutex
+	m map[reflect.Type]bool
+}
+
+// typeNeedsStructs reports whether values of type t may contain a struct with
+// a rest field or a field with the inline option, or a map with keys
+// encoding/json does not support or writes differently across versions.
+// Marshal and Unmarshal handle those by walking the Go value whether or not
+// any options are given.
+func typeNeedsStructs(t reflect.Type) bool {
+	structsCache.RLock()
+	has, ok := structsCache.m[t]
+	structsCache.RUnlock()
+	if ok {
+		return has
+	}
+
+	has = findStructOptions(t, map[reflect.Type]bool{})
+
+	structsCache.Lock()
+	if structsCache.m == nil {
+		structsCache.m = map[reflect.Type]bool{}
+	}
+	structsCache.m[t] = has
+	structsCache.Unlock()
+	return has
+}
+
+func findStructOptions(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+	switch t.Kind() {
+	case reflect.Map:
+		k := t.Key()
+		if jsonKeyUnsupported(k) || k.Kind() == reflect.String && k.Implements(textMarshalerType) {
+			return true
+		}
+		return findStructOptions(t.Elem(), seen)
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return findStructOptions(t.Elem(), seen)
+	case reflect.Struct:
+		if restField(t) != nil {
+			return true
+		}
+		for _, f := range cachedTypeFields(t) {
+			if f.yamlInline || findStructOptions(f.typ, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// applyStructs walks node, the decoded JSON that json.Marshal produced for v,
+// alongside v and applies the options that depend on struct fields, along with
+// the keys of any rest fields. It stops descending wherever a value marshals
+// itself, since from there on the JSON no longer follows the Go value.
+func (e *encoder) applyStructs(node interface{}, v reflect.Value) (interface{}, error) {
+	if e.redact && isRedacted(v) {
+		return e.placeholder, nil
+	}
+	if e.converters != nil && e.converted(v) {
+		// The JSON was written from what the converter returned.
+		return node, nil
+	}
+	if e.marshalFuncs != nil {
+		if obj, ok, err := e.funcMarshaled(v); ok {
+			return obj, err
+		}
+	}
+	if e.yamlMarshalers {
+		if obj, ok, err := yamlMarshaled(v); ok {
+			return obj, err
+		}
+	}
+	v = marshaledValue(v)
+	if !v.IsValid() {
+		return node, nil
+	}
+	if e.formatsFloats() && (v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64) {
+		return floatNode(node, v), nil
+	}
+
+	var err error
+	switch n := node.(type) {
+	case yaml.MapSlice:
+		switch v.Kind() {
+		case reflect.Struct:
+			fields := cachedTypeFields(v.Type())
+			kept := make(yaml.MapSlice, 0, len(n))
+			for _, item := range n {
+				f := fieldByName(fields, item.Key.(string))
+				if f != nil {
+					if skipField(f, e.tags) {
+						continue
+					}
+					fv := fieldByIndex(v, f.index)
+					if e.redact && f.redact {
+						item.Key = fieldKey(f, e.tags, e.fieldNaming)
+						item.Value = e.placeholder
+						kept = append(kept, item)
+						continue
+					}
+					item.Value, err = e.applyStructs(item.Value, fv)
+					if err != nil {
+						return nil, err
+					}
+					if f.yamlInline {
+						// The keys of inline maps are written by restItems.
+						if inner, ok := item.Value.(yaml.MapSlice); ok && f.typ.Kind() == reflect.Struct {
+							kept = append(kept, inner...)
+						}
+						continue
+					}
+					item.Key = fieldKey(f, e.tags, e.fieldNaming)
+				}
+				kept = append(kept, item)
+			}
+			if index := restField(v.Type()); index != nil {
+				rest, err := e.restItems(v.FieldByIndex(index), kept)
+				if err != nil {
+					return nil, err
+				}
+				kept = append(kept, rest...)
+			}
+			return kept, nil
+		case reflect.Map:
+			entries := mapEntriesByKey(v)
+			for i, item := range n {
+				entry, ok := entries[item.Key.(string)]
+				if !ok {
+					entry, ok = e.floatKeyEntry(v, item.Key.(string))
+				}
+				if !ok {
+					continue
+				}
+				n[i].Key = entry.key
+				n[i].Value, err = e.applyStructs(item.Value, entry.value)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+	case []interface{}:
+		if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+			for i := range n {
+				if i < v.Len() {
+					n[i], err = e.applyStructs(n[i], v.Index(i))
+					if err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+	}
+	return node, nil
+}
+
+// marshaledValue follows pointers and interfaces in v the way json.Marshal
+// does. It returns an invalid value if v is nil or marshals itself.
+func marshaledValue(v reflect.Value) reflect.Value {
+	for v.IsValid() {
+		if marshalsItself(v) {
+			return reflect.Value{}
+		}
+		switch v.Kind() {
+		case reflect.Ptr, reflect.Interface:
+			if v.IsNil() {
+				return reflect.Value{}
+			}
+			v = v.Elem()
+		default:
+			return v
+		}
+	}
+	return v
+}
+
+func marshalsItself(v reflect.Value) bool {
+	t := v.Type()
+	if t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) {
+		return true
+	}
+	if v.Kind() != reflect.Ptr && v.CanAddr() {
+		pt := reflect.PtrTo(t)
+		return pt.Implements(jsonMarshalerType) || pt.Implements(textMarshalerType)
+	}
+	return false
+}
+
+// fieldByName returns the field that json.Marshal writes under the given key.
+func fieldByName(fields []field, key string) *field {
+	for i := range fields {
+		if fields[i].name == key {
+			return &fields[i]
+		}
+	}
+	return nil
+}
+
+// fieldByIndex is like reflect.Value.FieldByIndex, but returns an invalid
+// value instead of panicking when it meets a nil embedded pointer.
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+// mapEntry is an entry of a map that is being marshaled, along with the YAML
+// key it is written under.
+type mapEntry struct {
+	key   string
+	value reflect.Value
+}
+
+// mapEntriesByKey returns the entries of the map v keyed by the strings
+// json.Marshal writes for their keys. Keys of string kind that implement
+// encoding.TextMarshaler are written by older versions of encoding/json as
+// the string itself and by newer ones as its text, so entries are found under
+// both and are written under their text.
+func mapEntriesByKey(v reflect.Value) map[string]mapEntry {
+	entries := make(map[string]mapEntry, v.Len())
+	for _, k := range v.MapKeys() {
+		s, ok := mapKeyString(k)
+		if !ok {
+			continue
+		}
+		entry := mapEntry{s, v.MapIndex(k)}
+		entries[s] = entry
+		if k.Kind() == reflect.String {
+			entries[k.String()] = entry
+		}
+	}
+	return entries
+}
+
+// mapKeyString returns the string the map key k is written as: the text of an
+// encoding.TextMarshaler, or else the string or integer itself.
+func mapKeyString(k reflect.Value) (string, bool) {
+	if tm, ok := k.Interface().(encoding.TextMarshaler); ok {
+		if k.Kind() == reflect.Ptr && k.IsNil() {
+			return "", true
+		}
+		b, err := tm.MarshalText()
+		return string(b), err == nil
+	}
+	switch k.Kind() {
+	case reflect.String:
+		return k.String(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), true
+	}
+	return "", false
+}
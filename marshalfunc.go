@@ -0,0 +1,65 @@
+package yaml
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+)
+
+// MarshalFunc makes Marshal write values of the type of sample with f, which
+// returns their JSON encoding, in place of their json tags or MarshalJSON
+// method, for instance to format the floats of a type one does not own:
+//
+//	yaml.MarshalFunc(float64(0), func(v interface{}) ([]byte, error) {
+//		return []byte(strconv.FormatFloat(v.(float64), 'f', 2, 64)), nil
+//	})
+//
+// A number f returns is written as its text, so the example writes 1.50 for
+// 1.5. The values are still marshaled by encoding/json first, so they must be
+// values it can marshal, and f applies to the values of the document, not to
+// map keys. Options for several types may be given; the last one given for a
+// type applies.
+func MarshalFunc(sample interface{}, f func(v interface{}) ([]byte, error)) MarshalOpt {
+	t := reflect.TypeOf(sample)
+	if t == nil {
+		panic("yaml: MarshalFunc needs a sample of a type")
+	}
+	return func(e *encoder) {
+		if e.marshalFuncs == nil {
+			e.marshalFuncs = make(map[reflect.Type]func(interface{}) ([]byte, error))
+		}
+		e.marshalFuncs[t] = f
+	}
+}
+
+// funcMarshaled returns what the MarshalFunc of e for the type of v, or of
+// what v points to, writes for it, as the values the encoder writes, and
+// false if there is no such function.
+func (e *encoder) funcMarshaled(v reflect.Value) (interface{}, bool, error) {
+	for v.IsValid() {
+		if f, ok := e.marshalFuncs[v.Type()]; ok {
+			if !v.CanInterface() {
+				return nil, false, nil
+			}
+			j, err := f(v.Interface())
+			if err != nil {
+				return nil, true, err
+			}
+			obj, err := decodeJSON(j)
+			if err != nil {
+				return nil, true, err
+			}
+			switch obj.(type) {
+			case int, int64, uint64, float64:
+				// Keep the text of the number.
+				obj = json.Number(bytes.TrimSpace(j))
+			}
+			return obj, true, nil
+		}
+		if v.Kind() != reflect.Ptr && v.Kind() != reflect.Interface || v.IsNil() {
+			return nil, false, nil
+		}
+		v = v.Elem()
+	}
+	return nil, false, nil
+}
@@ -0,0 +1,63 @@
+package yaml
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestMarshalFunc(t *testing.T) {
+	formatFloat := MarshalFunc(float64(0), func(v interface{}) ([]byte, error) {
+		return []byte(strconv.FormatFloat(v.(float64), 'f', 2, 64)), nil
+	})
+	formatDate := MarshalFunc(time.Time{}, func(v interface{}) ([]byte, error) {
+		return []byte(strconv.Quote(v.(time.Time).Format("2006-01-02"))), nil
+	})
+
+	type reading struct {
+		Taken  time.Time              `json:"taken"`
+		Value  float64                `json:"value"`
+		Max    *float64               `json:"max,omitempty"`
+		Values []float64              `json:"values"`
+		Extra  map[string]interface{} `json:"extra"`
+		Count  int                    `json:"count"`
+	}
+	max := 10.0
+	r := reading{
+		Taken:  time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+		Value:  1.5,
+		Max:    &max,
+		Values: []float64{1, 2.25},
+		Extra:  map[string]interface{}{"mean": 1.25},
+		Count:  2,
+	}
+	want := `count: 2
+extra:
+  mean: 1.25
+max: 10.00
+taken: "2024-03-01"
+value: 1.50
+values:
+- 1.00
+- 2.25
+`
+	y, err := Marshal(r, formatFloat, formatDate)
+	if err != nil {
+		t.Fatalf("Marshal() = %v", err)
+	}
+	if string(y) != want {
+		t.Errorf("Marshal() = %q, want %q", y, want)
+	}
+
+	if y, err := Marshal(2.5, formatFloat); err != nil || string(y) != "2.50\n" {
+		t.Errorf("Marshal(2.5) = %q, %v", y, err)
+	}
+
+	_, err = Marshal(r, MarshalFunc(float64(0), func(interface{}) ([]byte, error) {
+		return nil, errors.New("cannot format")
+	}))
+	if err == nil {
+		t.Error("Marshal() did not return the error of the function")
+	}
+}
@@ -0,0 +1,168 @@
+package yaml
+
+import (
+	"encoding/json"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// MaskFunc is called by Marshal with the path of each value it writes, such
+// as "credentials.token" or "users[0].password", and "" for the whole
+// document, along with the value. It returns the value to write in its place,
+// or false to leave the value and its key out. v is in the form it is written
+// in: a yaml.MapSlice of gopkg.in/yaml.v2 for a mapping, an []interface{} for
+// a sequence, or a string, bool, int, int64, uint64, float64 or nil. A
+// replacement is written like a value passed to Marshal; the values a
+// replaced mapping or sequence held are not passed to the function.
+type MaskFunc func(path string, v interface{}) (interface{}, bool)
+
+// Mask makes Marshal pass each value to f, a mapping or sequence before the
+// values it holds, so that values can be masked or dropped by path when
+// writing diagnostic output, whatever the Go types they come from:
+//
+//	yaml.Mask(func(path string, v interface{}) (interface{}, bool) {
+//		if yaml.MatchPath("credentials.*", path) {
+//			return nil, false
+//		}
+//		if yaml.MatchPath("**.token", path) {
+//			return "***", true
+//		}
+//		return v, true
+//	})
+func Mask(f MaskFunc) MarshalOpt {
+	return func(e *encoder) {
+		e.mask = f
+	}
+}
+
+// MatchPath reports whether path, as passed to a MaskFunc, matches pattern.
+// The elements of a pattern are keys and indexes, as in "users[0].name", that
+// match themselves, "*" or "[*]", which match any single key or index, and
+// "**", which matches any number of keys and indexes, including none.
+func MatchPath(pattern, path string) bool {
+	return matchElems(splitPath(pattern), splitPath(path))
+}
+
+func matchElems(pattern, path []string) bool {
+	for len(pattern) > 0 {
+		p := pattern[0]
+		if p == "**" {
+			for i := 0; i <= len(path); i++ {
+				if matchElems(pattern[1:], path[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(path) == 0 {
+			return false
+		}
+		switch {
+		case p == "*":
+		case p == "[*]":
+			if !strings.HasPrefix(path[0], "[") {
+				return false
+			}
+		case p != path[0]:
+			return false
+		}
+		pattern, path = pattern[1:], path[1:]
+	}
+	return len(path) == 0
+}
+
+// splitPath splits a path into its keys and indexes, keeping the brackets of
+// indexes.
+func splitPath(path string) []string {
+	var elems []string
+	for path != "" {
+		switch {
+		case path[0] == '.':
+			path = path[1:]
+		case path[0] == '[':
+			i := strings.IndexByte(path, ']')
+			if i < 0 {
+				i = len(path) - 1
+			}
+			elems = append(elems, path[:i+1])
+			path = path[i+1:]
+		default:
+			i := strings.IndexAny(path, ".[")
+			if i < 0 {
+				i = len(path)
+			}
+			elems = append(elems, path[:i])
+			path = path[i:]
+		}
+	}
+	return elems
+}
+
+// applyMask returns v, found at path, as the MaskFunc of e replaces it, and
+// whether to keep it.
+func (e *encoder) applyMask(v interface{}, path *keyPath) (interface{}, bool, error) {
+	masked, keep := e.mask(path.String(), v)
+	if !keep {
+		return nil, false, nil
+	}
+	if !sameValue(masked, v) {
+		masked, err := maskedValue(masked)
+		return masked, true, err
+	}
+	switch x := v.(type) {
+	case yaml.MapSlice:
+		kept := x[:0]
+		for _, item := range x {
+			k, _ := item.Key.(string)
+			value, keep, err := e.applyMask(item.Value, path.key(k))
+			if err != nil {
+				return nil, false, err
+			}
+			if keep {
+				kept = append(kept, yaml.MapItem{Key: item.Key, Value: value})
+			}
+		}
+		return kept, true, nil
+	case []interface{}:
+		kept := x[:0]
+		for i, item := range x {
+			value, keep, err := e.applyMask(item, path.index(i))
+			if err != nil {
+				return nil, false, err
+			}
+			if keep {
+				kept = append(kept, value)
+			}
+		}
+		return kept, true, nil
+	}
+	return v, true, nil
+}
+
+// sameValue reports whether a MaskFunc returned the value v it was given.
+func sameValue(masked, v interface{}) bool {
+	switch x := v.(type) {
+	case yaml.MapSlice:
+		m, ok := masked.(yaml.MapSlice)
+		return ok && len(m) == len(x) && (len(x) == 0 || &m[0] == &x[0])
+	case []interface{}:
+		s, ok := masked.([]interface{})
+		return ok && len(s) == len(x) && (len(x) == 0 || &s[0] == &x[0])
+	}
+	return masked == v
+}
+
+// maskedValue returns the replacement v returned by a MaskFunc in the form
+// the encoder writes.
+func maskedValue(v interface{}) (interface{}, error) {
+	switch v.(type) {
+	case nil, string, bool, int, int64, uint64, float64, yaml.MapSlice, []interface{}:
+		return v, nil
+	}
+	j, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return decodeJSON(j)
+}
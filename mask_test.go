@@ -0,0 +1,73 @@
+package yaml
+
+import "testing"
+
+func TestMask(t *testing.T) {
+	v := map[string]interface{}{
+		"name": "web",
+		"credentials": map[string]string{
+			"user":     "admin",
+			"password": "hunter2",
+		},
+		"db":    map[string]interface{}{"url": "postgres://db", "token": "abc"},
+		"users": []map[string]string{{"name": "ann", "token": "t1"}, {"name": "bob", "token": "t2"}},
+		"ports": []int{80, 443, 8080},
+	}
+	var paths []string
+	got, err := Marshal(v, Mask(func(path string, v interface{}) (interface{}, bool) {
+		paths = append(paths, path)
+		switch {
+		case MatchPath("credentials.*", path), MatchPath("ports[2]", path):
+			return nil, false
+		case MatchPath("**.token", path):
+			return "***", true
+		case path == "db.url":
+			return map[string]int{"redacted": 1}, true
+		}
+		return v, true
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `credentials: {}
+db:
+  token: '***'
+  url:
+    redacted: 1
+name: web
+ports:
+- 80
+- 443
+users:
+- name: ann
+  token: '***'
+- name: bob
+  token: '***'
+`
+	if string(got) != want {
+		t.Errorf("Marshal() with Mask = %#q; want %#q", string(got), want)
+	}
+	if len(paths) == 0 || paths[0] != "" || paths[1] != "credentials" {
+		t.Errorf("Mask() paths = %q", paths)
+	}
+
+	for _, tc := range []struct {
+		pattern, path string
+		want          bool
+	}{
+		{"*.token", "db.token", true},
+		{"*.token", "a.b.token", false},
+		{"**.token", "a.b.token", true},
+		{"**.token", "token", true},
+		{"users[*].name", "users[3].name", true},
+		{"users[*].name", "users.x.name", false},
+		{"users.*", "users[0]", true},
+		{"credentials.*", "credentials", false},
+		{"credentials.**", "credentials", true},
+		{"", "", true},
+	} {
+		if got := MatchPath(tc.pattern, tc.path); got != tc.want {
+			t.Errorf("MatchPath(%q, %q) = %v; want %v", tc.pattern, tc.path, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package yaml
+
+// MergeOpt is an option for Merge.
+type MergeOpt func(*merger)
+
+// merger holds the settings of the MergeOpts.
+type merger struct {
+	lists   ListStrategy
+	key     string
+	listsAt map[string]ListStrategy
+}
+
+// ListStrategy is how Merge merges a list of dst with the list at the same
+// path in src.
+type ListStrategy int
+
+const (
+	// ListReplace replaces the list of dst with that of src.
+	ListReplace ListStrategy = iota
+	// ListAppend appends the items of the list of src to those of dst.
+	ListAppend
+	// ListMergeByKey deep-merges items of the lists that are mappings with
+	// the same value under the merge key, and appends the other items of
+	// src.
+	ListMergeByKey
+)
+
+// MergeLists sets the strategy for merging lists. The default is ListReplace.
+func MergeLists(s ListStrategy) MergeOpt {
+	return func(m *merger) {
+		m.lists = s
+	}
+}
+
+// MergeListsAt sets the strategy for merging the lists at path, overriding
+// MergeLists. Paths are the keys that lead to a list joined by dots, leaving
+// out the items of lists along the way, as in a PatchSchema.
+func MergeListsAt(path string, s ListStrategy) MergeOpt {
+	return func(m *merger) {
+		if m.listsAt == nil {
+			m.listsAt = map[string]ListStrategy{}
+		}
+		m.listsAt[path] = s
+	}
+}
+
+// MergeKey sets the key that ListMergeByKey matches items by. The default is
+// "name".
+func MergeKey(key string) MergeOpt {
+	return func(m *merger) {
+		m.key = key
+	}
+}
+
+// Merge deep-merges the YAML document src into the YAML document dst and
+// returns the result as YAML, as when layering configuration files. Mappings
+// are merged key by key, lists by the strategy set by the options, and any
+// other value of src, null included, replaces the value in dst. Like
+// JSONToYAML, the result has its keys sorted and no comments.
+func Merge(dst, src []byte, opts ...MergeOpt) ([]byte, error) {
+	m := &merger{key: "name"}
+	for _, opt := range opts {
+		opt(m)
+	}
+	d, err := decodeDocument(dst)
+	if err != nil {
+		return nil, err
+	}
+	s, err := decodeDocument(src)
+	if err != nil {
+		return nil, err
+	}
+	return encodeDocument(m.merge(d, s, ""))
+}
+
+// merge returns dst, the value at path, with src merged into it.
+func (m *merger) merge(dst, src interface{}, path string) interface{} {
+	switch s := src.(type) {
+	case map[string]interface{}:
+		d, ok := dst.(map[string]interface{})
+		if !ok {
+			return src
+		}
+		for k, v := range s {
+			if dv, ok := d[k]; ok {
+				d[k] = m.merge(dv, v, joinPatchPath(path, k))
+			} else {
+				d[k] = v
+			}
+		}
+		return d
+	case []interface{}:
+		d, ok := dst.([]interface{})
+		if !ok {
+			return src
+		}
+		strategy, ok := m.listsAt[path]
+		if !ok {
+			strategy = m.lists
+		}
+		switch strategy {
+		case ListAppend:
+			return append(d, s...)
+		case ListMergeByKey:
+			return m.mergeByKey(d, s, path)
+		}
+	}
+	return src
+}
+
+func (m *merger) mergeByKey(dst, src []interface{}, path string) []interface{} {
+	for _, item := range src {
+		si, ok := item.(map[string]interface{})
+		key, hasKey := si[m.key]
+		i := -1
+		for j, d := range dst {
+			if di, ok := d.(map[string]interface{}); ok && hasKey {
+				if dk, ok := di[m.key]; ok && equalValues(dk, key) {
+					i = j
+					break
+				}
+			}
+		}
+		if ok && i >= 0 {
+			dst[i] = m.merge(dst[i], si, path)
+		} else {
+			dst = append(dst, item)
+		}
+	}
+	return dst
+}
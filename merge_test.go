@@ -0,0 +1,97 @@
+package yaml
+
+import "testing"
+
+func TestMerge(t *testing.T) {
+	base := `server:
+  host: localhost
+  port: 8080
+  tls: {enabled: false}
+features: [a, b]
+backends:
+- {name: api, url: http://api, timeout: 5}
+- {name: db, url: http://db}
+`
+	override := `server:
+  port: 9090
+  tls: {enabled: true, cert: /etc/cert}
+features: [c]
+backends:
+- {name: api, timeout: 10}
+- {name: cache, url: http://cache}
+log: ~
+`
+	for _, tc := range []struct {
+		name string
+		opts []MergeOpt
+		want string
+	}{
+		{"replace", nil, `backends:
+- name: api
+  timeout: 10
+- name: cache
+  url: http://cache
+features:
+- c
+log: null
+server:
+  host: localhost
+  port: 9090
+  tls:
+    cert: /etc/cert
+    enabled: true
+`},
+		{"append", []MergeOpt{MergeLists(ListAppend), MergeListsAt("backends", ListReplace)}, `backends:
+- name: api
+  timeout: 10
+- name: cache
+  url: http://cache
+features:
+- a
+- b
+- c
+log: null
+server:
+  host: localhost
+  port: 9090
+  tls:
+    cert: /etc/cert
+    enabled: true
+`},
+		{"merge by key", []MergeOpt{MergeListsAt("backends", ListMergeByKey)}, `backends:
+- name: api
+  timeout: 10
+  url: http://api
+- name: db
+  url: http://db
+- name: cache
+  url: http://cache
+features:
+- c
+log: null
+server:
+  host: localhost
+  port: 9090
+  tls:
+    cert: /etc/cert
+    enabled: true
+`},
+	} {
+		got, err := Merge([]byte(base), []byte(override), tc.opts...)
+		if err != nil {
+			t.Fatalf("%s: Merge() = %v", tc.name, err)
+		}
+		if string(got) != tc.want {
+			t.Errorf("%s: Merge() = %#q; want %#q", tc.name, string(got), tc.want)
+		}
+	}
+
+	got, err := Merge([]byte("- {id: 1, a: x}\n- 2\n"), []byte("- {id: 1, b: w}\n- {a: z}\n"), MergeLists(ListMergeByKey), MergeKey("id"))
+	if want := "- a: x\n  b: w\n  id: 1\n- 2\n- a: z\n"; err != nil || string(got) != want {
+		t.Errorf("Merge() = %#q, %v; want %#q", string(got), err, want)
+	}
+
+	if _, err := Merge([]byte("a: 1\n"), []byte("a: [")); err == nil {
+		t.Error("Merge() of invalid YAML returned no error")
+	}
+}
@@ -0,0 +1,40 @@
+package yaml
+
+// ApplyMergePatch applies the RFC 7386 JSON Merge Patch patch to the YAML
+// document doc and returns the patched document as YAML. The patch may be
+// written in JSON or YAML. Mappings in the patch are merged into those of the
+// document, a null value removes its key, and any other value, sequences
+// included, replaces the value in the document. Like JSONToYAML, the result
+// has its keys sorted and no comments.
+func ApplyMergePatch(doc, patch []byte) ([]byte, error) {
+	obj, err := decodeDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+	p, err := decodeDocument(patch)
+	if err != nil {
+		return nil, err
+	}
+	return encodeDocument(mergePatch(obj, p))
+}
+
+// mergePatch returns target with patch applied, as the MergePatch function
+// of RFC 7386 does.
+func mergePatch(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = map[string]interface{}{}
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+		} else {
+			t[k] = mergePatch(t[k], v)
+		}
+	}
+	return t
+}
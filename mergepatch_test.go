@@ -0,0 +1,54 @@
+package yaml
+
+import "testing"
+
+func TestApplyMergePatch(t *testing.T) {
+	doc := `metadata:
+  name: web
+  labels: {app: web, tier: front}
+spec:
+  replicas: 3
+  ports: [80, 443]
+`
+	for _, tc := range []struct {
+		patch, want string
+	}{
+		{`{"metadata": {"labels": {"tier": null, "team": "core"}}, "spec": {"ports": [8080]}}`, `metadata:
+  labels:
+    app: web
+    team: core
+  name: web
+spec:
+  ports:
+  - 8080
+  replicas: 3
+`},
+		{"spec:\n  replicas: 5\nstatus: {ready: true}\nmetadata: ~\n", `spec:
+  ports:
+  - 80
+  - 443
+  replicas: 5
+status:
+  ready: true
+`},
+		{"[1, 2]", "- 1\n- 2\n"},
+	} {
+		got, err := ApplyMergePatch([]byte(doc), []byte(tc.patch))
+		if err != nil {
+			t.Fatalf("ApplyMergePatch(%#q) = %v", tc.patch, err)
+		}
+		if string(got) != tc.want {
+			t.Errorf("ApplyMergePatch(%#q) = %#q; want %#q", tc.patch, string(got), tc.want)
+		}
+	}
+
+	// Keys of a patch that is merged into a scalar replace it.
+	got, err := ApplyMergePatch([]byte("a: 1\n"), []byte("a: {b: 2, c: null}\n"))
+	if err != nil || string(got) != "a:\n  b: 2\n" {
+		t.Errorf("ApplyMergePatch() = %#q, %v", string(got), err)
+	}
+
+	if _, err := ApplyMergePatch([]byte(doc), []byte("a: [")); err == nil {
+		t.Error("ApplyMergePatch() of invalid YAML returned no error")
+	}
+}
@@ -0,0 +1,52 @@
+package yaml
+
+import "time"
+
+// Metrics receives measures of the documents that Marshal and Unmarshal
+// convert, to be exported by a metrics library such as expvar or the
+// Prometheus client, which this package does not depend on. The names of the
+// measures are those of Prometheus, made of the operation, "marshal" or
+// "unmarshal", and the measure:
+//
+//	yaml_<op>_documents_total  counter of the documents converted
+//	yaml_<op>_bytes_total      counter of the bytes of YAML read or written
+//	yaml_<op>_errors_total     counter of the conversions that failed
+//	yaml_<op>_duration_seconds histogram of the time conversions take
+//
+// Failed conversions are counted as documents too, so that the rate of
+// errors is the one counter over the other. The methods must be safe for
+// concurrent use.
+type Metrics interface {
+	// Add adds delta to the counter name.
+	Add(name string, delta int64)
+	// Observe records value in the histogram name.
+	Observe(name string, value float64)
+}
+
+// WithMetrics makes Marshal report the documents it writes to m.
+func WithMetrics(m Metrics) MarshalOpt {
+	return func(e *encoder) {
+		e.metrics = m
+	}
+}
+
+// DecodeMetrics makes Unmarshal, and the Decoders and functions built upon
+// it, report the documents they decode to m. Each document that
+// UnmarshalDocuments decodes is reported on its own.
+func DecodeMetrics(m Metrics) JSONOpt {
+	return decodeOpt(func(o *decodeOptions) {
+		o.metrics = m
+	})
+}
+
+// recordMetrics reports to m a conversion of the operation op that started
+// at start, with n bytes of YAML, and that failed with err, if it is not nil.
+func recordMetrics(m Metrics, op string, start time.Time, n int, err error) {
+	prefix := "yaml_" + op + "_"
+	m.Add(prefix+"documents_total", 1)
+	m.Add(prefix+"bytes_total", int64(n))
+	if err != nil {
+		m.Add(prefix+"errors_total", 1)
+	}
+	m.Observe(prefix+"duration_seconds", time.Since(start).Seconds())
+}
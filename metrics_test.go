@@ -0,0 +1,78 @@
+package yaml
+
+import (
+	"expvar"
+	"sync"
+	"testing"
+)
+
+// expvarMetrics exports Metrics as expvar variables, the histograms as the
+// sum of their values.
+type expvarMetrics struct {
+	m *expvar.Map
+
+	mu           sync.Mutex
+	observations map[string]int
+}
+
+func (e *expvarMetrics) Add(name string, delta int64) {
+	e.m.Add(name, delta)
+}
+
+func (e *expvarMetrics) Observe(name string, value float64) {
+	e.m.AddFloat(name, value)
+	e.mu.Lock()
+	e.observations[name]++
+	e.mu.Unlock()
+}
+
+func TestMetrics(t *testing.T) {
+	m := &expvarMetrics{m: new(expvar.Map).Init(), observations: map[string]int{}}
+	counter := func(name string) int64 {
+		v, _ := m.m.Get(name).(*expvar.Int)
+		if v == nil {
+			return 0
+		}
+		return v.Value()
+	}
+
+	var v struct {
+		A int `json:"a"`
+	}
+	if err := Unmarshal([]byte("a: 1\n"), &v, DecodeMetrics(m)); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	if err := Unmarshal([]byte("a: x\n"), &v, DecodeMetrics(m)); err == nil {
+		t.Fatalf("Unmarshal() = nil, want an error")
+	}
+	var docs []map[string]int
+	stream := "a: 1\n---\na: 2\n"
+	if err := UnmarshalDocuments([]byte(stream), &docs, DecodeMetrics(m)); err != nil {
+		t.Fatalf("UnmarshalDocuments() = %v", err)
+	}
+	y, err := Marshal(v, WithMetrics(m))
+	if err != nil {
+		t.Fatalf("Marshal() = %v", err)
+	}
+
+	for name, want := range map[string]int64{
+		"yaml_unmarshal_documents_total": 4,
+		"yaml_unmarshal_bytes_total":     int64(len("a: 1\n")*2 + len(stream)),
+		"yaml_unmarshal_errors_total":    1,
+		"yaml_marshal_documents_total":   1,
+		"yaml_marshal_bytes_total":       int64(len(y)),
+		"yaml_marshal_errors_total":      0,
+	} {
+		if got := counter(name); got != want {
+			t.Errorf("%s = %d, want %d", name, got, want)
+		}
+	}
+	for name, want := range map[string]int{
+		"yaml_unmarshal_duration_seconds": 4,
+		"yaml_marshal_duration_seconds":   1,
+	} {
+		if got := m.observations[name]; got != want {
+			t.Errorf("observations of %s = %d, want %d", name, got, want)
+		}
+	}
+}
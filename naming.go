@@ -0,0 +1,137 @@
+package yaml
+
+import (
+	"strings"
+	"unicode"
+)
+
+// FieldNamer converts the name of a Go struct field into the key it is
+// written as. It only applies to fields without a name in their json tag.
+type FieldNamer func(fieldName string) string
+
+// FieldNaming converts the names of untagged struct fields with n when
+// marshaling, e.g. FieldNaming(SnakeCase) writes the field UserID as
+// "user_id".
+func FieldNaming(n FieldNamer) MarshalOpt {
+	return func(e *encoder) {
+		e.fieldNaming = n
+	}
+}
+
+// DecodeFieldNaming matches the keys of the YAML document against the names
+// of untagged struct fields as converted by n, e.g. DecodeFieldNaming(SnakeCase)
+// decodes "user_id" into the field UserID. Like encoding/json, keys that do
+// not match exactly are matched case-insensitively.
+func DecodeFieldNaming(n FieldNamer) JSONOpt {
+	return decodeOpt(func(o *decodeOptions) {
+		o.fieldNaming = n
+	})
+}
+
+// TagPrecedence selects which struct tags name the keys of struct fields.
+type TagPrecedence int
+
+const (
+	// JSONTagsOnly names fields by their json tags and ignores yaml tags.
+	JSONTagsOnly TagPrecedence = iota
+	// YAMLTagsFirst names fields by their yaml tags, falling back to their
+	// json tags. Fields tagged yaml:"-" are left out.
+	YAMLTagsFirst
+	// JSONTagsFirst names fields by their json tags, falling back to their
+	// yaml tags. Fields tagged yaml:"-" are left out.
+	JSONTagsFirst
+)
+
+// StructTags sets which struct tags name the keys of struct fields when
+// marshaling. The default is JSONTagsOnly. Only the names given by yaml tags
+// are used; options such as omitempty are always taken from the json tag.
+func StructTags(p TagPrecedence) MarshalOpt {
+	return func(e *encoder) {
+		e.tags = p
+	}
+}
+
+// DecodeStructTags sets which struct tags name the keys of struct fields when
+// unmarshaling. The default is JSONTagsOnly. Fields tagged json:"-" cannot be
+// decoded into regardless of their yaml tag.
+func DecodeStructTags(p TagPrecedence) JSONOpt {
+	return decodeOpt(func(o *decodeOptions) {
+		o.tags = p
+	})
+}
+
+// fieldKey returns the key f is written as in YAML.
+func fieldKey(f *field, tags TagPrecedence, naming FieldNamer) string {
+	switch tags {
+	case YAMLTagsFirst:
+		if f.yamlName != "" {
+			return f.yamlName
+		}
+	case JSONTagsFirst:
+		if !f.tag && f.yamlName != "" {
+			return f.yamlName
+		}
+	}
+	if !f.tag && naming != nil {
+		return naming(f.name)
+	}
+	return f.name
+}
+
+// skipField reports whether f is left out of the YAML document.
+func skipField(f *field, tags TagPrecedence) bool {
+	return tags != JSONTagsOnly && f.yamlSkip
+}
+
+// CamelCase converts a field name such as "APIVersion" to "apiVersion".
+func CamelCase(fieldName string) string {
+	words := splitWords(fieldName)
+	if len(words) == 0 {
+		return ""
+	}
+	words[0] = strings.ToLower(words[0])
+	return strings.Join(words, "")
+}
+
+// SnakeCase converts a field name such as "APIVersion" to "api_version".
+func SnakeCase(fieldName string) string {
+	return strings.ToLower(strings.Join(splitWords(fieldName), "_"))
+}
+
+// KebabCase converts a field name such as "APIVersion" to "api-version".
+func KebabCase(fieldName string) string {
+	return strings.ToLower(strings.Join(splitWords(fieldName), "-"))
+}
+
+// splitWords splits a Go identifier into its words, keeping initialisms such
+// as "HTTP" in "HTTPServer" together.
+func splitWords(s string) []string {
+	var words []string
+	rs := []rune(s)
+	start := 0
+	for i := 0; i < len(rs); i++ {
+		if rs[i] == '_' {
+			if i > start {
+				words = append(words, string(rs[start:i]))
+			}
+			start = i + 1
+			continue
+		}
+		if i == start || !unicode.IsUpper(rs[i]) {
+			continue
+		}
+		// An upper case letter starts a new word after a lower case letter
+		// or a digit, and ends an initialism when a lower case letter
+		// follows it.
+		prev := rs[i-1]
+		nextLower := i+1 < len(rs) && unicode.IsLower(rs[i+1])
+		if unicode.IsLower(prev) || unicode.IsDigit(prev) || unicode.IsUpper(prev) && nextLower {
+			words = append(words, string(rs[start:i]))
+			start = i
+		}
+	}
+	if start < len(rs) {
+		words = append(words, string(rs[start:]))
+	}
+	return words
+}
@@ -0,0 +1,133 @@
+package yaml
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFieldNamers(t *testing.T) {
+	for _, tc := range []struct {
+		name                string
+		camel, snake, kebab string
+	}{
+		{"Name", "name", "name", "name"},
+		{"APIVersion", "apiVersion", "api_version", "api-version"},
+		{"UserID", "userID", "user_id", "user-id"},
+		{"HTTPServerURL", "httpServerURL", "http_server_url", "http-server-url"},
+		{"Field2Name", "field2Name", "field2_name", "field2-name"},
+		{"Legacy_Field", "legacyField", "legacy_field", "legacy-field"},
+		{"lower", "lower", "lower", "lower"},
+	} {
+		if got := CamelCase(tc.name); got != tc.camel {
+			t.Errorf("CamelCase(%q) = %q; want %q", tc.name, got, tc.camel)
+		}
+		if got := SnakeCase(tc.name); got != tc.snake {
+			t.Errorf("SnakeCase(%q) = %q; want %q", tc.name, got, tc.snake)
+		}
+		if got := KebabCase(tc.name); got != tc.kebab {
+			t.Errorf("KebabCase(%q) = %q; want %q", tc.name, got, tc.kebab)
+		}
+	}
+}
+
+type NamingInner struct {
+	MaxRetries int
+}
+
+type NamingTest struct {
+	UserID  string
+	Tagged  string `json:"TAGGED"`
+	Inner   NamingInner
+	Inners  []NamingInner
+	ByName  map[string]*NamingInner
+	Skipped string `json:"-"`
+}
+
+func TestFieldNaming(t *testing.T) {
+	v := NamingTest{
+		UserID: "u",
+		Tagged: "t",
+		Inner:  NamingInner{1},
+		Inners: []NamingInner{{2}},
+		ByName: map[string]*NamingInner{"MyKey": {3}},
+	}
+	want := "TAGGED: t\n" +
+		"by_name:\n  MyKey:\n    max_retries: 3\n" +
+		"inner:\n  max_retries: 1\n" +
+		"inners:\n- max_retries: 2\n" +
+		"user_id: u\n"
+
+	y, err := Marshal(v, FieldNaming(SnakeCase))
+	if err != nil {
+		t.Fatalf("Marshal() = %v", err)
+	}
+	if string(y) != want {
+		t.Errorf("Marshal() = %#q; want %#q", string(y), want)
+	}
+
+	var got NamingTest
+	if err := Unmarshal(y, &got, DecodeFieldNaming(SnakeCase)); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	if !reflect.DeepEqual(got, v) {
+		t.Errorf("Unmarshal() = %+v; want %+v", got, v)
+	}
+
+	// Without the option, the converted names do not match any field.
+	got = NamingTest{}
+	if err := Unmarshal(y, &got); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	if got.UserID != "" || got.Inner.MaxRetries != 0 {
+		t.Errorf("Unmarshal() = %+v; want UserID and Inner.MaxRetries unset", got)
+	}
+}
+
+type TagsTest struct {
+	Both     string `json:"jsonBoth" yaml:"yamlBoth"`
+	JSONOnly string `json:"jsonOnly"`
+	YAMLOnly string `yaml:"yamlOnly"`
+	Neither  string
+	Hidden   string `yaml:"-"`
+}
+
+func TestStructTags(t *testing.T) {
+	v := TagsTest{"b", "j", "x", "z", "h"}
+	for _, tc := range []struct {
+		tags TagPrecedence
+		want string
+	}{
+		{JSONTagsOnly, "Hidden: h\nNeither: z\nYAMLOnly: x\njsonBoth: b\njsonOnly: j\n"},
+		{YAMLTagsFirst, "Neither: z\njsonOnly: j\nyamlBoth: b\nyamlOnly: x\n"},
+		{JSONTagsFirst, "Neither: z\njsonBoth: b\njsonOnly: j\nyamlOnly: x\n"},
+	} {
+		y, err := Marshal(v, StructTags(tc.tags))
+		if err != nil {
+			t.Fatalf("Marshal(%v) = %v", tc.tags, err)
+		}
+		if string(y) != tc.want {
+			t.Errorf("Marshal(%v) = %#q; want %#q", tc.tags, string(y), tc.want)
+		}
+
+		want := v
+		if tc.tags != JSONTagsOnly {
+			want.Hidden = ""
+		}
+		var got TagsTest
+		if err := Unmarshal(y, &got, DecodeStructTags(tc.tags)); err != nil {
+			t.Fatalf("Unmarshal(%v) = %v", tc.tags, err)
+		}
+		if got != want {
+			t.Errorf("Unmarshal(%v) = %+v; want %+v", tc.tags, got, want)
+		}
+	}
+
+	// A yaml:"-" field is not decoded even if the document has its key.
+	var got TagsTest
+	if err := Unmarshal([]byte("Hidden: h\n"), &got, DecodeStructTags(YAMLTagsFirst)); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	if got.Hidden != "" {
+		t.Errorf("Unmarshal() decoded Hidden = %q", got.Hidden)
+	}
+}
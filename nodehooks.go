@@ -0,0 +1,68 @@
+package yaml
+
+import (
+	yaml3 "gopkg.in/yaml.v3"
+)
+
+// NodeHook transforms a node of a YAML document, as parsed by yaml.v3, before
+// the document is converted to JSON. path is the path of the node, such as
+// "spec.containers[0]", or "" for the root. A hook may change the node and
+// the nodes it holds in place, such as to rename a deprecated key of a
+// mapping, add a key that is missing or remove keys; the nodes it adds to a
+// mapping or sequence are then passed to the hooks too.
+type NodeHook func(path string, n *yaml3.Node) error
+
+// DecodeNodeHooks registers hooks that are applied in order to each node of
+// the YAML document, a node before those it holds, after includes and
+// environment variables are expanded. Unlike DecodeHooks, they run before any
+// value is decoded, so they can rewrite the keys of mappings. Aliases are
+// passed to the hooks but not followed, so a node is only passed once, at its
+// anchor.
+func DecodeNodeHooks(hooks ...NodeHook) JSONOpt {
+	return decodeOpt(func(o *decodeOptions) {
+		o.nodeHooks = append(o.nodeHooks, hooks...)
+	})
+}
+
+// applyNodeHooks returns the document y transformed by the NodeHooks of o.
+func (o *decodeOptions) applyNodeHooks(y []byte) ([]byte, error) {
+	var doc yaml3.Node
+	if err := yaml3.Unmarshal(y, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return y, nil
+	}
+	if err := o.applyNodeHooksTo(doc.Content[0], nil); err != nil {
+		return nil, err
+	}
+	untagMergeKeys(&doc)
+	return yaml3.Marshal(&doc)
+}
+
+func (o *decodeOptions) applyNodeHooksTo(n *yaml3.Node, path *keyPath) error {
+	for _, h := range o.nodeHooks {
+		if err := h(path.String(), n); err != nil {
+			return path.wrap(err)
+		}
+	}
+	switch n.Kind {
+	case yaml3.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			cp := path
+			if k := n.Content[i]; k.Kind == yaml3.ScalarNode && k.ShortTag() != "!!merge" {
+				cp = path.key(k.Value)
+			}
+			if err := o.applyNodeHooksTo(n.Content[i+1], cp); err != nil {
+				return err
+			}
+		}
+	case yaml3.SequenceNode:
+		for i, c := range n.Content {
+			if err := o.applyNodeHooksTo(c, path.index(i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
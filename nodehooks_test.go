@@ -0,0 +1,85 @@
+package yaml
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	yaml3 "gopkg.in/yaml.v3"
+)
+
+func TestDecodeNodeHooks(t *testing.T) {
+	// Rename the deprecated key "image_name" of containers to "image".
+	rename := func(path string, n *yaml3.Node) error {
+		if n.Kind != yaml3.MappingNode || !strings.HasPrefix(path, "containers[") {
+			return nil
+		}
+		for i := 0; i < len(n.Content); i += 2 {
+			if n.Content[i].Value == "image_name" {
+				n.Content[i].Value = "image"
+			}
+		}
+		return nil
+	}
+	// Remove the vendor extensions, keys starting with "x-".
+	strip := func(path string, n *yaml3.Node) error {
+		if n.Kind != yaml3.MappingNode {
+			return nil
+		}
+		content := n.Content[:0]
+		for i := 0; i < len(n.Content); i += 2 {
+			if !strings.HasPrefix(n.Content[i].Value, "x-") {
+				content = append(content, n.Content[i], n.Content[i+1])
+			}
+		}
+		n.Content = content
+		return nil
+	}
+	// Give the root a default for "replicas".
+	defaults := func(path string, n *yaml3.Node) error {
+		if path != "" || n.Kind != yaml3.MappingNode {
+			return nil
+		}
+		for i := 0; i < len(n.Content); i += 2 {
+			if n.Content[i].Value == "replicas" {
+				return nil
+			}
+		}
+		var v yaml3.Node
+		if err := v.Encode(map[string]int{"replicas": 1}); err != nil {
+			return err
+		}
+		n.Content = append(n.Content, v.Content...)
+		return nil
+	}
+
+	y := `x-generated-by: tool
+containers:
+- name: web
+  image_name: nginx
+  x-note: hand-tuned
+`
+	var got interface{}
+	if err := Unmarshal([]byte(y), &got, DecodeNodeHooks(rename, strip, defaults)); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"containers": []interface{}{map[string]interface{}{"name": "web", "image": "nginx"}},
+		"replicas":   float64(1),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal() = %v; want %v", got, want)
+	}
+
+	fail := func(path string, n *yaml3.Node) error {
+		if n.Kind == yaml3.ScalarNode && n.Value == "nginx" {
+			return errors.New("image must be pinned")
+		}
+		return nil
+	}
+	err := Unmarshal([]byte(y), &got, DecodeNodeHooks(fail))
+	if err == nil || !strings.Contains(err.Error(), "containers[0].image_name: image must be pinned") {
+		t.Errorf("Unmarshal() with a failing hook = %v", err)
+	}
+}
@@ -0,0 +1,288 @@
+package yaml
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"gopkg.in/yaml.v2"
+)
+
+// NonFinite selects what becomes of NaN and infinite floats, which YAML has
+// (as .nan, .inf and -.inf) but JSON does not.
+type NonFinite int
+
+const (
+	// NonFiniteError fails on NaN and infinite floats. This is the default.
+	NonFiniteError NonFinite = iota
+	// NonFiniteNull turns NaN and infinite floats into null.
+	NonFiniteNull
+	// NonFiniteString turns NaN and infinite floats into the strings ".nan",
+	// ".inf" and "-.inf".
+	NonFiniteString
+	// NonFiniteFloat writes NaN and infinite floats as the YAML floats .nan,
+	// .inf and -.inf. It only applies to Marshal; JSON cannot hold them.
+	NonFiniteFloat
+)
+
+// NonFiniteFloats sets what Marshal writes NaN and infinite floats as.
+func NonFiniteFloats(p NonFinite) MarshalOpt {
+	return func(e *encoder) {
+		e.nonFinite = p
+	}
+}
+
+// DecodeNonFiniteFloats sets what the conversion to JSON turns .nan, .inf and
+// -.inf into, unless they are decoded into a string, which still gets "NaN",
+// "+Inf" or "-Inf". NonFiniteFloat acts like NonFiniteError.
+func DecodeNonFiniteFloats(p NonFinite) JSONOpt {
+	return decodeOpt(func(o *decodeOptions) {
+		o.nonFinite = p
+	})
+}
+
+// value returns the JSON-compatible value the NaN or infinite float f
+// converts to.
+func (p NonFinite) value(f float64) (interface{}, error) {
+	switch p {
+	case NonFiniteNull:
+		return nil, nil
+	case NonFiniteString:
+		return formatFloat(f), nil
+	}
+	return nil, fmt.Errorf("cannot convert %s to JSON", formatFloat(f))
+}
+
+// isNonFiniteError reports whether err is the error json.Marshal returns for
+// NaN and infinite floats.
+func isNonFiniteError(err error) bool {
+	uerr, ok := err.(*json.UnsupportedValueError)
+	if !ok {
+		return false
+	}
+	switch uerr.Str {
+	case "NaN", "+Inf", "-Inf":
+		return true
+	}
+	return false
+}
+
+// marshalValue marshals o by building the decoded JSON that json.Marshal
+// would have produced for it itself, for values json.Marshal refuses, which
+// hold NaN or infinite floats or values of unsupported types, and for values
+// that converters apply to.
+func (e *encoder) marshalValue(o interface{}) ([]byte, error) {
+	v := reflect.ValueOf(o)
+	jsonObj, err := e.jsonValue(v, 0)
+	if jsonObj == (skippedValue{}) {
+		jsonObj = nil
+	}
+	if ute, ok := err.(*json.UnsupportedTypeError); ok {
+		if err := unsupportedTypeError(v, ute.Type); err != nil {
+			return nil, err
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling into JSON: %v", err)
+	}
+	y, err := e.encode(jsonObj, v)
+	if err != nil {
+		return nil, fmt.Errorf("error converting JSON to YAML: %v", err)
+	}
+	return y, nil
+}
+
+// maxJSONDepth bounds the depth of the values jsonValue walks, as they may be
+// cyclic.
+const maxJSONDepth = 10000
+
+// jsonValue returns the value decodeJSON produces for the JSON encoding of v,
+// except that NaN and infinite floats are handled as e.nonFinite says, and
+// values of unsupported types as e.unsupported says.
+func (e *encoder) jsonValue(v reflect.Value, depth int) (interface{}, error) {
+	if depth > maxJSONDepth {
+		return nil, fmt.Errorf("json: unsupported value: encountered a cycle via %v", v.Type())
+	}
+	if !v.IsValid() {
+		return nil, nil
+	}
+	if e.converters != nil {
+		if x, ok, err := e.convert(v); ok {
+			if err != nil {
+				return nil, err
+			}
+			return e.jsonValue(reflect.ValueOf(x), depth+1)
+		}
+	}
+	if marshalsItself(v) {
+		if v.Kind() == reflect.Ptr && v.IsNil() {
+			return nil, nil
+		}
+		if v.Kind() != reflect.Ptr && v.CanAddr() {
+			v = v.Addr()
+		}
+		b, err := json.Marshal(v.Interface())
+		if err != nil {
+			return nil, err
+		}
+		return decodeJSON(b)
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return e.jsonValue(v.Elem(), depth+1)
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return jsonNumberValue(strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return jsonNumberValue(strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			if e.nonFinite == NonFiniteFloat {
+				return f, nil
+			}
+			return e.nonFinite.value(f)
+		}
+		b, err := json.Marshal(v.Interface())
+		if err != nil {
+			return nil, err
+		}
+		return jsonNumberValue(string(b))
+	case reflect.String:
+		if v.Type() == reflect.TypeOf(json.Number("")) {
+			return jsonNumberValue(v.String())
+		}
+		return v.String(), nil
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil, nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 && !marshalsItself(reflect.New(v.Type().Elem()).Elem()) {
+			return base64.StdEncoding.EncodeToString(v.Bytes()), nil
+		}
+		fallthrough
+	case reflect.Array:
+		s := make([]interface{}, v.Len())
+		for i := range s {
+			var err error
+			if s[i], err = e.jsonValue(v.Index(i), depth+1); err != nil {
+				return nil, err
+			}
+			if s[i] == (skippedValue{}) {
+				s[i] = nil
+			}
+		}
+		return s, nil
+	case reflect.Map:
+		if v.IsNil() {
+			return nil, nil
+		}
+		entries := make(map[string]reflect.Value, v.Len())
+		keys := make([]string, 0, v.Len())
+		for _, k := range v.MapKeys() {
+			s, ok := mapKeyString(k)
+			if !ok {
+				return e.unsupportedValue(v.Type())
+			}
+			entries[s] = v.MapIndex(k)
+			keys = append(keys, s)
+		}
+		sort.Strings(keys)
+		m := make(yaml.MapSlice, 0, len(keys))
+		for _, k := range keys {
+			x, err := e.jsonValue(entries[k], depth+1)
+			if err != nil {
+				return nil, err
+			}
+			if x == (skippedValue{}) {
+				continue
+			}
+			m = append(m, yaml.MapItem{Key: k, Value: x})
+		}
+		return m, nil
+	case reflect.Struct:
+		m := yaml.MapSlice{}
+		for i := range cachedTypeFields(v.Type()) {
+			f := &cachedTypeFields(v.Type())[i]
+			fv := fieldByIndex(v, f.index)
+			if !fv.IsValid() || f.omitEmpty && isEmptyValue(fv) || f.omitZero && isZero(fv) {
+				continue
+			}
+			x, err := e.jsonValue(fv, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			if x == (skippedValue{}) {
+				continue
+			}
+			if f.quoted {
+				x = quotedJSONValue(x)
+			}
+			m = append(m, yaml.MapItem{Key: f.name, Value: x})
+		}
+		return m, nil
+	}
+	return e.unsupportedValue(v.Type())
+}
+
+// quotedJSONValue returns the value decodeJSON produces for the scalar x when
+// it is written with the ",string" option.
+func quotedJSONValue(x interface{}) interface{} {
+	switch x.(type) {
+	case string, bool, int, int64, uint64, float64:
+		if b, err := json.Marshal(x); err == nil {
+			return string(b)
+		}
+	}
+	return x
+}
+
+// isEmptyValue reports whether v is empty for the omitempty option, as in
+// encoding/json.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
+
+// isZero reports whether v is zero for the omitzero option, as in
+// encoding/json: it is either a nil pointer or a value whose IsZero method
+// returns true or, if it has no such method, reflect.Value.IsZero does.
+func isZero(v reflect.Value) bool {
+	if !v.IsValid() {
+		return true
+	}
+	if z, ok := v.Interface().(interface{ IsZero() bool }); ok {
+		if v.Kind() == reflect.Ptr && v.IsNil() {
+			return true
+		}
+		return z.IsZero()
+	}
+	if v.CanAddr() {
+		if z, ok := v.Addr().Interface().(interface{ IsZero() bool }); ok {
+			return z.IsZero()
+		}
+	}
+	return v.IsZero()
+}
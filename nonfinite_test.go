@@ -0,0 +1,93 @@
+package yaml
+
+import (
+	"math"
+	"testing"
+)
+
+type NonFiniteTest struct {
+	A float64            `json:"a"`
+	B []float32          `json:"b,omitempty"`
+	C map[string]float64 `json:"c,omitempty"`
+	D string             `json:"d"`
+}
+
+func TestNonFiniteFloats(t *testing.T) {
+	v := NonFiniteTest{
+		A: math.NaN(),
+		B: []float32{float32(math.Inf(1)), 1.5},
+		C: map[string]float64{"x": math.Inf(-1)},
+		D: "s",
+	}
+
+	if _, err := Marshal(v); err == nil {
+		t.Errorf("Marshal() = nil; want an error")
+	}
+	for _, tc := range []struct {
+		p    NonFinite
+		want string
+	}{
+		{NonFiniteError, ""},
+		{NonFiniteNull, "a: null\nb:\n- null\n- 1.5\nc:\n  x: null\nd: s\n"},
+		{NonFiniteString, "a: \".nan\"\nb:\n- \".inf\"\n- 1.5\nc:\n  x: \"-.inf\"\nd: s\n"},
+		{NonFiniteFloat, "a: .nan\nb:\n- .inf\n- 1.5\nc:\n  x: -.inf\nd: s\n"},
+	} {
+		y, err := Marshal(v, NonFiniteFloats(tc.p))
+		if tc.want == "" {
+			if err == nil {
+				t.Errorf("Marshal(%v) = nil; want an error", tc.p)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Marshal(%v) = %v", tc.p, err)
+		}
+		if string(y) != tc.want {
+			t.Errorf("Marshal(%v) = %#q; want %#q", tc.p, string(y), tc.want)
+		}
+	}
+
+	// The strings NonFiniteString writes read back as strings.
+	y, err := Marshal(map[string]interface{}{"a": math.Inf(1)}, NonFiniteFloats(NonFiniteString))
+	if err != nil {
+		t.Fatalf("Marshal() = %v", err)
+	}
+	var s map[string]string
+	if err := Unmarshal(y, &s); err != nil || s["a"] != ".inf" {
+		t.Errorf("Unmarshal(%#q) = %v, %v; want .inf", string(y), s, err)
+	}
+}
+
+func TestDecodeNonFiniteFloats(t *testing.T) {
+	y := []byte("a: .nan\nb: [.inf, 1.5]\nd: -.inf\n")
+
+	var v map[string]interface{}
+	if err := Unmarshal(y, &v); err == nil {
+		t.Errorf("Unmarshal() = nil; want an error")
+	}
+
+	v = nil
+	if err := Unmarshal(y, &v, DecodeNonFiniteFloats(NonFiniteNull)); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	if v["a"] != nil || v["b"].([]interface{})[0] != nil || v["d"] != nil {
+		t.Errorf("Unmarshal() = %v; want nulls", v)
+	}
+
+	var s struct {
+		A string    `json:"a"`
+		B []float64 `json:"b"`
+		D string    `json:"d"`
+	}
+	if err := Unmarshal(y, &s, DecodeNonFiniteFloats(NonFiniteString)); err == nil {
+		t.Errorf("Unmarshal() = nil; want an error decoding \".inf\" into a float64")
+	}
+	s.B = nil
+	y = []byte("a: .nan\nd: -.inf\n")
+	if err := Unmarshal(y, &s); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	if s.A != "NaN" || s.D != "-Inf" {
+		t.Errorf("Unmarshal() = %+v; want NaN and -Inf", s)
+	}
+}
@@ -0,0 +1,68 @@
+package yaml
+
+import (
+	"math"
+	"math/big"
+	"strings"
+)
+
+// ValidateOpenAPISchema is like ValidateSchema for the Schema Objects of
+// OpenAPI 3, such as the openAPIV3Schema of a Kubernetes
+// CustomResourceDefinition. spec is the document that holds the schema, such
+// as an OpenAPI description or a CRD, and ref refers to the schema in it, as
+// in "#/components/schemas/Pet" or
+// "#/spec/versions/0/schema/openAPIV3Schema"; with a ref of "", spec is the
+// schema itself. References in the schema are resolved within spec.
+//
+// On top of JSON Schema, null is valid for a schema with "nullable: true",
+// and the formats int32, int64, float and double are checked for numbers and
+// byte, for base64 text, for strings.
+func ValidateOpenAPISchema(doc, spec []byte, ref string) []ValidationError {
+	return validateSchema(doc, spec, ref, true)
+}
+
+// SchemaError is returned by Unmarshal when the document fails the schema
+// given with ValidateWithOpenAPISchema.
+type SchemaError struct {
+	Errors []ValidationError
+}
+
+func (e *SchemaError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return "schema validation failed: " + strings.Join(msgs, "; ")
+}
+
+// ValidateWithOpenAPISchema makes Unmarshal validate the document with
+// ValidateOpenAPISchema before decoding it, and return a *SchemaError holding
+// the failures, if any, instead of decoding it.
+func ValidateWithOpenAPISchema(spec []byte, ref string) JSONOpt {
+	return decodeOpt(func(o *decodeOptions) {
+		o.validate = func(y []byte) error {
+			if errs := ValidateOpenAPISchema(y, spec, ref); errs != nil {
+				return &SchemaError{Errors: errs}
+			}
+			return nil
+		}
+	})
+}
+
+// numberFormatMatches reports whether n is within the range of the OpenAPI
+// number format f. Formats it does not know match any number.
+func numberFormatMatches(f string, n *big.Rat) bool {
+	switch f {
+	case "int32":
+		return n.IsInt() && n.Num().IsInt64() && n.Num().Int64() >= math.MinInt32 && n.Num().Int64() <= math.MaxInt32
+	case "int64":
+		return n.IsInt() && n.Num().IsInt64()
+	case "float":
+		x, _ := n.Float64()
+		return math.Abs(x) <= math.MaxFloat32
+	case "double":
+		x, _ := n.Float64()
+		return !math.IsInf(x, 0)
+	}
+	return true
+}
@@ -0,0 +1,105 @@
+package yaml
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const testOpenAPISpec = `
+openapi: 3.0.3
+components:
+  schemas:
+    Port:
+      type: integer
+      format: int32
+      minimum: 1
+    Service:
+      type: object
+      required: [name]
+      properties:
+        name: {type: string}
+        owner: {type: string, nullable: true}
+        ports:
+          type: array
+          items: {$ref: "#/components/schemas/Port"}
+        cert: {type: string, format: byte}
+        weight: {type: number, format: float}
+`
+
+func TestValidateOpenAPISchema(t *testing.T) {
+	ref := "#/components/schemas/Service"
+	valid := "name: web\nowner: ~\nports: [80]\ncert: aGVsbG8=\nweight: 0.5\n"
+	if errs := ValidateOpenAPISchema([]byte(valid), []byte(testOpenAPISpec), ref); errs != nil {
+		t.Errorf("ValidateOpenAPISchema() of a valid document = %v", errs)
+	}
+	// JSON Schema itself knows neither nullable nor these formats.
+	if errs := ValidateSchema([]byte("~"), []byte("{type: string, nullable: true}")); errs == nil {
+		t.Error("ValidateSchema() applied nullable")
+	}
+
+	invalid := "name: ~\nports: [80, 4294967296]\ncert: '!!'\nweight: 1.0e+39\n"
+	var got []string
+	for _, e := range ValidateOpenAPISchema([]byte(invalid), []byte(testOpenAPISpec), ref) {
+		got = append(got, e.Error())
+	}
+	want := []string{
+		`1:1: /name: got null, want string`,
+		`2:13: /ports/1: must be a valid int32`,
+		`3:1: /cert: must be a valid byte`,
+		`4:1: /weight: must be a valid float`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("ValidateOpenAPISchema() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	crd := `
+spec:
+  versions:
+  - name: v1
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          replicas: {type: integer, format: int32}
+`
+	errs := ValidateOpenAPISchema([]byte("replicas: 3.5\n"), []byte(crd), "#/spec/versions/0/schema/openAPIV3Schema")
+	if len(errs) != 1 || errs[0].Path != "/replicas" {
+		t.Errorf("ValidateOpenAPISchema() against a CRD = %v", errs)
+	}
+
+	errs = ValidateOpenAPISchema([]byte("a: 1"), []byte(testOpenAPISpec), "#/components/schemas/Missing")
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "not found") {
+		t.Errorf("ValidateOpenAPISchema() with a missing ref = %v", errs)
+	}
+}
+
+func TestValidateWithOpenAPISchema(t *testing.T) {
+	type service struct {
+		Name  string  `json:"name"`
+		Owner *string `json:"owner"`
+		Ports []int   `json:"ports"`
+	}
+	opt := ValidateWithOpenAPISchema([]byte(testOpenAPISpec), "#/components/schemas/Service")
+
+	var s service
+	if err := Unmarshal([]byte("name: web\nowner: ~\nports: [80]\n"), &s, opt); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	if s.Name != "web" || s.Owner != nil || len(s.Ports) != 1 {
+		t.Errorf("Unmarshal() = %+v", s)
+	}
+
+	s = service{}
+	err := Unmarshal([]byte("ports: [0]\n"), &s, opt)
+	var se *SchemaError
+	if !errors.As(err, &se) || len(se.Errors) != 2 {
+		t.Fatalf("Unmarshal() of an invalid document = %v; want a *SchemaError with 2 errors", err)
+	}
+	if want := "schema validation failed: 1:1: /name: missing required property \"name\"; 1:9: /ports/0: must be at least 1"; err.Error() != want {
+		t.Errorf("Error() = %q; want %q", err.Error(), want)
+	}
+	if s.Ports != nil {
+		t.Errorf("Unmarshal() decoded an invalid document: %+v", s)
+	}
+}
@@ -0,0 +1,66 @@
+package overlay
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ghodss/yaml"
+)
+
+// Load reads the files named by paths, merges them in order with Merge and
+// decodes the merged document into v with yaml.Unmarshal and opts. A path
+// may be a pattern, such as "conf.d/*.yaml", which stands for the files that
+// match it in lexical order, and may match none; any other path must name a
+// file. The layers are named after their files, so that the Result tells
+// which file and line set each value, as in:
+//
+//	cfg := &Config{}
+//	res, err := overlay.Load(cfg, []string{"base.yaml", "conf.d/*.yaml"})
+//	...
+//	if cfg.Replicas < 1 {
+//		return fmt.Errorf("replicas set at %s must be positive", res.Source("replicas"))
+//	}
+func Load(v interface{}, paths []string, opts ...yaml.JSONOpt) (*Result, error) {
+	var layers []Layer
+	for _, p := range paths {
+		files := []string{p}
+		if hasMeta(p) {
+			var err error
+			if files, err = filepath.Glob(p); err != nil {
+				return nil, fmt.Errorf("overlay: %s: %v", p, err)
+			}
+		}
+		for _, f := range files {
+			data, err := os.ReadFile(f)
+			if err != nil {
+				return nil, fmt.Errorf("overlay: %v", err)
+			}
+			layers = append(layers, Layer{Name: f, Data: data})
+		}
+	}
+
+	res, err := Merge(layers...)
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(res.Document, v, opts...); err != nil {
+		return nil, fmt.Errorf("overlay: %v", err)
+	}
+	return res, nil
+}
+
+// hasMeta reports whether path is a pattern for filepath.Glob.
+func hasMeta(path string) bool {
+	for i := 0; i < len(path); i++ {
+		switch path[i] {
+		case '*', '?', '[':
+			return true
+		case '\\':
+			if filepath.Separator != '\\' {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package overlay
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	for name, data := range map[string]string{
+		"base.yaml":        "name: web\nreplicas: 1\nports: [80]\n",
+		"conf.d/10-a.yaml": "replicas: 2\n",
+		"conf.d/20-b.yaml": "# Scale up.\nreplicas: 3\n",
+		"conf.d/notes.txt": "replicas: 4\n",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var cfg struct {
+		Name     string `json:"name"`
+		Replicas int    `json:"replicas"`
+		Ports    []int  `json:"ports"`
+	}
+	base := filepath.Join(dir, "base.yaml")
+	b := filepath.Join(dir, "conf.d", "20-b.yaml")
+	res, err := Load(&cfg, []string{base, filepath.Join(dir, "conf.d", "*.yaml"), filepath.Join(dir, "none", "*.yaml")})
+	if err != nil {
+		t.Fatalf("Load() = %v", err)
+	}
+	if cfg.Name != "web" || cfg.Replicas != 3 || len(cfg.Ports) != 1 || cfg.Ports[0] != 80 {
+		t.Errorf("Load() decoded %+v", cfg)
+	}
+	if got, want := res.Source("replicas"), b+":2:1"; got != want {
+		t.Errorf("Source(%q) = %q; want %q", "replicas", got, want)
+	}
+	if got, want := res.Source("ports"), base+":3:1"; got != want {
+		t.Errorf("Source(%q) = %q; want %q", "ports", got, want)
+	}
+	if len(res.Conflicts) != 2 {
+		t.Errorf("Load() conflicts = %v", res.Conflicts)
+	}
+
+	if _, err := Load(&cfg, []string{filepath.Join(dir, "missing.yaml")}); err == nil {
+		t.Error("Load() of a missing file returned no error")
+	}
+	var wrong struct {
+		Name int `json:"name"`
+	}
+	if _, err := Load(&wrong, []string{base}); err == nil {
+		t.Error("Load() into a mismatched struct returned no error")
+	}
+}
@@ -0,0 +1,244 @@
+// Package overlay merges layers of YAML configuration, such as a base file
+// followed by per-environment and local overrides, recording which layer set
+// each value and where layers disagree. Load reads the layers from files and
+// decodes the merged configuration into a struct.
+package overlay
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	yaml2 "gopkg.in/yaml.v2"
+)
+
+// Layer is a YAML document to merge.
+type Layer struct {
+	// Name identifies the layer in provenance and conflicts, such as the
+	// name of the file it was read from.
+	Name string
+	Data []byte
+}
+
+// Conflict is a value that a layer set over a different value set by an
+// earlier layer.
+type Conflict struct {
+	// Path is the path of the value, such as "server.port", or "" for the
+	// whole document.
+	Path string
+	// Layer is the name of the layer that set the value, and Previous that
+	// of the layer whose value it replaced.
+	Layer, Previous string
+	// Old and New are the replaced value and the value that replaced it,
+	// in the JSON-compatible form of yaml.YAMLToJSON.
+	Old, New interface{}
+}
+
+func (c Conflict) String() string {
+	path := c.Path
+	if path == "" {
+		path = "document"
+	}
+	return fmt.Sprintf("%s: %s overrides %s", path, c.Layer, c.Previous)
+}
+
+// Result is the outcome of merging layers.
+type Result struct {
+	// Document is the merged YAML document, with its keys sorted.
+	Document []byte
+	// Provenance maps the path of each value of Document that is not a
+	// mapping to the name of the layer that set it. Lists are set as a
+	// whole, by the last layer that has them.
+	Provenance map[string]string
+	// Positions maps the paths of Provenance to where the layer wrote the
+	// values, at their keys.
+	Positions map[string]yaml.Position
+	// Conflicts are the values that layers replaced, in the order of the
+	// layers and then of their paths.
+	Conflicts []Conflict
+}
+
+// Source returns where the value at path was set, such as
+// "prod.yaml:12:3", or "" if Provenance has no layer for it.
+func (r *Result) Source(path string) string {
+	l, ok := r.Provenance[path]
+	if !ok {
+		return ""
+	}
+	if pos, ok := r.Positions[path]; ok {
+		return l + ":" + pos.String()
+	}
+	return l
+}
+
+// Merge merges the layers in order, each over the ones before it. Mappings
+// are merged key by key, and any other value of a layer, lists and null
+// included, replaces the value before it. Layers that are empty documents are
+// skipped.
+func Merge(layers ...Layer) (*Result, error) {
+	m := &merger{provenance: map[string]string{}, positions: map[string]yaml.Position{}}
+	var doc interface{}
+	set := false
+	for _, l := range layers {
+		v, empty, err := decode(l.Data)
+		if err != nil {
+			return nil, fmt.Errorf("overlay: layer %s: %v", l.Name, err)
+		}
+		if empty {
+			continue
+		}
+		_, sources, err := yaml.YAMLToJSONWithSourceMap(l.Data)
+		if err != nil {
+			return nil, fmt.Errorf("overlay: layer %s: %v", l.Name, err)
+		}
+		m.layer, m.sources = l.Name, map[string]yaml.Position{}
+		for p, pos := range sources {
+			m.sources[pointerPath(p)] = pos
+		}
+		doc = m.merge(doc, v, "", set)
+		set = true
+	}
+
+	j, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	y, err := yaml.JSONToYAML(j)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{Document: y, Provenance: m.provenance, Positions: m.positions, Conflicts: m.conflicts}, nil
+}
+
+// decode converts the YAML document y into the values encoding/json decodes
+// JSON into, keeping numbers as a json.Number. It reports whether y holds no
+// document.
+func decode(y []byte) (interface{}, bool, error) {
+	j, err := yaml.YAMLToJSON(y)
+	if err != nil {
+		return nil, false, err
+	}
+	d := json.NewDecoder(bytes.NewReader(j))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return nil, false, err
+	}
+	if v != nil {
+		return v, false, nil
+	}
+	var doc interface{}
+	err = yaml2.NewDecoder(bytes.NewReader(y)).Decode(&doc)
+	return v, err == io.EOF, nil
+}
+
+// merger records the provenance and conflicts of the values it merges.
+type merger struct {
+	layer      string
+	sources    map[string]yaml.Position // the positions of the values of layer
+	provenance map[string]string
+	positions  map[string]yaml.Position
+	conflicts  []Conflict
+}
+
+// merge returns dst, the value at path, with src merged into it. isSet
+// reports whether dst was set by an earlier layer.
+func (m *merger) merge(dst, src interface{}, path string, isSet bool) interface{} {
+	s, sok := src.(map[string]interface{})
+	d, dok := dst.(map[string]interface{})
+	if sok && (dok || !isSet) {
+		if d == nil {
+			d = map[string]interface{}{}
+		}
+		keys := make([]string, 0, len(s))
+		for k := range s {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			dv, ok := d[k]
+			d[k] = m.merge(dv, s[k], join(path, k), ok)
+		}
+		if len(s) == 0 && !dok {
+			m.setBy(path)
+		}
+		return d
+	}
+
+	if isSet && !reflect.DeepEqual(dst, src) {
+		c := Conflict{Path: path, Layer: m.layer, Previous: m.previous(path), Old: dst, New: src}
+		m.conflicts = append(m.conflicts, c)
+	}
+	m.forget(path)
+	if sok {
+		// The mapping replaces a value that was not one, so its own values
+		// are all set by this layer.
+		return m.merge(nil, src, path, false)
+	}
+	m.setBy(path)
+	return src
+}
+
+// setBy records that the value at path is set by the current layer.
+func (m *merger) setBy(path string) {
+	m.provenance[path] = m.layer
+	if pos, ok := m.sources[path]; ok {
+		m.positions[path] = pos
+	}
+}
+
+// previous returns the layer that set the value at path, or the first of the
+// layers that set values within it.
+func (m *merger) previous(path string) string {
+	if l, ok := m.provenance[path]; ok {
+		return l
+	}
+	var paths []string
+	for p := range m.provenance {
+		if within(p, path) {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+	if len(paths) == 0 {
+		return ""
+	}
+	return m.provenance[paths[0]]
+}
+
+// forget removes the provenance of the value at path and those within it.
+func (m *merger) forget(path string) {
+	for p := range m.provenance {
+		if p == path || within(p, path) {
+			delete(m.provenance, p)
+			delete(m.positions, p)
+		}
+	}
+}
+
+func within(p, path string) bool {
+	return path == "" || strings.HasPrefix(p, path+".")
+}
+
+// pointerPath returns the path of the value at the JSON Pointer p.
+func pointerPath(p string) string {
+	var path string
+	for _, t := range strings.Split(p, "/")[1:] {
+		path = join(path, pointerUnescaper.Replace(t))
+	}
+	return path
+}
+
+var pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
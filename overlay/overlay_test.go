@@ -0,0 +1,92 @@
+package overlay
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/ghodss/yaml"
+)
+
+func TestMerge(t *testing.T) {
+	base := Layer{"base.yaml", []byte(`server:
+  host: localhost
+  port: 8080
+features: [a, b]
+db: {url: postgres://db}
+`)}
+	prod := Layer{"prod.yaml", []byte(`# Production.
+server:
+  host: example.com
+  port: 8080
+features: [a, b, c]
+db: memory
+`)}
+	local := Layer{"local.yaml", []byte(`server: {port: 9090, debug: true}
+db: {url: sqlite://local}
+`)}
+
+	got, err := Merge(base, Layer{"empty.yaml", []byte("# Nothing.\n")}, prod, local)
+	if err != nil {
+		t.Fatalf("Merge() = %v", err)
+	}
+	want := &Result{
+		Document: []byte(`db:
+  url: sqlite://local
+features:
+- a
+- b
+- c
+server:
+  debug: true
+  host: example.com
+  port: 9090
+`),
+		Provenance: map[string]string{
+			"db.url":       "local.yaml",
+			"features":     "prod.yaml",
+			"server.debug": "local.yaml",
+			"server.host":  "prod.yaml",
+			"server.port":  "local.yaml",
+		},
+		Conflicts: []Conflict{
+			{"db", "prod.yaml", "base.yaml", map[string]interface{}{"url": "postgres://db"}, "memory"},
+			{"features", "prod.yaml", "base.yaml", []interface{}{"a", "b"}, []interface{}{"a", "b", "c"}},
+			{"server.host", "prod.yaml", "base.yaml", "localhost", "example.com"},
+			{"db", "local.yaml", "prod.yaml", "memory", map[string]interface{}{"url": "sqlite://local"}},
+			{"server.port", "local.yaml", "prod.yaml", json.Number("8080"), json.Number("9090")},
+		},
+	}
+	if string(got.Document) != string(want.Document) {
+		t.Errorf("Merge() document = %#q; want %#q", got.Document, want.Document)
+	}
+	if !reflect.DeepEqual(got.Provenance, want.Provenance) {
+		t.Errorf("Merge() provenance = %v; want %v", got.Provenance, want.Provenance)
+	}
+	wantPositions := map[string]yaml.Position{
+		"db.url":       {Line: 2, Column: 6},
+		"features":     {Line: 5, Column: 1},
+		"server.debug": {Line: 1, Column: 22},
+		"server.host":  {Line: 3, Column: 3},
+		"server.port":  {Line: 1, Column: 10},
+	}
+	if !reflect.DeepEqual(got.Positions, wantPositions) {
+		t.Errorf("Merge() positions = %v; want %v", got.Positions, wantPositions)
+	}
+	if s := got.Source("server.host"); s != "prod.yaml:3:3" {
+		t.Errorf("Source(%q) = %q", "server.host", s)
+	}
+	if s := got.Source("server"); s != "" {
+		t.Errorf("Source(%q) = %q", "server", s)
+	}
+	if !reflect.DeepEqual(got.Conflicts, want.Conflicts) {
+		t.Errorf("Merge() conflicts = %v; want %v", got.Conflicts, want.Conflicts)
+	}
+	if s := got.Conflicts[0].String(); s != "db: prod.yaml overrides base.yaml" {
+		t.Errorf("Conflict.String() = %q", s)
+	}
+
+	if _, err := Merge(base, Layer{"bad.yaml", []byte("a: [")}); err == nil {
+		t.Error("Merge() of invalid YAML returned no error")
+	}
+}
@@ -0,0 +1,301 @@
+package yaml
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+	yaml3 "gopkg.in/yaml.v3"
+)
+
+// A path selects a value in a YAML document with keys and indexes, as in
+// yq and jq: ".spec.containers[0].image". Keys that hold dots, brackets or
+// spaces are written in brackets and double quotes, as in
+// `.metadata.annotations["example.com/owner"]`. Negative indexes count from
+// the end of a sequence. The path "." or "" selects the whole document.
+
+// pathElem is a key or index of a path.
+type pathElem struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+func (e pathElem) String() string {
+	switch {
+	case e.isIndex:
+		return "[" + strconv.Itoa(e.index) + "]"
+	case e.key == "" || strings.ContainsAny(e.key, ".[]\"' \t\n"):
+		return "[" + strconv.Quote(e.key) + "]"
+	}
+	return "." + e.key
+}
+
+// pathString formats the path p.
+func pathString(p []pathElem) string {
+	if len(p) == 0 {
+		return "."
+	}
+	var b strings.Builder
+	for _, e := range p {
+		b.WriteString(e.String())
+	}
+	return b.String()
+}
+
+// parsePath parses the path s.
+func parsePath(s string) ([]pathElem, error) {
+	var p []pathElem
+	i := 0
+	if s == "." {
+		return nil, nil
+	}
+	for i < len(s) {
+		switch {
+		case s[i] == '[':
+			end := strings.IndexByte(s[i:], ']')
+			if strings.HasPrefix(s[i+1:], `"`) {
+				// The quoted key may hold a ']' of its own.
+				q, err := strconv.QuotedPrefix(s[i+1:])
+				if err != nil {
+					return nil, fmt.Errorf("yaml: invalid path %q: unterminated key", s)
+				}
+				end = 1 + len(q)
+				if !strings.HasPrefix(s[i+end:], "]") {
+					return nil, fmt.Errorf("yaml: invalid path %q: missing ']'", s)
+				}
+				key, _ := strconv.Unquote(q)
+				p = append(p, pathElem{key: key})
+			} else {
+				if end < 0 {
+					return nil, fmt.Errorf("yaml: invalid path %q: missing ']'", s)
+				}
+				n, err := strconv.Atoi(s[i+1 : i+end])
+				if err != nil {
+					return nil, fmt.Errorf("yaml: invalid path %q: invalid index %q", s, s[i+1:i+end])
+				}
+				p = append(p, pathElem{index: n, isIndex: true})
+			}
+			i += end + 1
+		case s[i] == '.' || i == 0:
+			if s[i] == '.' {
+				i++
+			}
+			j := i
+			for j < len(s) && s[j] != '.' && s[j] != '[' {
+				j++
+			}
+			if j == i {
+				if j < len(s) && s[j] == '[' {
+					// A "." before a bracket, as in `.["a.b"]`.
+					continue
+				}
+				return nil, fmt.Errorf("yaml: invalid path %q: empty key", s)
+			}
+			p = append(p, pathElem{key: s[i:j]})
+			i = j
+		default:
+			return nil, fmt.Errorf("yaml: invalid path %q", s)
+		}
+	}
+	return p, nil
+}
+
+// PathNotFoundError is returned when a path selects no value of a document.
+type PathNotFoundError struct {
+	// Path is the part of the path that was not found, such as
+	// ".spec.containers[3]".
+	Path string
+}
+
+func (e *PathNotFoundError) Error() string {
+	return "yaml: path " + e.Path + " not found"
+}
+
+// Get returns the value at path in the YAML document doc, decoded as
+// Unmarshal decodes into an interface{}: mappings become a
+// map[string]interface{}, sequences a []interface{} and numbers a float64.
+// It returns a *PathNotFoundError if the document has no value at path.
+func Get(doc []byte, path string) (interface{}, error) {
+	p, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := yamlToJSONObject(doc, nil, yaml.Unmarshal, &decodeOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i, e := range p {
+		var ok bool
+		if obj, ok = lookupElem(obj, e); !ok {
+			return nil, &PathNotFoundError{pathString(p[:i+1])}
+		}
+	}
+	j, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	err = json.Unmarshal(j, &v)
+	return v, err
+}
+
+// lookupElem returns the value of the JSON-compatible value v under the key
+// or index e.
+func lookupElem(v interface{}, e pathElem) (interface{}, bool) {
+	switch c := v.(type) {
+	case map[string]interface{}:
+		if !e.isIndex {
+			v, ok := c[e.key]
+			return v, ok
+		}
+	case []interface{}:
+		if i, ok := sequenceIndex(e, len(c)); ok {
+			return c[i], true
+		}
+	}
+	return nil, false
+}
+
+// sequenceIndex returns the index e selects in a sequence of length n.
+func sequenceIndex(e pathElem, n int) (int, bool) {
+	if !e.isIndex {
+		return 0, false
+	}
+	i := e.index
+	if i < 0 {
+		i += n
+	}
+	return i, i >= 0 && i < n
+}
+
+// GetRaw returns the value at path in the YAML document doc as a YAML
+// document of its own, keeping its comments and the style of its scalars.
+// Aliases within the value are expanded. It returns a *PathNotFoundError if
+// the document has no value at path.
+func GetRaw(doc []byte, path string) ([]byte, error) {
+	p, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	doc, err = toUTF8(doc)
+	if err != nil {
+		return nil, err
+	}
+	var n yaml3.Node
+	if err := yaml3.Unmarshal(doc, &n); err != nil {
+		return nil, err
+	}
+	if n.Kind == 0 {
+		return nil, &PathNotFoundError{pathString(p)}
+	}
+	node := n.Content[0]
+	for i, e := range p {
+		if node = lookupNode(node, e); node == nil {
+			return nil, &PathNotFoundError{pathString(p[:i+1])}
+		}
+	}
+	var out bytes.Buffer
+	enc := yaml3.NewEncoder(&out)
+	enc.SetIndent(2)
+	if err := enc.Encode(expandAliases(node)); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// lookupNode returns the node of the value under the key or index e of the
+// node n, or nil if there is none. Like go-yaml, it looks up keys that a
+// mapping does not have itself in the mappings it merges with "<<".
+func lookupNode(n *yaml3.Node, e pathElem) *yaml3.Node {
+	for n.Kind == yaml3.AliasNode {
+		n = n.Alias
+	}
+	switch n.Kind {
+	case yaml3.MappingNode:
+		if e.isIndex {
+			return nil
+		}
+		var merged []*yaml3.Node
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			if k.ShortTag() == "!!merge" {
+				merged = append(merged, v)
+			} else if k.Kind == yaml3.ScalarNode && k.Value == e.key {
+				return v
+			}
+		}
+		for _, m := range merged {
+			for m.Kind == yaml3.AliasNode {
+				m = m.Alias
+			}
+			if m.Kind == yaml3.SequenceNode {
+				// Earlier mappings of a merge sequence take precedence.
+				for _, c := range m.Content {
+					if v := lookupNode(c, e); v != nil {
+						return v
+					}
+				}
+			} else if v := lookupNode(m, e); v != nil {
+				return v
+			}
+		}
+	case yaml3.SequenceNode:
+		if i, ok := sequenceIndex(e, len(n.Content)); ok {
+			return n.Content[i]
+		}
+	}
+	return nil
+}
+
+// expandAliases returns a copy of n with its aliases replaced by copies of
+// the nodes they refer to, and without anchors. Merge keys are expanded too,
+// into the keys they add to their mapping.
+func expandAliases(n *yaml3.Node) *yaml3.Node {
+	for n.Kind == yaml3.AliasNode {
+		n = n.Alias
+	}
+	c := *n
+	c.Anchor = ""
+	c.Content = nil
+	if n.Kind != yaml3.MappingNode {
+		for _, child := range n.Content {
+			c.Content = append(c.Content, expandAliases(child))
+		}
+		return &c
+	}
+
+	own := map[string]bool{}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if k := n.Content[i]; k.ShortTag() != "!!merge" {
+			own[k.Value] = true
+		}
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		k, v := n.Content[i], n.Content[i+1]
+		if k.ShortTag() != "!!merge" {
+			c.Content = append(c.Content, expandAliases(k), expandAliases(v))
+			continue
+		}
+		merged := expandAliases(v)
+		maps := []*yaml3.Node{merged}
+		if merged.Kind == yaml3.SequenceNode {
+			maps = merged.Content
+		}
+		for _, m := range maps {
+			for j := 0; j+1 < len(m.Content); j += 2 {
+				if mk := m.Content[j]; !own[mk.Value] {
+					own[mk.Value] = true
+					c.Content = append(c.Content, mk, m.Content[j+1])
+				}
+			}
+		}
+	}
+	return &c
+}
@@ -0,0 +1,107 @@
+package yaml
+
+import (
+	"reflect"
+	"testing"
+)
+
+const pathDoc = `metadata:
+  name: web
+  annotations: {example.com/owner: team-a}
+spec:
+  defaults: &defaults
+    image: nginx # The default image.
+    pull: Always
+  containers:
+  - name: web
+    <<: *defaults
+    ports: [80, 443]
+  - name: sidecar
+    image: envoy
+`
+
+func TestParsePath(t *testing.T) {
+	for _, tc := range []struct {
+		path string
+		want []pathElem
+	}{
+		{"", nil},
+		{".", nil},
+		{".spec.containers[0].image", []pathElem{{key: "spec"}, {key: "containers"}, {index: 0, isIndex: true}, {key: "image"}}},
+		{"spec.containers.[-1]", []pathElem{{key: "spec"}, {key: "containers"}, {index: -1, isIndex: true}}},
+		{`.a["b.c"]["]"]`, []pathElem{{key: "a"}, {key: "b.c"}, {key: "]"}}},
+		{"[1][2]", []pathElem{{index: 1, isIndex: true}, {index: 2, isIndex: true}}},
+	} {
+		got, err := parsePath(tc.path)
+		if err != nil {
+			t.Errorf("parsePath(%q) = %v", tc.path, err)
+		} else if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("parsePath(%q) = %+v; want %+v", tc.path, got, tc.want)
+		}
+	}
+	for _, path := range []string{"a..b", "a.", "a[x]", "a[0", `a["b]`, `a["b"`} {
+		if _, err := parsePath(path); err == nil {
+			t.Errorf("parsePath(%q) returned no error", path)
+		}
+	}
+}
+
+func TestGet(t *testing.T) {
+	for _, tc := range []struct {
+		path string
+		want interface{}
+	}{
+		{".metadata.name", "web"},
+		{`.metadata.annotations["example.com/owner"]`, "team-a"},
+		{".spec.containers[0].image", "nginx"},
+		{".spec.containers[-1].image", "envoy"},
+		{".spec.containers[0].ports", []interface{}{float64(80), float64(443)}},
+		{".spec.defaults", map[string]interface{}{"image": "nginx", "pull": "Always"}},
+	} {
+		got, err := Get([]byte(pathDoc), tc.path)
+		if err != nil {
+			t.Errorf("Get(%q) = %v", tc.path, err)
+		} else if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Get(%q) = %#v; want %#v", tc.path, got, tc.want)
+		}
+	}
+
+	for path, missing := range map[string]string{
+		".spec.containers[2].image": ".spec.containers[2]",
+		".metadata.labels":          ".metadata.labels",
+		".metadata.name.first":      ".metadata.name.first",
+		".spec[0]":                  ".spec[0]",
+	} {
+		_, err := Get([]byte(pathDoc), path)
+		if e, ok := err.(*PathNotFoundError); !ok || e.Path != missing {
+			t.Errorf("Get(%q) = %v; want path %s not found", path, err, missing)
+		}
+	}
+}
+
+func TestGetRaw(t *testing.T) {
+	for _, tc := range []struct {
+		path, want string
+	}{
+		{".metadata.name", "web\n"},
+		{".spec.containers[0].image", "nginx # The default image.\n"},
+		{".spec.containers[0]", "name: web\nimage: nginx # The default image.\npull: Always\nports: [80, 443]\n"},
+	} {
+		got, err := GetRaw([]byte(pathDoc), tc.path)
+		if err != nil {
+			t.Errorf("GetRaw(%q) = %v", tc.path, err)
+		} else if string(got) != tc.want {
+			t.Errorf("GetRaw(%q) = %#q; want %#q", tc.path, string(got), tc.want)
+		}
+	}
+
+	// Nested values are indented as Marshal indents them.
+	got, err := GetRaw([]byte("a:\n  b:\n    c: 1 # One.\n    d:\n      - x\n"), ".a")
+	if want := "b:\n  c: 1 # One.\n  d:\n    - x\n"; err != nil || string(got) != want {
+		t.Errorf("GetRaw() of a nested mapping = %#q, %v; want %#q", string(got), err, want)
+	}
+
+	if _, err := GetRaw([]byte(pathDoc), ".spec.missing"); err == nil {
+		t.Error("GetRaw() of a missing path returned no error")
+	}
+}
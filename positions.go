@@ -0,0 +1,25 @@
+package yaml
+
+// DecodeFieldPositions makes Unmarshal record in pos where each value it
+// decodes into a struct field is written in the YAML document, so that an
+// error found when checking the decoded struct can point to its source, as in
+// "replicas at 42:3 must be positive". Values are recorded under their paths,
+// such as "spec.containers[1].image", at the positions of their keys; values
+// that come from an alias or a merge key are recorded where the SourceMap of
+// the document has them. pos must not be nil.
+func DecodeFieldPositions(pos map[string]Position) JSONOpt {
+	return decodeOpt(func(o *decodeOptions) {
+		o.fieldPositions = pos
+	})
+}
+
+// recordField records the position of the value at path, which is decoded
+// into a struct field, if o records field positions.
+func (o *decodeOptions) recordField(path *keyPath) {
+	if o.fieldPositions == nil {
+		return
+	}
+	if pos, ok := o.sourcePositions[path.String()]; ok {
+		o.fieldPositions[path.String()] = pos
+	}
+}
@@ -0,0 +1,42 @@
+package yaml
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecodeFieldPositions(t *testing.T) {
+	type Container struct {
+		Name  string `json:"name"`
+		Image string `json:"image"`
+	}
+	type Config struct {
+		Replicas   int               `json:"replicas"`
+		Containers []Container       `json:"containers"`
+		Labels     map[string]string `json:"labels"`
+	}
+	y := `defaults: &defaults
+  image: nginx
+replicas: 3
+containers:
+- name: web
+  <<: *defaults
+labels:
+  app: web
+`
+	pos := map[string]Position{}
+	var c Config
+	if err := Unmarshal([]byte(y), &c, DecodeFieldPositions(pos)); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]Position{
+		"replicas":            {3, 1},
+		"containers":          {4, 1},
+		"containers[0].name":  {5, 3},
+		"containers[0].image": {2, 3},
+		"labels":              {7, 1},
+	}
+	if !reflect.DeepEqual(pos, want) {
+		t.Errorf("Unmarshal() recorded %v; want %v", pos, want)
+	}
+}
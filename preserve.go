@@ -0,0 +1,559 @@
+package yaml
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	yaml3 "gopkg.in/yaml.v3"
+)
+
+// EditOpt is an option for Set and Delete.
+type EditOpt func(*editOptions)
+
+// editOptions holds the settings of the EditOpts.
+type editOptions struct {
+	preserve bool
+}
+
+// PreserveFormatting makes Set and Delete edit the text of the document in
+// place, changing only the value at the path, its key and the line breaks and
+// indentation around them. Everything else, including comments, the order of
+// keys, the quotes and styles of other values and blank lines, is kept byte
+// for byte, as is the quoting of a string that replaces another. Set and
+// Delete return an error rather than an edit that would change the document
+// in other ways, such as when the path goes through a multi-line plain
+// scalar that cannot be told apart from what follows it.
+func PreserveFormatting() EditOpt {
+	return func(o *editOptions) {
+		o.preserve = true
+	}
+}
+
+func newEditOptions(opts []EditOpt) *editOptions {
+	o := &editOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// editInPlace calls edit with a textEditor for the YAML stream y and the root
+// node of its first document, and returns the edited text, checking that it
+// holds the same values as want.
+func editInPlace(y, want []byte, path string, edit func(t *textEditor, root *yaml3.Node) error) ([]byte, error) {
+	y, err := toUTF8(y)
+	if err != nil {
+		return nil, err
+	}
+	var doc yaml3.Node
+	if err := yaml3.Unmarshal(y, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind == 0 {
+		// There is no document to edit in place.
+		return want, nil
+	}
+	t := newTextEditor(y)
+	if err := edit(t, doc.Content[0]); err != nil {
+		return nil, err
+	}
+	out := t.result()
+	if err := sameDocuments(want, out); err != nil {
+		return nil, fmt.Errorf("yaml: cannot edit %s in place", path)
+	}
+	return out, nil
+}
+
+// textEditor replaces a span of the text of a YAML document.
+type textEditor struct {
+	src   []byte
+	lines []int // the offsets of the start of each line
+	nl    string
+
+	start, end int
+	text       string
+}
+
+func newTextEditor(src []byte) *textEditor {
+	t := &textEditor{src: src, lines: []int{0}, nl: "\n"}
+	for i, b := range src {
+		if b == '\n' {
+			t.lines = append(t.lines, i+1)
+		}
+	}
+	if bytes.Contains(src, []byte("\r\n")) {
+		t.nl = "\r\n"
+	}
+	return t
+}
+
+// edit sets the edit to replacing the text from start to end with text.
+func (t *textEditor) edit(start, end int, text string) {
+	t.start, t.end, t.text = start, end, text
+}
+
+func (t *textEditor) result() []byte {
+	out := make([]byte, 0, len(t.src)-(t.end-t.start)+len(t.text))
+	out = append(out, t.src[:t.start]...)
+	out = append(out, t.text...)
+	return append(out, t.src[t.end:]...)
+}
+
+// offset returns the offset of the node n.
+func (t *textEditor) offset(n *yaml3.Node) int {
+	i := t.lines[n.Line-1]
+	for c := 1; c < n.Column && i < len(t.src) && t.src[i] != '\n'; c++ {
+		_, size := utf8.DecodeRune(t.src[i:])
+		i += size
+	}
+	return i
+}
+
+// column returns the 0-based column of the offset i.
+func (t *textEditor) column(i int) int {
+	return utf8.RuneCount(t.src[t.lineStart(i):i])
+}
+
+func (t *textEditor) lineStart(i int) int {
+	return bytes.LastIndexByte(t.src[:i], '\n') + 1
+}
+
+// lineEnd returns the offset of the line break that ends the line of the
+// offset i, or the end of the text.
+func (t *textEditor) lineEnd(i int) int {
+	if j := bytes.IndexByte(t.src[i:], '\n'); j >= 0 {
+		i += j
+		if i > 0 && t.src[i-1] == '\r' {
+			i--
+		}
+		return i
+	}
+	return len(t.src)
+}
+
+// nextLine returns the offset of the start of the line after that of i, or
+// the end of the text.
+func (t *textEditor) nextLine(i int) int {
+	if j := bytes.IndexByte(t.src[i:], '\n'); j >= 0 {
+		return i + j + 1
+	}
+	return len(t.src)
+}
+
+// startsLine reports whether the offset i is preceded only by indentation on
+// its line.
+func (t *textEditor) startsLine(i int) bool {
+	return len(bytes.TrimLeft(t.src[t.lineStart(i):i], " ")) == 0
+}
+
+func (t *textEditor) trimRight(start, end int) int {
+	for end > start && (t.src[end-1] == ' ' || t.src[end-1] == '\t') {
+		end--
+	}
+	return end
+}
+
+// spanEnd returns the offset of the end of the text of the node n, which
+// starts at start. flow is set if n is within a flow collection.
+func (t *textEditor) spanEnd(n *yaml3.Node, start int, flow bool) int {
+	switch {
+	case n.Kind == yaml3.AliasNode:
+		return start + 1 + len(n.Value)
+	case n.Style&yaml3.FlowStyle != 0:
+		return t.flowEnd(start)
+	case n.Kind == yaml3.MappingNode || n.Kind == yaml3.SequenceNode:
+		return t.blockEnd(n, start)
+	case n.Style&(yaml3.LiteralStyle|yaml3.FoldedStyle) != 0:
+		return t.blockScalarEnd(start)
+	case n.Style&yaml3.DoubleQuotedStyle != 0:
+		return t.quotedEnd(start, '"')
+	case n.Style&yaml3.SingleQuotedStyle != 0:
+		return t.quotedEnd(start, '\'')
+	}
+	// A plain scalar, which may be empty.
+	i := start
+	for ; i < len(t.src); i++ {
+		c := t.src[i]
+		if c == '\n' || c == '\r' || c == '#' && i > start && (t.src[i-1] == ' ' || t.src[i-1] == '\t') {
+			break
+		}
+		if flow && strings.IndexByte(",[]{}", c) >= 0 {
+			break
+		}
+	}
+	return t.trimRight(start, i)
+}
+
+// quotedEnd returns the end of the scalar quoted with q that starts at or
+// after start.
+func (t *textEditor) quotedEnd(start int, q byte) int {
+	i := bytes.IndexByte(t.src[start:], q)
+	if i < 0 {
+		return len(t.src)
+	}
+	for i = start + i + 1; i < len(t.src); i++ {
+		switch {
+		case q == '"' && t.src[i] == '\\':
+			i++
+		case t.src[i] == q && q == '\'' && i+1 < len(t.src) && t.src[i+1] == '\'':
+			i++
+		case t.src[i] == q:
+			return i + 1
+		}
+	}
+	return len(t.src)
+}
+
+// flowEnd returns the end of the flow collection that starts at or after
+// start.
+func (t *textEditor) flowEnd(start int) int {
+	depth := 0
+	for i := start; i < len(t.src); i++ {
+		switch c := t.src[i]; c {
+		case '"', '\'':
+			i = t.quotedEnd(i, c) - 1
+		case '#':
+			if i > start && (t.src[i-1] == ' ' || t.src[i-1] == '\t' || t.src[i-1] == '\n') {
+				i = t.lineEnd(i)
+			}
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return len(t.src)
+}
+
+// blockEnd returns the end of the last line of the block collection n that
+// starts at start. Comments and blank lines after it are left out.
+func (t *textEditor) blockEnd(n *yaml3.Node, start int) int {
+	col := n.Column - 1
+	end := t.trimRight(start, t.lineEnd(start))
+	for i := t.nextLine(start); i < len(t.src); i = t.nextLine(i) {
+		line := t.src[i:t.lineEnd(i)]
+		trimmed := bytes.TrimLeft(line, " ")
+		if len(bytes.TrimSpace(trimmed)) == 0 || trimmed[0] == '#' {
+			continue
+		}
+		indent := len(line) - len(trimmed)
+		if indent == 0 && (bytes.HasPrefix(line, []byte("---")) || bytes.HasPrefix(line, []byte("..."))) {
+			break
+		}
+		dash := trimmed[0] == '-' && (len(trimmed) == 1 || trimmed[1] == ' ')
+		if indent < col || indent == col && n.Kind == yaml3.SequenceNode && !dash {
+			break
+		}
+		end = t.trimRight(i, t.lineEnd(i))
+	}
+	return end
+}
+
+// blockScalarEnd returns the end of the last line of the literal or folded
+// scalar whose header starts at start.
+func (t *textEditor) blockScalarEnd(start int) int {
+	end := t.lineEnd(start)
+	header := t.src[t.lineStart(start):end]
+	headerIndent := len(header) - len(bytes.TrimLeft(header, " "))
+	indent := -1
+	for i := t.nextLine(start); i < len(t.src); i = t.nextLine(i) {
+		line := t.src[i:t.lineEnd(i)]
+		trimmed := bytes.TrimLeft(line, " ")
+		if len(trimmed) == 0 {
+			continue
+		}
+		if indent < 0 {
+			indent = len(line) - len(trimmed)
+			if indent <= headerIndent {
+				break
+			}
+		}
+		if len(line)-len(trimmed) < indent {
+			break
+		}
+		end = t.lineEnd(i)
+	}
+	return end
+}
+
+// place is where a value is written in the document.
+type place struct {
+	// flow is set within a flow collection.
+	flow bool
+	// inline is set if a block collection may start on the line of the
+	// value, as it may after "- " but not after "key:".
+	inline bool
+	// indent is the indentation of the lines of a block collection.
+	indent int
+}
+
+// childPlace returns the place of the value at index i of the content of the
+// collection n.
+func (t *textEditor) childPlace(n *yaml3.Node, i int) place {
+	if n.Style&yaml3.FlowStyle != 0 {
+		return place{flow: true}
+	}
+	if n.Kind == yaml3.MappingNode {
+		return place{indent: n.Content[i-1].Column - 1 + 2}
+	}
+	return place{inline: true, indent: t.column(t.dash(n.Content[i])) + 2}
+}
+
+// dash returns the offset of the "-" before the item n of a block sequence.
+func (t *textEditor) dash(n *yaml3.Node) int {
+	i := t.offset(n)
+	for i > 0 && t.src[i-1] != '-' && (t.src[i-1] == ' ' || t.src[i-1] == '\n' || t.src[i-1] == '\r') {
+		i--
+	}
+	return i - 1
+}
+
+// render returns the text of the value v written at the place p.
+func (t *textEditor) render(v *yaml3.Node, p place) (string, error) {
+	if p.flow && (v.Kind == yaml3.MappingNode || v.Kind == yaml3.SequenceNode) {
+		v.Style |= yaml3.FlowStyle
+	}
+	var b bytes.Buffer
+	enc := yaml3.NewEncoder(&b)
+	enc.SetIndent(2)
+	untagMergeKeys(v)
+	if err := enc.Encode(v); err != nil {
+		return "", err
+	}
+	if err := enc.Close(); err != nil {
+		return "", err
+	}
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	collection := (v.Kind == yaml3.MappingNode || v.Kind == yaml3.SequenceNode) && v.Style&yaml3.FlowStyle == 0 && len(v.Content) > 0
+
+	indent := p.indent
+	if !collection {
+		// The lines of a block scalar are already indented.
+		indent -= 2
+	}
+	if indent < 0 {
+		indent = 0
+	}
+	var s strings.Builder
+	for i, line := range lines {
+		if i > 0 || collection && !p.inline {
+			s.WriteString(t.nl)
+			if line != "" {
+				s.WriteString(strings.Repeat(" ", indent))
+			}
+		}
+		s.WriteString(line)
+	}
+	return s.String(), nil
+}
+
+// replace replaces the text of the node old, written at the place p, with
+// the value v.
+func (t *textEditor) replace(old, v *yaml3.Node, p place) error {
+	if old.Kind == yaml3.ScalarNode && v.Kind == yaml3.ScalarNode && v.Tag == "!!str" && !strings.Contains(v.Value, "\n") {
+		// Keep the quotes of the string, unless the new one needs double
+		// quotes where it had single ones.
+		q := old.Style & (yaml3.SingleQuotedStyle | yaml3.DoubleQuotedStyle)
+		if q != 0 && (q == yaml3.DoubleQuotedStyle || v.Style != yaml3.DoubleQuotedStyle) {
+			v.Style = q
+		}
+	}
+	text, err := t.render(v, p)
+	if err != nil {
+		return err
+	}
+	start := t.offset(old)
+	end := t.spanEnd(old, start, p.flow)
+	if strings.HasPrefix(text, t.nl) {
+		for start > 0 && t.src[start-1] == ' ' {
+			start--
+		}
+	} else if start > 0 && !strings.HasPrefix(text, t.nl) && t.src[start-1] != ' ' && t.src[start-1] != '\n' && !p.flow {
+		text = " " + text
+	}
+	t.edit(start, end, text)
+	return nil
+}
+
+// insertKey adds the key k with the value v to the end of the mapping m.
+func (t *textEditor) insertKey(m *yaml3.Node, k string, v *yaml3.Node) error {
+	key, err := t.render(keyNode(k), place{})
+	if err != nil {
+		return err
+	}
+	start := t.offset(m)
+	end := t.spanEnd(m, start, false)
+	if m.Style&yaml3.FlowStyle != 0 {
+		value, err := t.render(v, place{flow: true})
+		if err != nil {
+			return err
+		}
+		t.flowAppend(m, end, key+": "+value)
+		return nil
+	}
+	value, err := t.render(v, place{indent: m.Column - 1 + 2})
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(value, t.nl) {
+		value = " " + value
+	}
+	t.edit(end, end, t.nl+strings.Repeat(" ", m.Column-1)+key+":"+value)
+	return nil
+}
+
+// appendItem adds the value v to the end of the sequence s.
+func (t *textEditor) appendItem(s *yaml3.Node, v *yaml3.Node) error {
+	start := t.offset(s)
+	end := t.spanEnd(s, start, false)
+	if s.Style&yaml3.FlowStyle != 0 {
+		text, err := t.render(v, place{flow: true})
+		if err != nil {
+			return err
+		}
+		t.flowAppend(s, end, text)
+		return nil
+	}
+	col := t.column(start)
+	text, err := t.render(v, place{inline: true, indent: col + 2})
+	if err != nil {
+		return err
+	}
+	t.edit(end, end, t.nl+strings.Repeat(" ", col)+"- "+text)
+	return nil
+}
+
+// flowAppend adds the entry text to the end of the flow collection n, which
+// ends at end, right after its last entry.
+func (t *textEditor) flowAppend(n *yaml3.Node, end int, text string) {
+	if len(n.Content) == 0 {
+		t.edit(end-1, end-1, text)
+		return
+	}
+	last := n.Content[len(n.Content)-1]
+	i := t.spanEnd(last, t.offset(last), true)
+	t.edit(i, i, ", "+text)
+}
+
+// set sets the value at path p below root to v, as setNode does.
+func (t *textEditor) set(root *yaml3.Node, p []pathElem, v *yaml3.Node) error {
+	n, pl := root, place{inline: true}
+	if len(p) == 0 {
+		return t.replace(n, v, pl)
+	}
+	for i, e := range p {
+		last := i == len(p)-1
+		if n.Kind == yaml3.ScalarNode && n.ShortTag() == "!!null" && (!e.isIndex || e.index == 0) {
+			nested, err := nestValue(p, i, v)
+			if err != nil {
+				return err
+			}
+			return t.replace(n, nested, pl)
+		}
+		if j := ownNode(n, e); j >= 0 {
+			if last {
+				return t.replace(n.Content[j], v, t.childPlace(n, j))
+			}
+			c, err := editableNode(n, p, i)
+			if err != nil {
+				return err
+			}
+			n, pl = c, t.childPlace(n, j)
+			continue
+		}
+		if !last && lookupNode(n, e) != nil {
+			return missingError(n, p, i)
+		}
+		nested, err := nestValue(p, i+1, v)
+		if err != nil {
+			return err
+		}
+		switch {
+		case n.Kind == yaml3.MappingNode && !e.isIndex:
+			return t.insertKey(n, e.key, nested)
+		case n.Kind == yaml3.SequenceNode && e.isIndex && e.index == len(n.Content):
+			return t.appendItem(n, nested)
+		}
+		return &PathNotFoundError{pathString(p[:i+1])}
+	}
+	return nil
+}
+
+// nestValue returns v nested under the elements of p from index from on, in
+// mappings under their keys and in sequences as their first items.
+func nestValue(p []pathElem, from int, v *yaml3.Node) (*yaml3.Node, error) {
+	for i := len(p) - 1; i >= from; i-- {
+		switch {
+		case !p[i].isIndex:
+			v = &yaml3.Node{Kind: yaml3.MappingNode, Tag: "!!map", Content: []*yaml3.Node{keyNode(p[i].key), v}}
+		case p[i].index == 0:
+			v = &yaml3.Node{Kind: yaml3.SequenceNode, Tag: "!!seq", Content: []*yaml3.Node{v}}
+		default:
+			return nil, &PathNotFoundError{pathString(p[:i+1])}
+		}
+	}
+	return v, nil
+}
+
+// delete removes the value at path p below root, as deleteNode does.
+func (t *textEditor) delete(root *yaml3.Node, p []pathElem) error {
+	n := root
+	for i := range p[:len(p)-1] {
+		c, err := editableNode(n, p, i)
+		if err != nil {
+			return err
+		}
+		n = c
+	}
+	j := ownNode(n, p[len(p)-1])
+	if j < 0 {
+		return missingError(n, p, len(p)-1)
+	}
+
+	// entry returns the start and end of the key and value, or the item, at
+	// index i of the content of n.
+	flow := n.Style&yaml3.FlowStyle != 0
+	step := 1
+	if n.Kind == yaml3.MappingNode {
+		step = 2
+	}
+	entry := func(i int) (int, int) {
+		v := n.Content[i]
+		start := t.offset(v)
+		end := t.spanEnd(v, start, flow)
+		switch {
+		case n.Kind == yaml3.MappingNode:
+			start = t.offset(n.Content[i-1])
+		case !flow:
+			start = t.dash(v)
+		}
+		return start, end
+	}
+	start, end := entry(j)
+	next := j + step
+	switch {
+	case next < len(n.Content) && (flow || !t.startsLine(start)):
+		next, _ := entry(next)
+		t.edit(start, next, "")
+	case flow && j >= step:
+		_, prev := entry(j - step)
+		t.edit(prev, end, "")
+	case len(n.Content) == step:
+		empty := "[]"
+		if n.Kind == yaml3.MappingNode {
+			empty = "{}"
+		}
+		if flow {
+			empty = ""
+		}
+		t.edit(start, end, empty)
+	default:
+		t.edit(t.lineStart(start), t.nextLine(end), "")
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package yaml
+
+import (
+	"strings"
+	"testing"
+)
+
+const preserveDoc = `# Deployment, hand-formatted.
+metadata:
+    name:   web     # The name.
+    labels: { app: web,  tier: front }
+    annotations:
+spec:
+
+    # Containers.
+    containers:
+    -   name: web
+        image: "nginx:1.24"   # Pinned.
+        args: [ -a,  -b ]
+        script: |
+            echo hi
+            echo bye
+    -   name: sidecar
+        image: 'envoy'
+    replicas: 3
+---
+other:   document
+`
+
+func TestSetPreserveFormatting(t *testing.T) {
+	for _, tc := range []struct {
+		path  string
+		value interface{}
+		old   string
+		new   string
+	}{
+		{".spec.containers[0].image", "nginx:1.25", `image: "nginx:1.24"   # Pinned.`, `image: "nginx:1.25"   # Pinned.`},
+		{".spec.containers[1].image", "it's", `image: 'envoy'`, `image: 'it''s'`},
+		{".metadata.name", true, "name:   web     # The name.", "name:   true     # The name."},
+		{".metadata.name", "true", "name:   web     # The name.", `name:   "true"     # The name.`},
+		{".metadata.labels.team", "core", "{ app: web,  tier: front }", "{ app: web,  tier: front, team: core }"},
+		{".metadata.labels.tier", []int{1, 2}, "tier: front }", "tier: [1, 2] }"},
+		{".metadata.annotations.owner", "me", "annotations:\n", "annotations:\n      owner: me\n"},
+		{".spec.containers[0].args[2]", "-c", "[ -a,  -b ]", "[ -a,  -b, -c ]"},
+		{".spec.containers[2]", map[string]string{"name": "logger", "image": "fluentd"}, "image: 'envoy'\n", "image: 'envoy'\n    - image: fluentd\n      name: logger\n"},
+		{".spec.containers[1].env.LEVEL", "debug", "image: 'envoy'\n", "image: 'envoy'\n        env:\n          LEVEL: debug\n"},
+		{".spec.replicas", map[string]int{"min": 1}, "replicas: 3", "replicas:\n      min: 1"},
+		{".spec.containers[0].script", "echo 1\necho 2\n", "|\n            echo hi\n            echo bye\n", "|\n          echo 1\n          echo 2\n"},
+		{".spec.containers[0]", "web", "name: web\n        image: \"nginx:1.24\"   # Pinned.\n        args: [ -a,  -b ]\n        script: |\n            echo hi\n            echo bye\n", "web\n"},
+	} {
+		got, err := Set([]byte(preserveDoc), tc.path, tc.value, PreserveFormatting())
+		if err != nil {
+			t.Errorf("Set(%q) = %v", tc.path, err)
+			continue
+		}
+		if want := strings.Replace(preserveDoc, tc.old, tc.new, 1); string(got) != want {
+			t.Errorf("Set(%q) =\n%s\nwant\n%s", tc.path, got, want)
+		}
+	}
+
+	got, err := Set([]byte("a: 1\r\nb:\r\n  c: 2\r\n"), ".b.d", "x", PreserveFormatting())
+	if want := "a: 1\r\nb:\r\n  c: 2\r\n  d: x\r\n"; err != nil || string(got) != want {
+		t.Errorf("Set() with CRLF line endings = %#q, %v; want %#q", string(got), err, want)
+	}
+
+	got, err = Set([]byte("a:   1\nb:\n"), ".new.deep[0]", "v", PreserveFormatting())
+	if want := "a:   1\nb:\nnew:\n  deep:\n    - v\n"; err != nil || string(got) != want {
+		t.Errorf("Set() of a missing sequence = %#q, %v; want %#q", string(got), err, want)
+	}
+	got, err = Set([]byte("a:   1\nb:\n"), ".b[0]", "v", PreserveFormatting())
+	if want := "a:   1\nb:\n  - v\n"; err != nil || string(got) != want {
+		t.Errorf("Set() of a null sequence = %#q, %v; want %#q", string(got), err, want)
+	}
+
+	got, err = Set(nil, ".a", 1, PreserveFormatting())
+	if err != nil || string(got) != "a: 1\n" {
+		t.Errorf("Set() on an empty document = %#q, %v", string(got), err)
+	}
+
+	got, err = Set([]byte("a: &x {b: 1}\nc: *x\n"), ".a.b", 2, PreserveFormatting())
+	if want := "a: &x {b: 2}\nc: *x\n"; err != nil || string(got) != want {
+		t.Errorf("Set() of an anchored value = %#q, %v; want %#q", string(got), err, want)
+	}
+	got, err = Set([]byte("a: .nan\nb: 1\n"), ".b", 2, PreserveFormatting())
+	if want := "a: .nan\nb: 2\n"; err != nil || string(got) != want {
+		t.Errorf("Set() beside a NaN = %#q, %v; want %#q", string(got), err, want)
+	}
+	if _, err := Set([]byte("a: &x {b: 1}\nc: *x\n"), ".c.b", 2, PreserveFormatting()); err == nil {
+		t.Error("Set() through an alias returned no error")
+	}
+}
+
+func TestDeletePreserveFormatting(t *testing.T) {
+	for _, tc := range []struct {
+		path     string
+		old, new string
+	}{
+		{".metadata.name", "    name:   web     # The name.\n", ""},
+		{".metadata.labels.app", "{ app: web,  tier: front }", "{ tier: front }"},
+		{".metadata.labels.tier", "{ app: web,  tier: front }", "{ app: web }"},
+		{".spec.containers[0].args[1]", "[ -a,  -b ]", "[ -a ]"},
+		{".spec.containers[0].name", "name: web\n        image", "image"},
+		{".spec.containers[1]", "    -   name: sidecar\n        image: 'envoy'\n", ""},
+		{".spec.containers[0].script", "        script: |\n            echo hi\n            echo bye\n", ""},
+		{".spec.containers", "    # Containers.\n    containers:\n    -   name: web\n        image: \"nginx:1.24\"   # Pinned.\n        args: [ -a,  -b ]\n        script: |\n            echo hi\n            echo bye\n    -   name: sidecar\n        image: 'envoy'\n", "    # Containers.\n"},
+	} {
+		got, err := Delete([]byte(preserveDoc), tc.path, PreserveFormatting())
+		if err != nil {
+			t.Errorf("Delete(%q) = %v", tc.path, err)
+			continue
+		}
+		if want := strings.Replace(preserveDoc, tc.old, tc.new, 1); string(got) != want {
+			t.Errorf("Delete(%q) =\n%s\nwant\n%s", tc.path, got, want)
+		}
+	}
+
+	for _, tc := range []struct {
+		doc, path, want string
+	}{
+		{"a:\n  - x\n", ".a[0]", "a:\n  []\n"},
+		{"- a: 1\n", ".[0].a", "- {}\n"},
+		{"a: [x]\n", ".a[0]", "a: []\n"},
+	} {
+		got, err := Delete([]byte(tc.doc), tc.path, PreserveFormatting())
+		if err != nil || string(got) != tc.want {
+			t.Errorf("Delete(%#q, %q) = %#q, %v; want %#q", tc.doc, tc.path, string(got), err, tc.want)
+		}
+	}
+
+	if _, err := Delete([]byte(preserveDoc), ".spec.missing", PreserveFormatting()); err == nil {
+		t.Error("Delete() of a missing key returned no error")
+	}
+}
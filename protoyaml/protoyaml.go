@@ -0,0 +1,68 @@
+// Package protoyaml converts protocol buffer messages to and from YAML. The
+// JSON that yaml.Marshal and yaml.Unmarshal go through is written and read by
+// protojson rather than encoding/json, so that messages are in the canonical
+// JSON mapping of protocol buffers: fields are named by their JSON names,
+// enums by their value names, and well-known types such as
+// google.protobuf.Timestamp, Duration, Struct and FieldMask by their own
+// JSON forms. encoding/json knows none of this and writes the Go fields of
+// the generated structs instead.
+package protoyaml
+
+import (
+	"github.com/ghodss/yaml"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Marshal writes the message m as YAML with the default options.
+func Marshal(m proto.Message) ([]byte, error) {
+	return MarshalOptions{}.Marshal(m)
+}
+
+// Unmarshal reads the YAML document y into the message m with the default
+// options.
+func Unmarshal(y []byte, m proto.Message) error {
+	return UnmarshalOptions{}.Unmarshal(y, m)
+}
+
+// MarshalOptions configures Marshal.
+type MarshalOptions struct {
+	// JSON configures the conversion of the message to JSON, such as
+	// whether fields are named by their proto names or unpopulated fields
+	// are written.
+	JSON protojson.MarshalOptions
+	// YAML configures the conversion of the JSON to YAML.
+	YAML []yaml.MarshalOpt
+}
+
+// Marshal writes the message m as YAML. Fields are in the order protojson
+// writes them, which is the order of their field numbers.
+func (o MarshalOptions) Marshal(m proto.Message) ([]byte, error) {
+	j, err := o.JSON.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	// Options make the conversion keep the order of the keys.
+	opts := append(o.YAML[:len(o.YAML):len(o.YAML)], yaml.SortKeys(yaml.KeyOrderDocument))
+	return yaml.JSONToYAMLWithOpts(j, opts...)
+}
+
+// UnmarshalOptions configures Unmarshal.
+type UnmarshalOptions struct {
+	// JSON configures the conversion of JSON to the message, such as
+	// whether unknown fields are discarded.
+	JSON protojson.UnmarshalOptions
+	// YAML configures the conversion of the YAML to JSON, with the options
+	// of yaml.YAMLToJSONWithOpts.
+	YAML []yaml.JSONOpt
+}
+
+// Unmarshal reads the YAML document y into the message m, which is reset
+// first.
+func (o UnmarshalOptions) Unmarshal(y []byte, m proto.Message) error {
+	j, err := yaml.YAMLToJSONWithOpts(y, o.YAML...)
+	if err != nil {
+		return err
+	}
+	return o.JSON.Unmarshal(j, m)
+}
@@ -0,0 +1,78 @@
+package protoyaml
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/typepb"
+)
+
+func TestMarshal(t *testing.T) {
+	f := &typepb.Field{
+		Kind:     typepb.Field_TYPE_INT64,
+		Name:     "max_size",
+		JsonName: "maxSize",
+		Number:   3,
+	}
+	want := "kind: TYPE_INT64\nnumber: 3\nname: max_size\njsonName: maxSize\n"
+	got, err := Marshal(f)
+	if err != nil {
+		t.Fatalf("Marshal() = %v", err)
+	}
+	if string(got) != want {
+		t.Errorf("Marshal() = %q; want %q", got, want)
+	}
+
+	got, err = MarshalOptions{JSON: protojson.MarshalOptions{UseProtoNames: true}}.Marshal(f)
+	if err != nil || string(got) != "kind: TYPE_INT64\nnumber: 3\nname: max_size\njson_name: maxSize\n" {
+		t.Errorf("Marshal() with proto names = %q, %v", got, err)
+	}
+
+	ts := timestamppb.New(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	if got, err := Marshal(ts); err != nil || string(got) != "\"2024-05-01T12:00:00Z\"\n" {
+		t.Errorf("Marshal() of a Timestamp = %q, %v", got, err)
+	}
+	if got, err := Marshal(durationpb.New(90 * time.Second)); err != nil || string(got) != "90s\n" {
+		t.Errorf("Marshal() of a Duration = %q, %v", got, err)
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	var f typepb.Field
+	if err := Unmarshal([]byte("kind: TYPE_STRING\nnumber: 1\njson_name: id\npacked: yes\n"), &f); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	want := &typepb.Field{Kind: typepb.Field_TYPE_STRING, Number: 1, JsonName: "id", Packed: true}
+	if !proto.Equal(&f, want) {
+		t.Errorf("Unmarshal() = %v; want %v", &f, want)
+	}
+
+	var ts timestamppb.Timestamp
+	if err := Unmarshal([]byte("2024-05-01T12:00:00Z"), &ts); err != nil || ts.AsTime() != time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC) {
+		t.Errorf("Unmarshal() of a Timestamp = %v, %v", ts.AsTime(), err)
+	}
+
+	var s structpb.Struct
+	if err := Unmarshal([]byte("a: [1, x]\nb: {c: ~}\n"), &s); err != nil {
+		t.Fatalf("Unmarshal() of a Struct = %v", err)
+	}
+	if got := s.Fields["a"].GetListValue().GetValues()[1].GetStringValue(); got != "x" {
+		t.Errorf("Unmarshal() of a Struct = %v", &s)
+	}
+
+	if err := Unmarshal([]byte("nope: 1\n"), &f); err == nil {
+		t.Error("Unmarshal() of an unknown field returned no error")
+	}
+	opts := UnmarshalOptions{JSON: protojson.UnmarshalOptions{DiscardUnknown: true}}
+	if err := opts.Unmarshal([]byte("nope: 1\nname: x\n"), &f); err != nil || f.Name != "x" {
+		t.Errorf("Unmarshal() discarding unknown fields = %v, %v", &f, err)
+	}
+	if err := Unmarshal([]byte("a: ["), &f); err == nil {
+		t.Error("Unmarshal() of invalid YAML returned no error")
+	}
+}
@@ -0,0 +1,42 @@
+package protoyaml
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/ghodss/yaml"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// ToStructPB reads the YAML document y, which must be a mapping, into a
+// google.protobuf.Struct, as gRPC services take free-form configuration.
+// Values are read as yaml.Unmarshal reads them, and numbers become doubles.
+// An empty document is an empty Struct.
+func ToStructPB(y []byte, opts ...yaml.JSONOpt) (*structpb.Struct, error) {
+	j, err := yaml.YAMLToJSONWithOpts(y, opts...)
+	if err != nil {
+		return nil, err
+	}
+	s := &structpb.Struct{}
+	if bytes.Equal(j, []byte("null")) {
+		return s, nil
+	}
+	if len(j) == 0 || j[0] != '{' {
+		return nil, errors.New("protoyaml: document is not a mapping")
+	}
+	if err := s.UnmarshalJSON(j); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// FromStructPB writes the Struct s as a YAML mapping. Doubles that are
+// integers are written as integers, so that a document read by ToStructPB is
+// written back as it was.
+func FromStructPB(s *structpb.Struct, opts ...yaml.MarshalOpt) ([]byte, error) {
+	j, err := s.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	return yaml.JSONToYAMLWithOpts(j, opts...)
+}
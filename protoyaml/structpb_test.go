@@ -0,0 +1,38 @@
+package protoyaml
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestStructPB(t *testing.T) {
+	y := "name: web\nreplicas: 3\nratio: 0.5\nenabled: yes\nowner: ~\nports:\n- 80\n- 443\nlabels:\n  tier: front\n"
+	s, err := ToStructPB([]byte(y))
+	if err != nil {
+		t.Fatalf("ToStructPB() = %v", err)
+	}
+	if s.Fields["replicas"].GetNumberValue() != 3 || !s.Fields["enabled"].GetBoolValue() ||
+		s.Fields["owner"].GetNullValue() != structpb.NullValue_NULL_VALUE ||
+		s.Fields["labels"].GetStructValue().Fields["tier"].GetStringValue() != "front" {
+		t.Errorf("ToStructPB() = %v", s)
+	}
+
+	got, err := FromStructPB(s)
+	if err != nil {
+		t.Fatalf("FromStructPB() = %v", err)
+	}
+	want := "enabled: true\nlabels:\n  tier: front\nname: web\nowner: null\nports:\n- 80\n- 443\nratio: 0.5\nreplicas: 3\n"
+	if string(got) != want {
+		t.Errorf("FromStructPB() = %q; want %q", got, want)
+	}
+
+	if s, err := ToStructPB(nil); err != nil || len(s.Fields) != 0 {
+		t.Errorf("ToStructPB() of an empty document = %v, %v", s, err)
+	}
+	for _, y := range []string{"- 1\n", "a", "a: ["} {
+		if _, err := ToStructPB([]byte(y)); err == nil {
+			t.Errorf("ToStructPB(%q) returned no error", y)
+		}
+	}
+}
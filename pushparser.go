@@ -0,0 +1,85 @@
+package yaml
+
+import (
+	"bytes"
+	"errors"
+)
+
+// PushParser splits a YAML stream that is given to it in chunks, such as the
+// chunks of an HTTP response or the messages of a queue, into its documents,
+// which it passes on as soon as each is complete. It is the counterpart of
+// UnmarshalDocuments for input that does not come as an io.Reader. Documents
+// are split as UnmarshalDocuments splits them, at their "---" and "..."
+// lines, so a document is complete once the line that starts the next one
+// is fed, or when the stream is done.
+type PushParser struct {
+	emit func(doc []byte) error
+	buf  []byte
+	// read is the offset in buf of the first line that is not yet split.
+	read int
+	s    documentSplitter
+	err  error
+	done bool
+}
+
+// NewPushParser returns a PushParser that calls emit with each document of
+// the stream, such as to Unmarshal it. The document holds its "---" line, if
+// it has one, and may be kept after emit returns.
+func NewPushParser(emit func(doc []byte) error) *PushParser {
+	return &PushParser{emit: emit}
+}
+
+// Feed gives the next chunk of the stream to p, which may be cut anywhere,
+// even within a line or a character, and emits the documents it completes.
+// It returns the error of emit, after which p stops: Feed and Done then
+// return that error again.
+func (p *PushParser) Feed(chunk []byte) error {
+	if p.err != nil {
+		return p.err
+	}
+	if p.done {
+		return errors.New("yaml: Feed after Done")
+	}
+	p.buf = append(p.buf, chunk...)
+	for {
+		i := bytes.IndexByte(p.buf[p.read:], '\n')
+		if i < 0 {
+			break
+		}
+		p.s.line(p.buf, p.read, i+1)
+		p.read += i + 1
+	}
+	return p.flush()
+}
+
+// Done ends the stream, emitting its last document.
+func (p *PushParser) Done() error {
+	if p.err != nil || p.done {
+		return p.err
+	}
+	p.done = true
+	if p.read < len(p.buf) {
+		p.s.line(p.buf, p.read, len(p.buf)-p.read)
+		p.read = len(p.buf)
+	}
+	p.s.end(p.buf, len(p.buf))
+	return p.flush()
+}
+
+// flush emits the documents that are complete and drops them from the
+// buffer.
+func (p *PushParser) flush() error {
+	docs := p.s.docs
+	p.s.docs = nil
+	for _, doc := range docs {
+		if p.err = p.emit(doc); p.err != nil {
+			return p.err
+		}
+	}
+	// The emitted documents keep the start of the buffer, which appending to
+	// it never writes over.
+	p.buf = p.buf[p.s.start:]
+	p.read -= p.s.start
+	p.s.start = 0
+	return nil
+}
@@ -0,0 +1,67 @@
+package yaml
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestPushParser(t *testing.T) {
+	y := "%YAML 1.2\n--- # first\na: 1\n---\nb: [1,\n  2]\n...\n# trailing\n--- text\n"
+	want := []string{"%YAML 1.2\n--- # first\na: 1\n", "---\nb: [1,\n  2]\n...\n", "# trailing\n--- text\n"}
+
+	for _, size := range []int{1, 3, 7, len(y)} {
+		var docs []string
+		p := NewPushParser(func(doc []byte) error {
+			docs = append(docs, string(doc))
+			return nil
+		})
+		for i := 0; i < len(y); i += size {
+			end := i + size
+			if end > len(y) {
+				end = len(y)
+			}
+			if err := p.Feed([]byte(y[i:end])); err != nil {
+				t.Fatalf("Feed() = %v", err)
+			}
+		}
+		// The last document is only complete once the stream is done.
+		if len(docs) != 2 {
+			t.Errorf("chunks of %d: %d documents before Done, want 2", size, len(docs))
+		}
+		if err := p.Done(); err != nil {
+			t.Fatalf("Done() = %v", err)
+		}
+		if !reflect.DeepEqual(docs, want) {
+			t.Errorf("chunks of %d: documents = %q, want %q", size, docs, want)
+		}
+		if err := p.Feed([]byte("a: 1\n")); err == nil {
+			t.Errorf("Feed() after Done = nil, want an error")
+		}
+	}
+
+	// The last document needs no line break.
+	var docs []string
+	p := NewPushParser(func(doc []byte) error {
+		docs = append(docs, string(doc))
+		return nil
+	})
+	p.Feed([]byte("a: 1\n--"))
+	p.Feed([]byte("-\nb: 2"))
+	if err := p.Done(); err != nil || !reflect.DeepEqual(docs, []string{"a: 1\n", "---\nb: 2"}) {
+		t.Errorf("documents = %q, %v", docs, err)
+	}
+
+	errStop := errors.New("stop")
+	calls := 0
+	p = NewPushParser(func(doc []byte) error {
+		calls++
+		return errStop
+	})
+	if err := p.Feed([]byte("a: 1\n---\nb: 2\n---\nc: 3\n")); err != errStop {
+		t.Errorf("Feed() = %v, want %v", err, errStop)
+	}
+	if err := p.Done(); err != errStop || calls != 1 {
+		t.Errorf("Done() = %v with %d calls, want %v with 1", err, calls, errStop)
+	}
+}
@@ -0,0 +1,41 @@
+package yaml
+
+import "reflect"
+
+// Redactor is implemented by types whose values are sensitive, such as a type
+// for passwords or tokens.
+type Redactor interface {
+	// Redacted reports whether the value is to be hidden by Redact.
+	Redacted() bool
+}
+
+var redactorType = reflect.TypeOf((*Redactor)(nil)).Elem()
+
+// Redact makes Marshal write placeholder, such as "***", instead of the
+// values of struct fields with the redact option, as in
+//
+//	Password string `json:"password,redact"`
+//
+// and of values whose Redacted method returns true, so that configuration can
+// be dumped into logs and support bundles. The option may also be given in
+// the yaml tag. Without Redact, such values are written as usual.
+func Redact(placeholder string) MarshalOpt {
+	return func(e *encoder) {
+		e.redact = true
+		e.placeholder = placeholder
+	}
+}
+
+// isRedacted reports whether v is a Redactor that is to be redacted.
+func isRedacted(v reflect.Value) bool {
+	if !v.IsValid() {
+		return false
+	}
+	if v.Kind() != reflect.Ptr && v.CanAddr() && reflect.PtrTo(v.Type()).Implements(redactorType) {
+		v = v.Addr()
+	}
+	if !v.Type().Implements(redactorType) || (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+		return false
+	}
+	return v.Interface().(Redactor).Redacted()
+}
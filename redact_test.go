@@ -0,0 +1,72 @@
+package yaml
+
+import "testing"
+
+type testSecret string
+
+func (s testSecret) Redacted() bool { return s != "" }
+
+type testToken struct {
+	Value string `json:"value"`
+}
+
+func (t *testToken) Redacted() bool { return true }
+
+func TestRedact(t *testing.T) {
+	type Database struct {
+		URL      string `json:"url"`
+		Password string `json:"password,redact"`
+		User     string `json:"user" yaml:",redact"`
+	}
+	type Config struct {
+		Name     string                `json:"name"`
+		Database Database              `json:"database"`
+		Keys     map[string]testSecret `json:"keys"`
+		Token    testToken             `json:"token"`
+		Empty    testSecret            `json:"empty"`
+		Missing  string                `json:"missing,omitempty,redact"`
+	}
+	c := &Config{
+		Name:     "web",
+		Database: Database{URL: "postgres://db", Password: "hunter2", User: "admin"},
+		Keys:     map[string]testSecret{"api": "abc123"},
+		Token:    testToken{"t0k3n"},
+	}
+
+	got, err := Marshal(c, Redact("***"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `database:
+  password: '***'
+  url: postgres://db
+  user: '***'
+empty: ""
+keys:
+  api: '***'
+name: web
+token: '***'
+`
+	if string(got) != want {
+		t.Errorf("Marshal() with Redact = %#q; want %#q", string(got), want)
+	}
+
+	got, err = Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = `database:
+  password: hunter2
+  url: postgres://db
+  user: admin
+empty: ""
+keys:
+  api: abc123
+name: web
+token:
+  value: t0k3n
+`
+	if string(got) != want {
+		t.Errorf("Marshal() = %#q; want %#q", string(got), want)
+	}
+}
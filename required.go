@@ -0,0 +1,53 @@
+package yaml
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// A struct field tagged `yaml:",required"`, or `required:"true"`, must have a
+// key in the YAML mapping it is decoded from, or Unmarshal returns a
+// *MissingFieldsError. A key with a null value counts as present, so that a
+// field left out can be told apart from one explicitly set to its zero value.
+// The fields of a struct are only checked if its own key is present, unless
+// it is the struct the document is decoded into.
+
+// MissingFieldsError is returned by Unmarshal if required fields have no key
+// in the YAML document.
+type MissingFieldsError struct {
+	// Paths are the paths of the missing fields, such as
+	// "spec.containers[0].image", sorted.
+	Paths []string
+}
+
+func newMissingFieldsError(paths []string) *MissingFieldsError {
+	sort.Strings(paths)
+	return &MissingFieldsError{Paths: paths}
+}
+
+func (e *MissingFieldsError) Error() string {
+	return "missing required fields: " + strings.Join(e.Paths, ", ")
+}
+
+// typeHasRequired reports whether the struct type t has a required field.
+func typeHasRequired(t reflect.Type) bool {
+	for _, f := range cachedTypeFields(t) {
+		if f.required {
+			return true
+		}
+	}
+	return false
+}
+
+// checkRequired records the required fields of the struct type t that are
+// not in set, the fields that have a key in the mapping at path.
+func (o *decodeOptions) checkRequired(t reflect.Type, set map[string]bool, path *keyPath) {
+	fields := cachedTypeFields(t)
+	for i := range fields {
+		f := &fields[i]
+		if f.required && !set[f.name] && !skipField(f, o.tags) {
+			o.missingFields = append(o.missingFields, path.key(fieldKey(f, o.tags, o.fieldNaming)).String())
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package yaml
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRequiredFields(t *testing.T) {
+	type Container struct {
+		Name  string `json:"name" yaml:"name,required"`
+		Image string `json:"image" required:"true"`
+	}
+	type Spec struct {
+		Replicas   *int        `json:"replicas" yaml:",required"`
+		Containers []Container `json:"containers"`
+		Selector   *Container  `json:"selector"`
+	}
+	type Deployment struct {
+		Spec Spec `json:"spec" required:"true"`
+	}
+
+	var d Deployment
+	if err := Unmarshal([]byte("spec:\n  replicas: null\n  containers:\n  - {name: web, image: nginx}\n"), &d); err != nil {
+		t.Errorf("Unmarshal() of a complete document = %v", err)
+	}
+
+	for _, tc := range []struct {
+		y    string
+		want []string
+	}{
+		{"spec:\n  containers:\n  - name: web\n  - image: nginx\n", []string{"spec.containers[0].image", "spec.containers[1].name", "spec.replicas"}},
+		{"spec: {replicas: 0, selector: {}}\n", []string{"spec.selector.image", "spec.selector.name"}},
+		{"# Nothing.\n", []string{"spec"}},
+		{"{}", []string{"spec"}},
+	} {
+		err := Unmarshal([]byte(tc.y), &d)
+		var missing *MissingFieldsError
+		if !errors.As(err, &missing) {
+			t.Errorf("Unmarshal(%#q) = %v; want a *MissingFieldsError", tc.y, err)
+			continue
+		}
+		if !reflect.DeepEqual(missing.Paths, tc.want) {
+			t.Errorf("Unmarshal(%#q) missing fields = %q; want %q", tc.y, missing.Paths, tc.want)
+		}
+	}
+
+	err := Unmarshal([]byte("spec: {}\n"), &d)
+	if want := "missing required fields: spec.replicas"; err == nil || err.Error() != want {
+		t.Errorf("Unmarshal() = %v; want %q", err, want)
+	}
+}
@@ -0,0 +1,331 @@
+package yaml
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// go-yaml resolves plain scalars by the rules of neither YAML 1.1 nor YAML
+// 1.2 exactly. Some options resolve them differently, which needs the text
+// scalars are written as, so for them the document is decoded into textYAML
+// values and resolveScalars then resolves the scalars in it again.
+
+// YAMLVersion is a version of the YAML specification whose rules resolve and
+// emit plain scalars. Without one, scalars are resolved and emitted as go-yaml
+// does, which mostly follows YAML 1.1.
+type YAMLVersion int
+
+const (
+	// YAML11 follows YAML 1.1, where yes, no, on and off are booleans, 0777 is
+	// an octal integer and 1:30:00 is a base 60 integer, and floats need a
+	// decimal point.
+	YAML11 YAMLVersion = iota + 1
+	// YAML12 follows the core schema of YAML 1.2, where only true and false
+	// are booleans and 0777 is the decimal integer 777.
+	YAML12
+)
+
+// WithYAMLVersion sets the version of YAML whose rules decide which strings
+// Marshal writes as plain scalars: with YAML12, strings such as yes are no
+// longer quoted, while strings such as 1e3 are.
+func WithYAMLVersion(v YAMLVersion) MarshalOpt {
+	return func(e *encoder) {
+		e.yamlVersion = v
+	}
+}
+
+// DecodeYAMLVersion sets the version of YAML whose rules resolve plain
+// scalars. go-yaml does not tell quoted and unquoted strings apart, so plain
+// scalars that go-yaml resolves as strings stay strings, except for base 60
+// numbers under YAML11. DecodeLegacyNumbers and AmbiguousNumbersAsStrings
+// take precedence over the version.
+func DecodeYAMLVersion(v YAMLVersion) JSONOpt {
+	return decodeOpt(func(o *decodeOptions) {
+		o.yamlVersion = v
+	})
+}
+
+// LegacyNumbers selects how the forms of numbers that YAML 1.1 has and YAML
+// 1.2 dropped are resolved: octal integers with a leading zero, such as 0777,
+// and base 60 numbers, such as 1:30:00.
+type LegacyNumbers int
+
+const (
+	// LegacyNumbersUnchanged resolves them as go-yaml does: 0777 as the
+	// integer 511 and 1:30:00 as a string. This is the default.
+	LegacyNumbersUnchanged LegacyNumbers = iota
+	// LegacyNumbersResolved resolves them as numbers, as YAML 1.1 does: 0777
+	// as the integer 511 and 1:30:00 as the integer 5400.
+	LegacyNumbersResolved
+	// LegacyNumbersAsStrings leaves them as strings.
+	LegacyNumbersAsStrings
+)
+
+// DecodeLegacyNumbers sets how legacy octal and base 60 numbers are resolved.
+// go-yaml does not tell quoted and unquoted scalars apart once they are
+// decoded into strings, so with LegacyNumbersResolved a quoted "1:30:00" is
+// resolved as a number too.
+func DecodeLegacyNumbers(p LegacyNumbers) JSONOpt {
+	return decodeOpt(func(o *decodeOptions) {
+		o.legacyNumbers = p
+	})
+}
+
+// AmbiguousNumbersAsStrings configures the conversion to leave plain scalars
+// that look like numbers but are likely meant as strings as strings: those
+// with leading zeros, such as the zip code 01234, which go-yaml resolves as
+// octal, and those with an exponent but no decimal point, such as 12e+3, which
+// YAML 1.1 does not resolve as floats and abbreviated git commits may look
+// like.
+func AmbiguousNumbersAsStrings() JSONOpt {
+	return decodeOpt(func(o *decodeOptions) {
+		o.ambiguous = true
+	})
+}
+
+var (
+	// ambiguousNumber matches the numbers left as strings by
+	// AmbiguousNumbersAsStrings.
+	ambiguousNumber = regexp.MustCompile(`^[-+]?(?:0[0-9_]|[0-9_]+[eE])`)
+	// legacyOctal matches the octal integers of YAML 1.1.
+	legacyOctal = regexp.MustCompile(`^[-+]?0[0-7_]+$`)
+	// yaml11Int matches the integers of YAML 1.1, except base 60 ones.
+	yaml11Int = regexp.MustCompile(`^[-+]?(?:0b[01_]+|0x[0-9a-fA-F_]+|0[0-7_]*|[1-9][0-9_]*)$`)
+	// yaml12Int matches the integers of the YAML 1.2 core schema.
+	yaml12Int = regexp.MustCompile(`^(?:[-+]?[0-9]+|0o[0-7]+|0x[0-9a-fA-F]+)$`)
+	// yaml12Float matches the floats of the YAML 1.2 core schema.
+	yaml12Float = regexp.MustCompile(`^(?:[-+]?(?:\.[0-9]+|[0-9]+(?:\.[0-9]*)?)(?:[eE][-+]?[0-9]+)?|[-+]?\.(?:inf|Inf|INF)|\.(?:nan|NaN|NAN))$`)
+)
+
+// textYAML decodes a YAML value into the same value as go-yaml decodes into
+// an interface{}, except that scalars go-yaml resolves as something other than
+// a string or null are kept as a yamlScalar along with the text they are
+// written as.
+type textYAML struct {
+	v interface{}
+}
+
+// textKey decodes a key of a YAML mapping like textYAML. Keys that are not
+// scalars are told apart by other alone, as they cannot be map keys.
+type textKey struct {
+	v     interface{}
+	other *bool
+}
+
+func (k *textKey) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var t textYAML
+	if err := unmarshal(&t); err != nil {
+		return err
+	}
+	switch t.v.(type) {
+	case map[interface{}]interface{}, []interface{}:
+		k.other = new(bool)
+	default:
+		k.v = t.v
+	}
+	return nil
+}
+
+// UnmarshalText decodes the quoted strings "~" and "null", for which go-yaml
+// calls it in place of UnmarshalYAML, taking them for null.
+func (k *textKey) UnmarshalText(text []byte) error {
+	k.v = string(text)
+	return nil
+}
+
+// yamlScalar is a scalar as go-yaml resolves it along with its text.
+type yamlScalar struct {
+	v    interface{}
+	text string
+}
+
+func (p *textYAML) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	if err := unmarshal(&p.v); err != nil {
+		return err
+	}
+	switch v := p.v.(type) {
+	case map[interface{}]interface{}:
+		var m map[textKey]textYAML
+		if err := unmarshal(&m); err != nil {
+			return err
+		}
+		for k, x := range m {
+			if k.other != nil {
+				continue
+			}
+			if n, ok := k.v.(yamlScalar); ok {
+				delete(v, n.v)
+			}
+			v[k.v] = x.v
+		}
+	case []interface{}:
+		var s []textYAML
+		if err := unmarshal(&s); err != nil {
+			return err
+		}
+		for i, x := range s {
+			v[i] = x.v
+		}
+	case bool, int, int64, uint64, float64:
+		// go-yaml decodes scalars into strings as they are written.
+		var text string
+		if err := unmarshal(&text); err != nil {
+			return err
+		}
+		p.v = yamlScalar{v, text}
+	}
+	return nil
+}
+
+// UnmarshalText decodes the quoted strings "~" and "null", for which go-yaml
+// calls it in place of UnmarshalYAML, taking them for null.
+func (p *textYAML) UnmarshalText(text []byte) error {
+	p.v = string(text)
+	return nil
+}
+
+// resolveScalars replaces the yamlScalars in v, which has been decoded from
+// textYAML, with the values they resolve to under the options set on o. If
+// precise is set, floats that do not hold the exact number written in the
+// document are kept as a json.Number.
+func (o *decodeOptions) resolveScalars(v interface{}, precise bool) interface{} {
+	switch x := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[interface{}]interface{}, len(x))
+		for k, e := range x {
+			if s, ok := k.(yamlScalar); ok {
+				k = o.resolveScalar(s, false)
+			}
+			m[o.resolveScalars(k, false)] = o.resolveScalars(e, precise)
+		}
+		return m
+	case []interface{}:
+		for i, e := range x {
+			x[i] = o.resolveScalars(e, precise)
+		}
+	case yamlScalar:
+		if o.textScalars {
+			return x
+		}
+		return o.resolveScalar(x, precise)
+	case string:
+		if o.resolvesBase60() && base60Float.MatchString(x) {
+			if n, ok := parseSexagesimal(x); ok {
+				return n
+			}
+		}
+	}
+	return v
+}
+
+func (o *decodeOptions) resolveScalar(x yamlScalar, precise bool) interface{} {
+	switch v := x.v.(type) {
+	case bool:
+		if o.yamlVersion == YAML12 && !nonStringScalars12[x.text] {
+			return x.text
+		}
+	case float64:
+		if o.ambiguous && ambiguousNumber.MatchString(x.text) {
+			return x.text
+		}
+		switch o.yamlVersion {
+		case YAML11:
+			if !strings.Contains(x.text, ".") {
+				return x.text
+			}
+		case YAML12:
+			if !yaml12Float.MatchString(x.text) {
+				return x.text
+			}
+		}
+		if precise && jsonNumber.MatchString(x.text) && strconv.FormatFloat(v, 'g', -1, 64) != x.text {
+			return json.Number(x.text)
+		}
+	default:
+		if o.ambiguous && ambiguousNumber.MatchString(x.text) {
+			return x.text
+		}
+		if o.legacyNumbers == LegacyNumbersAsStrings && legacyOctal.MatchString(x.text) {
+			return x.text
+		}
+		switch o.yamlVersion {
+		case YAML11:
+			if !yaml11Int.MatchString(x.text) {
+				return x.text
+			}
+		case YAML12:
+			if !yaml12Int.MatchString(x.text) {
+				return x.text
+			}
+			if o.legacyNumbers != LegacyNumbersResolved && legacyOctal.MatchString(x.text) {
+				// The core schema has no octal integers with a leading
+				// zero, only decimal ones.
+				if n, err := strconv.ParseInt(x.text, 10, 64); err == nil {
+					if n == int64(int(n)) {
+						return int(n)
+					}
+					return n
+				}
+				return x.text
+			}
+		}
+	}
+	return x.v
+}
+
+// resolvesScalars reports whether any of the options set on o resolve scalars
+// differently from go-yaml.
+func (o *decodeOptions) resolvesScalars() bool {
+	return o.legacyNumbers != LegacyNumbersUnchanged || o.ambiguous || o.yamlVersion != 0
+}
+
+// resolvesBase60 reports whether base 60 numbers are resolved as numbers.
+func (o *decodeOptions) resolvesBase60() bool {
+	return o.legacyNumbers == LegacyNumbersResolved || o.yamlVersion == YAML11 && o.legacyNumbers == LegacyNumbersUnchanged
+}
+
+// parseSexagesimal parses a base 60 number, returning an int if it has no
+// fractional part and a float64 otherwise.
+func parseSexagesimal(s string) (interface{}, bool) {
+	s = strings.Replace(s, "_", "", -1)
+	sign := 1
+	switch s[0] {
+	case '-':
+		sign = -1
+		fallthrough
+	case '+':
+		s = s[1:]
+	}
+	parts := strings.Split(s, ":")
+	last := parts[len(parts)-1]
+	if strings.Contains(last, ".") {
+		var f float64
+		for _, p := range parts[:len(parts)-1] {
+			n, err := strconv.ParseFloat(p, 64)
+			if err != nil {
+				return nil, false
+			}
+			f = f*60 + n
+		}
+		n, err := strconv.ParseFloat(last, 64)
+		if err != nil {
+			return nil, false
+		}
+		return float64(sign) * (f*60 + n), true
+	}
+	var n int64
+	for _, p := range parts {
+		d, err := strconv.ParseInt(p, 10, 64)
+		if err != nil || n > (1<<63-1-d)/60 {
+			return nil, false
+		}
+		n = n*60 + d
+	}
+	n *= int64(sign)
+	if n == int64(int(n)) {
+		return int(n), true
+	}
+	return n, true
+}
@@ -0,0 +1,148 @@
+package yaml
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecodeLegacyNumbers(t *testing.T) {
+	y := []byte(`octal: 0777
+negative: -010
+modern: 0o17
+hex: 0x1F
+zero: 0
+base60: 1:30:00
+base60float: -1:30.5
+quoted: "0777"
+time: "12:00"
+`)
+	for _, tc := range []struct {
+		p    LegacyNumbers
+		want map[string]interface{}
+	}{
+		{LegacyNumbersUnchanged, map[string]interface{}{
+			"octal": float64(511), "negative": float64(-8), "modern": float64(15), "hex": float64(31), "zero": float64(0),
+			"base60": "1:30:00", "base60float": "-1:30.5", "quoted": "0777", "time": "12:00",
+		}},
+		{LegacyNumbersResolved, map[string]interface{}{
+			"octal": float64(511), "negative": float64(-8), "modern": float64(15), "hex": float64(31), "zero": float64(0),
+			"base60": float64(5400), "base60float": float64(-90.5), "quoted": "0777", "time": float64(720),
+		}},
+		{LegacyNumbersAsStrings, map[string]interface{}{
+			"octal": "0777", "negative": "-010", "modern": float64(15), "hex": float64(31), "zero": float64(0),
+			"base60": "1:30:00", "base60float": "-1:30.5", "quoted": "0777", "time": "12:00",
+		}},
+	} {
+		var got map[string]interface{}
+		if err := Unmarshal(y, &got, DecodeLegacyNumbers(tc.p)); err != nil {
+			t.Fatalf("Unmarshal(%v) = %v", tc.p, err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Unmarshal(%v) = %v; want %v", tc.p, got, tc.want)
+		}
+	}
+
+	// Legacy numbers decode into matching fields.
+	var s struct {
+		Mode    string `json:"mode"`
+		Timeout int    `json:"timeout"`
+	}
+	if err := Unmarshal([]byte("mode: 0644\ntimeout: 1:00\n"), &s, DecodeLegacyNumbers(LegacyNumbersResolved)); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	if s.Mode != "420" || s.Timeout != 60 {
+		t.Errorf("Unmarshal() = %+v; want mode 420 and timeout 60", s)
+	}
+	if err := Unmarshal([]byte("mode: 0644\n"), &s, DecodeLegacyNumbers(LegacyNumbersAsStrings)); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	if s.Mode != "0644" {
+		t.Errorf("Unmarshal() = %+v; want mode 0644", s)
+	}
+}
+
+func TestAmbiguousNumbersAsStrings(t *testing.T) {
+	y := []byte(`zip: 01234
+id: 0123
+commit: 123e456
+exp: 12e+3
+float: 1.5
+zero: 0
+half: 0.5
+count: 42
+01234: key
+`)
+	j, err := YAMLToJSON(y)
+	if err != nil {
+		t.Fatalf("YAMLToJSON() = %v", err)
+	}
+	want := `{"668":"key","commit":"123e456","count":42,"exp":12000,"float":1.5,"half":0.5,"id":83,"zero":0,"zip":668}`
+	if string(j) != want {
+		t.Errorf("YAMLToJSON() = %s; want %s", j, want)
+	}
+
+	j, err = YAMLToJSONWithOpts(y, AmbiguousNumbersAsStrings())
+	if err != nil {
+		t.Fatalf("YAMLToJSONWithOpts() = %v", err)
+	}
+	want = `{"01234":"key","commit":"123e456","count":42,"exp":"12e+3","float":1.5,"half":0.5,"id":"0123","zero":0,"zip":"01234"}`
+	if string(j) != want {
+		t.Errorf("YAMLToJSONWithOpts() = %s; want %s", j, want)
+	}
+
+	var s struct {
+		Zip string `json:"zip"`
+	}
+	if err := Unmarshal(y, &s, AmbiguousNumbersAsStrings()); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	if s.Zip != "01234" {
+		t.Errorf("Unmarshal() = %+v; want zip 01234", s)
+	}
+}
+
+func TestYAMLVersion(t *testing.T) {
+	y := []byte(`bool: true
+word: yes
+octal: 0777
+modern: 0o17
+binary: 0b101
+exp: 1e+3
+under: 1_000
+base60: 1:30
+date: 2001-12-14
+`)
+	for _, tc := range []struct {
+		v    YAMLVersion
+		want string
+	}{
+		{0, `{"base60":"1:30","binary":5,"bool":true,"date":"2001-12-14","exp":1000,"modern":15,"octal":511,"under":1000,"word":true}`},
+		{YAML11, `{"base60":90,"binary":5,"bool":true,"date":"2001-12-14","exp":"1e+3","modern":"0o17","octal":511,"under":1000,"word":true}`},
+		{YAML12, `{"base60":"1:30","binary":"0b101","bool":true,"date":"2001-12-14","exp":1000,"modern":15,"octal":777,"under":"1_000","word":"yes"}`},
+	} {
+		j, err := YAMLToJSONWithOpts(y, DecodeYAMLVersion(tc.v))
+		if err != nil {
+			t.Fatalf("YAMLToJSONWithOpts(%v) = %v", tc.v, err)
+		}
+		if string(j) != tc.want {
+			t.Errorf("YAMLToJSONWithOpts(%v) = %s; want %s", tc.v, j, tc.want)
+		}
+	}
+
+	v := []string{"yes", "1e3", "0o17", "1:30", "true", "null", "0x1F", "text"}
+	for _, tc := range []struct {
+		v    YAMLVersion
+		want string
+	}{
+		{YAML11, "- \"yes\"\n- 1e3\n- \"0o17\"\n- \"1:30\"\n- \"true\"\n- \"null\"\n- \"0x1F\"\n- text\n"},
+		{YAML12, "- yes\n- \"1e3\"\n- \"0o17\"\n- 1:30\n- \"true\"\n- \"null\"\n- \"0x1F\"\n- text\n"},
+	} {
+		y, err := Marshal(v, WithYAMLVersion(tc.v))
+		if err != nil {
+			t.Fatalf("Marshal(%v) = %v", tc.v, err)
+		}
+		if string(y) != tc.want {
+			t.Errorf("Marshal(%v) = %#q; want %#q", tc.v, string(y), tc.want)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package yaml
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"gopkg.in/yaml.v2"
+)
+
+// A rest field is a struct field tagged `json:"-" yaml:",rest"` that holds the
+// keys of a YAML mapping that match none of the other fields of the struct.
+// Unmarshal decodes those keys into it and Marshal writes them back out, so
+// that documents round-trip through structs that only model part of them. The
+// field must be a map with string keys, such as map[string]interface{}.
+//
+// Like in go-yaml, a map field tagged `yaml:",inline"` is a rest field too,
+// whatever its json tag.
+
+// restField returns the index of the rest field of the struct type t, or nil
+// if it has none.
+func restField(t reflect.Type) []int {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" || sf.Type.Kind() != reflect.Map || sf.Type.Key().Kind() != reflect.String {
+			continue
+		}
+		_, opts := parseTag(sf.Tag.Get("yaml"))
+		if opts.Contains("inline") || opts.Contains("rest") && sf.Tag.Get("json") == "-" {
+			return sf.Index
+		}
+	}
+	return nil
+}
+
+// An inline field is a struct field tagged `yaml:",inline"` whose fields are
+// written into the mapping of the struct that holds it, as go-yaml does,
+// rather than into a mapping of their own. Fields of embedded structs are
+// already inlined by encoding/json.
+
+// inlineField returns the inline struct field of the struct type t that the
+// given key of a YAML mapping decodes into, if any.
+func inlineField(t reflect.Type, key string, opts *decodeOptions) *field {
+	fields := cachedTypeFields(t)
+	for i := range fields {
+		ff := &fields[i]
+		if !ff.yamlInline || ff.typ.Kind() != reflect.Struct {
+			continue
+		}
+		if f, _ := findField(ff.typ, key, opts); f != nil || inlineField(ff.typ, key, opts) != nil {
+			return ff
+		}
+	}
+	return nil
+}
+
+// restObject is the JSON-compatible form of a YAML mapping that is decoded
+// into a struct with a rest field. Only fields is written to the JSON
+// document; rest holds the keys left for the rest field.
+type restObject struct {
+	fields map[string]interface{}
+	rest   map[string]interface{}
+}
+
+func (o restObject) MarshalJSON() ([]byte, error) {
+	return json.Marshal(o.fields)
+}
+
+// restItems returns the keys of the rest field rv as mapping items, leaving out
+// any that the other fields of the struct already wrote.
+func (e *encoder) restItems(rv reflect.Value, written yaml.MapSlice) (yaml.MapSlice, error) {
+	if rv.Len() == 0 {
+		return nil, nil
+	}
+	b, err := json.Marshal(rv.Interface())
+	if err != nil {
+		return nil, err
+	}
+	obj, err := decodeJSON(b)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[interface{}]bool, len(written))
+	for _, item := range written {
+		seen[item.Key] = true
+	}
+	entries := mapEntriesByKey(rv)
+	var items yaml.MapSlice
+	for _, item := range obj.(yaml.MapSlice) {
+		if seen[item.Key] {
+			continue
+		}
+		entry := entries[item.Key.(string)]
+		item.Value, err = e.applyStructs(item.Value, entry.value)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
@@ -0,0 +1,113 @@
+package yaml
+
+import (
+	"reflect"
+	"testing"
+)
+
+type RestInner struct {
+	Known string                 `json:"known"`
+	Rest  map[string]interface{} `json:"-" yaml:",rest"`
+}
+
+type RestTest struct {
+	Name   string                 `json:"name"`
+	Inner  RestInner              `json:"inner"`
+	Inners []RestInner            `json:"inners"`
+	ByName map[string]RestInner   `json:"byName"`
+	Rest   map[string]interface{} `json:"-" yaml:",rest"`
+}
+
+func TestRestField(t *testing.T) {
+	y := "byName:\n  a:\n    known: a\n    other: 1\n" +
+		"extra:\n  nested: true\n" +
+		"inner:\n  known: i\n  other: x\n" +
+		"inners:\n- known: s\n  other:\n  - 2\n" +
+		"name: nm\n"
+	want := RestTest{
+		Name:   "nm",
+		Inner:  RestInner{"i", map[string]interface{}{"other": "x"}},
+		Inners: []RestInner{{"s", map[string]interface{}{"other": []interface{}{float64(2)}}}},
+		ByName: map[string]RestInner{"a": {"a", map[string]interface{}{"other": float64(1)}}},
+		Rest:   map[string]interface{}{"extra": map[string]interface{}{"nested": true}},
+	}
+
+	var got RestTest
+	if err := Unmarshal([]byte(y), &got, DisallowUnknownFields); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal() = %+v; want %+v", got, want)
+	}
+
+	out, err := Marshal(got)
+	if err != nil {
+		t.Fatalf("Marshal() = %v", err)
+	}
+	if string(out) != y {
+		t.Errorf("Marshal() = %#q; want %#q", string(out), y)
+	}
+}
+
+func TestRestFieldKeepsKnownKeys(t *testing.T) {
+	// Keys in the rest field never override the struct's own fields.
+	v := RestInner{"k", map[string]interface{}{"known": "rest", "other": "o"}}
+	out, err := Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal() = %v", err)
+	}
+	if want := "known: k\nother: o\n"; string(out) != want {
+		t.Errorf("Marshal() = %#q; want %#q", string(out), want)
+	}
+}
+
+type InlineMeta struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+type InlineBase struct {
+	Kind string `json:"kind"`
+}
+
+type InlineTest struct {
+	Meta   InlineMeta        `json:"meta" yaml:",inline"`
+	Base   *InlineBase       `json:"base" yaml:",inline"`
+	Size   int               `json:"size"`
+	Extras map[string]string `json:"extras" yaml:",inline"`
+}
+
+func TestInline(t *testing.T) {
+	y := "kind: Pod\nlabels:\n  app: web\nname: web-1\nother: x\nsize: 3\n"
+	want := InlineTest{
+		Meta:   InlineMeta{Name: "web-1", Labels: map[string]string{"app": "web"}},
+		Base:   &InlineBase{Kind: "Pod"},
+		Size:   3,
+		Extras: map[string]string{"other": "x"},
+	}
+
+	var got InlineTest
+	if err := Unmarshal([]byte(y), &got, StrictFields()); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal() = %+v; want %+v", got, want)
+	}
+
+	out, err := Marshal(got)
+	if err != nil {
+		t.Fatalf("Marshal() = %v", err)
+	}
+	if string(out) != y {
+		t.Errorf("Marshal() = %#q; want %#q", string(out), y)
+	}
+
+	// The names of inline fields are not keys of the document.
+	got = InlineTest{}
+	if err := Unmarshal([]byte("meta: a\n"), &got); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	if got.Meta.Name != "" || got.Extras["meta"] != "a" {
+		t.Errorf("Unmarshal() = %+v; want meta kept in Extras", got)
+	}
+}
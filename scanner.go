@@ -0,0 +1,201 @@
+package yaml
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+
+	yaml3 "gopkg.in/yaml.v3"
+)
+
+// TokenKind is the kind of a Token.
+type TokenKind int
+
+const (
+	// DocumentStartToken starts each document of the stream.
+	DocumentStartToken TokenKind = iota
+	// DocumentEndToken ends each document of the stream.
+	DocumentEndToken
+	// MappingStartToken starts a mapping, whose keys and values follow in
+	// turn.
+	MappingStartToken
+	// MappingEndToken ends a mapping.
+	MappingEndToken
+	// SequenceStartToken starts a sequence, whose items follow.
+	SequenceStartToken
+	// SequenceEndToken ends a sequence.
+	SequenceEndToken
+	// KeyToken is a scalar that is the key of a mapping.
+	KeyToken
+	// ScalarToken is any other scalar.
+	ScalarToken
+	// AliasToken is an alias, whose Value is the name of the anchor it
+	// refers to.
+	AliasToken
+)
+
+func (k TokenKind) String() string {
+	switch k {
+	case DocumentStartToken:
+		return "document start"
+	case DocumentEndToken:
+		return "document end"
+	case MappingStartToken:
+		return "mapping start"
+	case MappingEndToken:
+		return "mapping end"
+	case SequenceStartToken:
+		return "sequence start"
+	case SequenceEndToken:
+		return "sequence end"
+	case KeyToken:
+		return "key"
+	case ScalarToken:
+		return "scalar"
+	case AliasToken:
+		return "alias"
+	}
+	return "TokenKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// TokenStyle is the style a scalar or collection is written in.
+type TokenStyle int
+
+const (
+	// TokenPlain is an unquoted scalar, or a block collection.
+	TokenPlain TokenStyle = iota
+	// TokenSingleQuoted is a scalar in single quotes.
+	TokenSingleQuoted
+	// TokenDoubleQuoted is a scalar in double quotes.
+	TokenDoubleQuoted
+	// TokenLiteral is a literal block scalar, introduced by "|".
+	TokenLiteral
+	// TokenFolded is a folded block scalar, introduced by ">".
+	TokenFolded
+	// TokenFlow is a flow collection, in brackets or braces.
+	TokenFlow
+)
+
+// Token is an element of the structure of a YAML document, as a Scanner
+// reads it.
+type Token struct {
+	Kind TokenKind
+	// Pos is where the token starts in the document. It is the zero
+	// Position for tokens that end documents and collections.
+	Pos Position
+	// Value is the text of a scalar, after unquoting and folding, or the
+	// name of the anchor of an alias.
+	Value string
+	// Tag is the tag of a scalar or collection, either given in the
+	// document or resolved from a plain scalar, such as "!!int".
+	Tag string
+	// Anchor is the name of the anchor of a scalar or collection, if it
+	// has one.
+	Anchor string
+	Style  TokenStyle
+}
+
+// Scanner reads the tokens of a stream of YAML documents, with their
+// positions, for tools such as highlighters and linters that look at how a
+// document is written rather than decode it. Comments are not tokens.
+type Scanner struct {
+	d      *yaml3.Decoder
+	y      []byte
+	tokens []Token
+	err    error
+}
+
+// NewScanner returns a Scanner reading the stream y.
+func NewScanner(y []byte) *Scanner {
+	return &Scanner{d: yaml3.NewDecoder(bytes.NewReader(y)), y: y}
+}
+
+// Next returns the next token of the stream. It returns io.EOF after the
+// last one, and an error, which it keeps returning, once it finds a document
+// that cannot be parsed. The tokens of a document are only returned once all
+// of the document is parsed.
+func (s *Scanner) Next() (Token, error) {
+	for len(s.tokens) == 0 {
+		if s.err != nil {
+			return Token{}, s.err
+		}
+		var doc yaml3.Node
+		if err := s.d.Decode(&doc); err != nil {
+			s.err = tabIndentError(s.y, err)
+			continue
+		}
+		s.tokens = appendTokens(s.tokens, &doc, false)
+	}
+	t := s.tokens[0]
+	s.tokens = s.tokens[1:]
+	return t, nil
+}
+
+// appendTokens appends the tokens of n to tokens. key is set if n is a
+// mapping key.
+func appendTokens(tokens []Token, n *yaml3.Node, key bool) []Token {
+	t := Token{
+		Pos:    Position{n.Line, n.Column},
+		Tag:    n.ShortTag(),
+		Anchor: n.Anchor,
+	}
+	switch {
+	case n.Style&yaml3.SingleQuotedStyle != 0:
+		t.Style = TokenSingleQuoted
+	case n.Style&yaml3.DoubleQuotedStyle != 0:
+		t.Style = TokenDoubleQuoted
+	case n.Style&yaml3.LiteralStyle != 0:
+		t.Style = TokenLiteral
+	case n.Style&yaml3.FoldedStyle != 0:
+		t.Style = TokenFolded
+	case n.Style&yaml3.FlowStyle != 0:
+		t.Style = TokenFlow
+	}
+
+	switch n.Kind {
+	case yaml3.DocumentNode:
+		tokens = append(tokens, Token{Kind: DocumentStartToken, Pos: t.Pos})
+		for _, c := range n.Content {
+			tokens = appendTokens(tokens, c, false)
+		}
+		return append(tokens, Token{Kind: DocumentEndToken})
+	case yaml3.MappingNode:
+		t.Kind = MappingStartToken
+		tokens = append(tokens, t)
+		for i, c := range n.Content {
+			tokens = appendTokens(tokens, c, i%2 == 0)
+		}
+		return append(tokens, Token{Kind: MappingEndToken})
+	case yaml3.SequenceNode:
+		t.Kind = SequenceStartToken
+		tokens = append(tokens, t)
+		for _, c := range n.Content {
+			tokens = appendTokens(tokens, c, false)
+		}
+		return append(tokens, Token{Kind: SequenceEndToken})
+	case yaml3.AliasNode:
+		return append(tokens, Token{Kind: AliasToken, Pos: t.Pos, Value: n.Value})
+	}
+	t.Kind = ScalarToken
+	if key {
+		t.Kind = KeyToken
+	}
+	t.Value = n.Value
+	return append(tokens, t)
+}
+
+// Tokens returns all tokens of the stream y, as a Scanner reads them.
+func Tokens(y []byte) ([]Token, error) {
+	s := NewScanner(y)
+	var tokens []Token
+	for {
+		t, err := s.Next()
+		if err == io.EOF {
+			return tokens, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, t)
+	}
+}
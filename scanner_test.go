@@ -0,0 +1,74 @@
+package yaml
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestTokens(t *testing.T) {
+	y := `name: &n 'web'
+ports: [80, 443]
+alias: *n
+script: |
+  run
+---
+- 1
+`
+	want := []Token{
+		{Kind: DocumentStartToken, Pos: Position{1, 1}},
+		{Kind: MappingStartToken, Pos: Position{1, 1}, Tag: "!!map"},
+		{Kind: KeyToken, Pos: Position{1, 1}, Value: "name", Tag: "!!str"},
+		{Kind: ScalarToken, Pos: Position{1, 7}, Value: "web", Tag: "!!str", Anchor: "n", Style: TokenSingleQuoted},
+		{Kind: KeyToken, Pos: Position{2, 1}, Value: "ports", Tag: "!!str"},
+		{Kind: SequenceStartToken, Pos: Position{2, 8}, Tag: "!!seq", Style: TokenFlow},
+		{Kind: ScalarToken, Pos: Position{2, 9}, Value: "80", Tag: "!!int"},
+		{Kind: ScalarToken, Pos: Position{2, 13}, Value: "443", Tag: "!!int"},
+		{Kind: SequenceEndToken},
+		{Kind: KeyToken, Pos: Position{3, 1}, Value: "alias", Tag: "!!str"},
+		{Kind: AliasToken, Pos: Position{3, 8}, Value: "n"},
+		{Kind: KeyToken, Pos: Position{4, 1}, Value: "script", Tag: "!!str"},
+		{Kind: ScalarToken, Pos: Position{4, 9}, Value: "run\n", Tag: "!!str", Style: TokenLiteral},
+		{Kind: MappingEndToken},
+		{Kind: DocumentEndToken},
+		{Kind: DocumentStartToken, Pos: Position{6, 1}},
+		{Kind: SequenceStartToken, Pos: Position{7, 1}, Tag: "!!seq"},
+		{Kind: ScalarToken, Pos: Position{7, 3}, Value: "1", Tag: "!!int"},
+		{Kind: SequenceEndToken},
+		{Kind: DocumentEndToken},
+	}
+	got, err := Tokens([]byte(y))
+	if err != nil {
+		t.Fatalf("Tokens() = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		for i := range got {
+			if i >= len(want) || got[i] != want[i] {
+				t.Errorf("token %d = %+v", i, got[i])
+			}
+		}
+		t.Fatalf("Tokens() returned %d tokens, want %d", len(got), len(want))
+	}
+
+	if tokens, err := Tokens(nil); err != nil || len(tokens) != 0 {
+		t.Errorf("Tokens(nil) = %v, %v", tokens, err)
+	}
+
+	// The tokens of documents before one that cannot be parsed are returned.
+	s := NewScanner([]byte("a\n---\nb:\n\tc\n"))
+	var kinds []TokenKind
+	for {
+		tok, err := s.Next()
+		if err != nil {
+			var te *TabIndentError
+			if !errors.As(err, &te) {
+				t.Errorf("Next() = %v, want a *TabIndentError", err)
+			}
+			break
+		}
+		kinds = append(kinds, tok.Kind)
+	}
+	if want := []TokenKind{DocumentStartToken, ScalarToken, DocumentEndToken}; !reflect.DeepEqual(kinds, want) {
+		t.Errorf("Next() = %v, want %v", kinds, want)
+	}
+}
@@ -0,0 +1,621 @@
+package yaml
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ValidationError is a value of a YAML document that does not match its
+// schema.
+type ValidationError struct {
+	// Path is the JSON Pointer of the value, such as "/spec/replicas", or ""
+	// for the whole document.
+	Path string
+	// Position is where the value is written in the YAML document, as a
+	// SourceMap has it. For a missing property, it is the position of the
+	// object that lacks it. It is the zero Position if the document could
+	// not be read.
+	Position Position
+	// Keyword is the schema keyword that the value fails, such as
+	// "required" or "maximum".
+	Keyword string
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	path := e.Path
+	if path == "" {
+		path = "/"
+	}
+	if e.Position == (Position{}) {
+		return path + ": " + e.Message
+	}
+	return e.Position.String() + ": " + path + ": " + e.Message
+}
+
+// ValidateSchema validates the YAML document doc, in the JSON form YAMLToJSON
+// converts it to, against the JSON Schema schema, which may be written in
+// JSON or YAML. It returns the values that fail the schema, in the order of
+// the document, along with where they are in doc, or nil if it is valid.
+// Documents or schemas that cannot be read are reported as a single
+// ValidationError with no Keyword.
+//
+// The validation keywords of JSON Schema draft 2020-12 are supported, along
+// with the forms of items, additionalItems, exclusiveMinimum and
+// exclusiveMaximum of draft 7 and earlier. $ref can only refer to the schema
+// itself, as in "#/$defs/port". The formats date-time, date, time, email,
+// hostname, ipv4, ipv6, uri, uuid and regex are checked, while other formats
+// are not.
+func ValidateSchema(doc, schema []byte) []ValidationError {
+	return validateSchema(doc, schema, "", false)
+}
+
+// validateSchema validates doc against the schema that the reference ref
+// refers to in the document schema, or against the whole document if ref is
+// "". openAPI sets the dialect of OpenAPI.
+func validateSchema(doc, schema []byte, ref string, openAPI bool) []ValidationError {
+	root, err := decodeDocument(schema)
+	if err != nil {
+		return []ValidationError{{Message: "invalid schema: " + err.Error()}}
+	}
+	sv := &schemaValidator{root: root, openAPI: openAPI, patterns: map[string]*regexp.Regexp{}}
+	s := root
+	if ref != "" {
+		if s, err = sv.resolveRef(ref); err != nil {
+			return []ValidationError{{Message: "invalid schema: " + err.Error()}}
+		}
+	}
+	j, sources, err := YAMLToJSONWithSourceMap(doc)
+	if err != nil {
+		return []ValidationError{{Message: err.Error()}}
+	}
+	d := json.NewDecoder(bytes.NewReader(j))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return []ValidationError{{Message: err.Error()}}
+	}
+
+	sv.validate(s, v, "", 0)
+	if len(sv.errs) == 0 {
+		return nil
+	}
+	for i := range sv.errs {
+		sv.errs[i].Position, _ = sources.Lookup(sv.errs[i].Path)
+	}
+	sort.SliceStable(sv.errs, func(i, j int) bool {
+		a, b := sv.errs[i].Position, sv.errs[j].Position
+		return a.Line < b.Line || a.Line == b.Line && a.Column < b.Column
+	})
+	return sv.errs
+}
+
+// maxSchemaDepth bounds the nesting of schemas, so that a $ref that refers
+// to itself without consuming any of the document fails instead of
+// recursing forever.
+const maxSchemaDepth = 256
+
+// schemaValidator validates a JSON value against a schema.
+type schemaValidator struct {
+	root interface{}
+	// openAPI enables the nullable keyword and the formats of OpenAPI.
+	openAPI  bool
+	patterns map[string]*regexp.Regexp
+	errs     []ValidationError
+}
+
+func (sv *schemaValidator) fail(path, keyword, format string, args ...interface{}) {
+	sv.errs = append(sv.errs, ValidationError{Path: path, Keyword: keyword, Message: fmt.Sprintf(format, args...)})
+}
+
+// matches reports whether v, at path, is valid against schema s, without
+// recording its errors.
+func (sv *schemaValidator) matches(s, v interface{}, path string, depth int) bool {
+	errs := sv.errs
+	sv.errs = nil
+	sv.validate(s, v, path, depth)
+	ok := len(sv.errs) == 0
+	sv.errs = errs
+	return ok
+}
+
+// validate records the errors of the value v, found at path, against the
+// schema s.
+func (sv *schemaValidator) validate(s, v interface{}, path string, depth int) {
+	if depth > maxSchemaDepth {
+		sv.fail(path, "$ref", "schema nested too deep")
+		return
+	}
+	depth++
+	switch s := s.(type) {
+	case bool:
+		if !s {
+			sv.fail(path, "false", "no value is allowed")
+		}
+		return
+	case map[string]interface{}:
+		sv.validateObject(s, v, path, depth)
+	default:
+		sv.fail(path, "", "invalid schema: got %s, want an object or a boolean", jsonType(s))
+	}
+}
+
+func (sv *schemaValidator) validateObject(s map[string]interface{}, v interface{}, path string, depth int) {
+	if ref, ok := s["$ref"].(string); ok {
+		target, err := sv.resolveRef(ref)
+		if err != nil {
+			sv.fail(path, "$ref", "%v", err)
+		} else {
+			sv.validate(target, v, path, depth)
+		}
+	}
+
+	if v == nil && sv.openAPI && s["nullable"] == true {
+		return
+	}
+	if t, ok := s["type"]; ok && !sv.typeMatches(t, v) {
+		sv.fail(path, "type", "got %s, want %s", jsonType(v), typeNames(t))
+		return
+	}
+	if e, ok := s["enum"].([]interface{}); ok {
+		found := false
+		for _, x := range e {
+			if equalValues(x, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			sv.fail(path, "enum", "must be one of %s", jsonText(e))
+		}
+	}
+	if c, ok := s["const"]; ok && !equalValues(c, v) {
+		sv.fail(path, "const", "must be %s", jsonText(c))
+	}
+
+	sv.validateCombinators(s, v, path, depth)
+
+	switch v := v.(type) {
+	case string:
+		sv.validateString(s, v, path)
+	case json.Number:
+		sv.validateNumber(s, v, path)
+	case []interface{}:
+		sv.validateArray(s, v, path, depth)
+	case map[string]interface{}:
+		sv.validateProperties(s, v, path, depth)
+	}
+}
+
+func (sv *schemaValidator) validateCombinators(s map[string]interface{}, v interface{}, path string, depth int) {
+	if all, ok := s["allOf"].([]interface{}); ok {
+		for _, sub := range all {
+			sv.validate(sub, v, path, depth)
+		}
+	}
+	if any, ok := s["anyOf"].([]interface{}); ok {
+		found := false
+		for _, sub := range any {
+			if sv.matches(sub, v, path, depth) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			sv.fail(path, "anyOf", "must match at least one schema of anyOf")
+		}
+	}
+	if one, ok := s["oneOf"].([]interface{}); ok {
+		n := 0
+		for _, sub := range one {
+			if sv.matches(sub, v, path, depth) {
+				n++
+			}
+		}
+		if n != 1 {
+			sv.fail(path, "oneOf", "must match exactly one schema of oneOf, matches %d", n)
+		}
+	}
+	if not, ok := s["not"]; ok && sv.matches(not, v, path, depth) {
+		sv.fail(path, "not", "must not match the schema of not")
+	}
+	if cond, ok := s["if"]; ok {
+		if sv.matches(cond, v, path, depth) {
+			if then, ok := s["then"]; ok {
+				sv.validate(then, v, path, depth)
+			}
+		} else if els, ok := s["else"]; ok {
+			sv.validate(els, v, path, depth)
+		}
+	}
+}
+
+func (sv *schemaValidator) validateString(s map[string]interface{}, v, path string) {
+	n := utf8.RuneCountInString(v)
+	if min, ok := schemaInt(s["minLength"]); ok && n < min {
+		sv.fail(path, "minLength", "must be at least %d characters long", min)
+	}
+	if max, ok := schemaInt(s["maxLength"]); ok && n > max {
+		sv.fail(path, "maxLength", "must be at most %d characters long", max)
+	}
+	if p, ok := s["pattern"].(string); ok {
+		re, err := sv.pattern(p)
+		if err != nil {
+			sv.fail(path, "pattern", "invalid pattern %q: %v", p, err)
+		} else if !re.MatchString(v) {
+			sv.fail(path, "pattern", "must match the pattern %q", p)
+		}
+	}
+	if f, ok := s["format"].(string); ok && !sv.formatMatches(f, v) {
+		sv.fail(path, "format", "must be a valid %s", f)
+	}
+}
+
+func (sv *schemaValidator) validateNumber(s map[string]interface{}, v json.Number, path string) {
+	_, n, ok := parseNumber(string(v))
+	if !ok || n == nil {
+		return
+	}
+	compare := func(bound interface{}) (int, bool) {
+		_, b, ok := numberValue(bound)
+		if !ok || b == nil {
+			return 0, false
+		}
+		return n.Cmp(b), true
+	}
+	if c, ok := compare(s["minimum"]); ok {
+		if s["exclusiveMinimum"] == true && c <= 0 {
+			sv.fail(path, "minimum", "must be greater than %s", jsonText(s["minimum"]))
+		} else if c < 0 {
+			sv.fail(path, "minimum", "must be at least %s", jsonText(s["minimum"]))
+		}
+	}
+	if c, ok := compare(s["maximum"]); ok {
+		if s["exclusiveMaximum"] == true && c >= 0 {
+			sv.fail(path, "maximum", "must be less than %s", jsonText(s["maximum"]))
+		} else if c > 0 {
+			sv.fail(path, "maximum", "must be at most %s", jsonText(s["maximum"]))
+		}
+	}
+	if c, ok := compare(s["exclusiveMinimum"]); ok && c <= 0 {
+		sv.fail(path, "exclusiveMinimum", "must be greater than %s", jsonText(s["exclusiveMinimum"]))
+	}
+	if c, ok := compare(s["exclusiveMaximum"]); ok && c >= 0 {
+		sv.fail(path, "exclusiveMaximum", "must be less than %s", jsonText(s["exclusiveMaximum"]))
+	}
+	if _, m, ok := numberValue(s["multipleOf"]); ok && m != nil && m.Sign() > 0 {
+		if !new(big.Rat).Quo(n, m).IsInt() {
+			sv.fail(path, "multipleOf", "must be a multiple of %s", jsonText(s["multipleOf"]))
+		}
+	}
+	if f, ok := s["format"].(string); ok && sv.openAPI && !numberFormatMatches(f, n) {
+		sv.fail(path, "format", "must be a valid %s", f)
+	}
+}
+
+func (sv *schemaValidator) validateArray(s map[string]interface{}, v []interface{}, path string, depth int) {
+	if min, ok := schemaInt(s["minItems"]); ok && len(v) < min {
+		sv.fail(path, "minItems", "must have at least %d items", min)
+	}
+	if max, ok := schemaInt(s["maxItems"]); ok && len(v) > max {
+		sv.fail(path, "maxItems", "must have at most %d items", max)
+	}
+	if s["uniqueItems"] == true {
+	unique:
+		for i := range v {
+			for j := 0; j < i; j++ {
+				if equalValues(v[i], v[j]) {
+					sv.fail(path, "uniqueItems", "items %d and %d are equal", j, i)
+					break unique
+				}
+			}
+		}
+	}
+
+	// Draft 2020-12 has prefixItems and items, and earlier drafts an array
+	// of items and additionalItems.
+	prefix, _ := s["prefixItems"].([]interface{})
+	rest, hasRest := s["items"]
+	if tuple, ok := rest.([]interface{}); ok {
+		prefix = tuple
+		rest, hasRest = s["additionalItems"]
+	}
+	for i, item := range v {
+		p := path + "/" + strconv.Itoa(i)
+		if i < len(prefix) {
+			sv.validate(prefix[i], item, p, depth)
+		} else if hasRest {
+			sv.validate(rest, item, p, depth)
+		}
+	}
+
+	if c, ok := s["contains"]; ok {
+		n := 0
+		for i, item := range v {
+			if sv.matches(c, item, path+"/"+strconv.Itoa(i), depth) {
+				n++
+			}
+		}
+		min, ok := schemaInt(s["minContains"])
+		if !ok {
+			min = 1
+		}
+		if n < min {
+			sv.fail(path, "contains", "must contain at least %d items that match the schema of contains", min)
+		}
+		if max, ok := schemaInt(s["maxContains"]); ok && n > max {
+			sv.fail(path, "maxContains", "must contain at most %d items that match the schema of contains", max)
+		}
+	}
+}
+
+func (sv *schemaValidator) validateProperties(s map[string]interface{}, v map[string]interface{}, path string, depth int) {
+	if min, ok := schemaInt(s["minProperties"]); ok && len(v) < min {
+		sv.fail(path, "minProperties", "must have at least %d properties", min)
+	}
+	if max, ok := schemaInt(s["maxProperties"]); ok && len(v) > max {
+		sv.fail(path, "maxProperties", "must have at most %d properties", max)
+	}
+	if req, ok := s["required"].([]interface{}); ok {
+		for _, r := range req {
+			if name, ok := r.(string); ok {
+				if _, ok := v[name]; !ok {
+					sv.fail(path+"/"+pointerEscaper.Replace(name), "required", "missing required property %q", name)
+				}
+			}
+		}
+	}
+	if deps, ok := s["dependentRequired"].(map[string]interface{}); ok {
+		sv.validateDependencies(deps, v, path, depth)
+	}
+	if deps, ok := s["dependencies"].(map[string]interface{}); ok {
+		sv.validateDependencies(deps, v, path, depth)
+	}
+	if deps, ok := s["dependentSchemas"].(map[string]interface{}); ok {
+		sv.validateDependencies(deps, v, path, depth)
+	}
+
+	props, _ := s["properties"].(map[string]interface{})
+	patterns, _ := s["patternProperties"].(map[string]interface{})
+	additional, hasAdditional := s["additionalProperties"]
+	names, hasNames := s["propertyNames"]
+	keys := make([]string, 0, len(v))
+	for k := range v {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		p := path + "/" + pointerEscaper.Replace(k)
+		if hasNames && !sv.matches(names, k, p, depth) {
+			sv.fail(p, "propertyNames", "invalid property name %q", k)
+		}
+		matched := false
+		if ps, ok := props[k]; ok {
+			sv.validate(ps, v[k], p, depth)
+			matched = true
+		}
+		for pattern, ps := range patterns {
+			re, err := sv.pattern(pattern)
+			if err != nil {
+				sv.fail(p, "patternProperties", "invalid pattern %q: %v", pattern, err)
+				continue
+			}
+			if re.MatchString(k) {
+				sv.validate(ps, v[k], p, depth)
+				matched = true
+			}
+		}
+		if matched || !hasAdditional {
+			continue
+		}
+		if additional == false {
+			sv.fail(p, "additionalProperties", "property %q is not allowed", k)
+		} else {
+			sv.validate(additional, v[k], p, depth)
+		}
+	}
+}
+
+// validateDependencies checks the properties of v that deps makes depend on
+// others or on a schema.
+func (sv *schemaValidator) validateDependencies(deps map[string]interface{}, v map[string]interface{}, path string, depth int) {
+	keys := make([]string, 0, len(deps))
+	for k := range deps {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if _, ok := v[k]; !ok {
+			continue
+		}
+		required, ok := deps[k].([]interface{})
+		if !ok {
+			sv.validate(deps[k], v, path, depth)
+			continue
+		}
+		for _, r := range required {
+			if name, ok := r.(string); ok {
+				if _, ok := v[name]; !ok {
+					sv.fail(path+"/"+pointerEscaper.Replace(name), "dependentRequired", "missing property %q, which %q requires", name, k)
+				}
+			}
+		}
+	}
+}
+
+// resolveRef returns the schema that the reference ref refers to.
+func (sv *schemaValidator) resolveRef(ref string) (interface{}, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("unsupported reference %q", ref)
+	}
+	p, err := url.PathUnescape(ref[1:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid reference %q", ref)
+	}
+	tokens, err := parsePointer(p)
+	if err != nil {
+		return nil, fmt.Errorf("invalid reference %q", ref)
+	}
+	s := sv.root
+	for _, t := range tokens {
+		switch x := s.(type) {
+		case map[string]interface{}:
+			s = x[t]
+		case []interface{}:
+			i, err := strconv.Atoi(t)
+			if err != nil || i < 0 || i >= len(x) {
+				return nil, fmt.Errorf("reference %q not found", ref)
+			}
+			s = x[i]
+		default:
+			s = nil
+		}
+		if s == nil {
+			return nil, fmt.Errorf("reference %q not found", ref)
+		}
+	}
+	return s, nil
+}
+
+func (sv *schemaValidator) pattern(p string) (*regexp.Regexp, error) {
+	if re, ok := sv.patterns[p]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(p)
+	if err != nil {
+		return nil, err
+	}
+	sv.patterns[p] = re
+	return re, nil
+}
+
+// typeMatches reports whether v has one of the types t, the value of a type
+// keyword.
+func (sv *schemaValidator) typeMatches(t, v interface{}) bool {
+	if types, ok := t.([]interface{}); ok {
+		for _, t := range types {
+			if sv.typeMatches(t, v) {
+				return true
+			}
+		}
+		return false
+	}
+	name, _ := t.(string)
+	switch name {
+	case "integer":
+		_, n, ok := numberValue(v)
+		return ok && n != nil && n.IsInt()
+	case "number":
+		_, ok := v.(json.Number)
+		return ok
+	}
+	return jsonType(v) == name
+}
+
+// formatMatches reports whether the string v is valid for format f. Formats
+// it does not know match any string.
+func (sv *schemaValidator) formatMatches(f, v string) bool {
+	switch f {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339Nano, v)
+		return err == nil
+	case "date":
+		_, err := time.Parse("2006-01-02", v)
+		return err == nil
+	case "time":
+		_, err := time.Parse("15:04:05Z07:00", v)
+		if err != nil {
+			_, err = time.Parse("15:04:05.999999999Z07:00", v)
+		}
+		return err == nil
+	case "email":
+		a, err := mail.ParseAddress(v)
+		return err == nil && a.Address == v
+	case "hostname":
+		return hostnamePattern.MatchString(v) && len(v) <= 253
+	case "ipv4":
+		ip := net.ParseIP(v)
+		return ip != nil && ip.To4() != nil && !strings.Contains(v, ":")
+	case "ipv6":
+		return net.ParseIP(v) != nil && strings.Contains(v, ":")
+	case "uri":
+		u, err := url.Parse(v)
+		return err == nil && u.Scheme != ""
+	case "uuid":
+		return uuidPattern.MatchString(v)
+	case "regex":
+		_, err := regexp.Compile(v)
+		return err == nil
+	case "byte":
+		if sv.openAPI {
+			_, err := base64.StdEncoding.DecodeString(v)
+			return err == nil
+		}
+	}
+	return true
+}
+
+var (
+	hostnamePattern = regexp.MustCompile(`^[A-Za-z0-9](?:[A-Za-z0-9-]{0,61}[A-Za-z0-9])?(?:\.[A-Za-z0-9](?:[A-Za-z0-9-]{0,61}[A-Za-z0-9])?)*$`)
+	uuidPattern     = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+)
+
+// schemaInt returns the value of a keyword that takes a non-negative integer.
+func schemaInt(v interface{}) (int, bool) {
+	_, n, ok := numberValue(v)
+	if !ok || n == nil || !n.IsInt() || !n.Num().IsInt64() {
+		return 0, false
+	}
+	return int(n.Num().Int64()), true
+}
+
+// jsonType returns the name of the JSON Schema type of v.
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number, float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// typeNames returns the value of a type keyword as text.
+func typeNames(t interface{}) string {
+	if types, ok := t.([]interface{}); ok {
+		names := make([]string, len(types))
+		for i, t := range types {
+			names[i] = fmt.Sprint(t)
+		}
+		return strings.Join(names, " or ")
+	}
+	return fmt.Sprint(t)
+}
+
+func jsonText(v interface{}) string {
+	j, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(j)
+}
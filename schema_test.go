@@ -0,0 +1,114 @@
+package yaml
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testSchema = `
+$defs:
+  port: {type: integer, minimum: 1, maximum: 65535}
+type: object
+required: [name, spec]
+additionalProperties: false
+properties:
+  name: {type: string, pattern: "^[a-z-]+$", maxLength: 10}
+  spec:
+    type: object
+    required: [replicas]
+    properties:
+      replicas: {type: integer, minimum: 0, multipleOf: 1}
+      ports:
+        type: array
+        uniqueItems: true
+        items: {$ref: "#/$defs/port"}
+      mode: {enum: [fast, safe]}
+      contact: {type: string, format: email}
+      size: {oneOf: [{type: string}, {type: number, exclusiveMinimum: 0}]}
+`
+
+func TestValidateSchema(t *testing.T) {
+	valid := `name: web
+spec:
+  replicas: 3
+  ports: [80, 443]
+  mode: safe
+  contact: ops@example.com
+  size: 1.5
+`
+	if errs := ValidateSchema([]byte(valid), []byte(testSchema)); errs != nil {
+		t.Errorf("ValidateSchema() of a valid document = %v", errs)
+	}
+
+	invalid := `name: Web_Server_1
+extra: true
+spec:
+  replicas: 2.5
+  ports: [80, 80, 70000]
+  mode: quick
+  contact: not an address
+  size: -1
+`
+	var got []string
+	for _, e := range ValidateSchema([]byte(invalid), []byte(testSchema)) {
+		got = append(got, e.Error())
+	}
+	want := []string{
+		`1:1: /name: must be at most 10 characters long`,
+		`1:1: /name: must match the pattern "^[a-z-]+$"`,
+		`2:1: /extra: property "extra" is not allowed`,
+		`4:3: /spec/replicas: got number, want integer`,
+		`5:3: /spec/ports: items 0 and 1 are equal`,
+		`5:19: /spec/ports/2: must be at most 65535`,
+		`6:3: /spec/mode: must be one of ["fast","safe"]`,
+		`7:3: /spec/contact: must be a valid email`,
+		`8:3: /spec/size: must match exactly one schema of oneOf, matches 0`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ValidateSchema() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	errs := ValidateSchema([]byte("name: web\n"), []byte(testSchema))
+	if len(errs) != 1 || errs[0].Keyword != "required" || errs[0].Path != "/spec" || errs[0].Position != (Position{1, 1}) {
+		t.Errorf("ValidateSchema() of a document missing a property = %#v", errs)
+	}
+
+	for _, tc := range []struct {
+		doc, schema, err string
+	}{
+		{"a: [", "{}", "yaml: line 1"},
+		{"a: 1", "type: [", "invalid schema"},
+		{"a: 1", `{"$ref": "other.json"}`, `unsupported reference "other.json"`},
+		{"a: 1", `{"$ref": "#/missing"}`, `reference "#/missing" not found`},
+		{"a: 1", "false", "no value is allowed"},
+	} {
+		errs := ValidateSchema([]byte(tc.doc), []byte(tc.schema))
+		if len(errs) != 1 || !strings.Contains(errs[0].Error(), tc.err) {
+			t.Errorf("ValidateSchema(%#q, %#q) = %v; want an error containing %q", tc.doc, tc.schema, errs, tc.err)
+		}
+	}
+
+	for _, tc := range []struct {
+		doc, schema string
+		valid       bool
+	}{
+		{"[1, a]", `{items: [{type: integer}], additionalItems: {type: string}}`, true},
+		{"[1, 2]", `{prefixItems: [{type: integer}], items: false}`, false},
+		{"[a, 1]", `{contains: {type: integer}}`, true},
+		{"{a: 1}", `{dependentRequired: {a: [b]}}`, false},
+		{"{a: 1, b: 2}", `{propertyNames: {maxLength: 1}, minProperties: 2}`, true},
+		{"5", `{minimum: 5, exclusiveMinimum: true}`, false},
+		{"5", `{if: {minimum: 5}, then: {multipleOf: 2}}`, false},
+		{"2024-02-30", `{format: date}`, false},
+		{"10.0.0.1", `{format: ipv4}`, true},
+		{"9007199254740993", `{maximum: 9007199254740992}`, false},
+		{"~", `{type: [string, "null"]}`, true},
+		{"a", `{not: {type: string}}`, false},
+	} {
+		errs := ValidateSchema([]byte(tc.doc), []byte(tc.schema))
+		if valid := errs == nil; valid != tc.valid {
+			t.Errorf("ValidateSchema(%#q, %#q) = %v; want valid %v", tc.doc, tc.schema, errs, tc.valid)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package yaml
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// SequenceIterator decodes the items of a document whose root is a block
+// sequence one at a time, as they are read, so that a list of many items,
+// such as an inventory, is never held in memory as a whole:
+//
+//	it := yaml.NewSequenceIterator(f)
+//	for {
+//		var h Host
+//		if err := it.Next(&h); err == io.EOF {
+//			break
+//		} else if err != nil {
+//			return err
+//		}
+//		...
+//	}
+//
+// Each item is decoded as a document of its own, so an alias in one item
+// cannot refer to an anchor in another. The root of the document must be a
+// block sequence, with an item on each line that starts with "-" at the
+// indentation of the first; a flow sequence, such as [1, 2], is not read.
+// Only the first document of the stream is read.
+type SequenceIterator struct {
+	r *bufio.Reader
+	d *Decoder
+	// s splits the document into items. Its col, the column of the "-" of
+	// the items, is -1 before the first item is found.
+	s entrySplitter
+	// next is the first line of the next item, or nil if there is none, and
+	// nextLine its line number.
+	next     []byte
+	nextLine int
+	line     int
+	n        int
+	err      error
+}
+
+// NewSequenceIterator returns a SequenceIterator reading from r, which
+// decodes items with the options opts, which are the options of Unmarshal.
+func NewSequenceIterator(r io.Reader, opts ...JSONOpt) *SequenceIterator {
+	return &SequenceIterator{r: bufio.NewReader(r), d: NewDecoder(opts...), s: entrySplitter{seq: true, col: -1, block: -1}}
+}
+
+// Next decodes the next item into o, as Unmarshal would. It returns io.EOF
+// when there are no more items, and an error naming the item, counting from
+// 0, and the line it starts on if the item cannot be decoded, after which
+// the following items can still be read.
+func (it *SequenceIterator) Next(o interface{}) error {
+	item, line, err := it.item()
+	if err != nil {
+		return err
+	}
+	n := it.n
+	it.n++
+	if err := it.d.Unmarshal(item, o); err != nil {
+		return fmt.Errorf("item %d at line %d: %w", n, line, err)
+	}
+	return nil
+}
+
+// item returns the text of the next item, with its "-" replaced by a space,
+// and the line it starts on.
+func (it *SequenceIterator) item() ([]byte, int, error) {
+	if it.err != nil {
+		return nil, 0, it.err
+	}
+	if it.s.col < 0 {
+		if err := it.start(); err != nil {
+			it.err = err
+			return nil, 0, err
+		}
+	}
+	if it.next == nil {
+		it.err = io.EOF
+		return nil, 0, io.EOF
+	}
+	item, line := it.next, it.nextLine
+	it.s.scan(item)
+	item[it.s.col] = ' '
+	it.next = nil
+	for {
+		l, err := it.readLine()
+		if err == io.EOF || !it.s.open() && (isMarker(l, "---") || isMarker(l, "...")) {
+			break
+		}
+		if err != nil {
+			it.err = err
+			return nil, 0, err
+		}
+		if it.s.startsEntry(l) {
+			it.next, it.nextLine = l, it.line
+			break
+		}
+		item = append(item, l...)
+		it.s.scan(l)
+	}
+	return item, line, nil
+}
+
+// start finds the first item, skipping the comments, directives and "---"
+// line before it.
+func (it *SequenceIterator) start() error {
+	for {
+		l, err := it.readLine()
+		if err == io.EOF {
+			it.s.col = 0
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if it.line == 1 {
+			l = bytes.TrimPrefix(l, utf8BOM)
+		}
+		s := bytes.TrimSpace(l)
+		if isMarker(l, "---") {
+			s = bytes.TrimSpace(l[3:])
+		}
+		if len(s) == 0 || s[0] == '#' || s[0] == '%' && !isMarker(l, "---") {
+			continue
+		}
+		it.s.col = len(l) - len(bytes.TrimLeft(l, " "))
+		if isMarker(l, "---") || !it.s.startsEntry(l) {
+			return fmt.Errorf("yaml: line %d: the root of the document is not a block sequence", it.line)
+		}
+		it.next, it.nextLine = l, it.line
+		return nil
+	}
+}
+
+// readLine returns the next line, with its line break, and io.EOF once there
+// are no more.
+func (it *SequenceIterator) readLine() ([]byte, error) {
+	l, err := it.r.ReadBytes('\n')
+	if len(l) > 0 {
+		it.line++
+		return l, nil
+	}
+	return nil, err
+}
@@ -0,0 +1,107 @@
+package yaml
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSequenceIterator(t *testing.T) {
+	type host struct {
+		Name  string   `json:"name"`
+		Roles []string `json:"roles"`
+	}
+	y := `# inventory
+---
+- name: a
+  roles: [web]
+
+# the database
+- name: b
+  roles:
+  - db
+  - backup
+-
+  name: c
+- name: d
+  roles: x
+- name: e
+...
+- name: ignored
+`
+	it := NewSequenceIterator(strings.NewReader(y), DisallowUnknownFields)
+	var got []host
+	var errs []string
+	for {
+		var h host
+		err := it.Next(&h)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		got = append(got, h)
+	}
+	want := []host{{"a", []string{"web"}}, {"b", []string{"db", "backup"}}, {Name: "c"}, {Name: "e"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Next() = %v, want %v", got, want)
+	}
+	if len(errs) != 1 || !strings.HasPrefix(errs[0], "item 3 at line 13: ") {
+		t.Errorf("Next() errors = %q, want one for item 3", errs)
+	}
+	if err := it.Next(&host{}); err != io.EOF {
+		t.Errorf("Next() after the end = %v", err)
+	}
+
+	// An indented sequence of scalars.
+	it = NewSequenceIterator(strings.NewReader("  - 1\n  - |\n    text\n  -  3\n"))
+	var items []interface{}
+	for {
+		var v interface{}
+		if err := it.Next(&v); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("Next() = %v", err)
+		}
+		items = append(items, v)
+	}
+	if want := []interface{}{1.0, "text\n", 3.0}; !reflect.DeepEqual(items, want) {
+		t.Errorf("Next() = %#v, want %#v", items, want)
+	}
+
+	// Quoted scalars go on at the column of the items, on lines that look
+	// like items.
+	y = "- \"a\n- b\"\n- 'c\n- d'\n- e\n"
+	var all []interface{}
+	if err := Unmarshal([]byte(y), &all); err != nil {
+		t.Fatal(err)
+	}
+	it = NewSequenceIterator(strings.NewReader(y))
+	items = nil
+	for {
+		var v interface{}
+		if err := it.Next(&v); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("Next() = %v", err)
+		}
+		items = append(items, v)
+	}
+	if !reflect.DeepEqual(items, all) {
+		t.Errorf("Next() = %#v, want %#v", items, all)
+	}
+
+	for _, y := range []string{"", "# nothing\n---\n"} {
+		if err := NewSequenceIterator(strings.NewReader(y)).Next(&host{}); err != io.EOF {
+			t.Errorf("Next() of %q = %v, want io.EOF", y, err)
+		}
+	}
+	for _, y := range []string{"a: 1\n", "[1, 2]\n", "--- - 1\n"} {
+		if err := NewSequenceIterator(strings.NewReader(y)).Next(&host{}); err == nil || err == io.EOF {
+			t.Errorf("Next() of %q = %v, want an error", y, err)
+		}
+	}
+}
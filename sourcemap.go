@@ -0,0 +1,130 @@
+package yaml
+
+import (
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+	yaml3 "gopkg.in/yaml.v3"
+)
+
+// SourceMap maps the RFC 6901 JSON Pointers of the values of a JSON document,
+// such as "/spec/containers/0/image", to where they are written in the YAML
+// document the JSON was converted from. A value in a mapping is at its key,
+// and an item of a sequence and the whole document, under the pointer "", are
+// at the value itself. Values that come from an alias are at the values of
+// its anchor, and keys that come from a merge key are at the keys of the
+// merged mapping.
+type SourceMap map[string]Position
+
+// Lookup returns the position of the value at pointer. If the document has no
+// value there, such as for a required key that is missing, it returns the
+// position of the nearest value that holds pointer, and false.
+func (m SourceMap) Lookup(pointer string) (Position, bool) {
+	if pos, ok := m[pointer]; ok {
+		return pos, true
+	}
+	for pointer != "" {
+		pointer = pointer[:strings.LastIndex(pointer, "/")]
+		if pos, ok := m[pointer]; ok {
+			return pos, false
+		}
+	}
+	return Position{}, false
+}
+
+// YAMLToJSONWithSourceMap is like YAMLToJSONWithOpts but also returns the
+// SourceMap of the JSON document, so that a tool that checks the JSON, such
+// as a JSON Schema validator, can point to the YAML its errors are about.
+func YAMLToJSONWithSourceMap(y []byte, opts ...JSONOpt) ([]byte, SourceMap, error) {
+	j, err := YAMLToJSONWithOpts(y, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	m, err := sourcePositions(y, (*keyPath).pointer)
+	if err != nil {
+		return nil, nil, err
+	}
+	return j, m, nil
+}
+
+// pointer returns the JSON Pointer of the path p.
+func (p *keyPath) pointer() string {
+	if p == nil {
+		return ""
+	}
+	if p.idx >= 0 {
+		return p.parent.pointer() + "/" + strconv.Itoa(p.idx)
+	}
+	return p.parent.pointer() + "/" + pointerEscaper.Replace(p.name)
+}
+
+// sourcePositions returns the positions of the values of the first document
+// of the YAML stream y, as described for SourceMap, under the keys that name
+// returns for their paths.
+func sourcePositions(y []byte, name func(*keyPath) string) (map[string]Position, error) {
+	m := map[string]Position{}
+	err := eachNodeDocument(y, func(i int, doc *yaml3.Node) {
+		if i == 0 && len(doc.Content) > 0 {
+			root := doc.Content[0]
+			recordSources(root, nil, Position{root.Line, root.Column}, m, name)
+		}
+	})
+	return m, err
+}
+
+// recordSources adds the position pos of the node n at path, and the
+// positions of the values it holds, to m, keeping any already recorded for a
+// path.
+func recordSources(n *yaml3.Node, path *keyPath, pos Position, m map[string]Position, name func(*keyPath) string) {
+	if _, ok := m[name(path)]; !ok {
+		m[name(path)] = pos
+	}
+	switch n.Kind {
+	case yaml3.AliasNode:
+		recordSources(n.Alias, path, pos, m, name)
+	case yaml3.MappingNode:
+		// The keys of the mapping itself take precedence over merged ones,
+		// and earlier merged mappings over later ones.
+		var merged []*yaml3.Node
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			if k.Kind == yaml3.ScalarNode && k.ShortTag() == "!!merge" {
+				merged = append(merged, v)
+				continue
+			}
+			if key, ok := sourceKey(k); ok {
+				recordSources(v, path.key(key), Position{k.Line, k.Column}, m, name)
+			}
+		}
+		for _, v := range merged {
+			if v.Kind == yaml3.SequenceNode {
+				for _, c := range v.Content {
+					recordSources(c, path, pos, m, name)
+				}
+			} else {
+				recordSources(v, path, pos, m, name)
+			}
+		}
+	case yaml3.SequenceNode:
+		for i, c := range n.Content {
+			recordSources(c, path.index(i), Position{c.Line, c.Column}, m, name)
+		}
+	}
+}
+
+// sourceKey returns the key of the JSON object for the mapping key k, read
+// as Unmarshal reads it, so that the plain key yes is "true".
+func sourceKey(k *yaml3.Node) (string, bool) {
+	if k.Kind != yaml3.ScalarNode {
+		return "", false
+	}
+	if k.Style != 0 {
+		return k.Value, true
+	}
+	var v interface{}
+	if err := yaml.Unmarshal([]byte(k.Value), &v); err != nil {
+		return k.Value, true
+	}
+	return jsonKeyString(v)
+}
@@ -0,0 +1,73 @@
+package yaml
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestYAMLToJSONWithSourceMap(t *testing.T) {
+	y := `# Service.
+base: &base
+  port: 80
+  tags: [web, "a/b"]
+service:
+  <<: *base
+  port: 8080
+  yes: enabled
+  "a~b": 1
+items:
+- name: first
+-   name: second
+`
+	j, m, err := YAMLToJSONWithSourceMap([]byte(y))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, _ := YAMLToJSON([]byte(y)); string(j) != string(want) {
+		t.Errorf("YAMLToJSONWithSourceMap() JSON = %s; want %s", j, want)
+	}
+	want := SourceMap{
+		"":                {2, 1},
+		"/base":           {2, 1},
+		"/base/port":      {3, 3},
+		"/base/tags":      {4, 3},
+		"/base/tags/0":    {4, 10},
+		"/base/tags/1":    {4, 15},
+		"/service":        {5, 1},
+		"/service/port":   {7, 3},
+		"/service/tags":   {4, 3},
+		"/service/tags/0": {4, 10},
+		"/service/tags/1": {4, 15},
+		"/service/true":   {8, 3},
+		"/service/a~0b":   {9, 3},
+		"/items":          {10, 1},
+		"/items/0":        {11, 3},
+		"/items/0/name":   {11, 3},
+		"/items/1":        {12, 5},
+		"/items/1/name":   {12, 5},
+	}
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("YAMLToJSONWithSourceMap() source map = %v; want %v", m, want)
+	}
+
+	for _, tc := range []struct {
+		pointer string
+		pos     Position
+		ok      bool
+	}{
+		{"/service/port", Position{7, 3}, true},
+		{"/items/1/image", Position{12, 5}, false},
+		{"/missing/key", Position{2, 1}, false},
+	} {
+		if pos, ok := m.Lookup(tc.pointer); pos != tc.pos || ok != tc.ok {
+			t.Errorf("Lookup(%q) = %v, %v; want %v, %v", tc.pointer, pos, ok, tc.pos, tc.ok)
+		}
+	}
+
+	if _, m, err := YAMLToJSONWithSourceMap([]byte("# Empty.\n")); err != nil || len(m) != 0 {
+		t.Errorf("YAMLToJSONWithSourceMap() of an empty document = %v, %v", m, err)
+	}
+	if _, _, err := YAMLToJSONWithSourceMap([]byte("a: [")); err == nil {
+		t.Error("YAMLToJSONWithSourceMap() of invalid YAML returned no error")
+	}
+}
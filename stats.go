@@ -0,0 +1,116 @@
+package yaml
+
+import (
+	"bytes"
+	"io"
+
+	yaml3 "gopkg.in/yaml.v3"
+)
+
+// Stats describes the size and shape of a YAML stream, as Inspect finds it.
+type Stats struct {
+	Documents int
+	// Mappings, Sequences, Scalars and Aliases are the numbers of nodes of
+	// each kind in the stream, as it is written. The keys of mappings are
+	// scalars too.
+	Mappings  int
+	Sequences int
+	Scalars   int
+	Aliases   int
+	// MaxDepth is the greatest nesting of the nodes of a document, 1 for a
+	// document that is a scalar.
+	MaxDepth int
+	// AliasExpansion is the number of nodes the stream has once its aliases
+	// are replaced by the values they refer to, over the number of nodes it
+	// has as it is written. It is 1 for a stream without aliases, and grows
+	// with aliases to values that hold aliases, as in the documents of the
+	// "billion laughs" attack.
+	AliasExpansion float64
+	// ScalarBytes is the total length of the values of the scalars, not
+	// counting their quotes and escapes.
+	ScalarBytes int64
+}
+
+// Nodes returns the number of nodes in the stream, as it is written.
+func (s Stats) Nodes() int {
+	return s.Mappings + s.Sequences + s.Scalars + s.Aliases
+}
+
+// Inspect returns the Stats of the YAML stream y, such as to reject
+// documents that are too large or too deep before they are decoded, or to
+// find how much configuration a system holds. Aliases are counted where they
+// are written, without reading the values they refer to again, so Inspect
+// takes little time even for documents that expand greatly. Documents with
+// an alias to an anchor whose value holds the alias, which Unmarshal rejects,
+// are rejected as well.
+func Inspect(y []byte) (Stats, error) {
+	var s Stats
+	y, err := toUTF8(y)
+	if err != nil {
+		return s, err
+	}
+	// expanded holds the number of nodes of each node once its aliases are
+	// expanded, so that every node is counted once. It is a float64 since it
+	// may be too large for an int.
+	expanded := map[*yaml3.Node]float64{}
+	var size func(n *yaml3.Node) float64
+	size = func(n *yaml3.Node) float64 {
+		if n.Kind == yaml3.AliasNode {
+			// The alias itself is replaced by the value.
+			return size(n.Alias)
+		}
+		if c, ok := expanded[n]; ok {
+			return c
+		}
+		c := 1.0
+		for _, child := range n.Content {
+			c += size(child)
+		}
+		expanded[n] = c
+		return c
+	}
+	var count func(n *yaml3.Node, depth int)
+	count = func(n *yaml3.Node, depth int) {
+		if depth > s.MaxDepth {
+			s.MaxDepth = depth
+		}
+		switch n.Kind {
+		case yaml3.MappingNode:
+			s.Mappings++
+		case yaml3.SequenceNode:
+			s.Sequences++
+		case yaml3.ScalarNode:
+			s.Scalars++
+			s.ScalarBytes += int64(len(n.Value))
+		case yaml3.AliasNode:
+			s.Aliases++
+		}
+		for _, c := range n.Content {
+			count(c, depth+1)
+		}
+	}
+
+	var total float64
+	d := yaml3.NewDecoder(bytes.NewReader(y))
+	for {
+		var n yaml3.Node
+		if err := d.Decode(&n); err == io.EOF {
+			break
+		} else if err != nil {
+			return Stats{}, err
+		}
+		if err := checkRecursiveAliases(&n); err != nil {
+			return Stats{}, err
+		}
+		s.Documents++
+		for _, c := range n.Content {
+			count(c, 1)
+			total += size(c)
+		}
+	}
+	s.AliasExpansion = 1
+	if nodes := s.Nodes(); nodes > 0 {
+		s.AliasExpansion = total / float64(nodes)
+	}
+	return s, nil
+}
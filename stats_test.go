@@ -0,0 +1,63 @@
+package yaml
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInspect(t *testing.T) {
+	y := `name: web
+ports: [80, 443]
+tls:
+  cert: "a.pem"
+---
+- 1
+`
+	s, err := Inspect([]byte(y))
+	if err != nil {
+		t.Fatalf("Inspect() = %v", err)
+	}
+	want := Stats{
+		Documents:      2,
+		Mappings:       2,
+		Sequences:      2,
+		Scalars:        9,
+		MaxDepth:       3,
+		AliasExpansion: 1,
+		ScalarBytes:    int64(len("name" + "web" + "ports" + "80" + "443" + "tls" + "cert" + "a.pem" + "1")),
+	}
+	if s != want {
+		t.Errorf("Inspect() = %+v, want %+v", s, want)
+	}
+	if s.Nodes() != 13 {
+		t.Errorf("Nodes() = %d, want 13", s.Nodes())
+	}
+
+	// Each level holds the one before it twice.
+	laughs := `a: &a [lol, lol]
+b: &b [*a, *a]
+c: &c [*b, *b]
+d: [*c, *c]
+`
+	if s, err = Inspect([]byte(laughs)); err != nil {
+		t.Fatalf("Inspect() = %v", err)
+	}
+	if s.Aliases != 6 || s.MaxDepth != 3 {
+		t.Errorf("Inspect() = %+v", s)
+	}
+	// The expanded document has 1 + 4 keys + 3 + 7 + 15 + 31 nodes, the
+	// document as written 1 + 4 keys + 3 + 3 + 3 + 3.
+	if want := 61.0 / 17; s.AliasExpansion != want {
+		t.Errorf("AliasExpansion = %v, want %v", s.AliasExpansion, want)
+	}
+
+	if s, err = Inspect(nil); err != nil || s.Documents != 0 || s.AliasExpansion != 1 {
+		t.Errorf("Inspect(nil) = %+v, %v", s, err)
+	}
+	if _, err := Inspect([]byte("a: &a\n  b: [*a]\n")); err == nil || !strings.Contains(err.Error(), "contains itself") {
+		t.Errorf("Inspect() = %v, want an error for the recursive alias", err)
+	}
+	if _, err := Inspect([]byte("a: [1")); err == nil || !strings.Contains(err.Error(), "line") {
+		t.Errorf("Inspect() = %v, want a syntax error", err)
+	}
+}
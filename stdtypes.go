@@ -0,0 +1,102 @@
+package yaml
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+)
+
+// Of the types of the standard library that configurations hold, net.IP and
+// regexp.Regexp marshal to strings and unmarshal from them by themselves,
+// while net.IPNet and url.URL are structs to encoding/json. The StandardTypes
+// and DecodeStandardTypes options write and read those two as strings too.
+
+var (
+	ipNetType = reflect.TypeOf(net.IPNet{})
+	urlType   = reflect.TypeOf(url.URL{})
+)
+
+// StandardTypes makes Marshal write net.IPNet values as strings in CIDR
+// notation, such as "10.0.0.0/8", and url.URL values as URLs, such as
+// "https://example.com/", as net.IP and regexp.Regexp values already are.
+func StandardTypes() MarshalOpt {
+	return Converters(
+		func(n net.IPNet) (interface{}, error) {
+			return n.String(), nil
+		},
+		func(u url.URL) (interface{}, error) {
+			return u.String(), nil
+		},
+	)
+}
+
+// DecodeStandardTypes makes Unmarshal decode strings in CIDR notation into
+// net.IPNet values and URLs into url.URL values, as StandardTypes writes
+// them. net.IP and regexp.Regexp values are decoded from strings without it.
+func DecodeStandardTypes() JSONOpt {
+	return decodeOpt(func(o *decodeOptions) {
+		o.standardTypes = true
+	})
+}
+
+// parsedObject is a value that is parsed from the document by this package.
+// It is written to the JSON document as null, and fillUndecoded sets the Go
+// value to it once encoding/json is done.
+type parsedObject struct {
+	v reflect.Value
+}
+
+func (parsedObject) MarshalJSON() ([]byte, error) {
+	return []byte("null"), nil
+}
+
+// standardValue returns the parsedObject for yamlObj if values of type t are
+// parsed from strings by DecodeStandardTypes, and false if they are not.
+func (o *decodeOptions) standardValue(yamlObj interface{}, t reflect.Type) (interface{}, bool, error) {
+	if !o.standardTypes || yamlObj == nil {
+		return nil, false, nil
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t != ipNetType && t != urlType {
+		return nil, false, nil
+	}
+	s, ok := yamlObj.(string)
+	if !ok {
+		return nil, true, fmt.Errorf("cannot decode %v into a value of type %v", yamlObj, t)
+	}
+	var v interface{}
+	if t == ipNetType {
+		_, n, err := net.ParseCIDR(s)
+		if err != nil {
+			return nil, true, err
+		}
+		v = n
+	} else {
+		u, err := url.Parse(s)
+		if err != nil {
+			return nil, true, err
+		}
+		v = u
+	}
+	o.undecoded = true
+	return parsedObject{reflect.ValueOf(v).Elem()}, true, nil
+}
+
+// fill sets v to the value of o, allocating v if it is a nil pointer.
+func (o parsedObject) fill(v reflect.Value) {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			if !v.CanSet() {
+				return
+			}
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	if v.CanSet() && v.Type() == o.v.Type() {
+		v.Set(o.v)
+	}
+}
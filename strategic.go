@@ -0,0 +1,235 @@
+package yaml
+
+import (
+	"reflect"
+	"strings"
+)
+
+// PatchStrategy is how ApplyStrategicMergePatch merges a list of the
+// document with the list at the same path in the patch. By default, the list
+// in the patch replaces the one in the document.
+type PatchStrategy struct {
+	// Merge merges the lists. Lists of scalars are merged as sets, keeping
+	// the items of the document first.
+	Merge bool
+	// MergeKey, if set, merges lists of mappings, matching their items by
+	// the value of this key. An item of the patch is merged into the item of
+	// the document it matches, or added to the end of the list if there is
+	// none. MergeKey implies Merge.
+	MergeKey string
+}
+
+// PatchSchema gives the PatchStrategy of the lists of a document by their
+// path. Paths are the keys that lead to a list joined by dots, leaving out
+// the items of lists along the way, such as "spec.template.spec.containers"
+// or "spec.template.spec.containers.ports".
+type PatchSchema map[string]PatchStrategy
+
+// PatchSchemaFor returns the PatchSchema of documents that decode into the
+// Go value v, read from the Kubernetes struct tags patchStrategy and
+// patchMergeKey of its fields, e.g.
+//
+//	Containers []Container `json:"containers" patchStrategy:"merge" patchMergeKey:"name"`
+//
+// Keys are the names json tags give to fields.
+func PatchSchemaFor(v interface{}) PatchSchema {
+	s := PatchSchema{}
+	if t := reflect.TypeOf(v); t != nil {
+		s.addType(t, "", map[reflect.Type]bool{})
+	}
+	return s
+}
+
+func (s PatchSchema) addType(t reflect.Type, path string, seen map[reflect.Type]bool) {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || seen[t] {
+		return
+	}
+	// A type may recur below itself, but at a path of its own.
+	seen[t] = true
+	defer delete(seen, t)
+	for _, f := range cachedTypeFields(t) {
+		p := joinPatchPath(path, f.name)
+		tag := t.FieldByIndex(f.index).Tag
+		// patchStrategy lists strategies, such as "merge,retainKeys".
+		if tagOptions(tag.Get("patchStrategy")).Contains("merge") {
+			s[p] = PatchStrategy{Merge: true, MergeKey: tag.Get("patchMergeKey")}
+		}
+		s.addType(f.typ, p, seen)
+	}
+}
+
+func joinPatchPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// ApplyStrategicMergePatch applies a Kubernetes strategic merge patch to the
+// YAML document doc and returns the patched document as YAML. The patch may
+// be written in JSON or YAML. Mappings are merged as by ApplyMergePatch, and
+// lists as schema says, which may be nil. The patch may also hold these
+// directives:
+//
+//   - "$patch: replace" in a mapping replaces the mapping of the document
+//     with it, and as an item of a list replaces the list.
+//   - "$patch: delete" in a mapping removes the mapping from the document,
+//     and in an item of a list with a merge key removes the matching item.
+//   - "$deleteFromPrimitiveList/<key>: [...]" removes the given items from
+//     the list of scalars under key.
+//   - "$retainKeys: [...]" removes the keys of the mapping that are not
+//     listed.
+//
+// "$setElementOrder" directives are accepted but ignored. Like JSONToYAML,
+// the result has its keys sorted and no comments.
+func ApplyStrategicMergePatch(doc, patch []byte, schema PatchSchema) ([]byte, error) {
+	obj, err := decodeDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+	p, err := decodeDocument(patch)
+	if err != nil {
+		return nil, err
+	}
+	v, _ := strategicMerge(obj, p, "", schema)
+	return encodeDocument(v)
+}
+
+// strategicMerge returns original with patch, the value at path in the patch,
+// merged into it. It returns false if the value is to be deleted.
+func strategicMerge(original, patch interface{}, path string, schema PatchSchema) (interface{}, bool) {
+	switch p := patch.(type) {
+	case map[string]interface{}:
+		o, _ := original.(map[string]interface{})
+		return strategicMergeMap(o, p, path, schema)
+	case []interface{}:
+		o, _ := original.([]interface{})
+		return strategicMergeList(o, p, path, schema), true
+	}
+	return patch, true
+}
+
+func strategicMergeMap(original, patch map[string]interface{}, path string, schema PatchSchema) (interface{}, bool) {
+	switch patch["$patch"] {
+	case "delete":
+		return nil, false
+	case "replace":
+		original = nil
+	}
+	if original == nil {
+		original = map[string]interface{}{}
+	}
+	if keys, ok := patch["$retainKeys"].([]interface{}); ok {
+		retain := make(map[string]bool, len(keys))
+		for _, k := range keys {
+			if s, ok := k.(string); ok {
+				retain[s] = true
+			}
+		}
+		for k := range original {
+			if !retain[k] {
+				delete(original, k)
+			}
+		}
+	}
+	for k, v := range patch {
+		if strings.HasPrefix(k, "$deleteFromPrimitiveList/") {
+			key := strings.TrimPrefix(k, "$deleteFromPrimitiveList/")
+			if list, ok := original[key].([]interface{}); ok {
+				original[key] = removeItems(list, v)
+			}
+			continue
+		}
+		if strings.HasPrefix(k, "$") {
+			continue
+		}
+		if v == nil {
+			delete(original, k)
+			continue
+		}
+		if merged, keep := strategicMerge(original[k], v, joinPatchPath(path, k), schema); keep {
+			original[k] = merged
+		} else {
+			delete(original, k)
+		}
+	}
+	return original, true
+}
+
+func strategicMergeList(original, patch []interface{}, path string, schema PatchSchema) []interface{} {
+	items := make([]interface{}, 0, len(patch))
+	replace := false
+	for _, item := range patch {
+		if m, ok := item.(map[string]interface{}); ok && len(m) == 1 && m["$patch"] == "replace" {
+			replace = true
+			continue
+		}
+		items = append(items, item)
+	}
+	strategy := schema[path]
+	if replace || !strategy.Merge && strategy.MergeKey == "" {
+		return items
+	}
+
+	if strategy.MergeKey == "" {
+		for _, item := range items {
+			if !hasItem(original, item) {
+				original = append(original, item)
+			}
+		}
+		return original
+	}
+	for _, item := range items {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			original = append(original, item)
+			continue
+		}
+		key, hasKey := m[strategy.MergeKey]
+		i := -1
+		for j, o := range original {
+			if om, ok := o.(map[string]interface{}); ok && hasKey && equalValues(om[strategy.MergeKey], key) {
+				i = j
+				break
+			}
+		}
+		switch {
+		case m["$patch"] == "delete":
+			if i >= 0 {
+				original = append(original[:i], original[i+1:]...)
+			}
+		case i >= 0:
+			merged, _ := strategicMergeMap(original[i].(map[string]interface{}), m, path, schema)
+			original[i] = merged
+		default:
+			merged, _ := strategicMergeMap(nil, m, path, schema)
+			original = append(original, merged)
+		}
+	}
+	return original
+}
+
+// hasItem reports whether the list holds a value equal to v.
+func hasItem(list []interface{}, v interface{}) bool {
+	for _, item := range list {
+		if equalValues(item, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// removeItems returns list without the items that equal those of remove.
+func removeItems(list []interface{}, remove interface{}) []interface{} {
+	r, _ := remove.([]interface{})
+	kept := list[:0]
+	for _, item := range list {
+		if !hasItem(r, item) {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
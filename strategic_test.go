@@ -0,0 +1,130 @@
+package yaml
+
+import (
+	"reflect"
+	"testing"
+)
+
+type PatchPort struct {
+	Port     int    `json:"port"`
+	Protocol string `json:"protocol"`
+}
+
+type PatchContainer struct {
+	Name  string      `json:"name"`
+	Image string      `json:"image"`
+	Args  []string    `json:"args" patchStrategy:"merge"`
+	Ports []PatchPort `json:"ports" patchStrategy:"merge,retainKeys" patchMergeKey:"port"`
+}
+
+type PatchPodSpec struct {
+	Containers []PatchContainer `json:"containers" patchStrategy:"merge" patchMergeKey:"name"`
+	Volumes    []string         `json:"volumes"`
+}
+
+type PatchPod struct {
+	Spec *PatchPodSpec `json:"spec"`
+}
+
+func TestPatchSchemaFor(t *testing.T) {
+	want := PatchSchema{
+		"spec.containers":       {Merge: true, MergeKey: "name"},
+		"spec.containers.args":  {Merge: true},
+		"spec.containers.ports": {Merge: true, MergeKey: "port"},
+	}
+	if got := PatchSchemaFor(PatchPod{}); !reflect.DeepEqual(got, want) {
+		t.Errorf("PatchSchemaFor() = %v; want %v", got, want)
+	}
+}
+
+func TestApplyStrategicMergePatch(t *testing.T) {
+	doc := `spec:
+  containers:
+  - name: web
+    image: nginx:1.24
+    args: [-a, -b]
+    ports: [{port: 80, protocol: TCP}]
+  - name: sidecar
+    image: envoy
+  volumes: [data]
+`
+	schema := PatchSchemaFor(&PatchPod{})
+	for _, tc := range []struct {
+		name, patch, want string
+	}{
+		{"merge", `spec:
+  containers:
+  - name: web
+    image: nginx:1.25
+    args: [-b, -c]
+    ports: [{port: 443, protocol: TCP}]
+  - name: logger
+    image: fluentd
+  volumes: [cache]
+`, `spec:
+  containers:
+  - args:
+    - -a
+    - -b
+    - -c
+    image: nginx:1.25
+    name: web
+    ports:
+    - port: 80
+      protocol: TCP
+    - port: 443
+      protocol: TCP
+  - image: envoy
+    name: sidecar
+  - image: fluentd
+    name: logger
+  volumes:
+  - cache
+`},
+		{"delete", `spec:
+  containers:
+  - {name: sidecar, $patch: delete}
+  - name: web
+    $deleteFromPrimitiveList/args: [-a]
+    ports: [{$patch: replace}, {port: 8080}]
+`, `spec:
+  containers:
+  - args:
+    - -b
+    image: nginx:1.24
+    name: web
+    ports:
+    - port: 8080
+  volumes:
+  - data
+`},
+		{"replace", `spec:
+  $patch: replace
+  volumes: [cache]
+`, `spec:
+  volumes:
+  - cache
+`},
+		{"retain keys", `spec:
+  $retainKeys: [volumes]
+  $setElementOrder/containers: [{name: web}]
+`, `spec:
+  volumes:
+  - data
+`},
+	} {
+		got, err := ApplyStrategicMergePatch([]byte(doc), []byte(tc.patch), schema)
+		if err != nil {
+			t.Fatalf("%s: ApplyStrategicMergePatch() = %v", tc.name, err)
+		}
+		if string(got) != tc.want {
+			t.Errorf("%s: ApplyStrategicMergePatch() = %#q; want %#q", tc.name, string(got), tc.want)
+		}
+	}
+
+	// Without a schema, lists are replaced.
+	got, err := ApplyStrategicMergePatch([]byte(doc), []byte("spec: {containers: [{name: x}]}\n"), nil)
+	if err != nil || string(got) != "spec:\n  containers:\n  - name: x\n  volumes:\n  - data\n" {
+		t.Errorf("ApplyStrategicMergePatch() = %#q, %v", string(got), err)
+	}
+}
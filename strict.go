@@ -0,0 +1,149 @@
+package yaml
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// StrictFields configures the conversion to fail if keys of the YAML document
+// match no struct field, at any depth. Unlike DisallowUnknownFields, which
+// stops at the first unknown field, the error is an *UnknownFieldsError that
+// lists the path of every one of them.
+func StrictFields() JSONOpt {
+	return decodeOpt(func(o *decodeOptions) {
+		o.strictFields = true
+	})
+}
+
+// UnknownField is a key of a YAML document that matches no struct field.
+type UnknownField struct {
+	// Path is the path of the key in the document, such as
+	// "spec.containers[0].nmae".
+	Path string
+	// Suggestion is the name of a field the key may have been meant as, if
+	// one is close enough.
+	Suggestion string
+}
+
+// UnknownFieldsError is returned when decoding with StrictFields if the YAML
+// document has unknown fields.
+type UnknownFieldsError struct {
+	// Fields are the unknown fields, sorted by path.
+	Fields []UnknownField
+}
+
+func newUnknownFieldsError(fields []UnknownField) *UnknownFieldsError {
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].Path < fields[j].Path
+	})
+	return &UnknownFieldsError{Fields: fields}
+}
+
+func (e *UnknownFieldsError) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		msgs[i] = f.Path
+		if f.Suggestion != "" {
+			msgs[i] += fmt.Sprintf(" (did you mean %q?)", f.Suggestion)
+		}
+	}
+	return "unknown fields: " + strings.Join(msgs, ", ")
+}
+
+// unknownField records that the key at path matches no field of the struct
+// type t, if o has strictFields set.
+func (o *decodeOptions) unknownField(t reflect.Type, path *keyPath) {
+	if !o.strictFields {
+		return
+	}
+	f := UnknownField{Path: path.String()}
+	best := 0
+	fields := cachedTypeFields(t)
+	for i := range fields {
+		ff := &fields[i]
+		if skipField(ff, o.tags) {
+			continue
+		}
+		name := fieldKey(ff, o.tags, o.fieldNaming)
+		d := editDistance(strings.ToLower(path.name), strings.ToLower(name))
+		// Only suggest names that differ in a few characters and in less
+		// than half of the key.
+		if d <= 2 && 2*d < len(path.name) && (f.Suggestion == "" || d < best) {
+			f.Suggestion, best = name, d
+		}
+	}
+	o.unknownFields = append(o.unknownFields, f)
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+// keyPath is the path of a node in a YAML document, built up as the document
+// is converted. The nil *keyPath is the root.
+type keyPath struct {
+	parent *keyPath
+	name   string
+	idx    int // the index into a sequence, or -1 for a mapping key
+}
+
+func (p *keyPath) key(name string) *keyPath {
+	return &keyPath{parent: p, name: name, idx: -1}
+}
+
+func (p *keyPath) index(i int) *keyPath {
+	return &keyPath{parent: p, idx: i}
+}
+
+func (p *keyPath) String() string {
+	if p == nil {
+		return ""
+	}
+	parent := p.parent.String()
+	if p.idx >= 0 {
+		return parent + "[" + strconv.Itoa(p.idx) + "]"
+	}
+	if parent == "" {
+		return p.name
+	}
+	return parent + "." + p.name
+}
+
+// wrap prefixes err, if any, with the path, if it is not the root.
+func (p *keyPath) wrap(err error) error {
+	if p == nil || err == nil {
+		return err
+	}
+	return fmt.Errorf("%s: %v", p, err)
+}
@@ -0,0 +1,94 @@
+package yaml
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type StrictContainer struct {
+	Name  string `json:"name"`
+	Image string `json:"image"`
+}
+
+type StrictSpec struct {
+	Containers []StrictContainer `json:"containers"`
+	Labels     map[string]string `json:"labels"`
+}
+
+type StrictTest struct {
+	Spec  StrictSpec             `json:"spec"`
+	Extra map[string]interface{} `json:"extra"`
+}
+
+func TestStrictFields(t *testing.T) {
+	y := `
+spec:
+  contaners: []
+  containers:
+  - name: a
+    imgae: b
+    unrelated: c
+  labels:
+    any: key
+extra:
+  anything: goes
+kind: Pod
+`
+	var v StrictTest
+	err := Unmarshal([]byte(y), &v, StrictFields())
+	uerr, ok := err.(*UnknownFieldsError)
+	if !ok {
+		t.Fatalf("Unmarshal() = %v; want an *UnknownFieldsError", err)
+	}
+	want := []UnknownField{
+		{Path: "kind"},
+		{Path: "spec.containers[0].imgae", Suggestion: "image"},
+		{Path: "spec.containers[0].unrelated"},
+		{Path: "spec.contaners", Suggestion: "containers"},
+	}
+	if !reflect.DeepEqual(uerr.Fields, want) {
+		t.Errorf("Unmarshal() fields = %+v; want %+v", uerr.Fields, want)
+	}
+	wantMsg := `unknown fields: kind, spec.containers[0].imgae (did you mean "image"?), ` +
+		`spec.containers[0].unrelated, spec.contaners (did you mean "containers"?)`
+	if uerr.Error() != wantMsg {
+		t.Errorf("Error() = %q; want %q", uerr.Error(), wantMsg)
+	}
+
+	if err := Unmarshal([]byte("spec:\n  containers:\n  - name: a\n"), &v, StrictFields()); err != nil {
+		t.Errorf("Unmarshal() = %v", err)
+	}
+}
+
+func TestStrictFieldsWithJSONOpts(t *testing.T) {
+	// StrictFields takes effect after a JSONOpt that returns another decoder,
+	// and within one that applies it itself.
+	replace := func(d *json.Decoder) *json.Decoder {
+		return json.NewDecoder(d.Buffered())
+	}
+	wrap := func(d *json.Decoder) *json.Decoder {
+		d.UseNumber()
+		return StrictFields()(d)
+	}
+	for _, opts := range [][]JSONOpt{{replace, StrictFields()}, {wrap}} {
+		var v StrictTest
+		if err := Unmarshal([]byte("kind: Pod\n"), &v, opts...); err == nil || err.Error() != "unknown fields: kind" {
+			t.Errorf("Unmarshal() = %v; want an *UnknownFieldsError", err)
+		}
+		if err := NewDecoder(opts...).Unmarshal([]byte("kind: Pod\n"), &v); err == nil || err.Error() != "unknown fields: kind" {
+			t.Errorf("Decoder.Unmarshal() = %v; want an *UnknownFieldsError", err)
+		}
+	}
+
+	// Applied to a json.Decoder of its own, it leaves it unchanged.
+	d := json.NewDecoder(strings.NewReader(`{"kind": "Pod"}`))
+	if got := StrictFields()(d); got != d {
+		t.Errorf("StrictFields() returned another decoder")
+	}
+	var v StrictTest
+	if err := d.Decode(&v); err != nil {
+		t.Errorf("Decode() = %v", err)
+	}
+}
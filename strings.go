@@ -0,0 +1,21 @@
+package yaml
+
+import "unsafe"
+
+// MarshalToString is like Marshal but returns the document as a string,
+// without the copy that converting the result of Marshal makes.
+func MarshalToString(o interface{}, opts ...MarshalOpt) (string, error) {
+	y, err := Marshal(o, opts...)
+	if err != nil || len(y) == 0 {
+		return "", err
+	}
+	// Nothing else holds y, so the string may share its memory.
+	return unsafe.String(&y[0], len(y)), nil
+}
+
+// UnmarshalFromString is like Unmarshal but reads the document from the
+// string s. The document is copied, as the options of Unmarshal may hand it
+// to code that writes to it or keeps it.
+func UnmarshalFromString(s string, o interface{}, opts ...JSONOpt) error {
+	return Unmarshal([]byte(s), o, opts...)
+}
@@ -0,0 +1,29 @@
+package yaml
+
+import "testing"
+
+func TestStrings(t *testing.T) {
+	type config struct {
+		Name  string   `json:"name"`
+		Ports []int    `json:"ports"`
+		Tags  []string `json:"tags,omitempty"`
+	}
+	s, err := MarshalToString(config{Name: "web", Ports: []int{80, 443}}, SortKeys(KeyOrderDocument))
+	if want := "name: web\nports:\n- 80\n- 443\n"; err != nil || s != want {
+		t.Errorf("MarshalToString() = %q, %v, want %q", s, err, want)
+	}
+	if _, err := MarshalToString(make(chan int)); err == nil {
+		t.Error("MarshalToString() did not fail")
+	}
+
+	var c config
+	if err := UnmarshalFromString(s, &c, DisallowUnknownFields); err != nil || c.Name != "web" || len(c.Ports) != 2 {
+		t.Errorf("UnmarshalFromString() = %+v, %v", c, err)
+	}
+	if err := UnmarshalFromString("", &c); err != nil {
+		t.Errorf("UnmarshalFromString(\"\") = %v", err)
+	}
+	if err := UnmarshalFromString("name: web\nport: 80\n", &c, DisallowUnknownFields); err == nil {
+		t.Error("UnmarshalFromString() did not pass on the options")
+	}
+}
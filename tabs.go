@@ -0,0 +1,56 @@
+package yaml
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// TabIndentError is returned when a document is indented with tabs, which
+// YAML forbids, in place of the error of the parser, which only says that it
+// found a character that cannot start any token.
+type TabIndentError struct {
+	// Line is the line, counting from 1, that is indented with a tab.
+	Line int
+	// Err is the error of the parser.
+	Err error
+}
+
+func (e *TabIndentError) Error() string {
+	return fmt.Sprintf("yaml: line %d: found a tab in the indentation, but YAML forbids tabs for indentation; indent with spaces instead", e.Line)
+}
+
+func (e *TabIndentError) Unwrap() error {
+	return e.Err
+}
+
+var tokenError = regexp.MustCompile(`^yaml: (?:line (\d+): )?found character that cannot start any token$`)
+
+// tabIndentError returns a *TabIndentError in place of err, an error parsing
+// y, if the parser failed on a line of y that is indented with a tab, and err
+// otherwise.
+func tabIndentError(y []byte, err error) error {
+	if err == nil {
+		return nil
+	}
+	m := tokenError.FindStringSubmatch(err.Error())
+	if m == nil {
+		return err
+	}
+	// The parser leaves out the number of the first line.
+	line := 1
+	if m[1] != "" {
+		line, _ = strconv.Atoi(m[1])
+	}
+	lines := bytes.Split(y, []byte("\n"))
+	if line < 1 || line > len(lines) {
+		return err
+	}
+	indent := lines[line-1]
+	indent = indent[:len(indent)-len(bytes.TrimLeft(indent, " \t"))]
+	if bytes.IndexByte(indent, '\t') < 0 {
+		return err
+	}
+	return &TabIndentError{Line: line, Err: err}
+}
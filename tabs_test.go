@@ -0,0 +1,44 @@
+package yaml
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTabIndentError(t *testing.T) {
+	tests := []struct {
+		y    string
+		line int
+	}{
+		{"a:\n\tb: 1\n", 2},
+		{"a:\n  b:\n  \t- 1\n", 3},
+		{"\ta: 1\n", 1},
+		// Tabs elsewhere are allowed.
+		{"a: [1,\n\t2]\nb: \"x\ty\"\nc: 1\t# c\n", 0},
+		// So the error is only replaced for the tabs.
+		{"a: @b\n", 0},
+	}
+	for _, tt := range tests {
+		var v interface{}
+		err := Unmarshal([]byte(tt.y), &v)
+		var te *TabIndentError
+		if !errors.As(err, &te) {
+			if tt.line != 0 {
+				t.Errorf("Unmarshal(%q) = %v, want a *TabIndentError", tt.y, err)
+			}
+			continue
+		}
+		if te.Line != tt.line {
+			t.Errorf("Unmarshal(%q) = %v, want line %d", tt.y, err, tt.line)
+		}
+		if te.Err == nil {
+			t.Errorf("Unmarshal(%q) lost the error of the parser", tt.y)
+		}
+	}
+
+	_, err := YAMLToJSON([]byte("a:\n\tb: 1\n"))
+	want := "yaml: line 2: found a tab in the indentation, but YAML forbids tabs for indentation; indent with spaces instead"
+	if err == nil || err.Error() != want {
+		t.Errorf("YAMLToJSON() = %v, want %v", err, want)
+	}
+}
@@ -0,0 +1,147 @@
+package yaml
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+	yaml3 "gopkg.in/yaml.v3"
+)
+
+// go-yaml accepts %TAG directives and tags it does not know, but decodes the
+// nodes they are on as if they had no tag. When tags are registered with
+// DecodeTag, the document is first parsed with yaml.v3, which keeps the tags
+// of nodes, expanding the handles declared by %TAG directives. Each node with
+// a registered tag is wrapped in a mapping whose single key, starting with
+// tagMarker, holds the tag, and the document is then decoded by go-yaml as
+// usual. applyTags finally replaces the wrapping mappings with the values
+// their TagFuncs return.
+
+// tagMarker starts the keys of the mappings that wrap tagged nodes.
+const tagMarker = "\x00yaml-tag:"
+
+// TagFunc converts the value of a node with a custom tag to the value it
+// stands for. The value is given in the form it has in the JSON document when
+// the node has no tag; the returned value must be marshalable by json.Marshal.
+type TagFunc func(value interface{}) (interface{}, error)
+
+// DecodeTag registers f to convert the nodes tagged with tag, such as the
+// local tag "!Ref" or the global tag "tag:example.com,2000:app/point". Tags
+// are matched after the handles declared by %TAG directives are expanded, so
+// with
+//
+//	%TAG !e! tag:example.com,2000:app/
+//
+// the node "!e!point [1, 2]" matches the tag "tag:example.com,2000:app/point".
+// Nodes with other tags are decoded as if they had none, as go-yaml does.
+func DecodeTag(tag string, f TagFunc) JSONOpt {
+	return decodeOpt(func(o *decodeOptions) {
+		if o.tagFuncs == nil {
+			o.tagFuncs = make(map[string]TagFunc)
+		}
+		o.tagFuncs[tag] = f
+	})
+}
+
+// markTags returns the document y with the nodes that have a registered tag
+// wrapped in mappings for applyTags.
+func (o *decodeOptions) markTags(y []byte) ([]byte, error) {
+	var doc yaml3.Node
+	if err := yaml3.Unmarshal(y, &doc); err != nil {
+		return nil, err
+	}
+	if !o.markTagged(&doc) {
+		return y, nil
+	}
+	return yaml3.Marshal(&doc)
+}
+
+// markTagged wraps the nodes in n that have a registered tag and reports
+// whether it found any.
+func (o *decodeOptions) markTagged(n *yaml3.Node) bool {
+	found := false
+	for i, c := range n.Content {
+		// Keys of mappings are left alone.
+		if n.Kind == yaml3.MappingNode && i%2 == 0 {
+			continue
+		}
+		if o.markTagged(c) {
+			found = true
+		}
+	}
+	if n.Kind == yaml3.DocumentNode || n.Kind == yaml3.AliasNode || o.tagFuncs[n.Tag] == nil {
+		return found
+	}
+
+	inner := *n
+	inner.Anchor = ""
+	inner.Style &^= yaml3.TaggedStyle
+	inner.Tag = ""
+	if n.Kind == yaml3.ScalarNode {
+		// go-yaml decodes scalars with unknown tags as strings.
+		inner.Tag = "!!str"
+	}
+	*n = yaml3.Node{
+		Kind:   yaml3.MappingNode,
+		Anchor: n.Anchor,
+		Content: []*yaml3.Node{
+			{Kind: yaml3.ScalarNode, Style: yaml3.DoubleQuotedStyle, Value: tagMarker + n.Tag},
+			&inner,
+		},
+	}
+	return true
+}
+
+// applyTags replaces the mappings that wrap tagged nodes in v, which has been
+// decoded from the document returned by markTags, with the values their
+// TagFuncs return.
+func (o *decodeOptions) applyTags(v interface{}) (interface{}, error) {
+	var err error
+	switch x := v.(type) {
+	case map[interface{}]interface{}:
+		for k, e := range x {
+			if x[k], err = o.applyTags(e); err != nil {
+				return nil, err
+			}
+		}
+		if len(x) != 1 {
+			break
+		}
+		for k, e := range x {
+			if s, ok := k.(string); ok && strings.HasPrefix(s, tagMarker) {
+				return o.applyTag(strings.TrimPrefix(s, tagMarker), e)
+			}
+		}
+	case []interface{}:
+		for i, e := range x {
+			if x[i], err = o.applyTags(e); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return v, nil
+}
+
+// applyTag returns the value the TagFunc registered for tag converts v to.
+func (o *decodeOptions) applyTag(tag string, v interface{}) (interface{}, error) {
+	jsonObj, err := convertToJSONableObject(v, nil, o, nil)
+	if err != nil {
+		return nil, err
+	}
+	converted, err := o.tagFuncs[tag](jsonObj)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", tag, err)
+	}
+	// Go back to the values go-yaml decodes, which the rest of the
+	// conversion expects.
+	j, err := json.Marshal(converted)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", tag, err)
+	}
+	var yamlObj interface{}
+	if err := yaml.Unmarshal(j, &yamlObj); err != nil {
+		return nil, fmt.Errorf("%s: %v", tag, err)
+	}
+	return yamlObj, nil
+}
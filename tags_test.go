@@ -0,0 +1,90 @@
+package yaml
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeTag(t *testing.T) {
+	// A few of the short forms of CloudFormation's intrinsic functions.
+	cfn := []JSONOpt{
+		DecodeTag("!Ref", func(v interface{}) (interface{}, error) {
+			return map[string]interface{}{"Ref": v}, nil
+		}),
+		DecodeTag("!GetAtt", func(v interface{}) (interface{}, error) {
+			s, ok := v.(string)
+			if !ok {
+				return nil, errors.New("want a string")
+			}
+			return map[string]interface{}{"Fn::GetAtt": strings.SplitN(s, ".", 2)}, nil
+		}),
+		DecodeTag("!Join", func(v interface{}) (interface{}, error) {
+			return map[string]interface{}{"Fn::Join": v}, nil
+		}),
+	}
+
+	y := []byte(`bucket: !Ref Bucket
+arn: !GetAtt Bucket.Arn
+url: !Join ["", ["s3://", !Ref Bucket]]
+port: !Ref 0777
+name: &n !Ref Name
+again: *n
+other: !Unknown text
+`)
+	j, err := YAMLToJSONWithOpts(y, cfn...)
+	if err != nil {
+		t.Fatalf("YAMLToJSONWithOpts() = %v", err)
+	}
+	want := `{"again":{"Ref":"Name"},"arn":{"Fn::GetAtt":["Bucket","Arn"]},"bucket":{"Ref":"Bucket"},` +
+		`"name":{"Ref":"Name"},"other":"text","port":{"Ref":"0777"},"url":{"Fn::Join":["",["s3://",{"Ref":"Bucket"}]]}}`
+	if string(j) != want {
+		t.Errorf("YAMLToJSONWithOpts() = %s; want %s", j, want)
+	}
+
+	// Without the options, the tags are ignored.
+	j, err = YAMLToJSON(y)
+	if err != nil {
+		t.Fatalf("YAMLToJSON() = %v", err)
+	}
+	want = `{"again":"Name","arn":"Bucket.Arn","bucket":"Bucket","name":"Name","other":"text","port":"0777","url":["",["s3://","Bucket"]]}`
+	if string(j) != want {
+		t.Errorf("YAMLToJSON() = %s; want %s", j, want)
+	}
+
+	if _, err := YAMLToJSONWithOpts([]byte("arn: !GetAtt [a, b]\n"), cfn...); err == nil || !strings.Contains(err.Error(), "!GetAtt: want a string") {
+		t.Errorf("YAMLToJSONWithOpts() = %v; want an error from !GetAtt", err)
+	}
+}
+
+func TestDecodeTagDirective(t *testing.T) {
+	type Point struct {
+		Lat, Lon int
+	}
+	var got struct {
+		Points []Point `json:"points"`
+	}
+	y := []byte(`%TAG !e! tag:example.com,2000:app/
+---
+points:
+- !e!point [1, 2]
+- !<tag:example.com,2000:app/point> [3, 4]
+- {Lat: 5, Lon: 6}
+`)
+	point := DecodeTag("tag:example.com,2000:app/point", func(v interface{}) (interface{}, error) {
+		xy, ok := v.([]interface{})
+		if !ok || len(xy) != 2 {
+			return nil, fmt.Errorf("want [lat, lon], got %v", v)
+		}
+		return map[string]interface{}{"Lat": xy[0], "Lon": xy[1]}, nil
+	})
+	if err := Unmarshal(y, &got, point); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	want := []Point{{1, 2}, {3, 4}, {5, 6}}
+	if !reflect.DeepEqual(got.Points, want) {
+		t.Errorf("Unmarshal() = %v; want %v", got.Points, want)
+	}
+}
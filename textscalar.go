@@ -0,0 +1,64 @@
+package yaml
+
+import (
+	"reflect"
+	"sync"
+)
+
+// A value whose type is an encoding.TextUnmarshaler, such as net.IP or an
+// enum, is decoded from the text of its scalar as written in the document,
+// so that 0x10, yes and 1.50 reach UnmarshalText as they are rather than as
+// 16, true and 1.5, and so that booleans and numbers can be decoded into it at
+// all: encoding/json only decodes strings into a TextUnmarshaler. Types that
+// are json.Unmarshalers are decoded by UnmarshalJSON as before, as are
+// big.Int, big.Float and big.Rat, which decode numbers by their value.
+// Marshal already writes a TextMarshaler as a string, quoted if it would be
+// read as something else.
+
+var textScalarsCache sync.Map // map[reflect.Type]bool
+
+// textScalarType reports whether values of type t are decoded from the text
+// of their scalars.
+func textScalarType(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t {
+	case bigIntType, bigFloatType, bigRatType:
+		return false
+	}
+	pt := reflect.PtrTo(t)
+	return pt.Implements(textUnmarshalerType) && !pt.Implements(jsonUnmarshalerType)
+}
+
+// typeHasTextScalars reports whether values of type t may hold a value whose
+// type is decoded from the text of its scalar.
+func typeHasTextScalars(t reflect.Type) bool {
+	if has, ok := textScalarsCache.Load(t); ok {
+		return has.(bool)
+	}
+	has := findTextScalars(t, map[reflect.Type]bool{})
+	textScalarsCache.Store(t, has)
+	return has
+}
+
+func findTextScalars(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+	if textScalarType(t) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return findTextScalars(t.Elem(), seen)
+	case reflect.Struct:
+		for _, f := range cachedTypeFields(t) {
+			if findTextScalars(f.typ, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package yaml
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+	"reflect"
+	"testing"
+)
+
+// rawText keeps the text it is decoded from.
+type rawText string
+
+func (t rawText) MarshalText() ([]byte, error) { return []byte(t), nil }
+
+func (t *rawText) UnmarshalText(b []byte) error {
+	if string(b) == "bad" {
+		return fmt.Errorf("bad text")
+	}
+	*t = rawText(b)
+	return nil
+}
+
+func TestTextScalars(t *testing.T) {
+	type config struct {
+		Addr    net.IP             `json:"addr"`
+		Switch  rawText            `json:"switch"`
+		Code    rawText            `json:"code"`
+		Version *rawText           `json:"version"`
+		Quoted  rawText            `json:"quoted"`
+		List    []rawText          `json:"list"`
+		Map     map[string]rawText `json:"map"`
+		Count   int                `json:"count"`
+		Big     *big.Int           `json:"big"`
+		Flag    bool               `json:"flag"`
+	}
+	y := `addr: 10.0.0.1
+switch: yes
+code: 0x1F
+version: 1.50
+quoted: "on"
+list: [off, 010, ~]
+map: {a: 1e3}
+count: 0x10
+big: 0x10
+flag: on
+`
+	v150 := rawText("1.50")
+	want := config{
+		Addr:    net.ParseIP("10.0.0.1"),
+		Switch:  "yes",
+		Code:    "0x1F",
+		Version: &v150,
+		Quoted:  "on",
+		List:    []rawText{"off", "010", ""},
+		Map:     map[string]rawText{"a": "1e3"},
+		Count:   16,
+		Big:     big.NewInt(16),
+		Flag:    true,
+	}
+	var got config
+	if err := Unmarshal([]byte(y), &got); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal() = %+v; want %+v", got, want)
+	}
+	if err := Unmarshal([]byte("switch: bad\n"), &got); err == nil {
+		t.Error("Unmarshal() did not return the error of UnmarshalText")
+	}
+
+	out, err := Marshal(config{Addr: net.ParseIP("::1"), Switch: "yes", Code: "0x1F", List: []rawText{"a: b"}})
+	if err != nil {
+		t.Fatalf("Marshal() = %v", err)
+	}
+	var back config
+	if err := Unmarshal(out, &back); err != nil {
+		t.Fatalf("Unmarshal() of %q = %v", out, err)
+	}
+	if back.Addr.String() != "::1" || back.Switch != "yes" || back.Code != "0x1F" || back.List[0] != "a: b" {
+		t.Errorf("Unmarshal(Marshal()) = %+v, from %q", back, out)
+	}
+}
@@ -0,0 +1,74 @@
+package yaml
+
+import (
+	"fmt"
+	"time"
+)
+
+// TracePhase is a phase of decoding a YAML document.
+type TracePhase int
+
+const (
+	// TraceParse is the parsing of the YAML document by go-yaml.
+	TraceParse TracePhase = iota
+	// TraceConvert is the conversion of the parsed document into the values
+	// of a JSON document, guided by the type it is decoded into.
+	TraceConvert
+	// TraceDecode is the encoding of those values as JSON and its decoding
+	// into the Go value by encoding/json.
+	TraceDecode
+)
+
+func (p TracePhase) String() string {
+	switch p {
+	case TraceParse:
+		return "parse"
+	case TraceConvert:
+		return "convert"
+	case TraceDecode:
+		return "decode"
+	}
+	return fmt.Sprintf("TracePhase(%d)", int(p))
+}
+
+// TraceEvent is the start or end of a phase of decoding, as DecodeTrace
+// reports it.
+type TraceEvent struct {
+	Phase TracePhase
+	// Done is set for the end of the phase, and unset for its start.
+	Done bool
+	// Time is when the event happened.
+	Time time.Time
+	// Elapsed is the time the phase took, for the end of the phase.
+	Elapsed time.Duration
+	// Err is the error the phase failed with, if any, for the end of the
+	// phase.
+	Err error
+}
+
+// DecodeTrace makes Unmarshal, and the other functions that convert YAML,
+// call trace at the start and at the end of each phase of decoding a
+// document, to find whether the time decoding takes goes to parsing the YAML
+// or to reflecting on the value it is decoded into. Phases that are not
+// reached, such as after a phase fails, are not reported. trace must be safe
+// for concurrent use if it is passed to a Decoder that is used concurrently
+// or to UnmarshalDocuments with DecodeConcurrency.
+func DecodeTrace(trace func(TraceEvent)) JSONOpt {
+	return decodeOpt(func(o *decodeOptions) {
+		o.trace = trace
+	})
+}
+
+// traceStart reports the start of the phase p to the trace of o, and returns
+// the function that reports its end, with the error it failed with.
+func (o *decodeOptions) traceStart(p TracePhase) func(error) {
+	if o.trace == nil {
+		return func(error) {}
+	}
+	start := time.Now()
+	o.trace(TraceEvent{Phase: p, Time: start})
+	return func(err error) {
+		now := time.Now()
+		o.trace(TraceEvent{Phase: p, Done: true, Time: now, Elapsed: now.Sub(start), Err: err})
+	}
+}
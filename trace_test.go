@@ -0,0 +1,66 @@
+package yaml
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecodeTrace(t *testing.T) {
+	var events []TraceEvent
+	trace := DecodeTrace(func(e TraceEvent) {
+		events = append(events, e)
+	})
+	phases := func() string {
+		var s []string
+		for _, e := range events {
+			if e.Done {
+				s = append(s, e.Phase.String()+" done")
+			} else {
+				s = append(s, e.Phase.String())
+			}
+		}
+		return strings.Join(s, ", ")
+	}
+
+	var v struct {
+		A int `json:"a"`
+	}
+	if err := Unmarshal([]byte("a: 1\n"), &v, trace); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	if got, want := phases(), "parse, parse done, convert, convert done, decode, decode done"; got != want {
+		t.Errorf("events = %s, want %s", got, want)
+	}
+	for _, e := range events {
+		if e.Err != nil || e.Time.IsZero() || !e.Done && e.Elapsed != 0 {
+			t.Errorf("event = %+v", e)
+		}
+	}
+	if end, start := events[len(events)-1], events[len(events)-2]; end.Elapsed != end.Time.Sub(start.Time) {
+		t.Errorf("Elapsed = %v, want %v", end.Elapsed, end.Time.Sub(start.Time))
+	}
+
+	events = nil
+	if err := Unmarshal([]byte("a: [1\n"), &v, trace); err == nil {
+		t.Fatalf("Unmarshal() = nil, want an error")
+	}
+	if got, want := phases(), "parse, parse done"; got != want || events[1].Err == nil {
+		t.Errorf("events = %s, want %s with an error", got, want)
+	}
+
+	events = nil
+	if err := Unmarshal([]byte("a: x\n"), &v, trace); err == nil {
+		t.Fatalf("Unmarshal() = nil, want an error")
+	}
+	if got, want := phases(), "parse, parse done, convert, convert done, decode, decode done"; got != want || events[5].Err == nil {
+		t.Errorf("events = %s, want %s with an error", got, want)
+	}
+
+	events = nil
+	if _, err := YAMLToJSONWithOpts([]byte("a: 1\n"), trace); err != nil {
+		t.Fatalf("YAMLToJSONWithOpts() = %v", err)
+	}
+	if got, want := phases(), "parse, parse done, convert, convert done"; got != want {
+		t.Errorf("events = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,136 @@
+package yaml
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// union holds the concrete types registered with DecodeUnion for an
+// interface type.
+type union struct {
+	key   string
+	types map[string]reflect.Type
+}
+
+// DecodeUnion decodes YAML mappings into an interface type by picking a
+// concrete type according to the value of their discriminator key. iface is a
+// nil pointer to the interface type, and types maps each value of key to a
+// value of the concrete type to decode into, for instance:
+//
+//	yaml.DecodeUnion((*Storage)(nil), "type", map[string]interface{}{
+//		"s3":  &S3Config{},
+//		"gcs": &GCSConfig{},
+//	})
+//
+// decodes "type: s3" into a *S3Config wherever a Storage is expected. The
+// discriminator key is decoded into the concrete type along with the other
+// keys. It is an error for a mapping to lack the key or to have a value for it
+// that is not in types. As with encoding/json, an interface that already holds
+// a non-nil pointer is decoded into the value it points to instead.
+func DecodeUnion(iface interface{}, key string, types map[string]interface{}) JSONOpt {
+	it := reflect.TypeOf(iface)
+	if it == nil || it.Kind() != reflect.Ptr || it.Elem().Kind() != reflect.Interface {
+		panic("yaml: DecodeUnion needs a nil pointer to an interface type")
+	}
+	u := &union{key: key, types: make(map[string]reflect.Type, len(types))}
+	for name, v := range types {
+		t := reflect.TypeOf(v)
+		if !t.Implements(it.Elem()) {
+			panic(fmt.Sprintf("yaml: DecodeUnion type %v does not implement %v", t, it.Elem()))
+		}
+		u.types[name] = t
+	}
+	return decodeOpt(func(o *decodeOptions) {
+		if o.unions == nil {
+			o.unions = make(map[reflect.Type]*union)
+		}
+		o.unions[it.Elem()] = u
+	})
+}
+
+// DecodeConcreteType decodes YAML values into the interface type pointed to by
+// the nil pointer iface as values of the type of concrete, for instance
+//
+//	yaml.DecodeConcreteType((*Step)(nil), &ShellStep{})
+//
+// decodes a []Step as a slice of *ShellStep. Along with DecodeUnion for the
+// same interface type, it is used for mappings without a discriminator key.
+func DecodeConcreteType(iface, concrete interface{}) JSONOpt {
+	it := reflect.TypeOf(iface)
+	if it == nil || it.Kind() != reflect.Ptr || it.Elem().Kind() != reflect.Interface {
+		panic("yaml: DecodeConcreteType needs a nil pointer to an interface type")
+	}
+	t := reflect.TypeOf(concrete)
+	if t == nil || !t.Implements(it.Elem()) {
+		panic(fmt.Sprintf("yaml: DecodeConcreteType type %v does not implement %v", t, it.Elem()))
+	}
+	return decodeOpt(func(o *decodeOptions) {
+		if o.concreteTypes == nil {
+			o.concreteTypes = make(map[reflect.Type]reflect.Type)
+		}
+		o.concreteTypes[it.Elem()] = t
+	})
+}
+
+// concreteType returns the type to decode the YAML value v into for the
+// interface type iface, or nil if no type is registered for it.
+func (o *decodeOptions) concreteType(v interface{}, iface reflect.Type) (reflect.Type, error) {
+	if u := o.unions[iface]; u != nil {
+		if m, ok := v.(map[interface{}]interface{}); ok {
+			if name, ok := m[u.key]; ok {
+				s, ok := name.(string)
+				t := u.types[s]
+				if !ok || t == nil {
+					return nil, fmt.Errorf("unknown %v %s %v", iface, u.key, name)
+				}
+				return t, nil
+			}
+			if o.concreteTypes[iface] == nil {
+				return nil, fmt.Errorf("missing key %q to pick the type of %v", u.key, iface)
+			}
+		}
+	}
+	return o.concreteTypes[iface], nil
+}
+
+// unionObject is the JSON-compatible form of a YAML value that is decoded into
+// an interface registered with DecodeUnion or DecodeConcreteType. It is
+// written to the JSON document as null, and fillUndecoded decodes obj into a
+// value of typ.
+type unionObject struct {
+	obj interface{}
+	typ reflect.Type
+}
+
+func (unionObject) MarshalJSON() ([]byte, error) {
+	return []byte("null"), nil
+}
+
+// fill decodes u into the interface v.
+func (u unionObject) fill(v reflect.Value, decode func([]byte, interface{}) error) error {
+	if !v.CanSet() {
+		return nil
+	}
+	b, err := json.Marshal(u.obj)
+	if err != nil {
+		return err
+	}
+	t := u.typ
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	pv := reflect.New(t)
+	if err := decode(b, pv.Interface()); err != nil {
+		return err
+	}
+	if err := fillUndecoded(pv, u.obj, decode); err != nil {
+		return err
+	}
+	if u.typ.Kind() == reflect.Ptr {
+		v.Set(pv)
+	} else {
+		v.Set(pv.Elem())
+	}
+	return nil
+}
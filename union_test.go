@@ -0,0 +1,128 @@
+package yaml
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type unionStorage interface {
+	storage()
+}
+
+type unionS3 struct {
+	Type   string `json:"type"`
+	Bucket string `json:"bucket"`
+}
+
+func (*unionS3) storage() {}
+
+type unionDisk struct {
+	Type string `json:"type"`
+	Path string `json:"path"`
+}
+
+func (unionDisk) storage() {}
+
+type UnionTest struct {
+	Primary  unionStorage            `json:"primary"`
+	Replicas []unionStorage          `json:"replicas"`
+	ByName   map[string]unionStorage `json:"byName"`
+	Missing  unionStorage            `json:"missing"`
+}
+
+func TestDecodeUnion(t *testing.T) {
+	opt := DecodeUnion((*unionStorage)(nil), "type", map[string]interface{}{
+		"s3":   &unionS3{},
+		"disk": unionDisk{},
+	})
+	y := `
+primary: {type: s3, bucket: b}
+replicas:
+- {type: disk, path: /data}
+- {type: s3, bucket: c}
+byName:
+  local: {type: disk, path: /tmp}
+`
+	want := UnionTest{
+		Primary:  &unionS3{"s3", "b"},
+		Replicas: []unionStorage{unionDisk{"disk", "/data"}, &unionS3{"s3", "c"}},
+		ByName:   map[string]unionStorage{"local": unionDisk{"disk", "/tmp"}},
+	}
+
+	var got UnionTest
+	if err := Unmarshal([]byte(y), &got, opt, DisallowUnknownFields); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal() = %+v; want %+v", got, want)
+	}
+
+	var s unionStorage
+	if err := Unmarshal([]byte("type: disk\npath: /\n"), &s, opt); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	if want := (unionDisk{"disk", "/"}); s != want {
+		t.Errorf("Unmarshal() = %#v; want %#v", s, want)
+	}
+
+	for _, tc := range []struct {
+		y, err string
+	}{
+		{"primary: {bucket: b}", `primary: missing key "type" to pick the type of yaml.unionStorage`},
+		{"replicas: [{type: gcs}]", `replicas[0]: unknown yaml.unionStorage type gcs`},
+		{"primary: {type: s3, bukcet: b}", `unknown field`},
+	} {
+		var got UnionTest
+		err := Unmarshal([]byte(tc.y), &got, opt, DisallowUnknownFields)
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("Unmarshal(%q) = %v; want error containing %q", tc.y, err, tc.err)
+		}
+	}
+}
+
+type unionStep interface {
+	run() string
+}
+
+type unionShell struct {
+	Cmd string `json:"cmd"`
+}
+
+func (s *unionShell) run() string { return s.Cmd }
+
+type unionName string
+
+func (n unionName) run() string { return string(n) }
+
+func TestDecodeConcreteType(t *testing.T) {
+	var steps []unionStep
+	if err := Unmarshal([]byte("- cmd: a\n- cmd: b\n"), &steps, DecodeConcreteType((*unionStep)(nil), &unionShell{})); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	if want := []unionStep{&unionShell{"a"}, &unionShell{"b"}}; !reflect.DeepEqual(steps, want) {
+		t.Errorf("Unmarshal() = %+v; want %+v", steps, want)
+	}
+
+	steps = nil
+	if err := Unmarshal([]byte("[x, z]"), &steps, DecodeConcreteType((*unionStep)(nil), unionName(""))); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	if want := []unionStep{unionName("x"), unionName("z")}; !reflect.DeepEqual(steps, want) {
+		t.Errorf("Unmarshal() = %+v; want %+v", steps, want)
+	}
+
+	// Along with a union, the concrete type is used for mappings without a
+	// discriminator key.
+	var got UnionTest
+	opts := []JSONOpt{
+		DecodeUnion((*unionStorage)(nil), "type", map[string]interface{}{"s3": &unionS3{}}),
+		DecodeConcreteType((*unionStorage)(nil), unionDisk{}),
+	}
+	if err := Unmarshal([]byte("replicas: [{type: s3}, {path: /}]"), &got, opts...); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	if want := []unionStorage{&unionS3{Type: "s3"}, unionDisk{Path: "/"}}; !reflect.DeepEqual(got.Replicas, want) {
+		t.Errorf("Unmarshal() = %+v; want %+v", got.Replicas, want)
+	}
+}
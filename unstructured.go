@@ -0,0 +1,60 @@
+package yaml
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ToUnstructured reads the YAML document y, which must be a mapping, into the
+// form of the content of a Kubernetes unstructured.Unstructured: maps have
+// string keys, and the other values are []interface{}, string, bool, nil,
+// int64 for integers and float64 for other numbers. Integers out of the range
+// of int64 become float64. Values are read as Unmarshal reads them, with the
+// options that configure the conversion from YAML. An empty document is an
+// empty map.
+func ToUnstructured(y []byte, opts ...JSONOpt) (map[string]interface{}, error) {
+	j, err := YAMLToJSONWithOpts(y, opts...)
+	if err != nil {
+		return nil, err
+	}
+	v, err := decodeJSONNumbers(j)
+	if err != nil {
+		return nil, err
+	}
+	switch obj := unstructuredValue(v).(type) {
+	case nil:
+		return map[string]interface{}{}, nil
+	case map[string]interface{}:
+		return obj, nil
+	default:
+		return nil, fmt.Errorf("yaml: cannot read %T into an unstructured object", obj)
+	}
+}
+
+// FromUnstructured writes the unstructured object obj, as returned by
+// ToUnstructured, as YAML.
+func FromUnstructured(obj map[string]interface{}, opts ...MarshalOpt) ([]byte, error) {
+	return Marshal(obj, opts...)
+}
+
+// unstructuredValue converts the json.Numbers in v, as returned by
+// decodeJSONNumbers, into int64 and float64.
+func unstructuredValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case []interface{}:
+		for i, item := range v {
+			v[i] = unstructuredValue(item)
+		}
+	case map[string]interface{}:
+		for k, item := range v {
+			v[k] = unstructuredValue(item)
+		}
+	}
+	return v
+}
@@ -0,0 +1,63 @@
+package yaml
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestToUnstructured(t *testing.T) {
+	y := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web
+  labels: {1: one, true: yes}
+data:
+  replicas: 3
+  ratio: 0.5
+  big: 18446744073709551616
+  enabled: on
+  empty: ~
+  ports: [80, 443]
+`
+	want := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name":   "web",
+			"labels": map[string]interface{}{"1": "one", "true": true},
+		},
+		"data": map[string]interface{}{
+			"replicas": int64(3),
+			"ratio":    0.5,
+			"big":      18446744073709551616.0,
+			"enabled":  true,
+			"empty":    nil,
+			"ports":    []interface{}{int64(80), int64(443)},
+		},
+	}
+	got, err := ToUnstructured([]byte(y))
+	if err != nil {
+		t.Fatalf("ToUnstructured() = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ToUnstructured() = %#v; want %#v", got, want)
+	}
+
+	out, err := FromUnstructured(got)
+	if err != nil {
+		t.Fatalf("FromUnstructured() = %v", err)
+	}
+	back, err := ToUnstructured(out)
+	if err != nil || !reflect.DeepEqual(back, want) {
+		t.Errorf("ToUnstructured(FromUnstructured()) = %#v, %v", back, err)
+	}
+
+	if obj, err := ToUnstructured(nil); err != nil || obj == nil || len(obj) != 0 {
+		t.Errorf("ToUnstructured() of an empty document = %#v, %v", obj, err)
+	}
+	for _, y := range []string{"- a\n", "1", "a: ["} {
+		if _, err := ToUnstructured([]byte(y)); err == nil {
+			t.Errorf("ToUnstructured(%q) returned no error", y)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package yaml
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// Unsupported selects what Marshal does with values of types that JSON cannot
+// hold, such as channels, funcs and complex numbers.
+type Unsupported int
+
+const (
+	// UnsupportedError fails with an *UnsupportedTypeError. This is the
+	// default.
+	UnsupportedError Unsupported = iota
+	// UnsupportedSkip leaves out struct fields and map entries holding such
+	// values, and writes null for the items of sequences and for the whole
+	// document.
+	UnsupportedSkip
+	// UnsupportedNull writes null for such values.
+	UnsupportedNull
+)
+
+// UnsupportedTypes sets what Marshal does with values of types that JSON
+// cannot hold, so that the fields of live structs that hold such values can
+// be left out of diagnostic dumps. Values within the output of a MarshalJSON
+// method are not affected.
+func UnsupportedTypes(p Unsupported) MarshalOpt {
+	return func(e *encoder) {
+		e.unsupported = p
+	}
+}
+
+// UnsupportedTypeError is returned by Marshal for a value of a type that JSON
+// cannot hold, such as a channel, a func or a complex number, in place of the
+// error of encoding/json, which only names the type.
+type UnsupportedTypeError struct {
+	// Path is the Go expression of the value within the value passed to
+	// Marshal, starting from the name of its type, such as
+	// "Config.Hooks[2].Callback" or `Config.Plugins["lint"]`.
+	Path string
+	Type reflect.Type
+}
+
+func (e *UnsupportedTypeError) Error() string {
+	return fmt.Sprintf("yaml: unsupported type %v at %s", e.Type, e.Path)
+}
+
+// unsupportedTypeError returns the *UnsupportedTypeError for the first value
+// of type t in v that encoding/json writes, or nil if there is none.
+func unsupportedTypeError(v reflect.Value, t reflect.Type) error {
+	root := v.Type()
+	for root != nil && root.Kind() == reflect.Ptr {
+		root = root.Elem()
+	}
+	if root == nil {
+		return nil
+	}
+	name := root.Name()
+	if name == "" {
+		name = root.String()
+	}
+	if path, ok := findType(v, t, name, 0); ok {
+		return &UnsupportedTypeError{Path: path, Type: t}
+	}
+	return nil
+}
+
+// findType returns the path of the first value of type t in v, which is found
+// at path, in the order encoding/json writes them.
+func findType(v reflect.Value, t reflect.Type, path string, depth int) (string, bool) {
+	if !v.IsValid() || depth > maxJSONDepth {
+		return "", false
+	}
+	if v.Type() == t {
+		return path, true
+	}
+	if marshalsItself(v) {
+		return "", false
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			return findType(v.Elem(), t, path, depth+1)
+		}
+	case reflect.Struct:
+		for i := range cachedTypeFields(v.Type()) {
+			f := &cachedTypeFields(v.Type())[i]
+			fv := fieldByIndex(v, f.index)
+			if !fv.IsValid() || f.omitEmpty && isEmptyValue(fv) || f.omitZero && isZero(fv) {
+				continue
+			}
+			name := v.Type().FieldByIndex(f.index).Name
+			if p, ok := findType(fv, t, path+"."+name, depth+1); ok {
+				return p, true
+			}
+		}
+	case reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+		})
+		for _, k := range keys {
+			if p, ok := findType(v.MapIndex(k), t, path+"["+keyExpr(k)+"]", depth+1); ok {
+				return p, true
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if p, ok := findType(v.Index(i), t, path+"["+strconv.Itoa(i)+"]", depth+1); ok {
+				return p, true
+			}
+		}
+	}
+	return "", false
+}
+
+// keyExpr returns the Go expression of the map key k.
+func keyExpr(k reflect.Value) string {
+	if k.Kind() == reflect.String {
+		return strconv.Quote(k.String())
+	}
+	return fmt.Sprint(k)
+}
+
+// skippedValue is what jsonValue returns for a value that UnsupportedSkip
+// leaves out.
+type skippedValue struct{}
+
+// unsupportedValue returns what jsonValue returns for a value of the
+// unsupported type t.
+func (e *encoder) unsupportedValue(t reflect.Type) (interface{}, error) {
+	switch e.unsupported {
+	case UnsupportedSkip:
+		return skippedValue{}, nil
+	case UnsupportedNull:
+		return nil, nil
+	}
+	return nil, &json.UnsupportedTypeError{Type: t}
+}
@@ -0,0 +1,105 @@
+package yaml
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+type unsupportedHook struct {
+	Name     string      `json:"name"`
+	Callback interface{} `json:"callback,omitempty"`
+}
+
+type unsupportedConfig struct {
+	Hooks   []unsupportedHook      `json:"hooks"`
+	Plugins map[string]interface{} `json:"plugins"`
+	Scale   float64                `json:"scale"`
+}
+
+func TestUnsupportedTypeError(t *testing.T) {
+	tests := []struct {
+		v    interface{}
+		path string
+		typ  interface{}
+	}{
+		{
+			&unsupportedConfig{Hooks: []unsupportedHook{{Name: "a"}, {Name: "b"}, {Name: "c", Callback: func() {}}}},
+			"unsupportedConfig.Hooks[2].Callback", func() {},
+		},
+		{
+			unsupportedConfig{Plugins: map[string]interface{}{"a": 1, "lint": make(chan int)}},
+			`unsupportedConfig.Plugins["lint"]`, make(chan int),
+		},
+		{[]interface{}{1, complex(1, 2)}, "[]interface {}[1]", complex(1, 2)},
+		// The path is found when non-finite floats are handled too.
+		{
+			unsupportedConfig{Scale: math.Inf(1), Plugins: map[string]interface{}{"c": complex64(1)}},
+			`unsupportedConfig.Plugins["c"]`, complex64(1),
+		},
+	}
+	for _, tt := range tests {
+		_, err := Marshal(tt.v, NonFiniteFloats(NonFiniteNull))
+		var ue *UnsupportedTypeError
+		if !errors.As(err, &ue) {
+			t.Errorf("Marshal(%#v) = %v, want an *UnsupportedTypeError", tt.v, err)
+			continue
+		}
+		if ue.Path != tt.path || ue.Type != reflect.TypeOf(tt.typ) {
+			t.Errorf("Marshal(%#v) = %v, want %v at %s", tt.v, err, reflect.TypeOf(tt.typ), tt.path)
+		}
+	}
+
+	want := "yaml: unsupported type func() at unsupportedConfig.Hooks[0].Callback"
+	_, err := Marshal(unsupportedConfig{Hooks: []unsupportedHook{{Callback: func() {}}}})
+	if err == nil || err.Error() != want {
+		t.Errorf("Marshal() = %v, want %v", err, want)
+	}
+}
+
+func TestUnsupportedTypes(t *testing.T) {
+	type conn struct {
+		Addr   string                 `json:"addr"`
+		Done   chan struct{}          `json:"done"`
+		OnErr  func(error)            `json:"onErr"`
+		Stats  map[string]interface{} `json:"stats"`
+		Values []interface{}          `json:"values"`
+		Ratio  float64                `json:"ratio"`
+	}
+	c := conn{
+		Addr:   "localhost:80",
+		Done:   make(chan struct{}),
+		Stats:  map[string]interface{}{"open": 2, "lock": make(chan int)},
+		Values: []interface{}{1, func() {}},
+		Ratio:  math.NaN(),
+	}
+
+	tests := []struct {
+		opts []MarshalOpt
+		want string
+	}{
+		{
+			[]MarshalOpt{UnsupportedTypes(UnsupportedSkip), NonFiniteFloats(NonFiniteFloat)},
+			"addr: localhost:80\nratio: .nan\nstats:\n  open: 2\nvalues:\n- 1\n- null\n",
+		},
+		{
+			[]MarshalOpt{UnsupportedTypes(UnsupportedNull), NonFiniteFloats(NonFiniteNull)},
+			"addr: localhost:80\ndone: null\nonErr: null\nratio: null\nstats:\n  lock: null\n  open: 2\nvalues:\n- 1\n- null\n",
+		},
+	}
+	for _, tt := range tests {
+		y, err := Marshal(c, tt.opts...)
+		if err != nil || string(y) != tt.want {
+			t.Errorf("Marshal() = %q, %v, want %q", y, err, tt.want)
+		}
+	}
+
+	if y, err := Marshal(make(chan int), UnsupportedTypes(UnsupportedSkip)); err != nil || string(y) != "null\n" {
+		t.Errorf("Marshal(chan) = %q, %v", y, err)
+	}
+	// Non-finite floats are still refused.
+	if _, err := Marshal(c, UnsupportedTypes(UnsupportedSkip)); err == nil {
+		t.Error("Marshal() did not refuse NaN")
+	}
+}
@@ -0,0 +1,35 @@
+package yaml
+
+import "gopkg.in/yaml.v2"
+
+// Validate checks that y is a YAML document that Unmarshal can parse, without
+// converting it to JSON or decoding it. It reports syntax errors and aliases
+// to anchors that are not defined, along with the line they are on.
+func Validate(y []byte) error {
+	y, err := toUTF8(y)
+	if err != nil {
+		return err
+	}
+	// go-yaml parses the whole document, resolving aliases, before it hands
+	// it to an Unmarshaler.
+	return yaml.Unmarshal(y, &parseOnly{})
+}
+
+// ValidateStrict is like Validate but also reports duplicate keys in
+// mappings, as UnmarshalStrict does.
+func ValidateStrict(y []byte) error {
+	y, err := toUTF8(y)
+	if err != nil {
+		return err
+	}
+	// go-yaml only finds duplicate keys while decoding mappings.
+	var v interface{}
+	return yaml.UnmarshalStrict(y, &v)
+}
+
+// parseOnly is a yaml.Unmarshaler that decodes nothing.
+type parseOnly struct{}
+
+func (parseOnly) UnmarshalYAML(func(interface{}) error) error {
+	return nil
+}
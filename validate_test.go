@@ -0,0 +1,40 @@
+package yaml
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	for _, tc := range []struct {
+		yaml           string
+		err, strictErr string
+	}{
+		{"a: 1\nb: [1, 2]\n", "", ""},
+		{"", "", ""},
+		{"a: &x 1\nb: *x\n", "", ""},
+		{"a: [1\n", "line 1: did not find expected ',' or ']'", "line 1: did not find expected ',' or ']'"},
+		{"a: *x\n", "unknown anchor 'x'", "unknown anchor 'x'"},
+		{"a:\n\t- b\n", "line 2: found character that cannot start any token", "line 2: found character"},
+		{"a: 1\na: 2\n", "", `line 2: key "a" already set in map`},
+		{"a:\n  b: 1\n  b: 2\n", "", `line 3: key "b" already set in map`},
+	} {
+		for _, v := range []struct {
+			name string
+			f    func([]byte) error
+			want string
+		}{
+			{"Validate", Validate, tc.err},
+			{"ValidateStrict", ValidateStrict, tc.strictErr},
+		} {
+			err := v.f([]byte(tc.yaml))
+			if v.want == "" {
+				if err != nil {
+					t.Errorf("%s(%#q) = %v; want nil", v.name, tc.yaml, err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), v.want) {
+				t.Errorf("%s(%#q) = %v; want an error containing %q", v.name, tc.yaml, err, v.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package yaml
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// WeaklyTyped configures the conversion to coerce scalars of the YAML document
+// into the types of the fields they are decoded into, like the
+// WeaklyTypedInput option of mapstructure:
+//
+//   - strings such as "1" or "1.5" decode into numbers, and the empty string
+//     into 0
+//   - strings accepted by strconv.ParseBool decode into bools, and the empty
+//     string into false
+//   - bools decode into numbers as 1 and 0, and numbers into bools as whether
+//     they are non-zero
+//   - a single scalar decodes into a slice as a slice of one element
+//
+// Numbers and bools always decode into strings, with or without this option.
+func WeaklyTyped() JSONOpt {
+	return decodeOpt(func(o *decodeOptions) {
+		o.weaklyTyped = true
+	})
+}
+
+// StrictScalars configures the conversion to reject scalars of the YAML
+// document that do not have the type of the field they are decoded into,
+// instead of converting them implicitly. For instance, 1 no longer decodes
+// into a string or a float64, and "1" into an int. Null decodes into anything.
+// The error names the path of the scalar. StrictScalars takes precedence over
+// WeaklyTyped.
+func StrictScalars() JSONOpt {
+	return decodeOpt(func(o *decodeOptions) {
+		o.strictScalars = true
+	})
+}
+
+// checkScalar returns an error if the YAML scalar v does not have a type that
+// decodes into type t without conversion.
+func checkScalar(v interface{}, t reflect.Type) error {
+	var ok bool
+	switch t.Kind() {
+	case reflect.String:
+		_, ok = v.(string)
+	case reflect.Bool:
+		_, ok = v.(bool)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		switch v.(type) {
+		case int, int64, uint64:
+			ok = true
+		}
+	case reflect.Float32, reflect.Float64:
+		_, ok = v.(float64)
+	default:
+		return nil
+	}
+	if ok || v == nil {
+		return nil
+	}
+	return fmt.Errorf("cannot decode %s %#v into a value of type %v", yamlTypeName(v), v, t)
+}
+
+// yamlTypeName returns the name of the YAML type of the scalar v.
+func yamlTypeName(v interface{}) string {
+	switch v.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "bool"
+	case int, int64, uint64:
+		return "int"
+	case float64:
+		return "float"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// weakSlice reports whether a scalar decoded into type t is lifted into a
+// slice. []byte is left out, since JSON holds those as base64 strings.
+func weakSlice(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8
+}
+
+// weakScalar coerces the YAML scalar v for decoding into type t.
+func weakScalar(v interface{}, t reflect.Type) (interface{}, error) {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		switch x := v.(type) {
+		case bool:
+			if x {
+				return 1, nil
+			}
+			return 0, nil
+		case string:
+			s := strings.TrimSpace(x)
+			if s == "" {
+				return 0, nil
+			}
+			if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+				return n, nil
+			}
+			if n, err := strconv.ParseUint(s, 10, 64); err == nil {
+				return n, nil
+			}
+			if f, err := strconv.ParseFloat(s, 64); err == nil {
+				return f, nil
+			}
+			return nil, fmt.Errorf("cannot decode %q into a value of type %v", x, t)
+		}
+	case reflect.Bool:
+		switch x := v.(type) {
+		case string:
+			s := strings.TrimSpace(x)
+			if s == "" {
+				return false, nil
+			}
+			b, err := strconv.ParseBool(s)
+			if err != nil {
+				return nil, fmt.Errorf("cannot decode %q into a value of type %v", x, t)
+			}
+			return b, nil
+		case int:
+			return x != 0, nil
+		case int64:
+			return x != 0, nil
+		case uint64:
+			return x != 0, nil
+		case float64:
+			return x != 0, nil
+		}
+	}
+	return v, nil
+}
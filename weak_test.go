@@ -0,0 +1,97 @@
+package yaml
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type WeakTest struct {
+	Int    int      `json:"int"`
+	Uint   uint8    `json:"uint"`
+	Float  float64  `json:"float"`
+	Bool   bool     `json:"bool"`
+	Flag   bool     `json:"flag"`
+	FromB  int      `json:"fromBool"`
+	Str    string   `json:"str"`
+	Empty  int      `json:"empty"`
+	Ptr    *int     `json:"ptr"`
+	List   []string `json:"list"`
+	Ints   []int    `json:"ints"`
+	Nested []WeakTest
+}
+
+func TestWeaklyTyped(t *testing.T) {
+	y := `
+int: "42"
+uint: " 7 "
+float: "1.5"
+bool: "true"
+flag: 1
+fromBool: true
+str: 12
+empty: ""
+ptr: "3"
+list: single
+ints: ["1", 2]
+Nested:
+- int: "-1"
+`
+	three := 3
+	want := WeakTest{
+		Int: 42, Uint: 7, Float: 1.5, Bool: true, Flag: true, FromB: 1,
+		Str: "12", Ptr: &three, List: []string{"single"}, Ints: []int{1, 2},
+		Nested: []WeakTest{{Int: -1}},
+	}
+
+	var got WeakTest
+	if err := Unmarshal([]byte(y), &got, WeaklyTyped()); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal() = %+v; want %+v", got, want)
+	}
+
+	err := Unmarshal([]byte("Nested:\n- bool: maybe\n"), &got, WeaklyTyped())
+	if err == nil || !strings.Contains(err.Error(), `Nested[0].bool: cannot decode "maybe"`) {
+		t.Errorf("Unmarshal() = %v; want an error for Nested[0].bool", err)
+	}
+
+	if err := Unmarshal([]byte(`int: "42"`), &got); err == nil {
+		t.Errorf("Unmarshal() without WeaklyTyped succeeded; want an error")
+	}
+}
+
+func TestStrictScalars(t *testing.T) {
+	type Inner struct {
+		Ratio float64 `json:"ratio"`
+	}
+	type StrictScalarTest struct {
+		Name  string      `json:"name"`
+		Count int         `json:"count"`
+		On    bool        `json:"enabled"`
+		Any   interface{} `json:"any"`
+		Items []Inner     `json:"items"`
+	}
+
+	var got StrictScalarTest
+	y := "name: x\ncount: 3\nenabled: true\nany: 1\nitems:\n- ratio: 0.5\n- ratio: null\n"
+	if err := Unmarshal([]byte(y), &got, StrictScalars()); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+
+	for _, tc := range []struct {
+		y, err string
+	}{
+		{"name: 12", `name: cannot decode int 12 into a value of type string`},
+		{"count: \"3\"", `count: cannot decode string "3" into a value of type int`},
+		{"count: 1.5", `count: cannot decode float 1.5 into a value of type int`},
+		{"enabled: 1", `enabled: cannot decode int 1 into a value of type bool`},
+		{"items:\n- ratio: 1", `items[0].ratio: cannot decode int 1 into a value of type float64`},
+	} {
+		err := Unmarshal([]byte(tc.y), &got, StrictScalars(), WeaklyTyped())
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("Unmarshal(%q) = %v; want error containing %q", tc.y, err, tc.err)
+		}
+	}
+}
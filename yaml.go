@@ -12,24 +12,53 @@ package yaml  // import "github.com/ghodss/yaml"
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"reflect"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"gopkg.in/yaml.v2"
 )
 
 // Marshals the object into JSON then converts JSON to YAML and returns the
-// YAML.
-func Marshal(o interface{}) ([]byte, error) {
-	j, err := json.Marshal(o)
+// YAML, optionally configuring how the YAML is emitted.
+func Marshal(o interface{}, opts ...MarshalOpt) (y []byte, err error) {
+	e := newEncoder(opts)
+	if e.metrics != nil {
+		defer func(start time.Time) {
+			recordMetrics(e.metrics, "marshal", start, len(y), err)
+		}(time.Now())
+	}
+	if e.converters != nil {
+		return e.marshalValue(o)
+	}
+	marshal := json.Marshal
+	if e.codec != nil {
+		marshal = e.codec.Marshal
+	}
+	j, err := marshal(o)
+	if isNonFiniteError(err) && e.nonFinite != NonFiniteError {
+		return e.marshalValue(o)
+	}
+	if ute, ok := err.(*json.UnsupportedTypeError); ok {
+		if e.unsupported != UnsupportedError {
+			return e.marshalValue(o)
+		}
+		if err := unsupportedTypeError(reflect.ValueOf(o), ute.Type); err != nil {
+			return nil, err
+		}
+	}
 	if err != nil {
 		return nil, fmt.Errorf("error marshaling into JSON: %v", err)
 	}
 
-	y, err := JSONToYAML(j)
+	y, err = jsonToYAML(j, reflect.ValueOf(o), opts)
 	if err != nil {
 		return nil, fmt.Errorf("error converting JSON to YAML: %v", err)
 	}
@@ -37,46 +66,259 @@ func Marshal(o interface{}) ([]byte, error) {
 	return y, nil
 }
 
-// JSONOpt is a decoding option for decoding from JSON format.
+// JSONOpt is a decoding option for decoding from JSON format. Some of the
+// options provided by this package instead configure the conversion from
+// YAML to JSON that precedes it; those only take effect when passed to one of
+// this package's functions, and leave a json.Decoder they are applied to
+// otherwise unchanged.
 type JSONOpt func(*json.Decoder) *json.Decoder
 
+// decodeOptions holds the settings of the JSONOpts that configure the
+// conversion from YAML to JSON.
+type decodeOptions struct {
+	fieldNaming   FieldNamer
+	tags          TagPrecedence
+	nonFinite     NonFinite
+	legacyNumbers LegacyNumbers
+	yamlVersion   YAMLVersion
+	ambiguous     bool
+	caseSensitive bool
+	strictFields  bool
+	hooks         []DecodeHook
+	weaklyTyped   bool
+	strictScalars bool
+	unions        map[reflect.Type]*union
+	concreteTypes map[reflect.Type]reflect.Type
+	tagFuncs      map[string]TagFunc
+	lookupEnv     func(string) (string, bool)
+	includes      *includer
+	nodeHooks     []NodeHook
+	// validate checks the document before it is decoded.
+	validate func(y []byte) error
+
+	// textScalars is set if resolveScalars keeps the yamlScalars of values
+	// for convertToJSONableObject to resolve, so that those decoded into
+	// encoding.TextUnmarshalers get their text. precise is the setting they
+	// are then resolved with.
+	textScalars bool
+	precise     bool
+
+	// codec, if set, replaces encoding/json.
+	codec JSONCodec
+
+	// yamlUnmarshalers is set if values are decoded by their UnmarshalYAML
+	// methods.
+	yamlUnmarshalers bool
+	// standardTypes is set if net.IPNet and url.URL values are parsed from
+	// strings.
+	standardTypes bool
+	// concurrency is the number of documents UnmarshalDocuments decodes at
+	// a time.
+	concurrency int
+	// arrayLength sets how sequences are decoded into arrays of another
+	// length.
+	arrayLength ArrayLength
+	// uniqueAnchors is set if documents may not define an anchor twice.
+	uniqueAnchors bool
+	// trace, if set, receives the TraceEvents of each document.
+	trace func(TraceEvent)
+	// metrics, if set, receives the measures of each document decoded.
+	metrics Metrics
+	// ctx, if set, stops the conversion once it is done. converted counts
+	// the values converted, for checkContext.
+	ctx       context.Context
+	converted int
+
+	// foundFields and keyPaths are set by a Converter, which keeps them from
+	// one conversion to the next: foundFields caches what findField returns,
+	// and keyPaths allocates the paths of the values converted.
+	foundFields map[foundFieldKey]foundField
+	keyPaths    *keyPaths
+
+	// fieldPositions receives the positions of the values decoded into struct
+	// fields, which are looked up in sourcePositions.
+	fieldPositions  map[string]Position
+	sourcePositions map[string]Position
+
+	// unknownFields collects the keys that match no struct field while
+	// converting with strictFields set.
+	unknownFields []UnknownField
+	// missingFields collects the paths of the required fields that have no
+	// key.
+	missingFields []string
+	// undecoded is set if the conversion leaves parts of the document for
+	// fillUndecoded.
+	undecoded bool
+}
+
+// decodeOption is an option of this package that configures the conversion
+// from YAML to JSON rather than the json.Decoder. It is passed to the
+// functions of the package within the JSONOpt returned by decodeOpt.
+type decodeOption func(*decodeOptions)
+
+// optionSinks maps the json.Decoders that applyJSONOpt is applying an option
+// to, to the optionSinks that the options returned by decodeOpt record their
+// settings in.
+var optionSinks sync.Map
+
+// optionSink receives the settings of an option returned by decodeOpt.
+type optionSink struct {
+	do  *decodeOptions
+	set bool
+}
+
+// decodeOpt returns the JSONOpt that carries o. The JSONOpt applies o when
+// applied by applyJSONOpt, and returns the json.Decoder it is applied to
+// unchanged.
+func decodeOpt(o decodeOption) JSONOpt {
+	return func(d *json.Decoder) *json.Decoder {
+		if s, ok := optionSinks.Load(d); ok {
+			s := s.(*optionSink)
+			o(s.do)
+			s.set = true
+		}
+		return d
+	}
+}
+
+// applyJSONOpt applies opt to d and returns the decoder it returns, and
+// whether opt carries a decodeOption, which is applied to do.
+func applyJSONOpt(opt JSONOpt, d *json.Decoder, do *decodeOptions) (*json.Decoder, bool) {
+	s := &optionSink{do: do}
+	optionSinks.Store(d, s)
+	defer optionSinks.Delete(d)
+	return opt(d), s.set
+}
+
+// CaseSensitive configures the conversion to error out if a key of a YAML
+// mapping only matches the name of a struct field when ignoring case, instead
+// of decoding it into the field the way encoding/json does.
+func CaseSensitive() JSONOpt {
+	return decodeOpt(func(o *decodeOptions) {
+		o.caseSensitive = true
+	})
+}
+
 // Unmarshal converts YAML to JSON then uses JSON to unmarshal into an object,
 // optionally configuring the behavior of the JSON unmarshal.
 func Unmarshal(y []byte, o interface{}, opts ...JSONOpt) error {
+	if len(opts) == 0 && fastUnmarshal(y, o) {
+		return nil
+	}
 	return unmarshal(yaml.Unmarshal, y, o, opts)
 }
 
 // UnmarshalStrict is like Unmarshal except that any mapping keys that are
 // duplicates will result in an error.
-// To also be strict about unknown fields, add the DisallowUnknownFields option.
+// To also be strict about unknown fields, add the DisallowUnknownFields option,
+// or StrictFields to report all of them along with their paths.
 func UnmarshalStrict(y []byte, o interface{}, opts ...JSONOpt) error {
 	return unmarshal(yaml.UnmarshalStrict, y, o, opts)
 }
 
 func unmarshal(f func(in []byte, out interface{}) (err error), y []byte, o interface{}, opts []JSONOpt) error {
+	var j bytes.Buffer
+	d, do := newJSONDecoder(&j, opts)
+	newDecoder := func(r io.Reader) *json.Decoder {
+		d, _ := newJSONDecoder(r, opts)
+		return d
+	}
+	return decode(f, y, o, &j, d, do, newDecoder)
+}
+
+// decode unmarshals y into o with the decoder d, which reads the JSON
+// document converted from y once it is written to j. newDecoder returns
+// decoders with the same options as d.
+func decode(f func(in []byte, out interface{}) (err error), y []byte, o interface{}, j *bytes.Buffer, d *json.Decoder, do *decodeOptions, newDecoder func(io.Reader) *json.Decoder) (err error) {
+	if do.metrics != nil {
+		defer func(start time.Time) {
+			recordMetrics(do.metrics, "unmarshal", start, len(y), err)
+		}(time.Now())
+	}
+	if do.validate != nil {
+		if err := do.validate(y); err != nil {
+			return err
+		}
+	}
+
 	vo := reflect.ValueOf(o)
-	j, err := yamlToJSON(y, &vo, f)
+	jsonObj, err := yamlToJSONObject(y, &vo, f, do)
+	switch err.(type) {
+	case *TabIndentError, *DuplicateAnchorError:
+		return err
+	}
+	if do.ctx != nil && do.ctx.Err() != nil {
+		return do.ctx.Err()
+	}
+	if err != nil {
+		return fmt.Errorf("error converting YAML to JSON: %v", err)
+	}
+	if len(do.unknownFields) > 0 {
+		return newUnknownFieldsError(do.unknownFields)
+	}
+	if len(do.missingFields) > 0 {
+		return newMissingFieldsError(do.missingFields)
+	}
+	done := do.traceStart(TraceDecode)
+	err = decodeJSONObject(jsonObj, o, vo, j, d, do, newDecoder)
+	done(err)
+	return err
+}
+
+// decodeJSONObject does the work of decode once the document is converted to
+// jsonObj, decoding it into o, whose reflect.Value is vo.
+func decodeJSONObject(jsonObj, o interface{}, vo reflect.Value, j *bytes.Buffer, d *json.Decoder, do *decodeOptions, newDecoder func(io.Reader) *json.Decoder) error {
+	// Without a codec, the document is encoded straight into j, which d
+	// reads, rather than into a slice that is then copied.
+	var converted []byte
+	var err error
+	if do.codec != nil {
+		converted, err = do.codec.Marshal(jsonObj)
+	} else {
+		err = json.NewEncoder(j).Encode(jsonObj)
+	}
 	if err != nil {
 		return fmt.Errorf("error converting YAML to JSON: %v", err)
 	}
 
-	err = jsonUnmarshal(bytes.NewReader(j), o, opts...)
+	decode := func(b []byte, v interface{}) error {
+		return jsonUnmarshal(newDecoder(bytes.NewReader(b)), v)
+	}
+	if do.codec != nil {
+		decode = do.codec.Unmarshal
+		err = decode(converted, o)
+	} else {
+		err = jsonUnmarshal(d, o)
+	}
 	if err != nil {
 		return fmt.Errorf("error unmarshaling JSON: %v", err)
 	}
 
+	if do.undecoded {
+		if err := fillUndecoded(vo, jsonObj, decode); err != nil {
+			return fmt.Errorf("error unmarshaling JSON: %v", err)
+		}
+	}
+
 	return nil
 }
 
-// jsonUnmarshal unmarshals the JSON byte stream from the given reader into the
-// object, optionally applying decoder options prior to decoding.  We are not
-// using json.Unmarshal directly as we want the chance to pass in non-default
-// options.
-func jsonUnmarshal(r io.Reader, o interface{}, opts ...JSONOpt) error {
+// newJSONDecoder returns a JSON decoder reading from r with the given options
+// applied, along with the settings of those options that configure the
+// conversion from YAML to JSON.
+func newJSONDecoder(r io.Reader, opts []JSONOpt) (*json.Decoder, *decodeOptions) {
 	d := json.NewDecoder(r)
+	do := &decodeOptions{}
 	for _, opt := range opts {
-		d = opt(d)
+		d, _ = applyJSONOpt(opt, d, do)
 	}
+	return d, do
+}
+
+// jsonUnmarshal unmarshals the JSON byte stream read by the given decoder into
+// the object. We are not using json.Unmarshal directly as we want the chance
+// to pass in non-default options.
+func jsonUnmarshal(d *json.Decoder, o interface{}) error {
 	if err := d.Decode(&o); err != nil {
 		return fmt.Errorf("while decoding JSON: %v", err)
 	}
@@ -92,7 +334,11 @@ func JSONToYAML(j []byte) ([]byte, error) {
 	// etc.) when unmarshalling to interface{}, it just picks float64
 	// universally. go-yaml does go through the effort of picking the right
 	// number type, so we can preserve number type throughout this process.
-	err := yaml.Unmarshal(j, &jsonObj)
+	j, err := toUTF8(j)
+	if err != nil {
+		return nil, err
+	}
+	err = yaml.Unmarshal(j, &jsonObj)
 	if err != nil {
 		return nil, err
 	}
@@ -114,48 +360,173 @@ func JSONToYAML(j []byte) ([]byte, error) {
 //
 // For strict decoding of YAML, use YAMLToJSONStrict.
 func YAMLToJSON(y []byte) ([]byte, error) {
-	return yamlToJSON(y, nil, yaml.Unmarshal)
+	return yamlToJSON(y, nil, yaml.Unmarshal, &decodeOptions{})
+}
+
+// YAMLToJSONWithOpts is like YAMLToJSON but applies the options among opts
+// that configure the conversion, such as AmbiguousNumbersAsStrings.
+func YAMLToJSONWithOpts(y []byte, opts ...JSONOpt) ([]byte, error) {
+	_, do := newJSONDecoder(bytes.NewReader(nil), opts)
+	return yamlToJSON(y, nil, yaml.Unmarshal, do)
 }
 
 // YAMLToJSONStrict is like YAMLToJSON but enables strict YAML decoding,
 // returning an error on any duplicate field names.
 func YAMLToJSONStrict(y []byte) ([]byte, error) {
-	return yamlToJSON(y, nil, yaml.UnmarshalStrict)
+	return yamlToJSON(y, nil, yaml.UnmarshalStrict, &decodeOptions{})
+}
+
+func yamlToJSON(y []byte, jsonTarget *reflect.Value, yamlUnmarshal func([]byte, interface{}) error, opts *decodeOptions) ([]byte, error) {
+	jsonObj, err := yamlToJSONObject(y, jsonTarget, yamlUnmarshal, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert this object to JSON and return the data.
+	return json.Marshal(jsonObj)
 }
 
-func yamlToJSON(y []byte, jsonTarget *reflect.Value, yamlUnmarshal func([]byte, interface{}) error) ([]byte, error) {
+// yamlToJSONObject is like yamlToJSON but returns the JSON-compatible object
+// instead of encoding it.
+func yamlToJSONObject(y []byte, jsonTarget *reflect.Value, yamlUnmarshal func([]byte, interface{}) error, opts *decodeOptions) (interface{}, error) {
+	y, err := toUTF8(y)
+	if err != nil {
+		return nil, err
+	}
+	jsonObj, err := convertYAML(y, jsonTarget, yamlUnmarshal, opts)
+	return jsonObj, tabIndentError(y, err)
+}
+
+// convertYAML does the work of yamlToJSONObject on y, which is in UTF-8.
+func convertYAML(y []byte, jsonTarget *reflect.Value, yamlUnmarshal func([]byte, interface{}) error, opts *decodeOptions) (interface{}, error) {
+	var err error
+	if opts.uniqueAnchors {
+		if err := checkAnchors(y); err != nil {
+			return nil, err
+		}
+	}
+	if opts.fieldPositions != nil {
+		if opts.sourcePositions, err = sourcePositions(y, (*keyPath).String); err != nil {
+			return nil, err
+		}
+	}
+	if opts.includes != nil {
+		if y, err = opts.includes.include(y); err != nil {
+			return nil, err
+		}
+	}
+	if opts.lookupEnv != nil {
+		if y, err = opts.expandEnv(y); err != nil {
+			return nil, err
+		}
+	}
+	if len(opts.nodeHooks) > 0 {
+		if y, err = opts.applyNodeHooks(y); err != nil {
+			return nil, err
+		}
+	}
+	if len(opts.tagFuncs) > 0 {
+		if y, err = opts.markTags(y); err != nil {
+			return nil, err
+		}
+	}
+
 	// Convert the YAML to an object.
 	var yamlObj interface{}
-	err := yamlUnmarshal(y, &yamlObj)
-	if err != nil {
+	precise := jsonTarget != nil && jsonTarget.IsValid() && typeHasBigNumbers(jsonTarget.Type())
+	opts.textScalars = jsonTarget != nil && jsonTarget.IsValid() && len(opts.tagFuncs) == 0 && typeHasTextScalars(jsonTarget.Type())
+	done := opts.traceStart(TraceParse)
+	if precise || opts.textScalars || opts.resolvesScalars() {
+		var t textYAML
+		if err := yamlUnmarshal(y, &t); err != nil {
+			done(err)
+			return nil, err
+		}
+		opts.precise = precise
+		yamlObj = opts.resolveScalars(t.v, precise)
+	} else if err := yamlUnmarshal(y, &yamlObj); err != nil {
+		done(err)
 		return nil, err
 	}
+	done(nil)
+	if yamlObj == nil && jsonTarget != nil && jsonTarget.IsValid() && emptyStructDocument(y, jsonTarget.Type()) {
+		yamlObj = map[interface{}]interface{}{}
+	}
+	if len(opts.tagFuncs) > 0 {
+		if yamlObj, err = opts.applyTags(yamlObj); err != nil {
+			return nil, err
+		}
+	}
 
 	// YAML objects are not completely compatible with JSON objects (e.g. you
 	// can have non-string keys in YAML). So, convert the YAML-compatible object
 	// to a JSON-compatible object, failing with an error if irrecoverable
 	// incompatibilities happen along the way.
-	jsonObj, err := convertToJSONableObject(yamlObj, jsonTarget)
-	if err != nil {
-		return nil, err
-	}
-
-	// Convert this object to JSON and return the data.
-	return json.Marshal(jsonObj)
+	done = opts.traceStart(TraceConvert)
+	jsonObj, err := convertToJSONableObject(yamlObj, jsonTarget, opts, nil)
+	done(err)
+	return jsonObj, err
 }
 
-func convertToJSONableObject(yamlObj interface{}, jsonTarget *reflect.Value) (interface{}, error) {
+// convertToJSONableObject converts yamlObj, found at path in the document, to
+// a JSON-compatible object.
+func convertToJSONableObject(yamlObj interface{}, jsonTarget *reflect.Value, opts *decodeOptions, path *keyPath) (interface{}, error) {
+	if err := opts.checkContext(); err != nil {
+		return nil, err
+	}
 	var err error
 
 	// Resolve jsonTarget to a concrete value (i.e. not a pointer or an
 	// interface). We pass decodingNull as false because we're not actually
 	// decoding into the value, we're just checking if the ultimate target is a
 	// string.
+	if s, ok := yamlObj.(yamlScalar); ok {
+		if jsonTarget != nil && textScalarType(jsonTarget.Type()) {
+			return s.text, nil
+		}
+		yamlObj = opts.resolveScalar(s, opts.precise)
+	}
 	if jsonTarget != nil {
+		if len(opts.hooks) > 0 {
+			var done bool
+			yamlObj, done, err = opts.applyHooks(yamlObj, jsonTarget.Type(), path)
+			if err != nil || done {
+				return yamlObj, err
+			}
+		}
+		if obj, ok, err := opts.yamlUnmarshaled(yamlObj, jsonTarget.Type()); ok {
+			return obj, path.wrap(err)
+		}
+		if obj, ok, err := opts.standardValue(yamlObj, jsonTarget.Type()); ok {
+			return obj, path.wrap(err)
+		}
+
 		ju, tu, pv := indirect(*jsonTarget, false)
+		if ju == nil && tu == nil && pv.Kind() == reflect.Interface && yamlObj != nil {
+			t, err := opts.concreteType(yamlObj, pv.Type())
+			if err != nil {
+				return nil, path.wrap(err)
+			}
+			if t != nil {
+				cv := reflect.New(t).Elem()
+				obj, err := convertToJSONableObject(yamlObj, &cv, opts, path)
+				if err != nil {
+					return nil, err
+				}
+				opts.undecoded = true
+				return unionObject{obj, t}, nil
+			}
+		}
 		// We have a JSON or Text Umarshaler at this level, so we can't be trying
 		// to decode into a string.
 		if ju != nil || tu != nil {
+			// A TextUnmarshaler, such as big.Float, is decoded from a JSON
+			// string, so numbers are passed to it as the text of a string.
+			if ju == nil {
+				if s, ok := numberText(yamlObj); ok {
+					return s, nil
+				}
+			}
 			jsonTarget = nil
 		} else {
 			jsonTarget = &pv
@@ -176,40 +547,16 @@ func convertToJSONableObject(yamlObj interface{}, jsonTarget *reflect.Value) (in
 		// keys can only have the types string, int, int64, float64, binary
 		// (unsupported), or null (unsupported).
 		strMap := make(map[string]interface{})
+		var rest map[string]interface{}
+		var inline map[*field]map[interface{}]interface{}
+		var set map[string]bool // the fields with a key, if any have defaults or are required
+		if jsonTarget != nil && jsonTarget.Kind() == reflect.Struct && checksMissingKeys(jsonTarget.Type()) {
+			set = make(map[string]bool)
+		}
 		for k, v := range typedYAMLObj {
 			// Resolve the key to a string first.
-			var keyString string
-			switch typedKey := k.(type) {
-			case string:
-				keyString = typedKey
-			case int:
-				keyString = strconv.Itoa(typedKey)
-			case int64:
-				// go-yaml will only return an int64 as a key if the system
-				// architecture is 32-bit and the key's value is between 32-bit
-				// and 64-bit. Otherwise the key type will simply be int.
-				keyString = strconv.FormatInt(typedKey, 10)
-			case float64:
-				// Stolen from go-yaml to use the same conversion to string as
-				// the go-yaml library uses to convert float to string when
-				// Marshaling.
-				s := strconv.FormatFloat(typedKey, 'g', -1, 32)
-				switch s {
-				case "+Inf":
-					s = ".inf"
-				case "-Inf":
-					s = "-.inf"
-				case "NaN":
-					s = ".nan"
-				}
-				keyString = s
-			case bool:
-				if typedKey {
-					keyString = "true"
-				} else {
-					keyString = "false"
-				}
-			default:
+			keyString, ok := jsonKeyString(k)
+			if !ok {
 				return nil, fmt.Errorf("Unsupported map key of type: %s, key: %+#v, value: %+#v",
 					reflect.TypeOf(k), k, v)
 			}
@@ -222,60 +569,118 @@ func convertToJSONableObject(yamlObj interface{}, jsonTarget *reflect.Value) (in
 			if jsonTarget != nil {
 				t := *jsonTarget
 				if t.Kind() == reflect.Struct {
-					keyBytes := []byte(keyString)
 					// Find the field that the JSON library would use.
-					var f *field
-					fields := cachedTypeFields(t.Type())
-					for i := range fields {
-						ff := &fields[i]
-						if bytes.Equal(ff.nameBytes, keyBytes) {
-							f = ff
-							break
-						}
-						// Do case-insensitive comparison.
-						if f == nil && ff.equalFold(ff.nameBytes, keyBytes) {
-							f = ff
-						}
-					}
+					f, jsonKey := findField(t.Type(), keyString, opts)
 					if f != nil {
+						if opts.caseSensitive {
+							if name := fieldKey(f, opts.tags, opts.fieldNaming); name != keyString {
+								return nil, fmt.Errorf("key %q does not match the case of field %q", keyString, name)
+							}
+						}
 						// Find the reflect.Value of the most preferential
 						// struct field.
 						jtf := t.Field(f.index[0])
-						strMap[keyString], err = convertToJSONableObject(v, &jtf)
+						opts.recordField(path.key(keyString))
+						if set != nil {
+							set[f.name] = true
+						}
+						strMap[jsonKey], err = convertToJSONableObject(v, &jtf, opts, opts.pathKey(path, keyString))
+						if err != nil {
+							return nil, err
+						}
+						if f.quoted {
+							strMap[jsonKey] = quotedValue(strMap[jsonKey], f.typ)
+						}
+						continue
+					}
+					if jsonKey == "" {
+						opts.unknownField(t.Type(), path.key(keyString))
+						continue
+					}
+					// Gather the keys of inline fields to convert them
+					// along with the rest of their fields.
+					if g := inlineField(t.Type(), keyString, opts); g != nil {
+						if inline == nil {
+							inline = make(map[*field]map[interface{}]interface{})
+						}
+						if inline[g] == nil {
+							inline[g] = make(map[interface{}]interface{})
+						}
+						inline[g][k] = v
+						continue
+					}
+					// Keep keys that match no field for the rest field, if
+					// the struct has one.
+					if index := restField(t.Type()); index != nil {
+						if rest == nil {
+							rest = make(map[string]interface{})
+						}
+						jtv := reflect.Zero(t.Type().FieldByIndex(index).Type.Elem())
+						rest[keyString], err = convertToJSONableObject(v, &jtv, opts, opts.pathKey(path, keyString))
 						if err != nil {
 							return nil, err
 						}
 						continue
 					}
+					opts.unknownField(t.Type(), path.key(keyString))
 				} else if t.Kind() == reflect.Map {
+					if err := checkMapKey(keyString, t.Type().Key()); err != nil {
+						return nil, path.wrap(err)
+					}
 					// Create a zero value of the map's element type to use as
 					// the JSON target.
 					jtv := reflect.Zero(t.Type().Elem())
-					strMap[keyString], err = convertToJSONableObject(v, &jtv)
+					strMap[keyString], err = convertToJSONableObject(v, &jtv, opts, opts.pathKey(path, keyString))
 					if err != nil {
 						return nil, err
 					}
 					continue
 				}
 			}
-			strMap[keyString], err = convertToJSONableObject(v, nil)
+			strMap[keyString], err = convertToJSONableObject(v, nil, opts, opts.pathKey(path, keyString))
 			if err != nil {
 				return nil, err
 			}
 		}
+		if set != nil {
+			opts.checkRequired(jsonTarget.Type(), set, path)
+			if err := opts.applyDefaults(strMap, jsonTarget.Type(), set, path); err != nil {
+				return nil, err
+			}
+		}
+		for g, obj := range inline {
+			jtf := (*jsonTarget).Field(g.index[0])
+			strMap[g.name], err = convertToJSONableObject(obj, &jtf, opts, path)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if rest != nil {
+			opts.undecoded = true
+			return restObject{strMap, rest}, nil
+		}
+		if jsonTarget != nil && jsonTarget.Kind() == reflect.Map && jsonKeyUnsupported(jsonTarget.Type().Key()) {
+			opts.undecoded = true
+			return mapObject(strMap), nil
+		}
 		return strMap, nil
 	case []interface{}:
 		// We need to recurse into arrays in case there are any
 		// map[interface{}]interface{}'s inside and to convert any
 		// numbers to strings.
 
-		// If jsonTarget is a slice (which it really should be), find the
-		// thing it's going to map to. If it's not a slice, just pass nil
-		// - JSON conversion will error for us if it's a real issue.
+		// If jsonTarget is a slice or an array (which it really should be),
+		// find the thing it's going to map to. If it's neither, just pass
+		// nil - JSON conversion will error for us if it's a real issue.
 		var jsonSliceElemValue *reflect.Value
 		if jsonTarget != nil {
 			t := *jsonTarget
-			if t.Kind() == reflect.Slice {
+			if t.Kind() == reflect.Array {
+				if err := opts.checkArrayLength(len(typedYAMLObj), t.Type()); err != nil {
+					return nil, path.wrap(err)
+				}
+			}
+			if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
 				// By default slices point to nil, but we need a reflect.Value
 				// pointing to a value of the slice type, so we create one here.
 				ev := reflect.Indirect(reflect.New(t.Type().Elem()))
@@ -286,13 +691,42 @@ func convertToJSONableObject(yamlObj interface{}, jsonTarget *reflect.Value) (in
 		// Make and use a new array.
 		arr := make([]interface{}, len(typedYAMLObj))
 		for i, v := range typedYAMLObj {
-			arr[i], err = convertToJSONableObject(v, jsonSliceElemValue)
+			arr[i], err = convertToJSONableObject(v, jsonSliceElemValue, opts, opts.pathIndex(path, i))
 			if err != nil {
 				return nil, err
 			}
 		}
 		return arr, nil
 	default:
+		// encoding/json decodes numbers into an interface{} as a float64,
+		// so integers that a float64 cannot hold are decoded as a
+		// json.Number by fillUndecoded instead.
+		if jsonTarget == nil || jsonTarget.Kind() == reflect.Interface && jsonTarget.NumMethod() == 0 {
+			if n, ok := inexactInteger(yamlObj); ok {
+				opts.undecoded = true
+				return n, nil
+			}
+		}
+		if jsonTarget != nil && opts.strictScalars {
+			if err := checkScalar(yamlObj, jsonTarget.Type()); err != nil {
+				return nil, path.wrap(err)
+			}
+		}
+		if jsonTarget != nil && opts.weaklyTyped && yamlObj != nil {
+			if weakSlice(jsonTarget.Type()) {
+				return convertToJSONableObject([]interface{}{yamlObj}, jsonTarget, opts, path)
+			}
+			yamlObj, err = weakScalar(yamlObj, jsonTarget.Type())
+			if err != nil {
+				return nil, path.wrap(err)
+			}
+		}
+
+		if f, ok := yamlObj.(float64); ok && (math.IsNaN(f) || math.IsInf(f, 0)) && (jsonTarget == nil || (*jsonTarget).Kind() != reflect.String) {
+			v, err := opts.nonFinite.value(f)
+			return v, path.wrap(err)
+		}
+
 		// If the target type is a string and the YAML type is a number,
 		// convert the YAML type to a string.
 		if jsonTarget != nil && (*jsonTarget).Kind() == reflect.String {
@@ -308,6 +742,8 @@ func convertToJSONableObject(yamlObj interface{}, jsonTarget *reflect.Value) (in
 				s = strconv.FormatFloat(typedVal, 'g', -1, 32)
 			case uint64:
 				s = strconv.FormatUint(typedVal, 10)
+			case json.Number:
+				s = string(typedVal)
 			case bool:
 				if typedVal {
 					s = "true"
@@ -321,6 +757,137 @@ func convertToJSONableObject(yamlObj interface{}, jsonTarget *reflect.Value) (in
 		}
 		return yamlObj, nil
 	}
+}
 
-	return nil, nil
+// quotedValue returns v, which is decoded into a field of type t with the
+// ",string" option, in the form encoding/json expects: a JSON string holding
+// the JSON encoding of the value. YAML does not tell quoted and unquoted
+// scalars apart, so both "5" and 5 decode into an int field this way.
+func quotedValue(v interface{}, t reflect.Type) interface{} {
+	if t.Name() == "" && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch x := v.(type) {
+	case int, int64, uint64, float64, bool:
+		switch t.Kind() {
+		case reflect.Bool,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+			reflect.Float32, reflect.Float64:
+			b, err := json.Marshal(x)
+			if err == nil {
+				return string(b)
+			}
+		}
+	case string:
+		// A string field expects its value quoted once more, unless the
+		// document already holds it that way.
+		var s string
+		if t.Kind() == reflect.String && json.Unmarshal([]byte(x), &s) != nil {
+			b, _ := json.Marshal(x)
+			return string(b)
+		}
+	}
+	return v
+}
+
+// findField returns the field of the struct type t that the given key of a
+// YAML mapping decodes into, if any, along with the key to give it in the JSON
+// document. An empty key means the key belongs to a field the options leave
+// out and must be dropped.
+func findField(t reflect.Type, key string, opts *decodeOptions) (*field, string) {
+	if opts.foundFields == nil {
+		return lookupField(t, key, opts)
+	}
+	k := foundFieldKey{t, key}
+	if found, ok := opts.foundFields[k]; ok {
+		return found.f, found.jsonKey
+	}
+	f, jsonKey := lookupField(t, key, opts)
+	// Keys that match no field are not cached, nor are keys past the limit,
+	// so that documents with ever new keys do not grow the cache.
+	if f != nil && len(opts.foundFields) < maxFoundFields {
+		opts.foundFields[k] = foundField{f, jsonKey}
+	}
+	return f, jsonKey
+}
+
+// lookupField does the work of findField.
+func lookupField(t reflect.Type, key string, opts *decodeOptions) (*field, string) {
+	keyBytes := []byte(key)
+	var f *field
+	jsonKey := key
+	fields := cachedTypeFields(t)
+	for i := range fields {
+		ff := &fields[i]
+		if ff.yamlInline {
+			// Inline fields are matched by the keys of their own fields.
+			continue
+		}
+		if skipField(ff, opts.tags) {
+			if f == nil && ff.equalFold(ff.nameBytes, keyBytes) {
+				jsonKey = ""
+			}
+			continue
+		}
+		if name := fieldKey(ff, opts.tags, opts.fieldNaming); name != ff.name {
+			// The key is matched against the name the options give the
+			// field, so the JSON document must use its JSON name instead.
+			if name == key {
+				return ff, ff.name
+			}
+			if f == nil && strings.EqualFold(name, key) {
+				f, jsonKey = ff, ff.name
+			}
+			continue
+		}
+		if bytes.Equal(ff.nameBytes, keyBytes) {
+			return ff, key
+		}
+		// Do case-insensitive comparison.
+		if f == nil && jsonKey != "" && ff.equalFold(ff.nameBytes, keyBytes) {
+			f, jsonKey = ff, key
+		}
+	}
+	return f, jsonKey
+}
+
+// jsonKeyString returns the key of a JSON object for the key k of a mapping
+// decoded by go-yaml. It returns false if JSON has no key for k.
+func jsonKeyString(k interface{}) (string, bool) {
+	var keyString string
+	switch typedKey := k.(type) {
+	case string:
+		keyString = typedKey
+	case int:
+		keyString = strconv.Itoa(typedKey)
+	case int64:
+		// go-yaml will only return an int64 as a key if the system
+		// architecture is 32-bit and the key's value is between 32-bit
+		// and 64-bit. Otherwise the key type will simply be int.
+		keyString = strconv.FormatInt(typedKey, 10)
+	case float64:
+		// Stolen from go-yaml to use the same conversion to string as
+		// the go-yaml library uses to convert float to string when
+		// Marshaling.
+		s := strconv.FormatFloat(typedKey, 'g', -1, 32)
+		switch s {
+		case "+Inf":
+			s = ".inf"
+		case "-Inf":
+			s = "-.inf"
+		case "NaN":
+			s = ".nan"
+		}
+		keyString = s
+	case bool:
+		if typedKey {
+			keyString = "true"
+		} else {
+			keyString = "false"
+		}
+	default:
+		return "", false
+	}
+	return keyString, true
 }
@@ -1,6 +1,7 @@
 package yaml
 
 import (
+	"encoding/json"
 	"fmt"
 	"math"
 	"reflect"
@@ -427,3 +428,66 @@ foo: baz
 		t.Error("expected YAMLtoJSONStrict to fail on duplicate field names")
 	}
 }
+
+func TestCaseSensitive(t *testing.T) {
+	type CaseTest struct {
+		Name  string `json:"name"`
+		Other string
+	}
+
+	var got CaseTest
+	if err := Unmarshal([]byte("name: a\nOther: b\n"), &got, CaseSensitive()); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	if want := (CaseTest{"a", "b"}); got != want {
+		t.Errorf("Unmarshal() = %+v; want %+v", got, want)
+	}
+
+	for _, y := range []string{"Name: a\n", "other: b\n"} {
+		if err := Unmarshal([]byte(y), &got, CaseSensitive()); err == nil {
+			t.Errorf("Unmarshal(%q) succeeded; want a case mismatch error", y)
+		}
+		if err := Unmarshal([]byte(y), &got); err != nil {
+			t.Errorf("Unmarshal(%q) without CaseSensitive = %v", y, err)
+		}
+	}
+}
+
+func TestStringTagOption(t *testing.T) {
+	type StringTag struct {
+		Count int     `json:"count,string"`
+		Ratio float64 `json:"ratio,string"`
+		On    bool    `json:"enabled,string"`
+		Name  string  `json:"name,string"`
+		Ptr   *int    `json:"ptr,string"`
+	}
+	three := 3
+	want := StringTag{5, 1.5, true, "abc", &three}
+
+	y, err := Marshal(want)
+	if err != nil {
+		t.Fatalf("Marshal() = %v", err)
+	}
+	j, err := json.Marshal(want)
+	if err != nil {
+		t.Fatalf("json.Marshal() = %v", err)
+	}
+	if jy, err := JSONToYAML(j); err != nil || string(y) != string(jy) {
+		t.Errorf("Marshal() = %#q; want %#q, %v", string(y), string(jy), err)
+	}
+
+	for _, in := range []string{
+		string(y),
+		"count: 5\nratio: 1.5\nenabled: true\nname: abc\nptr: 3\n",
+		"count: \"5\"\nratio: \"1.5\"\nenabled: \"true\"\nname: '\"abc\"'\nptr: \"3\"\n",
+	} {
+		var got StringTag
+		if err := Unmarshal([]byte(in), &got); err != nil {
+			t.Errorf("Unmarshal(%q) = %v", in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Unmarshal(%q) = %+v; want %+v", in, got, want)
+		}
+	}
+}
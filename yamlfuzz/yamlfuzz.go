@@ -0,0 +1,94 @@
+// Package yamlfuzz checks the conversions of the yaml package on fuzzed
+// inputs, for projects that fuzz their own use of it, as in:
+//
+//	func FuzzYAML(f *testing.F) {
+//		for _, seed := range yamlfuzz.Seeds() {
+//			f.Add(seed)
+//		}
+//		f.Fuzz(func(t *testing.T, data []byte) {
+//			if err := yamlfuzz.RoundTrip(data); err != nil {
+//				t.Fatal(err)
+//			}
+//		})
+//	}
+//
+// It is kept apart from the yaml package so that programs that do not fuzz
+// do not carry its seed corpus.
+package yamlfuzz
+
+import (
+	"embed"
+	"fmt"
+	"path"
+
+	"github.com/ghodss/yaml"
+)
+
+// Limits of the inputs that RoundTrip checks, so that fuzzers do not spend
+// their time on documents that are slow to convert because they are large.
+const (
+	maxBytes = 64 << 10
+	maxDepth = 64
+	maxNodes = 10000
+)
+
+// RoundTrip converts data to JSON with yaml.YAMLToJSON, converts that back
+// to YAML with yaml.JSONToYAML and to JSON again, and returns an error if a
+// conversion fails that should not or if the value changes on the way. It
+// also decodes data with yaml.Unmarshal. Bugs that make a conversion panic
+// are left to panic. Inputs that are not valid YAML pass, as do those larger
+// than 64 KiB, nested more than 64 levels deep or that hold more than 10000
+// nodes once their aliases are expanded, which are not converted.
+func RoundTrip(data []byte) error {
+	if len(data) > maxBytes {
+		return nil
+	}
+	s, err := yaml.Inspect(data)
+	if err != nil || s.MaxDepth > maxDepth || float64(s.Nodes())*s.AliasExpansion > maxNodes {
+		return nil
+	}
+
+	j, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil
+	}
+	y, err := yaml.JSONToYAML(j)
+	if err != nil {
+		return fmt.Errorf("JSONToYAML(%q) = %v", j, err)
+	}
+	j2, err := yaml.YAMLToJSON(y)
+	if err != nil {
+		return fmt.Errorf("YAMLToJSON(%q) = %v, after JSONToYAML(%q)", y, err, j)
+	}
+	if eq, err := yaml.Equal(j, j2); err != nil || !eq {
+		return fmt.Errorf("YAMLToJSON(JSONToYAML(%q)) = %q", j, j2)
+	}
+
+	// Unmarshal may disagree with YAMLToJSON on documents whose keys are
+	// the same once converted, such as 1 and "1", so it is only run for
+	// panics.
+	var v interface{}
+	yaml.Unmarshal(data, &v)
+	return nil
+}
+
+//go:embed seeds
+var seeds embed.FS
+
+// Seeds returns documents that exercise the features of YAML, to start the
+// corpus of a fuzzer with, such as one that calls RoundTrip.
+func Seeds() [][]byte {
+	entries, err := seeds.ReadDir("seeds")
+	if err != nil {
+		panic(err)
+	}
+	var docs [][]byte
+	for _, e := range entries {
+		doc, err := seeds.ReadFile(path.Join("seeds", e.Name()))
+		if err != nil {
+			panic(err)
+		}
+		docs = append(docs, doc)
+	}
+	return docs
+}
@@ -0,0 +1,35 @@
+package yamlfuzz
+
+import (
+	"testing"
+
+	"github.com/ghodss/yaml"
+)
+
+func FuzzYAML(f *testing.F) {
+	for _, seed := range Seeds() {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if err := RoundTrip(data); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestSeeds(t *testing.T) {
+	seeds := Seeds()
+	if len(seeds) < 10 {
+		t.Errorf("Seeds() returned %d seeds, want at least 10", len(seeds))
+	}
+	converted := 0
+	for _, s := range seeds {
+		if _, err := yaml.YAMLToJSON(s); err == nil {
+			converted++
+		}
+	}
+	// Some seeds are for the errors of the conversion.
+	if converted <= len(seeds)/2 {
+		t.Errorf("%d of %d seeds convert to JSON", converted, len(seeds))
+	}
+}
@@ -0,0 +1,187 @@
+package yaml
+
+import (
+	"fmt"
+	"reflect"
+
+	"gopkg.in/yaml.v2"
+	yaml3 "gopkg.in/yaml.v3"
+)
+
+// Types written for go-yaml may marshal and unmarshal themselves with the
+// MarshalYAML and UnmarshalYAML methods of gopkg.in/yaml.v2 and
+// gopkg.in/yaml.v3, which this package ignores by default, going through
+// their json tags and JSON methods instead. The YAMLMarshalers and
+// DecodeYAMLUnmarshalers options make it call those methods, so that such
+// types keep working in code bases that move to this package.
+
+var (
+	yamlMarshalerType    = reflect.TypeOf((*yaml.Marshaler)(nil)).Elem()
+	yamlUnmarshalerType  = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+	yaml3UnmarshalerType = reflect.TypeOf((*yaml3.Unmarshaler)(nil)).Elem()
+)
+
+// YAMLMarshalers makes Marshal write values that have a MarshalYAML method,
+// as yaml.v2 and yaml.v3 define it, as go-yaml writes the value the method
+// returns, in place of what their json tags or MarshalJSON method would
+// write. The value is still marshaled to JSON first, so it must be one that
+// encoding/json can marshal.
+func YAMLMarshalers() MarshalOpt {
+	return func(e *encoder) {
+		e.yamlMarshalers = true
+	}
+}
+
+// DecodeYAMLUnmarshalers makes Unmarshal decode values whose type has an
+// UnmarshalYAML method, as yaml.v2 or yaml.v3 define it, with the go-yaml
+// version the method is written for, in place of encoding/json. The part of
+// the document such a value is decoded from is first read as the rest of the
+// document is, so anchors are expanded and the positions the method sees are
+// not those of the document.
+func DecodeYAMLUnmarshalers() JSONOpt {
+	return decodeOpt(func(o *decodeOptions) {
+		o.yamlUnmarshalers = true
+	})
+}
+
+// yamlMarshaled returns what go-yaml writes for v if v has a MarshalYAML
+// method, as the values the encoder writes, and false if it has none.
+func yamlMarshaled(v reflect.Value) (interface{}, bool, error) {
+	for v.IsValid() {
+		if v.Type().Implements(yamlMarshalerType) {
+			if v.Kind() == reflect.Ptr && v.IsNil() {
+				return nil, false, nil
+			}
+			break
+		}
+		if v.Kind() != reflect.Ptr && v.CanAddr() && reflect.PtrTo(v.Type()).Implements(yamlMarshalerType) {
+			v = v.Addr()
+			break
+		}
+		if v.Kind() != reflect.Ptr && v.Kind() != reflect.Interface || v.IsNil() {
+			return nil, false, nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() || !v.CanInterface() {
+		return nil, false, nil
+	}
+
+	m, err := v.Interface().(yaml.Marshaler).MarshalYAML()
+	if err != nil {
+		return nil, true, err
+	}
+	var y []byte
+	switch m.(type) {
+	case yaml3.Node, *yaml3.Node:
+		y, err = yaml3.Marshal(m)
+	default:
+		y, err = yaml.Marshal(m)
+	}
+	if err != nil {
+		return nil, true, err
+	}
+	var doc yaml3.Node
+	if err := yaml3.Unmarshal(y, &doc); err != nil {
+		return nil, true, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, true, nil
+	}
+	obj, err := encoderValue(doc.Content[0])
+	return obj, true, err
+}
+
+// encoderValue converts the node n into the values decodeJSON returns, so
+// that the encoder can write it.
+func encoderValue(n *yaml3.Node) (interface{}, error) {
+	switch n.Kind {
+	case yaml3.AliasNode:
+		return encoderValue(n.Alias)
+	case yaml3.MappingNode:
+		m := make(yaml.MapSlice, 0, len(n.Content)/2)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, ok := sourceKey(n.Content[i])
+			if !ok {
+				return nil, fmt.Errorf("yaml: line %d: unsupported map key", n.Content[i].Line)
+			}
+			v, err := encoderValue(n.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			m = append(m, yaml.MapItem{Key: k, Value: v})
+		}
+		return m, nil
+	case yaml3.SequenceNode:
+		s := make([]interface{}, len(n.Content))
+		for i, c := range n.Content {
+			v, err := encoderValue(c)
+			if err != nil {
+				return nil, err
+			}
+			s[i] = v
+		}
+		return s, nil
+	}
+	if n.Style != 0 && n.Style != yaml3.TaggedStyle {
+		return n.Value, nil
+	}
+	var v interface{}
+	if err := yaml.Unmarshal([]byte(n.Value), &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// yamlObject is a value that is decoded by go-yaml. It is written to the JSON
+// document as null, and fillUndecoded decodes it once encoding/json is done.
+type yamlObject struct {
+	y  []byte
+	v3 bool
+}
+
+func (yamlObject) MarshalJSON() ([]byte, error) {
+	return []byte("null"), nil
+}
+
+// yamlUnmarshaled returns the yamlObject for yamlObj if values of type t are
+// decoded by go-yaml, and false if they are not.
+func (o *decodeOptions) yamlUnmarshaled(yamlObj interface{}, t reflect.Type) (interface{}, bool, error) {
+	if !o.yamlUnmarshalers {
+		return nil, false, nil
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	pt := reflect.PtrTo(t)
+	v3 := pt.Implements(yaml3UnmarshalerType)
+	if !v3 && !pt.Implements(yamlUnmarshalerType) {
+		return nil, false, nil
+	}
+	y, err := yaml.Marshal(yamlObj)
+	if err != nil {
+		return nil, true, err
+	}
+	o.undecoded = true
+	return yamlObject{y, v3}, true, nil
+}
+
+// fill decodes o into v, which it allocates if it is a nil pointer.
+func (o yamlObject) fill(v reflect.Value) error {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			if !v.CanSet() {
+				return nil
+			}
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	if !v.CanAddr() {
+		return nil
+	}
+	if o.v3 {
+		return yaml3.Unmarshal(o.y, v.Addr().Interface())
+	}
+	return yaml.Unmarshal(o.y, v.Addr().Interface())
+}
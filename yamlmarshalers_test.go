@@ -0,0 +1,142 @@
+package yaml
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+	yaml3 "gopkg.in/yaml.v3"
+)
+
+// logLevel is written by name for go-yaml.
+type logLevel int
+
+var logLevelNames = []string{"debug", "info", "warn"}
+
+func (l logLevel) MarshalYAML() (interface{}, error) {
+	return logLevelNames[l], nil
+}
+
+func (l *logLevel) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	for i, name := range logLevelNames {
+		if name == s {
+			*l = logLevel(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown level %q", s)
+}
+
+// hostList is a single host or a sequence of them for go-yaml.
+type hostList []string
+
+func (h hostList) MarshalYAML() (interface{}, error) {
+	if len(h) == 1 {
+		return h[0], nil
+	}
+	return []string(h), nil
+}
+
+func (h *hostList) UnmarshalYAML(n *yaml3.Node) error {
+	if n.Kind == yaml3.ScalarNode {
+		*h = hostList{n.Value}
+		return nil
+	}
+	return n.Decode((*[]string)(h))
+}
+
+// upstream is written as an ordered mapping for go-yaml.
+type upstream struct {
+	Name string
+	Port int
+}
+
+func (u upstream) MarshalYAML() (interface{}, error) {
+	return yaml.MapSlice{{Key: "port", Value: u.Port}, {Key: "name", Value: u.Name}, {Key: 1, Value: true}}, nil
+}
+
+type yamlMarshalersConfig struct {
+	Level    logLevel   `json:"level"`
+	Hosts    hostList   `json:"hosts"`
+	Backup   *hostList  `json:"backup"`
+	Upstream upstream   `json:"upstream"`
+	Levels   []logLevel `json:"levels"`
+}
+
+func TestYAMLMarshalers(t *testing.T) {
+	c := yamlMarshalersConfig{
+		Level:    2,
+		Hosts:    hostList{"a"},
+		Backup:   &hostList{"b", "c"},
+		Upstream: upstream{"api", 80},
+		Levels:   []logLevel{0, 1},
+	}
+	got, err := Marshal(c, YAMLMarshalers())
+	if err != nil {
+		t.Fatalf("Marshal() = %v", err)
+	}
+	want := `backup:
+- b
+- c
+hosts: a
+level: warn
+levels:
+- debug
+- info
+upstream:
+  "1": true
+  name: api
+  port: 80
+`
+	if string(got) != want {
+		t.Errorf("Marshal() =\n%s\nwant\n%s", got, want)
+	}
+	got, err = Marshal(c.Upstream, YAMLMarshalers(), SortKeys(KeyOrderDocument))
+	if err != nil || string(got) != "port: 80\nname: api\n\"1\": true\n" {
+		t.Errorf("Marshal() in document order = %q, %v", got, err)
+	}
+	if got, err := Marshal(c); err != nil || !strings.HasPrefix(string(got), "backup:\n- b\n- c\nhosts:\n- a\nlevel: 2\n") {
+		t.Errorf("Marshal() without YAMLMarshalers = %q, %v", got, err)
+	}
+}
+
+func TestDecodeYAMLUnmarshalers(t *testing.T) {
+	y := `level: warn
+hosts: a
+backup: [b, c]
+upstream: {name: api, port: 80}
+levels: [debug, info]
+`
+	var c yamlMarshalersConfig
+	if err := Unmarshal([]byte(y), &c, DecodeYAMLUnmarshalers()); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	want := yamlMarshalersConfig{
+		Level:    2,
+		Hosts:    hostList{"a"},
+		Backup:   &hostList{"b", "c"},
+		Upstream: upstream{"api", 80},
+		Levels:   []logLevel{0, 1},
+	}
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("Unmarshal() = %+v; want %+v", c, want)
+	}
+
+	var l logLevel
+	if err := Unmarshal([]byte("info"), &l, DecodeYAMLUnmarshalers()); err != nil || l != 1 {
+		t.Errorf("Unmarshal() into a logLevel = %v, %v", l, err)
+	}
+	err := Unmarshal([]byte("level: loud\n"), &c, DecodeYAMLUnmarshalers())
+	if err == nil || !strings.Contains(err.Error(), `unknown level "loud"`) {
+		t.Errorf("Unmarshal() of an unknown level = %v", err)
+	}
+	if err := Unmarshal([]byte("level: warn\n"), &c); err == nil {
+		t.Error("Unmarshal() without DecodeYAMLUnmarshalers decoded a name into an int")
+	}
+}
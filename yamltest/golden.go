@@ -0,0 +1,90 @@
+package yamltest
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ghodss/yaml"
+)
+
+// update is the flag that makes RequireGolden write the golden files. Tests
+// that import this package get it, and so must not define a flag of the same
+// name.
+var update = flag.Bool("update", false, "write the golden files of yamltest.RequireGolden")
+
+// RequireGolden fails t unless v, marshaled with yaml.Marshal and opts, holds
+// the same values as the golden file at path, such as
+// "testdata/config.golden.yaml". The values are compared with yaml.Equal, so
+// the file may be formatted, commented and ordered as its readers like, and
+// the failure lists the values that differ. Running the test with the -update
+// flag, as with "go test -run TestConfig -update", writes the file in place
+// of comparing it, creating its directory if it is missing.
+func RequireGolden(t testing.TB, path string, v interface{}, opts ...yaml.MarshalOpt) {
+	t.Helper()
+	y, err := yaml.Marshal(v, opts...)
+	if err != nil {
+		t.Fatalf("yaml.Marshal(%#v) = %v", v, err)
+		return
+	}
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("yamltest: %v", err)
+			return
+		}
+		if err := os.WriteFile(path, y, 0o644); err != nil {
+			t.Fatalf("yamltest: %v", err)
+		}
+		return
+	}
+
+	golden, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		t.Fatalf("yamltest: golden file %s does not exist; run the test with -update to write it", path)
+		return
+	} else if err != nil {
+		t.Fatalf("yamltest: %v", err)
+		return
+	}
+	changes, err := yaml.Diff(golden, y)
+	if err != nil {
+		t.Fatalf("yamltest: golden file %s: %v", path, err)
+		return
+	}
+	if len(changes) == 0 {
+		return
+	}
+	var b strings.Builder
+	for _, c := range changes {
+		p := c.Path
+		if p == "" {
+			p = "(document)"
+		}
+		if c.Document > 0 {
+			p = fmt.Sprintf("document %d: %s", c.Document, p)
+		}
+		switch c.Kind {
+		case yaml.Added:
+			fmt.Fprintf(&b, "\n\t%s: added %s", p, format(c.New))
+		case yaml.Removed:
+			fmt.Fprintf(&b, "\n\t%s: removed %s", p, format(c.Old))
+		default:
+			fmt.Fprintf(&b, "\n\t%s: %s, want %s", p, format(c.New), format(c.Old))
+		}
+	}
+	t.Fatalf("yamltest: the value differs from golden file %s; run the test with -update to write it:%s", path, b.String())
+}
+
+// format returns the JSON-compatible value v as JSON, which is also flow
+// YAML.
+func format(v interface{}) string {
+	j, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(j)
+}
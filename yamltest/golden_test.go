@@ -0,0 +1,58 @@
+package yamltest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRequireGolden(t *testing.T) {
+	defer func(u bool) { *update = u }(*update)
+	*update = false
+
+	cfg := config{Name: "web", Replicas: 3, Tags: []string{"a", "yes"}, Limits: &limits{CPU: "1"}}
+	path := filepath.Join(t.TempDir(), "golden", "config.yaml")
+
+	rec := &recorder{TB: t}
+	RequireGolden(rec, path, cfg)
+	if !strings.Contains(rec.failure, "does not exist") {
+		t.Errorf("RequireGolden() of a missing file failed with %q", rec.failure)
+	}
+
+	*update = true
+	RequireGolden(t, path, cfg)
+	*update = false
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("RequireGolden() with -update did not write the file: %v", err)
+	}
+	RequireGolden(t, path, cfg)
+
+	// The file is compared by its values, not its text.
+	edited := `# The configuration of the web server.
+name: web
+replicas: 3.0
+tags: [a, "yes"]
+limits: {cpu: "1", memory: 0}
+backends: null
+extra: null
+pair: [0, 0]
+ratio: 0
+scale: 0
+size: 0
+`
+	if err := os.WriteFile(path, []byte(edited), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	RequireGolden(t, path, cfg)
+
+	cfg.Replicas = 4
+	cfg.Tags = cfg.Tags[:1]
+	rec = &recorder{TB: t}
+	RequireGolden(rec, path, cfg)
+	for _, want := range []string{"differs from golden file", "replicas: 4, want 3", "tags[1]: removed \"yes\""} {
+		if !strings.Contains(rec.failure, want) {
+			t.Errorf("RequireGolden() failed with %q, want it to contain %q", rec.failure, want)
+		}
+	}
+}
@@ -0,0 +1,306 @@
+// Package yamltest checks that values survive the conversions of
+// github.com/ghodss/yaml, for the tests of the types that programs read from
+// and write to YAML.
+package yamltest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ghodss/yaml"
+)
+
+// RequireRoundTrip fails t unless v survives its round trips through YAML:
+// v is marshaled with yaml.Marshal and unmarshaled with yaml.Unmarshal into a
+// new value of its type, which must be deeply equal to v, and the YAML is
+// converted to JSON with yaml.YAMLToJSON, which must hold the value that
+// encoding/json marshals v to. Fields that are not marshaled, such as
+// unexported ones, must be zero for v to survive.
+func RequireRoundTrip(t testing.TB, v interface{}) {
+	t.Helper()
+	if v == nil {
+		t.Fatalf("yamltest: RequireRoundTrip of nil")
+		return
+	}
+	y, err := yaml.Marshal(v)
+	if err != nil {
+		t.Fatalf("yaml.Marshal(%#v) = %v", v, err)
+		return
+	}
+	p := reflect.New(reflect.TypeOf(v))
+	if err := yaml.Unmarshal(y, p.Interface()); err != nil {
+		t.Fatalf("yaml.Unmarshal() = %v, for:\n%s", err, y)
+		return
+	}
+	if got := p.Elem().Interface(); !reflect.DeepEqual(got, v) {
+		t.Fatalf("yaml.Unmarshal(yaml.Marshal(%#v)) = %#v, with:\n%s", v, got, y)
+		return
+	}
+
+	j, err := yaml.YAMLToJSON(y)
+	if err != nil {
+		t.Fatalf("yaml.YAMLToJSON() = %v, for:\n%s", err, y)
+		return
+	}
+	want, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("json.Marshal(%#v) = %v", v, err)
+		return
+	}
+	if eq, err := yaml.Equal(j, want); err != nil || !eq {
+		t.Fatalf("yaml.YAMLToJSON() = %s, want %s, for:\n%s", j, want, y)
+	}
+}
+
+// RequireRandomRoundTrips calls RequireRoundTrip with n values of the type of
+// sample, filled with Fill. The seed of the values is logged when one fails,
+// so that the failure can be reproduced with Fill.
+func RequireRandomRoundTrips(t testing.TB, sample interface{}, n int) {
+	t.Helper()
+	seed := time.Now().UnixNano()
+	r := rand.New(rand.NewSource(seed))
+	typ := reflect.TypeOf(sample)
+	for i := 0; i < n && !t.Failed(); i++ {
+		p := reflect.New(typ)
+		Fill(r, p.Interface())
+		RequireRoundTrip(t, p.Elem().Interface())
+		if t.Failed() {
+			t.Logf("yamltest: value %d of seed %d", i, seed)
+		}
+	}
+}
+
+// maxDepth is how deeply Fill and Value nest values, so that recursive types
+// end.
+const maxDepth = 4
+
+// Fill sets the value v points to to random values drawn from r, such as
+// to check that any value of a type survives RequireRoundTrip. Every field
+// and item is filled, from the values that YAML documents are likely to get
+// wrong: strings like "yes", "0x10" and "1e5" that go-yaml would read as
+// booleans and numbers unless quoted, strings with YAML syntax and line
+// breaks, and integers and floats at the limits of their types. Integers
+// are kept within the range that float64 holds exactly, since JSON numbers
+// are commonly decoded as float64, and the key "<<" is left out, since
+// go-yaml writes it as a merge key. Fields that encoding/json does not
+// marshal, the fields of unexported embedded structs, values of interface
+// types and values of types that marshal themselves, such as with
+// MarshalJSON, are left alone.
+func Fill(r *rand.Rand, v interface{}) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		panic(fmt.Sprintf("yamltest: Fill of non-pointer %T", v))
+	}
+	fill(r, rv.Elem(), 0, false)
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*interface{ MarshalText() ([]byte, error) })(nil)).Elem()
+)
+
+// fill sets v to random values, as Fill does. Empty slices and maps are only
+// made if omitEmpty is unset, since encoding/json leaves them out of a field
+// with the omitempty option.
+func fill(r *rand.Rand, v reflect.Value, depth int, omitEmpty bool) {
+	t := v.Type()
+	if t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) ||
+		reflect.PtrTo(t).Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType) {
+		return
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		v.SetBool(r.Intn(2) == 0)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		bits := t.Bits()
+		if bits > 53 {
+			bits = 53
+		}
+		v.SetInt(randomInt(r, bits))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		bits := t.Bits()
+		if bits > 53 {
+			bits = 53
+		}
+		v.SetUint(uint64(randomInt(r, bits+1)) & (1<<uint(bits) - 1))
+	case reflect.Float32:
+		v.SetFloat(float64(float32(randomFloat(r, math.MaxFloat32))))
+	case reflect.Float64:
+		v.SetFloat(randomFloat(r, math.MaxFloat64))
+	case reflect.String:
+		v.SetString(randomString(r))
+	case reflect.Ptr:
+		if depth >= maxDepth || r.Intn(4) == 0 {
+			return
+		}
+		p := reflect.New(t.Elem())
+		fill(r, p.Elem(), depth+1, false)
+		v.Set(p)
+	case reflect.Slice:
+		if depth >= maxDepth {
+			return
+		}
+		n := r.Intn(4)
+		if n == 0 && (omitEmpty || r.Intn(2) == 0) {
+			return
+		}
+		s := reflect.MakeSlice(t, n, n)
+		for i := 0; i < n; i++ {
+			fill(r, s.Index(i), depth+1, false)
+		}
+		v.Set(s)
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			fill(r, v.Index(i), depth+1, false)
+		}
+	case reflect.Map:
+		if depth >= maxDepth || t.Key().Kind() != reflect.String {
+			return
+		}
+		n := r.Intn(4)
+		if n == 0 && (omitEmpty || r.Intn(2) == 0) {
+			return
+		}
+		m := reflect.MakeMap(t)
+		for i := n; i > 0; i-- {
+			k := reflect.New(t.Key()).Elem()
+			k.SetString(randomKey(r))
+			e := reflect.New(t.Elem()).Elem()
+			fill(r, e, depth+1, false)
+			m.SetMapIndex(k, e)
+		}
+		v.Set(m)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" && !f.Anonymous || f.Tag.Get("json") == "-" {
+				continue
+			}
+			if fv := v.Field(i); fv.CanSet() {
+				fill(r, fv, depth+1, strings.Contains(f.Tag.Get("json"), ",omitempty"))
+			}
+		}
+	}
+}
+
+// randomInt returns a random integer that fits in bits bits, often one at
+// its limits.
+func randomInt(r *rand.Rand, bits int) int64 {
+	max := int64(1)<<uint(bits-1) - 1
+	switch r.Intn(6) {
+	case 0:
+		return max
+	case 1:
+		return -max - 1
+	case 2:
+		return 0
+	}
+	return r.Int63n(max) - r.Int63n(max)
+}
+
+// randomFloat returns a random finite float up to max, often an integer or
+// one of great or small magnitude.
+func randomFloat(r *rand.Rand, max float64) float64 {
+	var f float64
+	switch r.Intn(5) {
+	case 0:
+		f = float64(r.Intn(1000))
+	case 1:
+		f = max * r.Float64()
+	case 2:
+		f = r.Float64() * 1e-300
+	default:
+		f = r.NormFloat64() * 1000
+	}
+	if r.Intn(2) == 0 {
+		f = -f
+	}
+	return f
+}
+
+// trickyStrings are strings that YAML documents are likely to get wrong.
+var trickyStrings = []string{
+	"", " ", "yes", "no", "on", "off", "y", "n", "Y", "true", "False", "null",
+	"Null", "~", "0x1F", "0o17", "017", "0b101", "1e5", "1_000", "+1", "-1",
+	".5", "1.", ".inf", "-.Inf", ".nan", "2001-12-14", "2001-12-14t21:59:43.10-05:00",
+	"12:30:45", "- a", "a: b", "a #b", "#c", "? q", "'", "\"", "`", "@", "%",
+	"!tag", "&anchor", "*alias", "{", "}", "[", "]", ",", "|", ">", "---",
+	"...", "=", "<<", "multi\nline", "trailing\n", "\n", "\ttab", " lead",
+	"trail ", "é", "日本語", "😀", "\\", "a\\nb",
+}
+
+// randomString returns one of trickyStrings, or random text.
+func randomString(r *rand.Rand) string {
+	if r.Intn(2) == 0 {
+		return trickyStrings[r.Intn(len(trickyStrings))]
+	}
+	const chars = "abcxyz019 :#-'\"\n\t.,{}[]é日"
+	runes := []rune(chars)
+	var b strings.Builder
+	for i := r.Intn(12); i > 0; i-- {
+		b.WriteRune(runes[r.Intn(len(runes))])
+	}
+	return b.String()
+}
+
+// randomKey returns a random string for a key. go-yaml writes the key "<<"
+// unquoted, to be read back as a merge key, so it is left out.
+func randomKey(r *rand.Rand) string {
+	for {
+		if k := randomString(r); k != "<<" {
+			return k
+		}
+	}
+}
+
+// Value returns a random value of the kinds that yaml.Unmarshal decodes a
+// document into when given an interface{}: nil, bool, float64, string,
+// []interface{} and map[string]interface{}, nested up to four levels deep.
+// Its values are as Fill draws them.
+func Value(r *rand.Rand) interface{} {
+	return value(r, 0)
+}
+
+func value(r *rand.Rand, depth int) interface{} {
+	n := 4
+	if depth < maxDepth {
+		n = 6
+	}
+	switch r.Intn(n) {
+	case 0:
+		return nil
+	case 1:
+		return r.Intn(2) == 0
+	case 2:
+		return randomFloat(r, math.MaxFloat64)
+	case 3:
+		return randomString(r)
+	case 4:
+		s := make([]interface{}, r.Intn(4))
+		for i := range s {
+			s[i] = value(r, depth+1)
+		}
+		return s
+	}
+	m := map[string]interface{}{}
+	for i := r.Intn(4); i > 0; i-- {
+		m[randomKey(r)] = value(r, depth+1)
+	}
+	return m
+}
+
+// Document returns a random YAML document, written by yaml.Marshal, of a
+// value as Value returns, which yaml.Unmarshal decodes back into it.
+func Document(r *rand.Rand) []byte {
+	y, err := yaml.Marshal(Value(r))
+	if err != nil {
+		panic(fmt.Sprintf("yamltest: %v", err))
+	}
+	return y
+}
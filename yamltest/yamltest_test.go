@@ -0,0 +1,97 @@
+package yamltest
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ghodss/yaml"
+)
+
+type config struct {
+	Name     string             `json:"name"`
+	Enabled  bool               `json:"enabled,omitempty"`
+	Replicas int32              `json:"replicas"`
+	Size     uint64             `json:"size"`
+	Ratio    float64            `json:"ratio"`
+	Scale    float32            `json:"scale"`
+	Tags     []string           `json:"tags"`
+	Labels   map[string]string  `json:"labels,omitempty"`
+	Limits   *limits            `json:"limits,omitempty"`
+	Backends []backend          `json:"backends"`
+	Extra    map[string]*limits `json:"extra"`
+	Pair     [2]int8            `json:"pair"`
+	Ignored  string             `json:"-"`
+}
+
+type limits struct {
+	CPU    string `json:"cpu"`
+	Memory int64  `json:"memory"`
+}
+
+type backend struct {
+	limits
+	Address string `json:"address"`
+	Next    *backend
+}
+
+func TestRequireRandomRoundTrips(t *testing.T) {
+	RequireRandomRoundTrips(t, config{}, 300)
+}
+
+func TestFill(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	var c config
+	for i := 0; i < 20; i++ {
+		Fill(r, &c)
+	}
+	if c.Ignored != "" {
+		t.Errorf("Fill() set a field that is not marshaled: %q", c.Ignored)
+	}
+	if reflect.DeepEqual(c, config{}) {
+		t.Errorf("Fill() = %+v, want random values", c)
+	}
+}
+
+func TestDocument(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 300; i++ {
+		want := Value(r)
+		y, err := yaml.Marshal(want)
+		if err != nil {
+			t.Fatalf("Marshal(%#v) = %v", want, err)
+		}
+		var got interface{}
+		if err := yaml.Unmarshal(y, &got); err != nil {
+			t.Fatalf("Unmarshal(%q) = %v", y, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("Unmarshal(%q) = %#v, want %#v", y, got, want)
+		}
+	}
+	if len(Document(r)) == 0 {
+		t.Errorf("Document() is empty")
+	}
+}
+
+// recorder is a testing.TB that records the failure of a test.
+type recorder struct {
+	testing.TB
+	failure string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.failure = fmt.Sprintf(format, args...)
+}
+
+func TestRequireRoundTripFailure(t *testing.T) {
+	rec := &recorder{TB: t}
+	RequireRoundTrip(rec, config{Name: "web", Ignored: "lost"})
+	if !strings.Contains(rec.failure, "yaml.Unmarshal(yaml.Marshal(") {
+		t.Errorf("RequireRoundTrip() of a value that loses a field failed with %q", rec.failure)
+	}
+}